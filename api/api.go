@@ -129,6 +129,7 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 	router.Route("/ingest", func(ingestRouter chi.Router) {
 		ingestRouter.Get("/{maskID}", a.HandleCrcCheck)
 		ingestRouter.Post("/{maskID}", a.IngestEvent)
+		ingestRouter.Post("/{maskID}/batch", a.IngestEventBatch)
 	})
 
 	// Public API.
@@ -147,6 +148,7 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 					projectSubRouter.Get("/", handler.GetProject)
 					projectSubRouter.Put("/", handler.UpdateProject)
 					projectSubRouter.Delete("/", handler.DeleteProject)
+					projectSubRouter.Get("/ingest_usage", handler.GetProjectIngestUsage)
 
 					projectSubRouter.Route("/endpoints", func(endpointSubRouter chi.Router) {
 						endpointSubRouter.Post("/", handler.CreateEndpoint)
@@ -174,6 +176,7 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 						eventRouter.Route("/{eventID}", func(eventSubRouter chi.Router) {
 							eventSubRouter.Get("/", handler.GetEndpointEvent)
 							eventSubRouter.Put("/replay", handler.ReplayEndpointEvent)
+							eventSubRouter.Get("/route_preview", handler.PreviewEventRoutes)
 						})
 					})
 
@@ -196,11 +199,16 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 					projectSubRouter.Route("/subscriptions", func(subscriptionRouter chi.Router) {
 						subscriptionRouter.Post("/", handler.CreateSubscription)
 						subscriptionRouter.Post("/test_filter", handler.TestSubscriptionFilter)
+						subscriptionRouter.Post("/test_filter/explain", handler.ExplainSubscriptionFilter)
 						subscriptionRouter.Post("/test_function", handler.TestSubscriptionFunction)
 						subscriptionRouter.With(middleware.Pagination).Get("/", handler.GetSubscriptions)
 						subscriptionRouter.Delete("/{subscriptionID}", handler.DeleteSubscription)
 						subscriptionRouter.Get("/{subscriptionID}", handler.GetSubscription)
 						subscriptionRouter.Put("/{subscriptionID}", handler.UpdateSubscription)
+						subscriptionRouter.Get("/{subscriptionID}/function/versions", handler.GetSubscriptionFunctionVersions)
+						subscriptionRouter.Post("/{subscriptionID}/function/rollback", handler.RollbackSubscriptionFunction)
+						subscriptionRouter.Post("/{subscriptionID}/function/pin", handler.PinSubscriptionFunction)
+						subscriptionRouter.Post("/{subscriptionID}/function/unpin", handler.UnpinSubscriptionFunction)
 						subscriptionRouter.Put("/{subscriptionID}/toggle_status", handler.ToggleSubscriptionStatus)
 					})
 
@@ -211,6 +219,28 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 						sourceRouter.Post("/test_function", handler.TestSourceFunction)
 						sourceRouter.Put("/{sourceID}", handler.UpdateSource)
 						sourceRouter.Delete("/{sourceID}", handler.DeleteSource)
+						sourceRouter.Get("/{sourceID}/function/versions", handler.GetSourceFunctionVersions)
+						sourceRouter.Post("/{sourceID}/function/rollback", handler.RollbackSourceFunction)
+					})
+
+					projectSubRouter.Route("/event-types", func(eventTypeRouter chi.Router) {
+						eventTypeRouter.Post("/", handler.CreateEventType)
+						eventTypeRouter.Get("/", handler.LoadEventTypes)
+						eventTypeRouter.Get("/{eventTypeID}", handler.GetEventType)
+						eventTypeRouter.Put("/{eventTypeID}", handler.UpdateEventType)
+						eventTypeRouter.Delete("/{eventTypeID}", handler.DeleteEventType)
+						eventTypeRouter.Post("/{eventTypeID}/versions", handler.CreateEventTypeVersion)
+						eventTypeRouter.Get("/{eventTypeID}/versions", handler.LoadEventTypeVersions)
+					})
+
+					projectSubRouter.Route("/function-libraries", func(libraryRouter chi.Router) {
+						libraryRouter.Post("/", handler.CreateFunctionLibrary)
+						libraryRouter.Get("/", handler.LoadFunctionLibraries)
+						libraryRouter.Get("/{libraryID}", handler.GetFunctionLibrary)
+						libraryRouter.Put("/{libraryID}", handler.UpdateFunctionLibrary)
+						libraryRouter.Delete("/{libraryID}", handler.DeleteFunctionLibrary)
+						libraryRouter.Get("/{libraryID}/versions", handler.GetFunctionLibraryVersions)
+						libraryRouter.Post("/{libraryID}/rollback", handler.RollbackFunctionLibrary)
 					})
 
 					projectSubRouter.Route("/portal-links", func(portalLinkRouter chi.Router) {
@@ -303,6 +333,7 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 						projectSubRouter.Put("/", handler.UpdateProject)
 						projectSubRouter.Delete("/", handler.DeleteProject)
 						projectSubRouter.Get("/stats", handler.GetProjectStatistics)
+						projectSubRouter.Get("/ingest_usage", handler.GetProjectIngestUsage)
 
 						projectSubRouter.Route("/security/keys", func(projectKeySubRouter chi.Router) {
 							projectKeySubRouter.Put("/regenerate", handler.RegenerateProjectAPIKey)
@@ -332,6 +363,7 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 							eventRouter.Route("/{eventID}", func(eventSubRouter chi.Router) {
 								eventSubRouter.Get("/", handler.GetEndpointEvent)
 								eventSubRouter.Put("/replay", handler.ReplayEndpointEvent)
+								eventSubRouter.Get("/route_preview", handler.PreviewEventRoutes)
 							})
 						})
 
@@ -355,11 +387,16 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 						projectSubRouter.Route("/subscriptions", func(subscriptionRouter chi.Router) {
 							subscriptionRouter.Post("/", handler.CreateSubscription)
 							subscriptionRouter.Post("/test_filter", handler.TestSubscriptionFilter)
+							subscriptionRouter.Post("/test_filter/explain", handler.ExplainSubscriptionFilter)
 							subscriptionRouter.Post("/test_function", handler.TestSubscriptionFunction)
 							subscriptionRouter.With(middleware.Pagination).Get("/", handler.GetSubscriptions)
 							subscriptionRouter.Delete("/{subscriptionID}", handler.DeleteSubscription)
 							subscriptionRouter.Get("/{subscriptionID}", handler.GetSubscription)
 							subscriptionRouter.Put("/{subscriptionID}", handler.UpdateSubscription)
+							subscriptionRouter.Get("/{subscriptionID}/function/versions", handler.GetSubscriptionFunctionVersions)
+							subscriptionRouter.Post("/{subscriptionID}/function/rollback", handler.RollbackSubscriptionFunction)
+							subscriptionRouter.Post("/{subscriptionID}/function/pin", handler.PinSubscriptionFunction)
+							subscriptionRouter.Post("/{subscriptionID}/function/unpin", handler.UnpinSubscriptionFunction)
 						})
 
 						projectSubRouter.Route("/sources", func(sourceRouter chi.Router) {
@@ -369,6 +406,28 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 							sourceRouter.Post("/test_function", handler.TestSourceFunction)
 							sourceRouter.Put("/{sourceID}", handler.UpdateSource)
 							sourceRouter.Delete("/{sourceID}", handler.DeleteSource)
+							sourceRouter.Get("/{sourceID}/function/versions", handler.GetSourceFunctionVersions)
+							sourceRouter.Post("/{sourceID}/function/rollback", handler.RollbackSourceFunction)
+						})
+
+						projectSubRouter.Route("/event-types", func(eventTypeRouter chi.Router) {
+							eventTypeRouter.Post("/", handler.CreateEventType)
+							eventTypeRouter.Get("/", handler.LoadEventTypes)
+							eventTypeRouter.Get("/{eventTypeID}", handler.GetEventType)
+							eventTypeRouter.Put("/{eventTypeID}", handler.UpdateEventType)
+							eventTypeRouter.Delete("/{eventTypeID}", handler.DeleteEventType)
+							eventTypeRouter.Post("/{eventTypeID}/versions", handler.CreateEventTypeVersion)
+							eventTypeRouter.Get("/{eventTypeID}/versions", handler.LoadEventTypeVersions)
+						})
+
+						projectSubRouter.Route("/function-libraries", func(libraryRouter chi.Router) {
+							libraryRouter.Post("/", handler.CreateFunctionLibrary)
+							libraryRouter.Get("/", handler.LoadFunctionLibraries)
+							libraryRouter.Get("/{libraryID}", handler.GetFunctionLibrary)
+							libraryRouter.Put("/{libraryID}", handler.UpdateFunctionLibrary)
+							libraryRouter.Delete("/{libraryID}", handler.DeleteFunctionLibrary)
+							libraryRouter.Get("/{libraryID}/versions", handler.GetFunctionLibraryVersions)
+							libraryRouter.Post("/{libraryID}/rollback", handler.RollbackFunctionLibrary)
 						})
 
 						projectSubRouter.Route("/meta-events", func(metaEventRouter chi.Router) {
@@ -450,9 +509,16 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 			})
 		})
 
+		portalLinkRouter.Route("/event-types", func(eventTypeRouter chi.Router) {
+			eventTypeRouter.Get("/", handler.LoadEventTypes)
+			eventTypeRouter.Get("/{eventTypeID}", handler.GetEventType)
+			eventTypeRouter.Get("/{eventTypeID}/versions", handler.LoadEventTypeVersions)
+		})
+
 		portalLinkRouter.Route("/subscriptions", func(subscriptionRouter chi.Router) {
 			subscriptionRouter.Post("/", handler.CreateSubscription)
 			subscriptionRouter.Post("/test_filter", handler.TestSubscriptionFilter)
+			subscriptionRouter.Post("/test_filter/explain", handler.ExplainSubscriptionFilter)
 			subscriptionRouter.With(middleware.Pagination).Get("/", handler.GetSubscriptions)
 			subscriptionRouter.Delete("/{subscriptionID}", handler.DeleteSubscription)
 			subscriptionRouter.Get("/{subscriptionID}", handler.GetSubscription)
@@ -479,6 +545,7 @@ func (a *ApplicationHandler) BuildDataPlaneRoutes() *chi.Mux {
 	router.Route("/ingest", func(ingestRouter chi.Router) {
 		ingestRouter.Get("/{maskID}", a.HandleCrcCheck)
 		ingestRouter.Post("/{maskID}", a.IngestEvent)
+		ingestRouter.Post("/{maskID}/batch", a.IngestEventBatch)
 	})
 
 	handler := &handlers.Handler{A: a.A, RM: a.rm}
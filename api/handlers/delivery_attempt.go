@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/render"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 	"github.com/frain-dev/convoy/util"
 )
 
@@ -26,6 +27,12 @@ import (
 //	@Security		ApiKeyAuth
 //	@Router			/v1/projects/{projectID}/eventdeliveries/{eventDeliveryID}/deliveryattempts/{deliveryAttemptID} [get]
 func (h *Handler) GetDeliveryAttempt(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
 	eventDelivery, err := h.retrieveEventDelivery(r)
 	if err != nil {
 		_ = render.Render(w, r, util.NewServiceErrResponse(err))
@@ -40,8 +47,9 @@ func (h *Handler) GetDeliveryAttempt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	attempt := redact.ForProject(project).Attempt(*deliveryAttempt)
 	_ = render.Render(w, r, util.NewServerResponse("App event delivery attempt fetched successfully",
-		deliveryAttempt, http.StatusOK))
+		&attempt, http.StatusOK))
 }
 
 // GetDeliveryAttempts
@@ -59,12 +67,19 @@ func (h *Handler) GetDeliveryAttempt(w http.ResponseWriter, r *http.Request) {
 //	@Security		ApiKeyAuth
 //	@Router			/v1/projects/{projectID}/eventdeliveries/{eventDeliveryID}/deliveryattempts [get]
 func (h *Handler) GetDeliveryAttempts(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
 	eventDelivery, err := h.retrieveEventDelivery(r)
 	if err != nil {
 		_ = render.Render(w, r, util.NewServiceErrResponse(err))
 		return
 	}
 
+	eventDelivery = redact.ForProject(project).Delivery(eventDelivery)
 	attempts := (*[]datastore.DeliveryAttempt)(&eventDelivery.DeliveryAttempts)
 	_ = render.Render(w, r, util.NewServerResponse("App event delivery attempts fetched successfully",
 		attempts, http.StatusOK))
@@ -174,7 +174,11 @@ func (h *Handler) GetEndpoints(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var q *models.QueryListEndpoint
-	data := q.Transform(r)
+	data, err := q.Transform(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
 
 	authUser := middleware.GetAuthUserFromContext(r.Context())
 	if h.IsReqWithPortalLinkToken(authUser) {
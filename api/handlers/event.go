@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/internal/pkg/middleware"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 	"github.com/frain-dev/convoy/pkg/msgpack"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/worker/task"
@@ -64,29 +66,38 @@ func (h *Handler) CreateEndpointEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var projectID string
+	var project *datastore.Project
 	authUser := middleware.GetAuthUserFromContext(r.Context())
 	if h.IsReqWithPortalLinkToken(authUser) {
-		project, err := h.retrieveProject(r)
+		project, err = h.retrieveProject(r)
 		if err != nil {
 			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
 			return
 		}
-
-		projectID = project.UID
-
 	} else {
-		projectID = chi.URLParam(r, "projectID")
+		projectID := chi.URLParam(r, "projectID")
 		if util.IsStringEmpty(projectID) {
 			_ = render.Render(w, r, util.NewErrorResponse("project id not present in request", http.StatusBadRequest))
 			return
 		}
+
+		project, err = postgres.NewProjectRepo(h.A.DB, h.A.Cache).FetchProjectByID(r.Context(), projectID)
+		if err != nil {
+			_ = render.Render(w, r, util.NewErrorResponse("failed to retrieve project", http.StatusBadRequest))
+			return
+		}
+	}
+
+	err = services.ValidateEventPayload(r.Context(), postgres.NewEventTypeRepo(h.A.DB, h.A.Cache), project, newMessage.EventType, newMessage.Data)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
 	}
 
 	e := task.CreateEvent{
 		Params: task.CreateEventTaskParams{
 			UID:            ulid.Make().String(),
-			ProjectID:      projectID,
+			ProjectID:      project.UID,
 			EndpointID:     newMessage.EndpointID,
 			EventType:      newMessage.EventType,
 			Data:           newMessage.Data,
@@ -97,6 +108,13 @@ func (h *Handler) CreateEndpointEvent(w http.ResponseWriter, r *http.Request) {
 		CreateSubscription: !util.IsStringEmpty(newMessage.EndpointID),
 	}
 
+	err = e.OffloadPayload(r.Context())
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to offload event payload")
+		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while saving the event payload", http.StatusInternalServerError))
+		return
+	}
+
 	eventByte, err := msgpack.EncodeMsgPack(e)
 	if err != nil {
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
@@ -167,6 +185,7 @@ func (h *Handler) CreateBroadcastEvent(w http.ResponseWriter, r *http.Request) {
 	newMessage.JobID = jid
 
 	cbe := services.CreateBroadcastEventService{
+		EventTypeRepo:  postgres.NewEventTypeRepo(h.A.DB, h.A.Cache),
 		Queue:          h.A.Queue,
 		BroadcastEvent: &newMessage,
 		Project:        project,
@@ -185,7 +204,7 @@ func (h *Handler) CreateBroadcastEvent(w http.ResponseWriter, r *http.Request) {
 // CreateEndpointFanoutEvent
 //
 //	@Summary		Fan out an event
-//	@Description	This endpoint uses the owner_id to fan out an event to multiple endpoints.
+//	@Description	This endpoint uses the owner_id or a label selector to fan out an event to multiple endpoints.
 //	@Id				CreateEndpointFanoutEvent
 //	@Tags			Events
 //	@Accept			json
@@ -232,6 +251,7 @@ func (h *Handler) CreateEndpointFanoutEvent(w http.ResponseWriter, r *http.Reque
 		EndpointRepo:   postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
 		EventRepo:      postgres.NewEventRepo(h.A.DB, h.A.Cache),
 		PortalLinkRepo: postgres.NewPortalLinkRepo(h.A.DB, h.A.Cache),
+		EventTypeRepo:  postgres.NewEventTypeRepo(h.A.DB, h.A.Cache),
 		Queue:          h.A.Queue,
 		NewMessage:     &newMessage,
 		Project:        project,
@@ -297,9 +317,10 @@ func (h *Handler) CreateDynamicEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cde := services.CreateDynamicEventService{
-		Queue:        h.A.Queue,
-		DynamicEvent: &newMessage,
-		Project:      project,
+		Queue:         h.A.Queue,
+		EventTypeRepo: postgres.NewEventTypeRepo(h.A.DB, h.A.Cache),
+		DynamicEvent:  &newMessage,
+		Project:       project,
 	}
 
 	err = cde.Run(r.Context())
@@ -348,6 +369,49 @@ func (h *Handler) ReplayEndpointEvent(w http.ResponseWriter, r *http.Request) {
 	_ = render.Render(w, r, util.NewServerResponse("Endpoint event replayed successfully", resp, http.StatusOK))
 }
 
+// PreviewEventRoutes
+//
+//	@Summary		Preview event routes
+//	@Description	This endpoint runs an event through the project's subscriptions and reports which would match and why.
+//	@Id				PreviewEventRoutes
+//	@Tags			Events
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			eventID		path		string	true	"event id"
+//	@Success		200			{object}	util.ServerResponse{data=models.RoutePreview}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/events/{eventID}/route_preview [get]
+func (h *Handler) PreviewEventRoutes(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	event, err := h.retrieveEvent(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
+		return
+	}
+
+	rs := services.RoutePreviewService{
+		SubRepo:      postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache),
+		EndpointRepo: postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
+		Project:      project,
+		Event:        event,
+	}
+
+	preview, err := rs.Run(r.Context())
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Event routes previewed successfully", preview, http.StatusOK))
+}
+
 // BatchReplayEvents
 //
 //	@Summary		Batch replay events
@@ -409,13 +473,26 @@ func (h *Handler) BatchReplayEvents(w http.ResponseWriter, r *http.Request) {
 //	@Security		ApiKeyAuth
 //	@Router			/v1/projects/{projectID}/events/{eventID} [get]
 func (h *Handler) GetEndpointEvent(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
 	event, err := h.retrieveEvent(r)
 	if err != nil {
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
 		return
 	}
 
-	resp := &models.EventResponse{Event: event}
+	err = claimcheck.Get().LoadEvent(r.Context(), event)
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to load offloaded event payload")
+		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while fetching the event payload", http.StatusInternalServerError))
+		return
+	}
+
+	resp := &models.EventResponse{Event: redact.ForProject(project).Event(event)}
 	_ = render.Render(w, r, util.NewServerResponse("Endpoint event fetched successfully",
 		resp, http.StatusOK))
 }
@@ -479,8 +556,9 @@ func (h *Handler) GetEventsPaged(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rd := redact.ForProject(project)
 	resp := models.NewListResponse(eventsPaged, func(event datastore.Event) models.EventResponse {
-		return models.EventResponse{Event: &event}
+		return models.EventResponse{Event: rd.Event(&event)}
 	})
 	_ = render.Render(w, r, util.NewServerResponse("App events fetched successfully",
 		models.PagedResponse{Content: resp, Pagination: &paginationData}, http.StatusOK))
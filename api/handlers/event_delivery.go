@@ -8,7 +8,9 @@ import (
 	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/internal/pkg/middleware"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -31,13 +33,26 @@ import (
 //	@Security		ApiKeyAuth
 //	@Router			/v1/projects/{projectID}/eventdeliveries/{eventDeliveryID} [get]
 func (h *Handler) GetEventDelivery(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
 	eventDelivery, err := h.retrieveEventDelivery(r)
 	if err != nil {
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
 		return
 	}
 
-	resp := &models.EventDeliveryResponse{EventDelivery: eventDelivery}
+	err = claimcheck.Get().LoadDelivery(r.Context(), eventDelivery)
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to load offloaded event delivery payload")
+		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while fetching the event delivery payload", http.StatusInternalServerError))
+		return
+	}
+
+	resp := &models.EventDeliveryResponse{EventDelivery: redact.ForProject(project).Delivery(eventDelivery)}
 	_ = render.Render(w, r, util.NewServerResponse("Event Delivery fetched successfully",
 		resp, http.StatusOK))
 }
@@ -253,8 +268,9 @@ func (h *Handler) GetEventDeliveriesPaged(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	rd := redact.ForProject(project)
 	resp := models.NewListResponse(ed, func(ed datastore.EventDelivery) models.EventDeliveryResponse {
-		return models.EventDeliveryResponse{EventDelivery: &ed}
+		return models.EventDeliveryResponse{EventDelivery: rd.Delivery(&ed)}
 	})
 
 	_ = render.Render(w, r, util.NewServerResponse("Event deliveries fetched successfully",
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/services"
+	"github.com/frain-dev/convoy/util"
+)
+
+// CreateEventType
+//
+//	@Summary		Create an event type
+//	@Description	This endpoint adds an event type to the project's event type catalog
+//	@Id				CreateEventType
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string					true	"Project ID"
+//	@Param			eventType	body		models.CreateEventType	true	"Event Type Details"
+//	@Success		201			{object}	util.ServerResponse{data=models.EventTypeResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types [post]
+func (h *Handler) CreateEventType(w http.ResponseWriter, r *http.Request) {
+	var newEventType models.CreateEventType
+	if err := util.ReadJSON(r, &newEventType); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if err := newEventType.Validate(); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventType, err := h.eventTypeService(project).CreateEventType(r.Context(), &newEventType)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.EventTypeResponse{ProjectEventType: eventType}
+	_ = render.Render(w, r, util.NewServerResponse("Event type created successfully", resp, http.StatusCreated))
+}
+
+// LoadEventTypes
+//
+//	@Summary		List all event types
+//	@Description	This endpoint fetches the project's event type catalog
+//	@Id				LoadEventTypes
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Success		200			{object}	util.ServerResponse{data=[]models.EventTypeResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types [get]
+func (h *Handler) LoadEventTypes(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventTypes, err := postgres.NewEventTypeRepo(h.A.DB, h.A.Cache).LoadEventTypes(r.Context(), project.UID)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while fetching event types", http.StatusBadRequest))
+		return
+	}
+
+	resp := make([]models.EventTypeResponse, 0, len(eventTypes))
+	for i := range eventTypes {
+		resp = append(resp, models.EventTypeResponse{ProjectEventType: &eventTypes[i]})
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Event types fetched successfully", resp, http.StatusOK))
+}
+
+// GetEventType
+//
+//	@Summary		Retrieve an event type
+//	@Description	This endpoint retrieves an event type from the project's catalog
+//	@Id				GetEventType
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			eventTypeID	path		string	true	"event type id"
+//	@Success		200			{object}	util.ServerResponse{data=models.EventTypeResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types/{eventTypeID} [get]
+func (h *Handler) GetEventType(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventType, ok := h.retrieveEventType(w, r, project)
+	if !ok {
+		return
+	}
+
+	resp := &models.EventTypeResponse{ProjectEventType: eventType}
+	_ = render.Render(w, r, util.NewServerResponse("Event type fetched successfully", resp, http.StatusOK))
+}
+
+// UpdateEventType
+//
+//	@Summary		Update an event type
+//	@Description	This endpoint updates an event type in the project's catalog
+//	@Id				UpdateEventType
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string					true	"Project ID"
+//	@Param			eventTypeID	path		string					true	"event type id"
+//	@Param			eventType	body		models.UpdateEventType	true	"Event Type Details"
+//	@Success		202			{object}	util.ServerResponse{data=models.EventTypeResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types/{eventTypeID} [put]
+func (h *Handler) UpdateEventType(w http.ResponseWriter, r *http.Request) {
+	var update models.UpdateEventType
+	if err := util.ReadJSON(r, &update); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if err := update.Validate(); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventType, ok := h.retrieveEventType(w, r, project)
+	if !ok {
+		return
+	}
+
+	eventType, err = h.eventTypeService(project).UpdateEventType(r.Context(), eventType, &update)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.EventTypeResponse{ProjectEventType: eventType}
+	_ = render.Render(w, r, util.NewServerResponse("Event type updated successfully", resp, http.StatusAccepted))
+}
+
+// DeleteEventType
+//
+//	@Summary		Delete an event type
+//	@Description	This endpoint removes an event type from the project's catalog
+//	@Id				DeleteEventType
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			eventTypeID	path		string	true	"event type id"
+//	@Success		200			{object}	util.ServerResponse{data=Stub}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types/{eventTypeID} [delete]
+func (h *Handler) DeleteEventType(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventType, ok := h.retrieveEventType(w, r, project)
+	if !ok {
+		return
+	}
+
+	err = h.eventTypeService(project).DeleteEventType(r.Context(), eventType)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Event type deleted successfully", nil, http.StatusOK))
+}
+
+// CreateEventTypeVersion
+//
+//	@Summary		Create an event type version
+//	@Description	This endpoint adds a new version of an event type's schema, with a down converter to the current version
+//	@Id				CreateEventTypeVersion
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string							true	"Project ID"
+//	@Param			eventTypeID	path		string							true	"event type id"
+//	@Param			version		body		models.CreateEventTypeVersion	true	"Event Type Version Details"
+//	@Success		201			{object}	util.ServerResponse{data=models.EventTypeVersionResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types/{eventTypeID}/versions [post]
+func (h *Handler) CreateEventTypeVersion(w http.ResponseWriter, r *http.Request) {
+	var newVersion models.CreateEventTypeVersion
+	if err := util.ReadJSON(r, &newVersion); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if err := newVersion.Validate(); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventType, ok := h.retrieveEventType(w, r, project)
+	if !ok {
+		return
+	}
+
+	version, err := h.eventTypeService(project).CreateEventTypeVersion(r.Context(), eventType, &newVersion)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.EventTypeVersionResponse{EventTypeVersion: version}
+	_ = render.Render(w, r, util.NewServerResponse("Event type version created successfully", resp, http.StatusCreated))
+}
+
+// LoadEventTypeVersions
+//
+//	@Summary		List event type versions
+//	@Description	This endpoint fetches every version of an event type, newest first
+//	@Id				LoadEventTypeVersions
+//	@Tags			Event Types
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			eventTypeID	path		string	true	"event type id"
+//	@Success		200			{object}	util.ServerResponse{data=[]models.EventTypeVersionResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/event-types/{eventTypeID}/versions [get]
+func (h *Handler) LoadEventTypeVersions(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventType, ok := h.retrieveEventType(w, r, project)
+	if !ok {
+		return
+	}
+
+	versions, err := postgres.NewEventTypeRepo(h.A.DB, h.A.Cache).LoadEventTypeVersions(r.Context(), project.UID, eventType.UID)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while fetching event type versions", http.StatusBadRequest))
+		return
+	}
+
+	resp := make([]models.EventTypeVersionResponse, 0, len(versions))
+	for i := range versions {
+		resp = append(resp, models.EventTypeVersionResponse{EventTypeVersion: &versions[i]})
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Event type versions fetched successfully", resp, http.StatusOK))
+}
+
+func (h *Handler) retrieveEventType(w http.ResponseWriter, r *http.Request, project *datastore.Project) (*datastore.ProjectEventType, bool) {
+	eventType, err := postgres.NewEventTypeRepo(h.A.DB, h.A.Cache).FindEventTypeByID(r.Context(), project.UID, chi.URLParam(r, "eventTypeID"))
+	if err != nil {
+		if errors.Is(err, datastore.ErrEventTypeNotFound) {
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
+			return nil, false
+		}
+
+		_ = render.Render(w, r, util.NewErrorResponse("error retrieving event type", http.StatusBadRequest))
+		return nil, false
+	}
+
+	return eventType, true
+}
+
+func (h *Handler) eventTypeService(project *datastore.Project) *services.EventTypeService {
+	return &services.EventTypeService{
+		EventTypeRepo: postgres.NewEventTypeRepo(h.A.DB, h.A.Cache),
+		Project:       project,
+	}
+}
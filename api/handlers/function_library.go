@@ -0,0 +1,637 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/services"
+	"github.com/frain-dev/convoy/util"
+)
+
+// CreateFunctionLibrary
+//
+//	@Summary		Create a function library
+//	@Description	This endpoint creates a js module the project's functions can require by its name
+//	@Id				CreateFunctionLibrary
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string							true	"Project ID"
+//	@Param			library		body		models.CreateFunctionLibrary	true	"Function Library Details"
+//	@Success		201			{object}	util.ServerResponse{data=models.FunctionLibraryResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries [post]
+func (h *Handler) CreateFunctionLibrary(w http.ResponseWriter, r *http.Request) {
+	var newLibrary models.CreateFunctionLibrary
+	if err := util.ReadJSON(r, &newLibrary); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if err := newLibrary.Validate(); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	library, err := h.functionLibraryService(project).CreateFunctionLibrary(r.Context(), &newLibrary)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.FunctionLibraryResponse{FunctionLibrary: library}
+	_ = render.Render(w, r, util.NewServerResponse("Function library created successfully", resp, http.StatusCreated))
+}
+
+// LoadFunctionLibraries
+//
+//	@Summary		List all function libraries
+//	@Description	This endpoint fetches the project's function libraries
+//	@Id				LoadFunctionLibraries
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Success		200			{object}	util.ServerResponse{data=[]models.FunctionLibraryResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries [get]
+func (h *Handler) LoadFunctionLibraries(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	libraries, err := postgres.NewFunctionLibraryRepo(h.A.DB, h.A.Cache).LoadFunctionLibraries(r.Context(), project.UID)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while fetching function libraries", http.StatusBadRequest))
+		return
+	}
+
+	resp := make([]models.FunctionLibraryResponse, 0, len(libraries))
+	for i := range libraries {
+		resp = append(resp, models.FunctionLibraryResponse{FunctionLibrary: &libraries[i]})
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Function libraries fetched successfully", resp, http.StatusOK))
+}
+
+// GetFunctionLibrary
+//
+//	@Summary		Retrieve a function library
+//	@Description	This endpoint retrieves a function library by its id
+//	@Id				GetFunctionLibrary
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			libraryID	path		string	true	"Function library ID"
+//	@Success		200			{object}	util.ServerResponse{data=models.FunctionLibraryResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries/{libraryID} [get]
+func (h *Handler) GetFunctionLibrary(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	library, ok := h.retrieveFunctionLibrary(w, r, project)
+	if !ok {
+		return
+	}
+
+	resp := &models.FunctionLibraryResponse{FunctionLibrary: library}
+	_ = render.Render(w, r, util.NewServerResponse("Function library fetched successfully", resp, http.StatusOK))
+}
+
+// UpdateFunctionLibrary
+//
+//	@Summary		Update a function library
+//	@Description	This endpoint updates a function library, a changed function is saved as a new version
+//	@Id				UpdateFunctionLibrary
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string							true	"Project ID"
+//	@Param			libraryID	path		string							true	"Function library ID"
+//	@Param			library		body		models.UpdateFunctionLibrary	true	"Function Library Details"
+//	@Success		202			{object}	util.ServerResponse{data=models.FunctionLibraryResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries/{libraryID} [put]
+func (h *Handler) UpdateFunctionLibrary(w http.ResponseWriter, r *http.Request) {
+	var update models.UpdateFunctionLibrary
+	if err := util.ReadJSON(r, &update); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if err := update.Validate(); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	library, ok := h.retrieveFunctionLibrary(w, r, project)
+	if !ok {
+		return
+	}
+
+	library, err = h.functionLibraryService(project).UpdateFunctionLibrary(r.Context(), library, &update)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.FunctionLibraryResponse{FunctionLibrary: library}
+	_ = render.Render(w, r, util.NewServerResponse("Function library updated successfully", resp, http.StatusAccepted))
+}
+
+// DeleteFunctionLibrary
+//
+//	@Summary		Delete a function library
+//	@Description	This endpoint deletes a function library, functions requiring it fail until it's recreated
+//	@Id				DeleteFunctionLibrary
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			libraryID	path		string	true	"Function library ID"
+//	@Success		200			{object}	util.ServerResponse{data=Stub}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries/{libraryID} [delete]
+func (h *Handler) DeleteFunctionLibrary(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	library, ok := h.retrieveFunctionLibrary(w, r, project)
+	if !ok {
+		return
+	}
+
+	err = h.functionLibraryService(project).DeleteFunctionLibrary(r.Context(), library)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Function library deleted successfully", nil, http.StatusOK))
+}
+
+// GetFunctionLibraryVersions
+//
+//	@Summary		List a function library's versions
+//	@Description	This endpoint fetches every version of a function library, latest first
+//	@Id				GetFunctionLibraryVersions
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			libraryID	path		string	true	"Function library ID"
+//	@Success		200			{object}	util.ServerResponse{data=[]models.FunctionVersionResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries/{libraryID}/versions [get]
+func (h *Handler) GetFunctionLibraryVersions(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	library, ok := h.retrieveFunctionLibrary(w, r, project)
+	if !ok {
+		return
+	}
+
+	h.renderFunctionVersions(w, r, project, datastore.FunctionLibraryOwner, library.UID)
+}
+
+// RollbackFunctionLibrary
+//
+//	@Summary		Roll back a function library
+//	@Description	This endpoint saves an earlier version of a function library as its latest version
+//	@Id				RollbackFunctionLibrary
+//	@Tags			Function Libraries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string							true	"Project ID"
+//	@Param			libraryID	path		string							true	"Function library ID"
+//	@Param			version		body		models.FunctionVersionRequest	true	"Version"
+//	@Success		202			{object}	util.ServerResponse{data=models.FunctionLibraryResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/function-libraries/{libraryID}/rollback [post]
+func (h *Handler) RollbackFunctionLibrary(w http.ResponseWriter, r *http.Request) {
+	version, ok := readFunctionVersionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	library, ok := h.retrieveFunctionLibrary(w, r, project)
+	if !ok {
+		return
+	}
+
+	library, err = h.functionLibraryService(project).RollbackFunctionLibrary(r.Context(), library, version.Version)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.FunctionLibraryResponse{FunctionLibrary: library}
+	_ = render.Render(w, r, util.NewServerResponse("Function library rolled back successfully", resp, http.StatusAccepted))
+}
+
+// GetSubscriptionFunctionVersions
+//
+//	@Summary		List a subscription function's versions
+//	@Description	This endpoint fetches every version of a subscription's function, latest first
+//	@Id				GetSubscriptionFunctionVersions
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string	true	"Project ID"
+//	@Param			subscriptionID	path		string	true	"subscription id"
+//	@Success		200				{object}	util.ServerResponse{data=[]models.FunctionVersionResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/{subscriptionID}/function/versions [get]
+func (h *Handler) GetSubscriptionFunctionVersions(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	subscription, ok := h.retrieveSubscription(w, r, project)
+	if !ok {
+		return
+	}
+
+	h.renderFunctionVersions(w, r, project, datastore.SubscriptionFunctionOwner, subscription.UID)
+}
+
+// RollbackSubscriptionFunction
+//
+//	@Summary		Roll back a subscription's function
+//	@Description	This endpoint saves an earlier version of a subscription's function as its latest version
+//	@Id				RollbackSubscriptionFunction
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string							true	"Project ID"
+//	@Param			subscriptionID	path		string							true	"subscription id"
+//	@Param			version			body		models.FunctionVersionRequest	true	"Version"
+//	@Success		202				{object}	util.ServerResponse{data=models.SubscriptionResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/{subscriptionID}/function/rollback [post]
+func (h *Handler) RollbackSubscriptionFunction(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscriptionFunction(w, r, "Subscription function rolled back successfully",
+		func(fs *services.FunctionVersionService, s *datastore.Subscription, version int) (*datastore.Subscription, error) {
+			return fs.RollbackSubscriptionFunction(r.Context(), s, version)
+		})
+}
+
+// PinSubscriptionFunction
+//
+//	@Summary		Pin a subscription's function
+//	@Description	This endpoint runs a version of a subscription's function until it's unpinned, the function can't be changed while it's pinned
+//	@Id				PinSubscriptionFunction
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string							true	"Project ID"
+//	@Param			subscriptionID	path		string							true	"subscription id"
+//	@Param			version			body		models.FunctionVersionRequest	true	"Version"
+//	@Success		202				{object}	util.ServerResponse{data=models.SubscriptionResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/{subscriptionID}/function/pin [post]
+func (h *Handler) PinSubscriptionFunction(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscriptionFunction(w, r, "Subscription function pinned successfully",
+		func(fs *services.FunctionVersionService, s *datastore.Subscription, version int) (*datastore.Subscription, error) {
+			return fs.PinSubscriptionFunction(r.Context(), s, version)
+		})
+}
+
+// UnpinSubscriptionFunction
+//
+//	@Summary		Unpin a subscription's function
+//	@Description	This endpoint lets a pinned subscription function be changed again
+//	@Id				UnpinSubscriptionFunction
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string	true	"Project ID"
+//	@Param			subscriptionID	path		string	true	"subscription id"
+//	@Success		202				{object}	util.ServerResponse{data=models.SubscriptionResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/{subscriptionID}/function/unpin [post]
+func (h *Handler) UnpinSubscriptionFunction(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	subscription, ok := h.retrieveSubscription(w, r, project)
+	if !ok {
+		return
+	}
+
+	subscription, err = h.functionVersionService(project).UnpinSubscriptionFunction(r.Context(), subscription)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := models.SubscriptionResponse{Subscription: subscription}
+	_ = render.Render(w, r, util.NewServerResponse("Subscription function unpinned successfully", resp, http.StatusAccepted))
+}
+
+// GetSourceFunctionVersions
+//
+//	@Summary		List a source function's versions
+//	@Description	This endpoint fetches every version of a source's body or header function, latest first
+//	@Id				GetSourceFunctionVersions
+//	@Tags			Sources
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			sourceID	path		string	true	"Source ID"
+//	@Param			type		query		string	false	"Function type, body or header"
+//	@Success		200			{object}	util.ServerResponse{data=[]models.FunctionVersionResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/sources/{sourceID}/function/versions [get]
+func (h *Handler) GetSourceFunctionVersions(w http.ResponseWriter, r *http.Request) {
+	ownerType, err := sourceFunctionOwner(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	source, ok := h.retrieveSource(w, r, project)
+	if !ok {
+		return
+	}
+
+	h.renderFunctionVersions(w, r, project, ownerType, source.UID)
+}
+
+// RollbackSourceFunction
+//
+//	@Summary		Roll back a source's function
+//	@Description	This endpoint saves an earlier version of a source's body or header function as its latest version
+//	@Id				RollbackSourceFunction
+//	@Tags			Sources
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string							true	"Project ID"
+//	@Param			sourceID	path		string							true	"Source ID"
+//	@Param			type		query		string							false	"Function type, body or header"
+//	@Param			version		body		models.FunctionVersionRequest	true	"Version"
+//	@Success		202			{object}	util.ServerResponse{data=models.SourceResponse}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/sources/{sourceID}/function/rollback [post]
+func (h *Handler) RollbackSourceFunction(w http.ResponseWriter, r *http.Request) {
+	ownerType, err := sourceFunctionOwner(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	version, ok := readFunctionVersionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	source, ok := h.retrieveSource(w, r, project)
+	if !ok {
+		return
+	}
+
+	source, err = h.functionVersionService(project).RollbackSourceFunction(r.Context(), source, ownerType, version.Version)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	org, err := postgres.NewOrgRepo(h.A.DB, h.A.Cache).FetchOrganisationByID(r.Context(), project.OrganisationID)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	baseUrl, err := h.retrieveHost()
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	fillSourceURL(source, baseUrl, org.CustomDomain.ValueOrZero())
+	resp := &models.SourceResponse{Source: source}
+	_ = render.Render(w, r, util.NewServerResponse("Source function rolled back successfully", resp, http.StatusAccepted))
+}
+
+func (h *Handler) changeSubscriptionFunction(w http.ResponseWriter, r *http.Request, msg string,
+	change func(*services.FunctionVersionService, *datastore.Subscription, int) (*datastore.Subscription, error),
+) {
+	version, ok := readFunctionVersionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	subscription, ok := h.retrieveSubscription(w, r, project)
+	if !ok {
+		return
+	}
+
+	subscription, err = change(h.functionVersionService(project), subscription, version.Version)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := models.SubscriptionResponse{Subscription: subscription}
+	_ = render.Render(w, r, util.NewServerResponse(msg, resp, http.StatusAccepted))
+}
+
+func (h *Handler) renderFunctionVersions(w http.ResponseWriter, r *http.Request, project *datastore.Project, ownerType datastore.FunctionOwnerType, ownerID string) {
+	versions, err := h.functionVersionService(project).LoadFunctionVersions(r.Context(), ownerType, ownerID)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := make([]models.FunctionVersionResponse, 0, len(versions))
+	for i := range versions {
+		resp = append(resp, models.FunctionVersionResponse{FunctionVersion: &versions[i]})
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Function versions fetched successfully", resp, http.StatusOK))
+}
+
+// retrieveTestFunction returns the function test_function runs, which is
+// a saved version of the owner's function when one is requested.
+func (h *Handler) retrieveTestFunction(r *http.Request, project *datastore.Project, test *models.FunctionRequest, ownerType datastore.FunctionOwnerType) (string, error) {
+	if test.Version <= 0 {
+		return test.Function, nil
+	}
+
+	if util.IsStringEmpty(test.OwnerID) {
+		return "", util.NewServiceError(http.StatusBadRequest, errors.New("owner_id is required to run a function version"))
+	}
+
+	v, err := h.functionVersionService(project).FindFunctionVersion(r.Context(), ownerType, test.OwnerID, test.Version)
+	if err != nil {
+		return "", err
+	}
+
+	return v.Function, nil
+}
+
+func (h *Handler) retrieveFunctionLibrary(w http.ResponseWriter, r *http.Request, project *datastore.Project) (*datastore.FunctionLibrary, bool) {
+	library, err := postgres.NewFunctionLibraryRepo(h.A.DB, h.A.Cache).FindFunctionLibraryByID(r.Context(), project.UID, chi.URLParam(r, "libraryID"))
+	if err != nil {
+		if errors.Is(err, datastore.ErrFunctionLibraryNotFound) {
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
+			return nil, false
+		}
+
+		_ = render.Render(w, r, util.NewErrorResponse("error retrieving function library", http.StatusBadRequest))
+		return nil, false
+	}
+
+	return library, true
+}
+
+func (h *Handler) retrieveSubscription(w http.ResponseWriter, r *http.Request, project *datastore.Project) (*datastore.Subscription, bool) {
+	subscription, err := postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache).FindSubscriptionByID(r.Context(), project.UID, chi.URLParam(r, "subscriptionID"))
+	if err != nil {
+		if errors.Is(err, datastore.ErrSubscriptionNotFound) {
+			_ = render.Render(w, r, util.NewErrorResponse("failed to find subscription", http.StatusNotFound))
+			return nil, false
+		}
+
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return nil, false
+	}
+
+	return subscription, true
+}
+
+func (h *Handler) retrieveSource(w http.ResponseWriter, r *http.Request, project *datastore.Project) (*datastore.Source, bool) {
+	source, err := postgres.NewSourceRepo(h.A.DB, h.A.Cache).FindSourceByID(r.Context(), project.UID, chi.URLParam(r, "sourceID"))
+	if err != nil {
+		if errors.Is(err, datastore.ErrSourceNotFound) {
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
+			return nil, false
+		}
+
+		_ = render.Render(w, r, util.NewErrorResponse("error retrieving source", http.StatusBadRequest))
+		return nil, false
+	}
+
+	return source, true
+}
+
+func (h *Handler) functionLibraryService(project *datastore.Project) *services.FunctionLibraryService {
+	return &services.FunctionLibraryService{
+		LibraryRepo: postgres.NewFunctionLibraryRepo(h.A.DB, h.A.Cache),
+		VersionRepo: postgres.NewFunctionVersionRepo(h.A.DB, h.A.Cache),
+		Project:     project,
+	}
+}
+
+func (h *Handler) functionVersionService(project *datastore.Project) *services.FunctionVersionService {
+	return &services.FunctionVersionService{
+		VersionRepo: postgres.NewFunctionVersionRepo(h.A.DB, h.A.Cache),
+		LibraryRepo: postgres.NewFunctionLibraryRepo(h.A.DB, h.A.Cache),
+		SubRepo:     postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache),
+		SourceRepo:  postgres.NewSourceRepo(h.A.DB, h.A.Cache),
+		Project:     project,
+	}
+}
+
+func readFunctionVersionRequest(w http.ResponseWriter, r *http.Request) (*models.FunctionVersionRequest, bool) {
+	var version models.FunctionVersionRequest
+	if err := util.ReadJSON(r, &version); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return nil, false
+	}
+
+	if err := version.Validate(); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return nil, false
+	}
+
+	return &version, true
+}
+
+// sourceFunctionOwner reads which of a source's functions is requested,
+// it defaults to the body function.
+func sourceFunctionOwner(r *http.Request) (datastore.FunctionOwnerType, error) {
+	switch t := r.URL.Query().Get("type"); t {
+	case "", "body":
+		return datastore.SourceBodyFunctionOwner, nil
+	case "header":
+		return datastore.SourceHeaderFunctionOwner, nil
+	default:
+		return "", fmt.Errorf("unknown function type %q, use body or header", t)
+	}
+}
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/frain-dev/convoy/internal/pkg/limiter"
 
 	"github.com/frain-dev/convoy/pkg/log"
 
@@ -59,6 +62,47 @@ func (h *Handler) GetProjectStatistics(w http.ResponseWriter, r *http.Request) {
 	_ = render.Render(w, r, util.NewServerResponse("Project Stats fetched successfully", project.Statistics, http.StatusOK))
 }
 
+// GetProjectIngestUsage returns the events counted against the project's
+// ingest quota in the current period. Events are only counted while the
+// project has a quota, so usage is zero for projects without one.
+func (h *Handler) GetProjectIngestUsage(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	var quota datastore.IngestQuotaConfiguration
+	if project.Config != nil {
+		quota = project.Config.GetIngestQuotaConfig()
+	}
+
+	start, end := quota.Window(time.Now())
+	used, err := h.A.Rate.QuotaUsage(r.Context(), limiter.IngestQuotaKey(project.UID, quota.Period, start))
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to fetch project ingest usage")
+		_ = render.Render(w, r, util.NewErrorResponse("failed to fetch project ingest usage", http.StatusBadRequest))
+		return
+	}
+
+	resp := &models.IngestUsageResponse{
+		Period:   datastore.DailyQuotaPeriod,
+		Limit:    quota.Count,
+		Used:     used,
+		ResetsAt: end,
+	}
+
+	if quota.Period == datastore.MonthlyQuotaPeriod {
+		resp.Period = quota.Period
+	}
+
+	if quota.Count > used {
+		resp.Remaining = quota.Count - used
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Project ingest usage fetched successfully", resp, http.StatusOK))
+}
+
 func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	project, err := h.retrieveProject(r)
 	if err != nil {
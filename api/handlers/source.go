@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/frain-dev/convoy/pkg/transform"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
 	"net/http"
 
 	"github.com/frain-dev/convoy/pkg/log"
 
 	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/services"
@@ -52,10 +53,12 @@ func (h *Handler) CreateSource(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cs := services.CreateSourceService{
-		SourceRepo: postgres.NewSourceRepo(h.A.DB, h.A.Cache),
-		Cache:      h.A.Cache,
-		NewSource:  &newSource,
-		Project:    project,
+		SourceRepo:          postgres.NewSourceRepo(h.A.DB, h.A.Cache),
+		EndpointRepo:        postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
+		FunctionVersionRepo: postgres.NewFunctionVersionRepo(h.A.DB, h.A.Cache),
+		Cache:               h.A.Cache,
+		NewSource:           &newSource,
+		Project:             project,
 	}
 
 	source, err := cs.Run(r.Context())
@@ -180,11 +183,13 @@ func (h *Handler) UpdateSource(w http.ResponseWriter, r *http.Request) {
 	}
 
 	us := services.UpdateSourceService{
-		SourceRepo:   postgres.NewSourceRepo(h.A.DB, h.A.Cache),
-		Cache:        h.A.Cache,
-		Project:      project,
-		SourceUpdate: &sourceUpdate,
-		Source:       source,
+		SourceRepo:          postgres.NewSourceRepo(h.A.DB, h.A.Cache),
+		EndpointRepo:        postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
+		FunctionVersionRepo: postgres.NewFunctionVersionRepo(h.A.DB, h.A.Cache),
+		Cache:               h.A.Cache,
+		Project:             project,
+		SourceUpdate:        &sourceUpdate,
+		Source:              source,
 	}
 
 	source, err = us.Run(r.Context())
@@ -318,6 +323,11 @@ func (h *Handler) LoadSourcesPaged(w http.ResponseWriter, r *http.Request) {
 }
 
 func fillSourceURL(s *datastore.Source, baseUrl string, customDomain string) {
+	if s.Type == datastore.EmailSource {
+		fillSourceAddress(s)
+		return
+	}
+
 	url := baseUrl
 	if len(customDomain) > 0 {
 		url = customDomain
@@ -326,6 +336,17 @@ func fillSourceURL(s *datastore.Source, baseUrl string, customDomain string) {
 	s.URL = fmt.Sprintf("%s/ingest/%s", url, s.MaskID)
 }
 
+// fillSourceAddress sets the address email sources receive mail at, it is
+// left empty when the inbound email domain isn't configured.
+func fillSourceAddress(s *datastore.Source) {
+	cfg, err := config.Get()
+	if err != nil || len(cfg.InboundEmail.Domain) == 0 {
+		return
+	}
+
+	s.URL = fmt.Sprintf("%s@%s", s.MaskID, cfg.InboundEmail.Domain)
+}
+
 // TestSourceFunction
 //
 //	@Summary		Validate source function
@@ -347,8 +368,25 @@ func (h *Handler) TestSourceFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transformer := transform.NewTransformer()
-	mutatedPayload, consoleLog, err := transformer.Transform(test.Function, test.Payload)
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	ownerType := datastore.SourceBodyFunctionOwner
+	if test.Type == "header" {
+		ownerType = datastore.SourceHeaderFunctionOwner
+	}
+
+	function, err := h.retrieveTestFunction(r, project, &test, ownerType)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	transformer := functions.NewTransformer(r.Context(), project.UID)
+	mutatedPayload, consoleLog, err := transformer.Transform(function, test.Payload)
 	if err != nil {
 		log.FromContext(r.Context()).WithError(err).Error("failed to transform function")
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
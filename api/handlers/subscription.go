@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/frain-dev/convoy/internal/pkg/celfilter"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
 	"github.com/frain-dev/convoy/internal/pkg/middleware"
-	"github.com/frain-dev/convoy/pkg/transform"
 
 	"github.com/frain-dev/convoy/pkg/log"
 
@@ -213,11 +215,12 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cs := services.CreateSubscriptionService{
-		SubRepo:         postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache),
-		EndpointRepo:    postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
-		SourceRepo:      postgres.NewSourceRepo(h.A.DB, h.A.Cache),
-		Project:         project,
-		NewSubscription: &sub,
+		SubRepo:             postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache),
+		EndpointRepo:        postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
+		SourceRepo:          postgres.NewSourceRepo(h.A.DB, h.A.Cache),
+		FunctionVersionRepo: postgres.NewFunctionVersionRepo(h.A.DB, h.A.Cache),
+		Project:             project,
+		NewSubscription:     &sub,
 	}
 
 	subscription, err := cs.Run(r.Context())
@@ -362,12 +365,13 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	}
 
 	us := services.UpdateSubscriptionService{
-		SubRepo:        postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache),
-		EndpointRepo:   postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
-		SourceRepo:     postgres.NewSourceRepo(h.A.DB, h.A.Cache),
-		ProjectId:      project.UID,
-		SubscriptionId: chi.URLParam(r, "subscriptionID"),
-		Update:         &update,
+		SubRepo:             postgres.NewSubscriptionRepo(h.A.DB, h.A.Cache),
+		EndpointRepo:        postgres.NewEndpointRepo(h.A.DB, h.A.Cache),
+		SourceRepo:          postgres.NewSourceRepo(h.A.DB, h.A.Cache),
+		FunctionVersionRepo: postgres.NewFunctionVersionRepo(h.A.DB, h.A.Cache),
+		ProjectId:           project.UID,
+		SubscriptionId:      chi.URLParam(r, "subscriptionID"),
+		Update:              &update,
 	}
 
 	sub, err := us.Run(r.Context())
@@ -422,11 +426,98 @@ func (h *Handler) TestSubscriptionFilter(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	isValid := isBodyValid && isHeaderValid
+	isMetadataValid, err := subRepo.TestSubscriptionFilter(r.Context(), test.Request.Metadata, test.Schema.Metadata, false)
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to validate subscription filter")
+		_ = render.Render(w, r, util.NewErrorResponse("failed to validate subscription filter", http.StatusBadRequest))
+		return
+	}
+
+	isExpressionValid := true
+	if !util.IsStringEmpty(test.Schema.Expression) {
+		program, err := celfilter.Compile(test.Schema.Expression)
+		if err != nil {
+			_ = render.Render(w, r, util.NewErrorResponse(fmt.Sprintf("invalid filter expression: %v", err), http.StatusBadRequest))
+			return
+		}
+
+		var headers map[string]interface{}
+		if h, ok := test.Request.Headers.(map[string]interface{}); ok {
+			headers = h
+		}
+
+		in := celfilter.Input{
+			Body:      test.Request.Body,
+			Headers:   headers,
+			EventType: test.Request.EventType,
+			Source:    test.Request.Source,
+		}
+
+		// an expression that fails to evaluate doesn't match, as when
+		// events are matched to subscriptions
+		isExpressionValid, err = celfilter.Match(program, in)
+		if err != nil {
+			log.FromContext(r.Context()).WithError(err).Debug("filter expression did not evaluate")
+		}
+	}
+
+	isValid := isBodyValid && isHeaderValid && isMetadataValid && isExpressionValid
 
 	_ = render.Render(w, r, util.NewServerResponse("Filter validated successfully", isValid, http.StatusOK))
 }
 
+// ExplainSubscriptionFilter
+//
+//	@Summary		Explain subscription filter
+//	@Description	This endpoint evaluates a filter against a payload and explains which of its clauses matched.
+//	@Id				ExplainSubscriptionFilter
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string				true	"Project ID"
+//	@Param			filter		body		models.TestFilter	true	"Filter Details"
+//	@Success		200			{object}	util.ServerResponse{data=models.FilterExplanation}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/test_filter/explain [post]
+func (h *Handler) ExplainSubscriptionFilter(w http.ResponseWriter, r *http.Request) {
+	var test models.TestFilter
+	err := util.ReadJSON(r, &test)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	filter, err := test.Schema.Transform()
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	in := services.FilterInput{
+		Body:      test.Request.Body,
+		EventType: test.Request.EventType,
+		Source:    test.Request.Source,
+	}
+
+	if headers, ok := test.Request.Headers.(map[string]interface{}); ok {
+		in.Headers = headers
+	}
+
+	if metadata, ok := test.Request.Metadata.(map[string]interface{}); ok {
+		in.Metadata = metadata
+	}
+
+	explanation, err := services.ExplainFilter(filter, in)
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to explain subscription filter")
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Filter explained successfully", explanation, http.StatusOK))
+}
+
 // TestSubscriptionFunction
 //
 //	@Summary		Test a subscription function
@@ -449,8 +540,20 @@ func (h *Handler) TestSubscriptionFunction(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	transformer := transform.NewTransformer()
-	mutatedPayload, consoleLog, err := transformer.Transform(test.Function, test.Payload)
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	function, err := h.retrieveTestFunction(r, project, &test, datastore.SubscriptionFunctionOwner)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	transformer := functions.NewTransformer(r.Context(), project.UID)
+	mutatedPayload, consoleLog, err := transformer.Transform(function, test.Payload)
 	if err != nil {
 		log.FromContext(r.Context()).WithError(err).Error("failed to transform function")
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
@@ -1,12 +1,18 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/frain-dev/convoy/pkg/msgpack"
 	"gopkg.in/guregu/null.v4"
 
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/frain-dev/convoy/internal/pkg/dedup"
@@ -14,11 +20,19 @@ import (
 	"github.com/oklog/ulid/v2"
 
 	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/internal/pkg/crc"
+	"github.com/frain-dev/convoy/internal/pkg/clientauth"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/internal/pkg/providers"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/normalize"
 	"github.com/frain-dev/convoy/pkg/verifier"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/util"
@@ -26,20 +40,257 @@ import (
 	"github.com/go-chi/render"
 )
 
+const (
+	defaultSyncForwardingTimeout = 10 * time.Second
+
+	// maxIngestBatchSize is the most events a batch request can contain.
+	maxIngestBatchSize = 1000
+)
+
+// ingestRequest is a request to a source's ingest url that has passed the
+// source's client restrictions, rate limits and verifier.
+type ingestRequest struct {
+	cfg      config.Configuration
+	source   *datastore.Source
+	project  *datastore.Project
+	provider providers.Provider
+	payload  []byte
+	metadata datastore.M
+}
+
 func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request) {
+	in := a.verifyIngestRequest(w, r)
+	if in == nil {
+		return
+	}
+
+	source := in.source
+
+	if in.provider != nil {
+		if challenge, ok := in.provider.Challenge(r, in.payload); ok {
+			w.Header().Set("Content-Type", challenge.ContentType)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(challenge.Body)
+			return
+		}
+	}
+
+	// 3.2 Check the idempotency key once the request has been verified, so
+	// rejected requests aren't remembered as seen.
+	checksum, isDuplicate, err := a.checkIdempotency(r, source, in.payload)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if isDuplicate {
+		w.Header().Set(dedup.DuplicateEventHeader, "true")
+	}
+
+	// 3.3 Convert form, multipart and XML bodies to JSON so they can be
+	// filtered and transformed like every other event.
+	data, originalBody, err := normalizePayload(r, source, in.payload)
+	if err != nil {
+		if !isDuplicate {
+			a.forgetIdempotencyKey(r.Context(), source, checksum)
+		}
+
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	headers, err := transformHeaders(r, source)
+	if err != nil {
+		if !isDuplicate {
+			a.forgetIdempotencyKey(r.Context(), source, checksum)
+		}
+
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	// 3.4 On success
+	// Attach Source to Event.
+	// Write Event to the Ingestion Queue.
+	event := a.newIngestEvent(r, in, data, originalBody, headers, checksum, isDuplicate)
+
+	// 3.5 Count the event against the project's quota.
+	if err = limiter.TakeIngestQuota(r.Context(), a.A.Rate, in.project); err != nil {
+		if !isDuplicate {
+			a.forgetIdempotencyKey(r.Context(), source, checksum)
+		}
+
+		rejectIngest(w, r, source, err)
+		return
+	}
+
+	createEvent := task.CreateEvent{
+		Event: event,
+	}
+
+	// 3.6 Forward the event while the sender waits, the queue is used
+	// as usual when the endpoint doesn't respond in time.
+	var forwarded *net.Response
+	if source.SyncForwarding != nil && !event.IsDuplicateEvent {
+		forwarded, createEvent.SyncDelivery = a.forwardEvent(r.Context(), in.cfg, in.project, source, event)
+	}
+
+	err = a.queueIngestEvent(r.Context(), createEvent)
+	if err != nil {
+		a.A.Logger.WithError(err).Error("Error occurred sending new event to the queue")
+		if forwarded == nil {
+			if !isDuplicate {
+				a.forgetIdempotencyKey(r.Context(), source, checksum)
+			}
+
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+			return
+		}
+	}
+
+	// 4. Relay the endpoint's response when the event was forwarded
+	if forwarded != nil {
+		if contentType := forwarded.ResponseHeader.Get("Content-Type"); !util.IsStringEmpty(contentType) {
+			w.Header().Set("Content-Type", contentType)
+		}
+
+		w.WriteHeader(forwarded.StatusCode)
+		_, _ = w.Write(forwarded.Body)
+		return
+	}
+
+	// 5. Return 200
+	if !util.IsStringEmpty(source.CustomResponse.Body) {
+		// send back custom response
+		if !util.IsStringEmpty(source.CustomResponse.ContentType) {
+			w.Header().Set("Content-Type", source.CustomResponse.ContentType)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(source.CustomResponse.Body))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.PlainText(w, r, source.CustomResponse.Body)
+		return
+
+	}
+
+	if event.IsDuplicateEvent {
+		_ = render.Render(w, r, util.NewServerResponse("Duplicate event received, but will not be sent", len(data), http.StatusOK))
+	} else {
+		_ = render.Render(w, r, util.NewServerResponse("Event received", len(data), http.StatusOK))
+	}
+}
+
+// IngestEventBatch accepts a JSON array or newline delimited JSON and
+// ingests each item as an event. Idempotency keys are read from each item's
+// body, header and query param keys apply to the whole batch.
+func (a *ApplicationHandler) IngestEventBatch(w http.ResponseWriter, r *http.Request) {
+	in := a.verifyIngestRequest(w, r)
+	if in == nil {
+		return
+	}
+
+	items, err := splitBatch(in.payload)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if len(items) > maxIngestBatchSize {
+		_ = render.Render(w, r, util.NewErrorResponse(fmt.Sprintf("a batch can contain at most %d events", maxIngestBatchSize), http.StatusBadRequest))
+		return
+	}
+
+	resp := &models.IngestBatchResponse{Items: make([]models.IngestBatchItem, 0, len(items))}
+	for i, item := range items {
+		result := a.ingestBatchItem(r, in, item)
+		result.Index = i
+
+		switch result.Status {
+		case models.AcceptedBatchItemStatus:
+			resp.Accepted++
+		case models.DuplicateBatchItemStatus:
+			resp.Duplicates++
+		default:
+			resp.Rejected++
+		}
+
+		resp.Items = append(resp.Items, result)
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Batch received", resp, http.StatusOK))
+}
+
+func (a *ApplicationHandler) ingestBatchItem(r *http.Request, in *ingestRequest, item json.RawMessage) models.IngestBatchItem {
+	if !json.Valid(item) {
+		return models.IngestBatchItem{Status: models.RejectedBatchItemStatus, Error: "invalid json"}
+	}
+
+	// each item is ingested as though it was sent on its own
+	itemReq := r.Clone(r.Context())
+	itemReq.Header.Set("Content-Type", "application/json")
+
+	data, _, err := normalizePayload(itemReq, in.source, item)
+	if err != nil {
+		return models.IngestBatchItem{Status: models.RejectedBatchItemStatus, Error: err.Error()}
+	}
+
+	headers, err := transformHeaders(itemReq, in.source)
+	if err != nil {
+		return models.IngestBatchItem{Status: models.RejectedBatchItemStatus, Error: err.Error()}
+	}
+
+	checksum, isDuplicate, err := a.checkIdempotency(itemReq, in.source, item)
+	if err != nil {
+		return models.IngestBatchItem{Status: models.RejectedBatchItemStatus, Error: err.Error()}
+	}
+
+	event := a.newIngestEvent(itemReq, in, data, "", headers, checksum, isDuplicate)
+
+	if err = limiter.TakeIngestQuota(r.Context(), a.A.Rate, in.project); err != nil {
+		metrics.GetDPInstance().IncrementIngestRejectedTotal(in.source, limiter.QuotaExceededReason)
+		if !isDuplicate {
+			a.forgetIdempotencyKey(r.Context(), in.source, checksum)
+		}
+
+		return models.IngestBatchItem{Status: models.RejectedBatchItemStatus, Error: err.Error()}
+	}
+
+	if err = a.queueIngestEvent(r.Context(), task.CreateEvent{Event: event}); err != nil {
+		a.A.Logger.WithError(err).Error("Error occurred sending new event to the queue")
+		if !isDuplicate {
+			a.forgetIdempotencyKey(r.Context(), in.source, checksum)
+		}
+
+		return models.IngestBatchItem{Status: models.RejectedBatchItemStatus, Error: "failed to queue event"}
+	}
+
+	status := models.AcceptedBatchItemStatus
+	if isDuplicate {
+		status = models.DuplicateBatchItemStatus
+	}
+
+	return models.IngestBatchItem{EventID: event.UID, Status: status}
+}
+
+// verifyIngestRequest loads the source the request was sent to and applies
+// the checks shared by single and batch ingestion. The error response is
+// written and nil returned when the request is rejected.
+func (a *ApplicationHandler) verifyIngestRequest(w http.ResponseWriter, r *http.Request) *ingestRequest {
 	cfg, err := config.Get()
 	if err != nil {
 		_ = render.Render(w, r, util.NewErrorResponse("failed to load config", http.StatusBadRequest))
-		return
+		return nil
 	}
 
 	err = a.A.Rate.Allow(r.Context(), cfg.InstanceId, cfg.InstanceIngestRate)
 	if err != nil {
+		setRetryAfter(w, err, time.Second)
 		_ = render.Render(w, r, util.NewErrorResponse("rate limit exceeded", http.StatusTooManyRequests))
-		return
+		return nil
 	}
 
-	// s.AppService.CountProjectApplications()
 	// 1. Retrieve mask ID
 	maskID := chi.URLParam(r, "maskID")
 
@@ -48,24 +299,45 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		if errors.Is(err, datastore.ErrSourceNotFound) {
 			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
-			return
+			return nil
 		}
 		_ = render.Render(w, r, util.NewErrorResponse("error retrieving source", http.StatusBadRequest))
-		return
+		return nil
 	}
 
-	// 2. Retrieve source using mask ID.
+	// 2. Retrieve the source's project.
 	projectRepo := postgres.NewProjectRepo(a.A.DB, a.A.Cache)
 	project, err := projectRepo.FetchProjectByID(r.Context(), source.ProjectID)
 	if err != nil {
 		_ = render.Render(w, r, util.NewServiceErrResponse(err))
-		return
+		return nil
 	}
 
 	if source.Type != datastore.HTTPSource {
 		_ = render.Render(w, r, util.NewErrorResponse("Source type needs to be HTTP",
 			http.StatusBadRequest))
-		return
+		return nil
+	}
+
+	// 2.1 Restrict the clients allowed to send to this source.
+	reason, err := verifyIngestClient(r, cfg, source)
+	if err != nil {
+		if len(reason) == 0 {
+			a.A.Logger.WithError(err).Error("failed to verify ingest client")
+			_ = render.Render(w, r, util.NewErrorResponse("failed to verify client", http.StatusInternalServerError))
+			return nil
+		}
+
+		metrics.GetDPInstance().IncrementIngestRejectedTotal(source, reason)
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusForbidden))
+		return nil
+	}
+
+	// 2.2 Apply the source's and the project's rate limits.
+	err = limiter.AllowIngest(r.Context(), a.A.Rate, source, project)
+	if err != nil {
+		rejectIngest(w, r, source, err)
+		return nil
 	}
 
 	// 3. Select verifier based of source config.
@@ -73,18 +345,27 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 	var v verifier.Verifier
 	verifierConfig := source.Verifier
 
+	var provider providers.Provider
 	if !util.IsStringEmpty(string(source.Provider)) {
-		switch source.Provider {
-		case datastore.GithubSourceProvider:
-			v = verifier.NewGithubVerifier(verifierConfig.HMac.Secret)
-		case datastore.TwitterSourceProvider:
-			v = verifier.NewTwitterVerifier(verifierConfig.HMac.Secret)
-		case datastore.ShopifySourceProvider:
-			v = verifier.NewShopifyVerifier(verifierConfig.HMac.Secret)
-		default:
+		var ok bool
+		provider, ok = providers.Get(source.Provider)
+		if !ok {
 			_ = render.Render(w, r, util.NewErrorResponse("Provider type undefined",
 				http.StatusBadRequest))
-			return
+			return nil
+		}
+
+		v = provider.Verifier(verifierConfig)
+
+		if pv, ok := v.(verifier.ProxiedVerifier); ok {
+			trustedProxies, err := clientauth.ParseCIDRs(cfg.Server.HTTP.TrustedProxies)
+			if err != nil {
+				a.A.Logger.WithError(err).Error("failed to parse trusted proxies")
+				_ = render.Render(w, r, util.NewErrorResponse("failed to verify request", http.StatusInternalServerError))
+				return nil
+			}
+
+			pv.SetTrustedProxies(trustedProxies)
 		}
 	} else {
 		switch verifierConfig.Type {
@@ -107,6 +388,15 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 				verifierConfig.ApiKey.HeaderValue,
 				verifierConfig.ApiKey.HeaderName,
 			)
+		case datastore.JWTVerifier:
+			v = verifier.NewJWTVerifier(&verifier.JWTOptions{
+				Algorithm:     verifierConfig.JWT.Algorithm,
+				Key:           verifierConfig.JWT.Key,
+				JwksURL:       verifierConfig.JWT.JwksURL,
+				Issuer:        verifierConfig.JWT.Issuer,
+				Audience:      verifierConfig.JWT.Audience,
+				BodyHashClaim: verifierConfig.JWT.BodyHashClaim,
+			})
 		default:
 			v = &verifier.NoopVerifier{}
 		}
@@ -118,116 +408,133 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 	}
 
 	if maxIngestSize == 0 {
-		cfg, err := config.Get()
-		if err != nil {
-			a.A.Logger.WithError(err).Error("failed to load config")
-			_ = render.Render(w, r, util.NewErrorResponse("failed to load config", http.StatusBadRequest))
-			return
-		}
-
 		maxIngestSize = cfg.MaxResponseSize
 	}
 
-	var checksum string
-	var isDuplicate bool
-	if len(source.IdempotencyKeys) > 0 {
-		duper := dedup.NewDeDuper(r.Context(), r, postgres.NewEventRepo(a.A.DB, a.A.Cache))
-		exists, err := duper.Exists(source.Name, source.ProjectID, source.IdempotencyKeys)
+	// 3.1 On Failure
+	// Return 400 Bad Request.
+	body := io.LimitReader(r.Body, int64(maxIngestSize))
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return nil
+	}
+
+	var metadata datastore.M
+	if cv, ok := v.(verifier.ClaimsVerifier); ok {
+		claims, err := cv.VerifyClaims(r, payload)
 		if err != nil {
 			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
-			return
+			return nil
 		}
 
-		isDuplicate = exists
+		metadata = datastore.M{"jwt": claims}
+	} else if err = v.VerifyRequest(r, payload); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return nil
+	}
 
-		checksum, err = duper.GenerateChecksum(source.Name, source.IdempotencyKeys)
-		if err != nil {
-			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
-			return
-		}
+	return &ingestRequest{
+		cfg:      cfg,
+		source:   source,
+		project:  project,
+		provider: provider,
+		payload:  payload,
+		metadata: metadata,
+	}
+}
+
+// checkIdempotency records the checksum of the request's idempotency keys,
+// the checksum is empty when the source has no idempotency keys.
+func (a *ApplicationHandler) checkIdempotency(r *http.Request, source *datastore.Source, payload []byte) (string, bool, error) {
+	if len(source.IdempotencyKeys) == 0 {
+		return "", false, nil
 	}
 
-	// 3.1 On Failure
-	// Return 400 Bad Request.
-	body := io.LimitReader(r.Body, int64(maxIngestSize))
-	payload, err := io.ReadAll(body)
+	r.Body = io.NopCloser(bytes.NewReader(payload))
+
+	duper := dedup.NewDeDuper(r.Context(), r, a.A.Dedup, dedup.Window(source.DedupWindow))
+	exists, err := duper.Exists(source.Name, source.ProjectID, source.IdempotencyKeys)
 	if err != nil {
-		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
-		return
+		return "", false, err
 	}
 
-	if err = v.VerifyRequest(r, payload); err != nil {
-		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+	checksum, err := duper.GenerateChecksum(source.Name, source.IdempotencyKeys)
+	if err != nil {
+		return "", false, err
+	}
+
+	if exists {
+		metrics.GetDPInstance().IncrementIngestDuplicateTotal(source)
+	}
+
+	return checksum, exists, nil
+}
+
+// forgetIdempotencyKey is used when an event couldn't be accepted after its
+// idempotency key was recorded, so the sender's retry isn't a duplicate.
+func (a *ApplicationHandler) forgetIdempotencyKey(ctx context.Context, source *datastore.Source, checksum string) {
+	if len(checksum) == 0 {
 		return
 	}
 
-	if len(payload) == 0 {
-		payload = []byte("{}")
+	if err := a.A.Dedup.Forget(ctx, dedup.Key(source.ProjectID, checksum)); err != nil {
+		a.A.Logger.WithError(err).Error("failed to forget idempotency key")
+	}
+}
+
+// newIngestEvent builds the event for data, the event type is read as
+// described by the source's event type config.
+func (a *ApplicationHandler) newIngestEvent(r *http.Request, in *ingestRequest, data json.RawMessage, originalBody string, headers httpheader.HTTPHeader, checksum string, isDuplicate bool) *datastore.Event {
+	source := in.source
+
+	// sources without an event type extractor use the mask id
+	eventType := source.MaskID
+	if et, err := providers.ExtractEventType(r.Context(), source.ProjectID, r, source.EventTypeConfig, in.provider, data); err != nil {
+		a.A.Logger.WithError(err).Errorf("failed to extract event type for source %s", source.UID)
+	} else if !util.IsStringEmpty(et) {
+		eventType = et
 	}
 
-	// 3.2 On success
-	// Attach Source to Event.
-	// Write Event to the Ingestion Queue.
 	event := &datastore.Event{
 		UID:              ulid.Make().String(),
-		EventType:        datastore.EventType(maskID),
+		EventType:        datastore.EventType(eventType),
 		SourceID:         source.UID,
 		ProjectID:        source.ProjectID,
-		Raw:              string(payload),
-		Data:             payload,
+		Raw:              string(data),
+		Data:             data,
+		OriginalBody:     originalBody,
 		IsDuplicateEvent: isDuplicate,
 		URLQueryParams:   r.URL.RawQuery,
 		IdempotencyKey:   checksum,
-		Headers:          httpheader.HTTPHeader(r.Header),
+		Headers:          headers,
+		Metadata:         in.metadata,
 		AcknowledgedAt:   null.TimeFrom(time.Now()),
 	}
 
 	event.Headers["X-Convoy-Source-Id"] = []string{source.MaskID}
 
-	createEvent := task.CreateEvent{
-		Event: event,
+	return event
+}
+
+func (a *ApplicationHandler) queueIngestEvent(ctx context.Context, createEvent task.CreateEvent) error {
+	err := createEvent.OffloadPayload(ctx)
+	if err != nil {
+		return err
 	}
 
 	eventByte, err := msgpack.EncodeMsgPack(createEvent)
 	if err != nil {
-		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
-		return
+		return err
 	}
 
 	job := &queue.Job{
-		ID:      event.UID,
+		ID:      createEvent.Event.UID,
 		Payload: eventByte,
 		Delay:   0,
 	}
 
-	err = a.A.Queue.Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, job)
-	if err != nil {
-		a.A.Logger.WithError(err).Error("Error occurred sending new event to the queue")
-		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
-		return
-	}
-
-	// 4. Return 200
-	if !util.IsStringEmpty(source.CustomResponse.Body) {
-		// send back custom response
-		if !util.IsStringEmpty(source.CustomResponse.ContentType) {
-			w.Header().Set("Content-Type", source.CustomResponse.ContentType)
-			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write([]byte(source.CustomResponse.Body))
-			return
-		}
-
-		render.Status(r, http.StatusOK)
-		render.PlainText(w, r, source.CustomResponse.Body)
-		return
-
-	}
-
-	if event.IsDuplicateEvent {
-		_ = render.Render(w, r, util.NewServerResponse("Duplicate event received, but will not be sent", len(payload), http.StatusOK))
-	} else {
-		_ = render.Render(w, r, util.NewServerResponse("Event received", len(payload), http.StatusOK))
-	}
+	return a.A.Queue.Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, job)
 }
 
 func (a *ApplicationHandler) HandleCrcCheck(w http.ResponseWriter, r *http.Request) {
@@ -252,12 +559,14 @@ func (a *ApplicationHandler) HandleCrcCheck(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var c crc.Crc
+	provider, ok := providers.Get(source.Provider)
+	if !ok {
+		_ = render.Render(w, r, util.NewErrorResponse("Provider type is not supported", http.StatusBadRequest))
+		return
+	}
 
-	switch source.Provider {
-	case datastore.TwitterSourceProvider:
-		c = crc.NewTwitterCrc(source.Verifier.HMac.Secret)
-	default:
+	c := provider.Crc(source.Verifier)
+	if c == nil {
 		_ = render.Render(w, r, util.NewErrorResponse("Provider type is not supported", http.StatusBadRequest))
 		return
 	}
@@ -269,3 +578,208 @@ func (a *ApplicationHandler) HandleCrcCheck(w http.ResponseWriter, r *http.Reque
 		return
 	}
 }
+
+// forwardEvent sends the event to the source's sync forwarding endpoint.
+// Nothing is returned when the endpoint can't be used or doesn't respond
+// in time, the event is then delivered asynchronously.
+func (a *ApplicationHandler) forwardEvent(ctx context.Context, cfg config.Configuration, project *datastore.Project, source *datastore.Source, event *datastore.Event) (*net.Response, *task.SyncDelivery) {
+	if project.Config == nil {
+		return nil, nil
+	}
+
+	endpoint, err := postgres.NewEndpointRepo(a.A.DB, a.A.Cache).FindEndpointByID(ctx, source.SyncForwarding.EndpointID, project.UID)
+	if err != nil {
+		a.A.Logger.WithError(err).Errorf("failed to find sync forwarding endpoint for source %s", source.UID)
+		return nil, nil
+	}
+
+	if endpoint.Status == datastore.InactiveEndpointStatus {
+		return nil, nil
+	}
+
+	dispatch, err := net.NewDispatcher(cfg.Server.HTTP.HttpProxy, project.Config.SSL.EnforceSecureEndpoints)
+	if err != nil {
+		a.A.Logger.WithError(err).Error("failed to create dispatcher")
+		return nil, nil
+	}
+
+	timeout := defaultSyncForwardingTimeout
+	if source.SyncForwarding.Timeout > 0 {
+		timeout = time.Duration(source.SyncForwarding.Timeout) * time.Second
+	}
+
+	resp, syncDelivery, err := task.ForwardEvent(ctx, dispatch, project, endpoint, event, int64(cfg.MaxResponseSize), timeout)
+	if err != nil {
+		a.A.Logger.WithError(err).Warnf("failed to forward event %s, it will be delivered asynchronously", event.UID)
+		return nil, nil
+	}
+
+	return resp, syncDelivery
+}
+
+// setRetryAfter sets the Retry-After header in seconds, fallback is used
+// when the rate limiter doesn't report when the limit resets.
+// rejectIngest responds to a request rejected by an ingest rate limit or
+// quota.
+func rejectIngest(w http.ResponseWriter, r *http.Request, source *datastore.Source, err error) {
+	var limitErr *limiter.IngestLimitError
+	if !errors.As(err, &limitErr) {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter().Seconds()))))
+	metrics.GetDPInstance().IncrementIngestRejectedTotal(source, limitErr.Reason)
+	_ = render.Render(w, r, util.NewErrorResponse(limitErr.Error(), http.StatusTooManyRequests))
+}
+
+func setRetryAfter(w http.ResponseWriter, err error, fallback time.Duration) {
+	delay := rlimiter.GetRetryAfter(err)
+	if delay <= 0 {
+		delay = fallback
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+}
+
+// splitBatch splits a JSON array or newline delimited JSON into its items.
+func splitBatch(payload []byte) ([]json.RawMessage, error) {
+	payload = bytes.TrimSpace(payload)
+
+	var items []json.RawMessage
+	if len(payload) > 0 && payload[0] == '[' {
+		if err := json.Unmarshal(payload, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode batch: %v", err)
+		}
+	} else {
+		for _, line := range bytes.Split(payload, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) > 0 {
+				items = append(items, line)
+			}
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("the batch does not contain any events")
+	}
+
+	return items, nil
+}
+
+// normalizePayload converts the request body to JSON based on its content
+// type and applies the source's body function. The original body is
+// returned when it was converted.
+func normalizePayload(r *http.Request, source *datastore.Source, payload []byte) (json.RawMessage, string, error) {
+	data, originalBody := json.RawMessage(payload), ""
+	if len(payload) == 0 {
+		data = []byte("{}")
+	} else {
+		converted, ok, err := normalize.ToJSON(r.Header.Get("Content-Type"), payload)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if ok {
+			data, originalBody = converted, string(payload)
+		}
+	}
+
+	if source.BodyFunction != nil && !util.IsStringEmpty(*source.BodyFunction) {
+		var body interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, "", fmt.Errorf("failed to decode payload for body function: %v", err)
+		}
+
+		mutated, _, err := functions.NewTransformer(r.Context(), source.ProjectID).Transform(*source.BodyFunction, body)
+		if err != nil {
+			return nil, "", err
+		}
+
+		data, err = json.Marshal(mutated)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return data, originalBody, nil
+}
+
+// transformHeaders returns the headers forwarded with the event, the
+// source's header function is applied to them like it is for pubsub
+// sources, with the first value of each header.
+func transformHeaders(r *http.Request, source *datastore.Source) (httpheader.HTTPHeader, error) {
+	if source.HeaderFunction == nil || util.IsStringEmpty(*source.HeaderFunction) {
+		return httpheader.HTTPHeader(r.Header), nil
+	}
+
+	in := make(map[string]string, len(r.Header))
+	for name, values := range r.Header {
+		if len(values) > 0 {
+			in[name] = values[0]
+		}
+	}
+
+	h, _, err := functions.NewTransformer(r.Context(), source.ProjectID).Transform(*source.HeaderFunction, in)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := httpheader.HTTPHeader{}
+	switch castedH := h.(type) {
+	case map[string]any:
+		for name, value := range castedH {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("header function values should be strings, got: %+v, of type %T", value, value)
+			}
+
+			headers[name] = []string{s}
+		}
+	case map[string]string:
+		for name, value := range castedH {
+			headers[name] = []string{value}
+		}
+	default:
+		return nil, fmt.Errorf("header function should return an object of strings, got: %+v, of type %T", castedH, castedH)
+	}
+
+	return headers, nil
+}
+
+// verifyIngestClient enforces the source's ip allowlist and client
+// certificate CA. The returned reason is set when the client is rejected.
+func verifyIngestClient(r *http.Request, cfg config.Configuration, source *datastore.Source) (string, error) {
+	if len(source.IPAllowlist) == 0 && util.IsStringEmpty(source.ClientCACert) {
+		return "", nil
+	}
+
+	trustedProxies, err := clientauth.ParseCIDRs(cfg.Server.HTTP.TrustedProxies)
+	if err != nil {
+		return "", err
+	}
+
+	if len(source.IPAllowlist) > 0 {
+		allowlist, err := clientauth.ParseCIDRs(source.IPAllowlist)
+		if err != nil {
+			return "", err
+		}
+
+		if err = clientauth.VerifyIP(r, allowlist, trustedProxies); err != nil {
+			return "ip_not_allowed", err
+		}
+	}
+
+	if !util.IsStringEmpty(source.ClientCACert) {
+		pool, err := clientauth.ParseCertPool(source.ClientCACert)
+		if err != nil {
+			return "", err
+		}
+
+		if err = clientauth.VerifyClientCert(r, pool, trustedProxies); err != nil {
+			return "invalid_client_cert", err
+		}
+	}
+
+	return "", nil
+}
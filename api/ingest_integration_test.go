@@ -4,6 +4,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,11 +13,14 @@ import (
 
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
 
+	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/api/testdb"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/stretchr/testify/suite"
@@ -370,6 +374,258 @@ func (i *IngestIntegrationTestSuite) Test_IngestEvent_NoopVerifier_EmptyRequestB
 	require.Equal(i.T(), float64(2), response["data"].(float64))
 }
 
+func (i *IngestIntegrationTestSuite) Test_IngestEvent_FormBody() {
+	maskID := "123456"
+	sourceID := "123456789"
+	expectedStatusCode := http.StatusOK
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	_, _ = testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+
+	// Arrange Request.
+	url := fmt.Sprintf("/ingest/%s", maskID)
+	req := createRequest(http.MethodPost, url, "", serialize("name=convoy&tag=a&tag=b"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), expectedStatusCode, w.Code)
+
+	var response map[string]interface{}
+	err := json.NewDecoder(w.Body).Decode(&response)
+	require.NoError(i.T(), err)
+
+	// the form body is stored as {"name":"convoy","tag":["a","b"]}
+	require.Equal(i.T(), float64(33), response["data"].(float64))
+}
+
+func (i *IngestIntegrationTestSuite) Test_IngestEvent_HeaderFunction() {
+	maskID := "123456"
+	sourceID := "123456789"
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	source, err := testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+	require.NoError(i.T(), err)
+
+	// the values the header function returns must be strings
+	headerFunction := `function transform(headers) { return { "X-Tenant": headers["X-Tenant"], "X-Count": 1 } }`
+	source.HeaderFunction = &headerFunction
+	err = postgres.NewSourceRepo(i.ConvoyApp.A.DB, nil).UpdateSource(context.Background(), i.DefaultProject.UID, source)
+	require.NoError(i.T(), err)
+
+	url := fmt.Sprintf("/ingest/%s", maskID)
+
+	// Arrange Request.
+	req := createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	req.Header.Set("X-Tenant", "acme")
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusBadRequest, w.Code)
+}
+
+func (i *IngestIntegrationTestSuite) Test_IngestEvent_IPAllowlist() {
+	maskID := "123456"
+	sourceID := "123456789"
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	source, err := testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+	require.NoError(i.T(), err)
+
+	source.IPAllowlist = []string{"203.0.113.0/24"}
+	err = postgres.NewSourceRepo(i.ConvoyApp.A.DB, nil).UpdateSource(context.Background(), i.DefaultProject.UID, source)
+	require.NoError(i.T(), err)
+
+	url := fmt.Sprintf("/ingest/%s", maskID)
+
+	// Arrange Request.
+	req := createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	req.RemoteAddr = "203.0.113.7:4321"
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusOK, w.Code)
+
+	// Arrange Request.
+	req = createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	req.RemoteAddr = "198.51.100.1:4321"
+	w = httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusForbidden, w.Code)
+}
+
+func (i *IngestIntegrationTestSuite) Test_IngestEvent_SourceRateLimit() {
+	maskID := "123456"
+	sourceID := ulid.Make().String()
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	source, err := testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+	require.NoError(i.T(), err)
+
+	source.IngestRateLimit = &datastore.IngestRateLimitConfiguration{Count: 1, Burst: 1, Duration: 60}
+	err = postgres.NewSourceRepo(i.ConvoyApp.A.DB, nil).UpdateSource(context.Background(), i.DefaultProject.UID, source)
+	require.NoError(i.T(), err)
+
+	url := fmt.Sprintf("/ingest/%s", maskID)
+
+	// Arrange Request.
+	req := createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusOK, w.Code)
+
+	// Arrange Request.
+	req = createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	w = httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(i.T(), w.Header().Get("Retry-After"))
+}
+
+func (i *IngestIntegrationTestSuite) Test_IngestEvent_DuplicateWithinDedupWindow() {
+	maskID := "123456"
+	sourceID := "123456789"
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	source, err := testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+	require.NoError(i.T(), err)
+
+	source.IdempotencyKeys = []string{"request.header.X-Delivery-Id"}
+	source.DedupWindow = 60
+	err = postgres.NewSourceRepo(i.ConvoyApp.A.DB, nil).UpdateSource(context.Background(), i.DefaultProject.UID, source)
+	require.NoError(i.T(), err)
+
+	url := fmt.Sprintf("/ingest/%s", maskID)
+	deliveryID := ulid.Make().String()
+
+	// Arrange Request.
+	req := createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	req.Header.Set("X-Delivery-Id", deliveryID)
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusOK, w.Code)
+	require.Empty(i.T(), w.Header().Get(dedup.DuplicateEventHeader))
+
+	// Arrange Request.
+	req = createRequest(http.MethodPost, url, "", serialize(`{ "name": "convoy" }`))
+	req.Header.Set("X-Delivery-Id", deliveryID)
+	w = httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusOK, w.Code)
+	require.Equal(i.T(), "true", w.Header().Get(dedup.DuplicateEventHeader))
+}
+
+func (i *IngestIntegrationTestSuite) Test_IngestEventBatch_NDJSON() {
+	maskID := "123456"
+	sourceID := ulid.Make().String()
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	source, err := testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+	require.NoError(i.T(), err)
+
+	source.IdempotencyKeys = []string{"request.body.id"}
+	err = postgres.NewSourceRepo(i.ConvoyApp.A.DB, nil).UpdateSource(context.Background(), i.DefaultProject.UID, source)
+	require.NoError(i.T(), err)
+
+	url := fmt.Sprintf("/ingest/%s/batch", maskID)
+	body := "{\"id\": \"1\"}\n\n{\"id\": \"2\"}\n{\"id\": \"1\"}\nnot json\n"
+
+	// Arrange Request.
+	req := createRequest(http.MethodPost, url, "", serialize(body))
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusOK, w.Code)
+
+	var resp models.IngestBatchResponse
+	parseResponse(i.T(), w.Result(), &resp)
+
+	require.Equal(i.T(), 2, resp.Accepted)
+	require.Equal(i.T(), 1, resp.Duplicates)
+	require.Equal(i.T(), 1, resp.Rejected)
+	require.Len(i.T(), resp.Items, 4)
+	require.Equal(i.T(), models.AcceptedBatchItemStatus, resp.Items[0].Status)
+	require.Equal(i.T(), models.AcceptedBatchItemStatus, resp.Items[1].Status)
+	require.Equal(i.T(), models.DuplicateBatchItemStatus, resp.Items[2].Status)
+	require.Equal(i.T(), models.RejectedBatchItemStatus, resp.Items[3].Status)
+	require.NotEmpty(i.T(), resp.Items[0].EventID)
+}
+
+func (i *IngestIntegrationTestSuite) Test_IngestEventBatch_EmptyBatch() {
+	maskID := "123456"
+	sourceID := ulid.Make().String()
+
+	// Just Before
+	v := &datastore.VerifierConfig{
+		Type: datastore.NoopVerifier,
+	}
+	_, err := testdb.SeedSource(i.ConvoyApp.A.DB, i.DefaultProject, sourceID, maskID, "", v, "", "")
+	require.NoError(i.T(), err)
+
+	url := fmt.Sprintf("/ingest/%s/batch", maskID)
+
+	// Arrange Request.
+	req := createRequest(http.MethodPost, url, "", serialize("[]"))
+	w := httptest.NewRecorder()
+
+	// Act.
+	i.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(i.T(), http.StatusBadRequest, w.Code)
+}
+
 func (i *IngestIntegrationTestSuite) Test_IngestEvent_WriteToQueueFailed() {
 	i.T().Skip("Depends on mocking")
 }
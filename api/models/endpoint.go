@@ -58,11 +58,27 @@ type CreateEndpoint struct {
 	// the internet.
 	Authentication *EndpointAuthentication `json:"authentication"`
 
+	// EventTypeVersions pins the version of each event type the endpoint
+	// receives, payloads are converted down to the pinned version
+	EventTypeVersions map[string]int `json:"event_type_versions"`
+
+	// Labels are key/value pairs e.g. region=eu, events can be fanned out
+	// to the endpoints that match a label selector
+	Labels map[string]string `json:"labels"`
+
 	// Deprecated but necessary for backward compatibility
 	AppID string
 }
 
 func (cE *CreateEndpoint) Validate() error {
+	if err := validateEventTypeVersions(cE.EventTypeVersions); err != nil {
+		return err
+	}
+
+	if err := datastore.Labels(cE.Labels).Validate(); err != nil {
+		return err
+	}
+
 	return util.Validate(cE)
 }
 
@@ -115,9 +131,25 @@ type UpdateEndpoint struct {
 	// shouldn't be needed often because webhook endpoints usually should be exposed to
 	// the internet.
 	Authentication *EndpointAuthentication `json:"authentication"`
+
+	// EventTypeVersions pins the version of each event type the endpoint
+	// receives, payloads are converted down to the pinned version
+	EventTypeVersions map[string]int `json:"event_type_versions"`
+
+	// Labels are key/value pairs e.g. region=eu, they replace the
+	// endpoint's labels when set
+	Labels map[string]string `json:"labels"`
 }
 
 func (uE *UpdateEndpoint) Validate() error {
+	if err := validateEventTypeVersions(uE.EventTypeVersions); err != nil {
+		return err
+	}
+
+	if err := datastore.Labels(uE.Labels).Validate(); err != nil {
+		return err
+	}
+
 	return util.Validate(uE)
 }
 
@@ -126,6 +158,8 @@ type QueryListEndpoint struct {
 	Name string `json:"q" example:"endpoint-1"`
 	// The owner ID of the endpoint
 	OwnerID string `json:"ownerId" example:"01H0JA5MEES38RRK3HTEJC647K"`
+	// A list of key=value labels the endpoints must have
+	Labels []string `json:"labels" example:"region=eu"`
 	Pageable
 }
 
@@ -134,14 +168,20 @@ type QueryListEndpointResponse struct {
 	*datastore.Filter
 }
 
-func (q *QueryListEndpoint) Transform(r *http.Request) *QueryListEndpointResponse {
+func (q *QueryListEndpoint) Transform(r *http.Request) (*QueryListEndpointResponse, error) {
+	labels, err := datastore.ParseLabelSelector(r.URL.Query()["labels"])
+	if err != nil {
+		return nil, err
+	}
+
 	return &QueryListEndpointResponse{
 		Pageable: m.GetPageableFromContext(r.Context()),
 		Filter: &datastore.Filter{
 			Query:   strings.TrimSpace(r.URL.Query().Get("q")),
 			OwnerID: r.URL.Query().Get("ownerId"),
+			Labels:  labels,
 		},
-	}
+	}, nil
 }
 
 type EndpointAuthentication struct {
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -76,6 +77,10 @@ type SearchParams struct {
 	StartDate string `json:"startDate" example:"2006-01-02T15:04:05"`
 	// The end date
 	EndDate string `json:"endDate" example:"2008-05-02T15:04:05"`
+	// Payload filters in the form path=value, only the events or deliveries
+	// whose payload has the value at the path are returned. Encrypted and
+	// offloaded payloads aren't matched.
+	Payload []string `json:"payload" example:"order.id=123"`
 }
 
 type QueryListEvent struct {
@@ -158,7 +163,11 @@ func (bs *BroadcastEvent) Validate() error {
 
 type FanoutEvent struct {
 	// Used for fanout, sends this event to all endpoints with this OwnerID.
-	OwnerID string `json:"owner_id" valid:"required~please provide an owner id"`
+	OwnerID string `json:"owner_id"`
+
+	// Labels is a selector e.g. {"region": "eu"}, the event is sent to all
+	// endpoints with these labels. Either owner_id or labels is required.
+	Labels map[string]string `json:"labels"`
 
 	// Event Type is used for filtering and debugging e.g invoice.paid
 	EventType string `json:"event_type" valid:"required~please provide an event type"`
@@ -175,6 +184,18 @@ type FanoutEvent struct {
 }
 
 func (fe *FanoutEvent) Validate() error {
+	if util.IsStringEmpty(fe.OwnerID) && len(fe.Labels) == 0 {
+		return errors.New("please provide an owner id or labels")
+	}
+
+	if !util.IsStringEmpty(fe.OwnerID) && len(fe.Labels) > 0 {
+		return errors.New("only one of owner id and labels can be provided")
+	}
+
+	if err := datastore.Labels(fe.Labels).Validate(); err != nil {
+		return err
+	}
+
 	return util.Validate(fe)
 }
 
@@ -182,6 +203,32 @@ type EventResponse struct {
 	*datastore.Event
 }
 
+// RoutePreview reports which of a project's subscriptions an event would
+// be routed to and why.
+type RoutePreview struct {
+	EventID       string                     `json:"event_id"`
+	EventType     string                     `json:"event_type"`
+	Subscriptions []SubscriptionRoutePreview `json:"subscriptions"`
+}
+
+type SubscriptionRoutePreview struct {
+	SubscriptionID string `json:"subscription_id"`
+	Name           string `json:"name"`
+	EndpointID     string `json:"endpoint_id,omitempty"`
+	SourceID       string `json:"source_id,omitempty"`
+
+	// Matched is true when the event would be delivered to the subscription
+	Matched bool `json:"matched"`
+
+	// RouteMatched is true when the subscription's endpoint is one of the
+	// event's endpoints or, for incoming projects, its source is the
+	// event's source
+	RouteMatched bool `json:"route_matched"`
+
+	EventTypeMatched bool               `json:"event_type_matched"`
+	Filter           *FilterExplanation `json:"filter"`
+}
+
 type QueryCountAffectedEvents struct {
 	SourceID   string `json:"sourceId"`
 	EndpointID string `json:"endpointId"`
@@ -264,10 +311,43 @@ func getSearchParams(r *http.Request) (datastore.SearchParams, error) {
 		return searchParams, err
 	}
 
+	payloadFilters, err := getPayloadFilters(r)
+	if err != nil {
+		return searchParams, err
+	}
+
 	searchParams = datastore.SearchParams{
 		CreatedAtStart: startT.Unix(),
 		CreatedAtEnd:   endT.Unix(),
+		PayloadFilters: payloadFilters,
 	}
 
 	return searchParams, nil
 }
+
+// maxPayloadFilters caps the payload filters of a request, each one is a
+// separate index lookup.
+const maxPayloadFilters = 5
+
+func getPayloadFilters(r *http.Request) ([]datastore.PayloadFilter, error) {
+	var filters []datastore.PayloadFilter
+
+	for _, s := range r.URL.Query()["payload"] {
+		if util.IsStringEmpty(s) {
+			continue
+		}
+
+		f, err := datastore.ParsePayloadFilter(s)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, f)
+	}
+
+	if len(filters) > maxPayloadFilters {
+		return nil, fmt.Errorf("at most %d payload filters can be used", maxPayloadFilters)
+	}
+
+	return filters, nil
+}
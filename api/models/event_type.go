@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+)
+
+type CreateEventType struct {
+	// Name of the event type, the event_type events of this type are sent with
+	Name string `json:"name" valid:"required~please provide a name"`
+
+	// Description of when the event is sent
+	Description string `json:"description"`
+
+	// JSONSchema the event's payload must match
+	JSONSchema json.RawMessage `json:"json_schema" swaggertype:"object"`
+
+	// Examples of the event's payload, they must match the schema
+	Examples []json.RawMessage `json:"examples" swaggertype:"array,object"`
+
+	// Deprecated event types are flagged in the catalog but still accepted
+	Deprecated bool `json:"deprecated"`
+}
+
+func (ce *CreateEventType) Validate() error {
+	return util.Validate(ce)
+}
+
+type UpdateEventType struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	// JSONSchema can't be changed, a new version of the event type must be
+	// created to change it
+	JSONSchema json.RawMessage   `json:"json_schema" swaggertype:"object"`
+	Examples   []json.RawMessage `json:"examples" swaggertype:"array,object"`
+	Deprecated *bool             `json:"deprecated"`
+}
+
+func (ue *UpdateEventType) Validate() error {
+	return util.Validate(ue)
+}
+
+type EventTypeResponse struct {
+	*datastore.ProjectEventType
+}
+
+type CreateEventTypeVersion struct {
+	// JSONSchema of the new version's payload
+	JSONSchema json.RawMessage `json:"json_schema" swaggertype:"object"`
+
+	// DownConverter is a js transform function that converts a payload of the
+	// new version to the current version
+	DownConverter string `json:"down_converter" valid:"required~please provide a down converter"`
+
+	// Examples of the new version's payload, they replace the event type's
+	// examples
+	Examples []json.RawMessage `json:"examples" swaggertype:"array,object"`
+}
+
+func (ce *CreateEventTypeVersion) Validate() error {
+	return util.Validate(ce)
+}
+
+type EventTypeVersionResponse struct {
+	*datastore.EventTypeVersion
+}
+
+func validateEventTypeVersions(versions map[string]int) error {
+	for eventType, version := range versions {
+		if version < 1 {
+			return fmt.Errorf("invalid version %d for event type %s", version, eventType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+)
+
+type CreateFunctionLibrary struct {
+	// Name the library is required with, it may contain letters, digits,
+	// dashes and underscores
+	Name string `json:"name" valid:"required~please provide a name,matches(^[a-zA-Z0-9_-]+$)~name may only contain letters, digits, dashes and underscores"`
+
+	// The library's source, its exports are set on module.exports
+	Function string `json:"function" valid:"required~please provide a function"`
+}
+
+func (cf *CreateFunctionLibrary) Validate() error {
+	return util.Validate(cf)
+}
+
+type UpdateFunctionLibrary struct {
+	Name     *string `json:"name" valid:"optional,matches(^[a-zA-Z0-9_-]+$)~name may only contain letters, digits, dashes and underscores"`
+	Function *string `json:"function"`
+}
+
+func (uf *UpdateFunctionLibrary) Validate() error {
+	return util.Validate(uf)
+}
+
+type FunctionVersionRequest struct {
+	// Version of the function
+	Version int `json:"version" valid:"required~please provide a version"`
+}
+
+func (fv *FunctionVersionRequest) Validate() error {
+	return util.Validate(fv)
+}
+
+type FunctionLibraryResponse struct {
+	*datastore.FunctionLibrary
+}
+
+type FunctionVersionResponse struct {
+	*datastore.FunctionVersion
+}
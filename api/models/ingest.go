@@ -0,0 +1,36 @@
+package models
+
+type BatchItemStatus string
+
+const (
+	AcceptedBatchItemStatus  BatchItemStatus = "accepted"
+	DuplicateBatchItemStatus BatchItemStatus = "duplicate"
+	RejectedBatchItemStatus  BatchItemStatus = "rejected"
+)
+
+type IngestBatchResponse struct {
+	// Number of events queued for delivery.
+	Accepted int `json:"accepted"`
+
+	// Number of events that matched an idempotency key seen within the
+	// source's dedup window, they are stored but not delivered.
+	Duplicates int `json:"duplicates"`
+
+	// Number of items that were not ingested.
+	Rejected int `json:"rejected"`
+
+	Items []IngestBatchItem `json:"items"`
+}
+
+type IngestBatchItem struct {
+	// Position of the item in the batch.
+	Index int `json:"index"`
+
+	// ID of the event created for the item.
+	EventID string `json:"event_id,omitempty"`
+
+	Status BatchItemStatus `json:"status"`
+
+	// Reason the item was rejected.
+	Error string `json:"error,omitempty"`
+}
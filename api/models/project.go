@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/frain-dev/convoy/config"
@@ -22,7 +23,11 @@ type CreateProject struct {
 }
 
 func (cP *CreateProject) Validate() error {
-	return util.Validate(cP)
+	if err := util.Validate(cP); err != nil {
+		return err
+	}
+
+	return cP.Config.validate()
 }
 
 type UpdateProject struct {
@@ -36,7 +41,11 @@ type UpdateProject struct {
 }
 
 func (uP *UpdateProject) Validate() error {
-	return util.Validate(uP)
+	if err := util.Validate(uP); err != nil {
+		return err
+	}
+
+	return uP.Config.validate()
 }
 
 type ProjectConfig struct {
@@ -74,6 +83,40 @@ type ProjectConfig struct {
 	// MultipleEndpointSubscriptions is used to configure if multiple subscriptions
 	// can be created for the endpoint in a project
 	MultipleEndpointSubscriptions bool `json:"multiple_endpoint_subscriptions"`
+
+	// IngestRateLimit limits the events accepted by all the project's sources
+	IngestRateLimit *IngestRateLimitConfiguration `json:"ingest_ratelimit"`
+
+	// IngestQuota caps the events the project's sources accept in a day or month
+	IngestQuota *IngestQuotaConfiguration `json:"ingest_quota"`
+
+	// ValidateEventTypes rejects events whose payload doesn't match the
+	// schema of their type in the event type catalog
+	ValidateEventTypes bool `json:"validate_event_types"`
+
+	// Redaction masks PII in the payloads and headers kept for the project's
+	// events and delivery attempts, the payloads sent to endpoints aren't changed
+	Redaction *RedactionConfiguration `json:"redaction"`
+
+	// EncryptPayloads encrypts the payloads and headers kept for the project's
+	// events and delivery attempts at rest, the instance must have encryption enabled
+	EncryptPayloads bool `json:"encrypt_payloads"`
+}
+
+func (pc *ProjectConfig) validate() error {
+	if pc == nil {
+		return nil
+	}
+
+	if err := pc.IngestRateLimit.validate(); err != nil {
+		return err
+	}
+
+	if pc.IngestQuota != nil && pc.IngestQuota.Count < 0 {
+		return errors.New("ingest quota count cannot be negative")
+	}
+
+	return pc.Redaction.transform().Validate()
 }
 
 func (pc *ProjectConfig) Transform() *datastore.ProjectConfig {
@@ -92,6 +135,11 @@ func (pc *ProjectConfig) Transform() *datastore.ProjectConfig {
 		Strategy:                      pc.Strategy.transform(),
 		Signature:                     pc.Signature.transform(),
 		MetaEvent:                     pc.MetaEvent.transform(),
+		IngestRateLimit:               pc.IngestRateLimit.Transform(),
+		IngestQuota:                   pc.IngestQuota.transform(),
+		ValidateEventTypes:            pc.ValidateEventTypes,
+		Redaction:                     pc.Redaction.transform(),
+		EncryptPayloads:               pc.EncryptPayloads,
 	}
 }
 
@@ -122,6 +170,97 @@ func (rc *RateLimitConfiguration) Transform() *datastore.RateLimitConfiguration
 	return &datastore.RateLimitConfiguration{Count: rc.Count, Duration: rc.Duration}
 }
 
+type IngestRateLimitConfiguration struct {
+	// Number of events allowed every duration
+	Count int `json:"count"`
+
+	// Number of events allowed at once after a quiet period, defaults to count
+	Burst int `json:"burst"`
+
+	// Rate limit window in seconds
+	Duration uint64 `json:"duration"`
+}
+
+func (rc *IngestRateLimitConfiguration) validate() error {
+	if rc == nil {
+		return nil
+	}
+
+	if rc.Count < 0 || rc.Burst < 0 {
+		return errors.New("ingest rate limit count and burst cannot be negative")
+	}
+
+	if rc.Count > 0 && rc.Duration == 0 {
+		return errors.New("please provide a valid ingest rate limit duration")
+	}
+
+	return nil
+}
+
+// Transform returns nil when count isn't set, which removes the limit.
+func (rc *IngestRateLimitConfiguration) Transform() *datastore.IngestRateLimitConfiguration {
+	if rc == nil || rc.Count == 0 {
+		return nil
+	}
+
+	return &datastore.IngestRateLimitConfiguration{Count: rc.Count, Burst: rc.Burst, Duration: rc.Duration}
+}
+
+type IngestQuotaConfiguration struct {
+	// Number of events allowed in each period, zero removes the quota
+	Count int `json:"count"`
+
+	// Quota period, supported values are `daily` and `monthly`. Periods are
+	// calendar days or months in UTC, defaults to `daily`
+	Period string `json:"period" valid:"optional,in(daily|monthly)~unsupported quota period"`
+}
+
+func (qc *IngestQuotaConfiguration) transform() *datastore.IngestQuotaConfiguration {
+	if qc == nil || qc.Count == 0 {
+		return nil
+	}
+
+	period := datastore.QuotaPeriod(qc.Period)
+	if util.IsStringEmpty(qc.Period) {
+		period = datastore.DailyQuotaPeriod
+	}
+
+	return &datastore.IngestQuotaConfiguration{Count: qc.Count, Period: period}
+}
+
+type RedactionConfiguration struct {
+	// JSON paths of the payload fields to mask e.g. `$.card.number`, a `*`
+	// matches any key or array element
+	Paths []string `json:"paths"`
+
+	// Names of the request and response headers to mask
+	Headers []string `json:"headers"`
+
+	// Built-in PII detectors, supported values are `email`, `card_number` and `ssn`
+	Detectors []string `json:"detectors"`
+
+	// Regular expressions whose matches are masked
+	Patterns []string `json:"patterns"`
+}
+
+func (rc *RedactionConfiguration) transform() datastore.RedactionConfiguration {
+	if rc == nil {
+		return datastore.RedactionConfiguration{}
+	}
+
+	detectors := make([]datastore.RedactionDetector, 0, len(rc.Detectors))
+	for _, d := range rc.Detectors {
+		detectors = append(detectors, datastore.RedactionDetector(d))
+	}
+
+	return datastore.RedactionConfiguration{
+		Paths:     rc.Paths,
+		Headers:   rc.Headers,
+		Detectors: detectors,
+		Patterns:  rc.Patterns,
+	}
+}
+
 type StrategyConfiguration struct {
 	Type       string `json:"type" valid:"optional~please provide a valid strategy type, in(linear|exponential)~unsupported strategy type"`
 	Duration   uint64 `json:"duration" valid:"optional~please provide a valid duration in seconds,int"`
@@ -196,6 +335,25 @@ type ProjectResponse struct {
 	*datastore.Project
 }
 
+type IngestUsageResponse struct {
+	// Quota period, `daily` or `monthly`
+	Period datastore.QuotaPeriod `json:"period"`
+
+	// Number of events the project's sources may accept in the period, zero
+	// when the project has no quota
+	Limit int `json:"limit"`
+
+	// Number of events accepted in the current period, events are only
+	// counted while the project has a quota
+	Used int `json:"used"`
+
+	// Number of events that may still be accepted in the current period
+	Remaining int `json:"remaining"`
+
+	// When the current period ends
+	ResetsAt time.Time `json:"resets_at"`
+}
+
 type CreateProjectResponse struct {
 	APIKey  *APIKeyResponse  `json:"api_key"`
 	Project *ProjectResponse `json:"project"`
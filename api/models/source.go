@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/clientauth"
 	m "github.com/frain-dev/convoy/internal/pkg/middleware"
+	"github.com/frain-dev/convoy/internal/pkg/providers"
 	"github.com/frain-dev/convoy/util"
 )
 
@@ -37,6 +39,11 @@ type CreateSource struct {
 	// identify the event in an incoming webhooks project.
 	IdempotencyKeys []string `json:"idempotency_keys"`
 
+	// DedupWindow is how long in seconds idempotency keys are remembered,
+	// events repeating a key within the window are marked as duplicates.
+	// Defaults to 24 hours.
+	DedupWindow uint64 `json:"dedup_window"`
+
 	// Function is a javascript function used to mutate the payload
 	// immediately after ingesting an event
 	BodyFunction *string `json:"body_function"`
@@ -44,6 +51,27 @@ type CreateSource struct {
 	// Function is a javascript function used to mutate the headers
 	// immediately after ingesting an event
 	HeaderFunction *string `json:"header_function"`
+
+	// IPAllowlist restricts ingestion to these ip addresses and CIDR
+	// blocks e.g. a provider's published egress ranges.
+	IPAllowlist []string `json:"ip_allowlist"`
+
+	// ClientCACert is a PEM encoded CA certificate, when set clients must
+	// present a certificate issued by it.
+	ClientCACert string `json:"client_ca_cert"`
+
+	// EventTypeConfig describes where to read the event type of ingested
+	// events from. It defaults to the provider's event type for provider
+	// sources and to the source's mask id otherwise.
+	EventTypeConfig *EventTypeConfig `json:"event_type_config"`
+
+	// SyncForwarding forwards ingested events to one endpoint while the
+	// sender waits, the endpoint's response is relayed back to the sender.
+	SyncForwarding *SyncForwardingConfig `json:"sync_forwarding"`
+
+	// IngestRateLimit limits the events accepted by this source, the
+	// project's ingest rate limit applies as well.
+	IngestRateLimit *IngestRateLimitConfiguration `json:"ingest_rate_limit"`
 }
 
 func (cs *CreateSource) Validate() error {
@@ -57,7 +85,7 @@ func (cs *CreateSource) Validate() error {
 		return err
 	}
 
-	if err := validateSourceVerifier(cs.Verifier); err != nil {
+	if err := cs.Verifier.Validate(); err != nil {
 		return err
 	}
 
@@ -65,10 +93,36 @@ func (cs *CreateSource) Validate() error {
 		return err
 	}
 
+	if err := validateDedupWindow(cs.DedupWindow); err != nil {
+		return err
+	}
+
+	if err := validateSourceClientAuth(cs.IPAllowlist, cs.ClientCACert); err != nil {
+		return err
+	}
+
+	if err := cs.EventTypeConfig.validate(); err != nil {
+		return err
+	}
+
+	if cs.EventTypeConfig != nil && cs.EventTypeConfig.Type == datastore.ProviderEventTypeSource && !cs.Provider.IsValid() {
+		return errors.New("the event type can only be read from a supported provider")
+	}
+
+	if err := cs.SyncForwarding.validate(); err != nil {
+		return err
+	}
+
+	if err := cs.IngestRateLimit.validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func validateSourceVerifier(cfg VerifierConfig) error {
+// Validate checks that the config of the verifier's type is set, it is
+// shared by the create and update source handlers and services.
+func (cfg VerifierConfig) Validate() error {
 	if cfg.Type == datastore.HMacVerifier && cfg.HMac == nil {
 		return errors.New("invalid verifier config for hmac")
 	}
@@ -81,6 +135,41 @@ func validateSourceVerifier(cfg VerifierConfig) error {
 		return errors.New("invalid verifier config for basic auth")
 	}
 
+	if cfg.Type == datastore.JWTVerifier {
+		if cfg.JWT == nil {
+			return errors.New("invalid verifier config for jwt")
+		}
+
+		if util.IsStringEmpty(cfg.JWT.Key) && util.IsStringEmpty(cfg.JWT.JwksURL) {
+			return errors.New("please provide either a key or a jwks url for the jwt verifier")
+		}
+	}
+
+	return nil
+}
+
+func validateSourceClientAuth(ipAllowlist []string, caCert string) error {
+	if _, err := clientauth.ParseCIDRs(ipAllowlist); err != nil {
+		return err
+	}
+
+	if !util.IsStringEmpty(caCert) {
+		if _, err := clientauth.ParseCertPool(caCert); err != nil {
+			return errors.New("invalid client ca certificate, please provide a PEM encoded certificate")
+		}
+	}
+
+	return nil
+}
+
+// MaxDedupWindow is the longest idempotency keys can be remembered, 30 days.
+const MaxDedupWindow = 30 * 24 * 60 * 60
+
+func validateDedupWindow(window uint64) error {
+	if window > MaxDedupWindow {
+		return fmt.Errorf("dedup window cannot be more than %d seconds", MaxDedupWindow)
+	}
+
 	return nil
 }
 
@@ -116,10 +205,7 @@ func validateSourceForProvider(newSource *CreateSource) error {
 		return errors.New("please provide a valid source type")
 	}
 
-	switch newSource.Provider {
-	case datastore.GithubSourceProvider,
-		datastore.ShopifySourceProvider,
-		datastore.TwitterSourceProvider:
+	if _, ok := providers.Get(newSource.Provider); ok {
 		verifierConfig := newSource.Verifier
 		if verifierConfig.HMac == nil || verifierConfig.HMac.Secret == "" {
 			return fmt.Errorf("hmac secret is required for %s source", newSource.Provider)
@@ -158,6 +244,10 @@ type UpdateSource struct {
 	// identify the event in an incoming webhooks project.
 	IdempotencyKeys []string `json:"idempotency_keys"`
 
+	// DedupWindow is how long in seconds idempotency keys are remembered,
+	// pass 0 to use the default of 24 hours.
+	DedupWindow *uint64 `json:"dedup_window"`
+
 	// Function is a javascript function used to mutate the payload
 	// immediately after ingesting an event
 	BodyFunction *string `json:"body_function"`
@@ -165,6 +255,27 @@ type UpdateSource struct {
 	// Function is a javascript function used to mutate the headers
 	// immediately after ingesting an event
 	HeaderFunction *string `json:"header_function"`
+
+	// IPAllowlist restricts ingestion to these ip addresses and CIDR
+	// blocks, pass an empty list to remove the restriction.
+	IPAllowlist []string `json:"ip_allowlist"`
+
+	// ClientCACert is a PEM encoded CA certificate, when set clients must
+	// present a certificate issued by it. Pass an empty string to disable
+	// client certificate verification.
+	ClientCACert *string `json:"client_ca_cert"`
+
+	// EventTypeConfig describes where to read the event type of ingested
+	// events from, pass an empty type to use the source's mask id.
+	EventTypeConfig *EventTypeConfig `json:"event_type_config"`
+
+	// SyncForwarding forwards ingested events to one endpoint while the
+	// sender waits, pass an empty endpoint id to turn it off.
+	SyncForwarding *SyncForwardingConfig `json:"sync_forwarding"`
+
+	// IngestRateLimit limits the events accepted by this source, pass a
+	// zero count to remove the limit.
+	IngestRateLimit *IngestRateLimitConfiguration `json:"ingest_rate_limit"`
 }
 
 func (us *UpdateSource) Validate() error {
@@ -172,7 +283,7 @@ func (us *UpdateSource) Validate() error {
 		return err
 	}
 
-	if err := validateSourceVerifier(us.Verifier); err != nil {
+	if err := us.Verifier.Validate(); err != nil {
 		return err
 	}
 
@@ -180,6 +291,33 @@ func (us *UpdateSource) Validate() error {
 		return err
 	}
 
+	if us.DedupWindow != nil {
+		if err := validateDedupWindow(*us.DedupWindow); err != nil {
+			return err
+		}
+	}
+
+	var caCert string
+	if us.ClientCACert != nil {
+		caCert = *us.ClientCACert
+	}
+
+	if err := validateSourceClientAuth(us.IPAllowlist, caCert); err != nil {
+		return err
+	}
+
+	if err := us.EventTypeConfig.validate(); err != nil {
+		return err
+	}
+
+	if err := us.SyncForwarding.validate(); err != nil {
+		return err
+	}
+
+	if err := us.IngestRateLimit.validate(); err != nil {
+		return err
+	}
+
 	return util.Validate(us)
 }
 
@@ -238,6 +376,7 @@ type VerifierConfig struct {
 	HMac      *HMac                  `json:"hmac"`
 	BasicAuth *BasicAuth             `json:"basic_auth"`
 	ApiKey    *ApiKey                `json:"api_key"`
+	JWT       *JWT                   `json:"jwt"`
 }
 
 func (vc *VerifierConfig) Transform() *datastore.VerifierConfig {
@@ -250,6 +389,100 @@ func (vc *VerifierConfig) Transform() *datastore.VerifierConfig {
 		HMac:      vc.HMac.transform(),
 		BasicAuth: vc.BasicAuth.transform(),
 		ApiKey:    vc.ApiKey.transform(),
+		JWT:       vc.JWT.transform(),
+	}
+}
+
+type EventTypeConfig struct {
+	// Where to read the event type from, one of header, body, function
+	// or provider.
+	Type datastore.EventTypeSource `json:"type" valid:"supported_event_type_source~please provide a valid event type source"`
+
+	// The request header holding the event type e.g. X-GitHub-Event.
+	Header string `json:"header"`
+
+	// A gjson path to the event type in the request body e.g. data.type.
+	Path string `json:"path"`
+
+	// A javascript function named transform that receives {headers, body}
+	// and returns the event type.
+	Function string `json:"function"`
+}
+
+// validate checks the fields required by the event type source.
+func (ec *EventTypeConfig) validate() error {
+	if ec == nil {
+		return nil
+	}
+
+	switch ec.Type {
+	case datastore.HeaderEventTypeSource:
+		if util.IsStringEmpty(ec.Header) {
+			return errors.New("please provide the header to read the event type from")
+		}
+	case datastore.BodyEventTypeSource:
+		if util.IsStringEmpty(ec.Path) {
+			return errors.New("please provide the body path to read the event type from")
+		}
+	case datastore.FunctionEventTypeSource:
+		if util.IsStringEmpty(ec.Function) {
+			return errors.New("please provide the function to read the event type with")
+		}
+	}
+
+	return nil
+}
+
+// Transform returns nil when no event type source is set, so the source
+// falls back to its mask id.
+func (ec *EventTypeConfig) Transform() *datastore.EventTypeConfig {
+	if ec == nil || util.IsStringEmpty(string(ec.Type)) {
+		return nil
+	}
+
+	return &datastore.EventTypeConfig{
+		Type:     ec.Type,
+		Header:   ec.Header,
+		Path:     ec.Path,
+		Function: ec.Function,
+	}
+}
+
+// MaxSyncForwardingTimeout keeps forwarding within the ingest server's
+// write timeout.
+const MaxSyncForwardingTimeout = 25
+
+type SyncForwardingConfig struct {
+	// The endpoint to forward events to, it must belong to the source's project.
+	EndpointID string `json:"endpoint_id"`
+
+	// How long in seconds to wait for the endpoint before acknowledging
+	// the event and delivering it asynchronously, defaults to 10 seconds.
+	Timeout uint64 `json:"timeout"`
+}
+
+func (sf *SyncForwardingConfig) validate() error {
+	if sf == nil {
+		return nil
+	}
+
+	if sf.Timeout > MaxSyncForwardingTimeout {
+		return fmt.Errorf("sync forwarding timeout cannot be more than %d seconds", MaxSyncForwardingTimeout)
+	}
+
+	return nil
+}
+
+// Transform returns nil when no endpoint is set, which turns sync
+// forwarding off.
+func (sf *SyncForwardingConfig) Transform() *datastore.SyncForwardingConfig {
+	if sf == nil || util.IsStringEmpty(sf.EndpointID) {
+		return nil
+	}
+
+	return &datastore.SyncForwardingConfig{
+		EndpointID: sf.EndpointID,
+		Timeout:    sf.Timeout,
 	}
 }
 
@@ -258,6 +491,10 @@ type HMac struct {
 	Hash     string                 `json:"hash" valid:"supported_hash,required"`
 	Secret   string                 `json:"secret" valid:"required"`
 	Encoding datastore.EncodingType `json:"encoding" valid:"supported_encoding~please provide a valid encoding type,required"`
+
+	// Tolerance is how old, in seconds, the signed timestamp of stripe
+	// and slack requests can be, it defaults to 300.
+	Tolerance uint64 `json:"tolerance"`
 }
 
 func (hm *HMac) transform() *datastore.HMac {
@@ -266,10 +503,11 @@ func (hm *HMac) transform() *datastore.HMac {
 	}
 
 	return &datastore.HMac{
-		Header:   hm.Header,
-		Hash:     hm.Hash,
-		Secret:   hm.Secret,
-		Encoding: hm.Encoding,
+		Header:    hm.Header,
+		Hash:      hm.Hash,
+		Secret:    hm.Secret,
+		Encoding:  hm.Encoding,
+		Tolerance: hm.Tolerance,
 	}
 }
 
@@ -305,6 +543,39 @@ func (ak *ApiKey) transform() *datastore.ApiKey {
 	}
 }
 
+type JWT struct {
+	// The signing algorithm tokens must use e.g. RS256, HS256.
+	Algorithm string `json:"algorithm" valid:"required~please provide a jwt algorithm,supported_jwt_algorithm~please provide a valid jwt algorithm"`
+
+	// The HMAC secret or PEM encoded public key used to verify tokens.
+	Key string `json:"key"`
+
+	// A JSON Web Key Set url, used instead of Key when the sender rotates its signing keys.
+	JwksURL string `json:"jwks_url" valid:"url~please provide a valid jwks url"`
+
+	// Expected values of the iss & aud claims, they are not checked when empty.
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+
+	// Name of an optional claim containing the SHA256 hash of the request body.
+	BodyHashClaim string `json:"body_hash_claim"`
+}
+
+func (j *JWT) transform() *datastore.JWT {
+	if j == nil {
+		return nil
+	}
+
+	return &datastore.JWT{
+		Algorithm:     j.Algorithm,
+		Key:           j.Key,
+		JwksURL:       j.JwksURL,
+		Issuer:        j.Issuer,
+		Audience:      j.Audience,
+		BodyHashClaim: j.BodyHashClaim,
+	}
+}
+
 type PubSubConfig struct {
 	Type    datastore.PubSubType `json:"type"`
 	Workers int                  `json:"workers"`
@@ -409,6 +680,11 @@ func (ac *AmqpPubSubconfig) transform() *datastore.AmqpPubSubConfig {
 		bind = *ac.BoundExchange
 	}
 
+	var routingKey string
+	if bind.RoutingKey != nil {
+		routingKey = *bind.RoutingKey
+	}
+
 	return &datastore.AmqpPubSubConfig{
 		Schema:             ac.Schema,
 		Host:               ac.Host,
@@ -416,7 +692,7 @@ func (ac *AmqpPubSubconfig) transform() *datastore.AmqpPubSubConfig {
 		Queue:              ac.Queue,
 		Vhost:              ac.Vhost,
 		BoundExchange:      bind.Exchange,
-		RoutingKey:         *bind.RoutingKey,
+		RoutingKey:         routingKey,
 		Auth:               (*datastore.AmqpCredentials)(ac.Auth),
 		DeadLetterExchange: ac.DeadLetterExchange,
 	}
@@ -89,6 +89,145 @@ func TestCreateSource_Validate(t *testing.T) {
 			wantErr: true,
 		},
 
+		{
+			name: "should_pass_jwt_verifier_with_jwks_url",
+			source: &CreateSource{
+				Name: "Convoy-Prod",
+				Type: datastore.HTTPSource,
+				Verifier: VerifierConfig{
+					Type: datastore.JWTVerifier,
+					JWT: &JWT{
+						Algorithm: "RS256",
+						JwksURL:   "https://partner.example.com/.well-known/jwks.json",
+						Issuer:    "partner",
+					},
+				},
+			},
+		},
+
+		{
+			name: "should_error_for_jwt_verifier_without_key",
+			source: &CreateSource{
+				Name: "Convoy-Prod",
+				Type: datastore.HTTPSource,
+				Verifier: VerifierConfig{
+					Type: datastore.JWTVerifier,
+					JWT:  &JWT{Algorithm: "RS256"},
+				},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_error_for_unsupported_jwt_algorithm",
+			source: &CreateSource{
+				Name: "Convoy-Prod",
+				Type: datastore.HTTPSource,
+				Verifier: VerifierConfig{
+					Type: datastore.JWTVerifier,
+					JWT:  &JWT{Algorithm: "none", Key: "Convoy"},
+				},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_pass_ip_allowlist",
+			source: &CreateSource{
+				Name:        "Convoy-Prod",
+				Type:        datastore.HTTPSource,
+				Verifier:    VerifierConfig{Type: datastore.NoopVerifier},
+				IPAllowlist: []string{"203.0.113.0/24", "198.51.100.7"},
+			},
+		},
+
+		{
+			name: "should_error_for_invalid_ip_allowlist",
+			source: &CreateSource{
+				Name:        "Convoy-Prod",
+				Type:        datastore.HTTPSource,
+				Verifier:    VerifierConfig{Type: datastore.NoopVerifier},
+				IPAllowlist: []string{"203.0.113.0/40"},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_error_for_invalid_client_ca_cert",
+			source: &CreateSource{
+				Name:         "Convoy-Prod",
+				Type:         datastore.HTTPSource,
+				Verifier:     VerifierConfig{Type: datastore.NoopVerifier},
+				ClientCACert: "not-a-certificate",
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_pass_header_event_type_config",
+			source: &CreateSource{
+				Name:            "Convoy-Prod",
+				Type:            datastore.HTTPSource,
+				Verifier:        VerifierConfig{Type: datastore.NoopVerifier},
+				EventTypeConfig: &EventTypeConfig{Type: datastore.HeaderEventTypeSource, Header: "X-Event-Type"},
+			},
+		},
+
+		{
+			name: "should_error_for_body_event_type_config_without_path",
+			source: &CreateSource{
+				Name:            "Convoy-Prod",
+				Type:            datastore.HTTPSource,
+				Verifier:        VerifierConfig{Type: datastore.NoopVerifier},
+				EventTypeConfig: &EventTypeConfig{Type: datastore.BodyEventTypeSource},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_error_for_provider_event_type_config_without_provider",
+			source: &CreateSource{
+				Name:            "Convoy-Prod",
+				Type:            datastore.HTTPSource,
+				Verifier:        VerifierConfig{Type: datastore.NoopVerifier},
+				EventTypeConfig: &EventTypeConfig{Type: datastore.ProviderEventTypeSource},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_error_for_unsupported_event_type_source",
+			source: &CreateSource{
+				Name:            "Convoy-Prod",
+				Type:            datastore.HTTPSource,
+				Verifier:        VerifierConfig{Type: datastore.NoopVerifier},
+				EventTypeConfig: &EventTypeConfig{Type: "query", Path: "type"},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_error_for_sync_forwarding_timeout_above_max",
+			source: &CreateSource{
+				Name:           "Convoy-Prod",
+				Type:           datastore.HTTPSource,
+				Verifier:       VerifierConfig{Type: datastore.NoopVerifier},
+				SyncForwarding: &SyncForwardingConfig{EndpointID: "endpoint-id-1", Timeout: 60},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "should_error_for_ingest_rate_limit_without_duration",
+			source: &CreateSource{
+				Name:            "Convoy-Prod",
+				Type:            datastore.HTTPSource,
+				Verifier:        VerifierConfig{Type: datastore.NoopVerifier},
+				IngestRateLimit: &IngestRateLimitConfiguration{Count: 100, Burst: 200},
+			},
+			wantErr: true,
+		},
+
 		{
 			name: "should_fail_invalid_source_configuration",
 			source: &CreateSource{
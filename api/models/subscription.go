@@ -1,11 +1,14 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
 	m "github.com/frain-dev/convoy/internal/pkg/middleware"
+	"github.com/frain-dev/convoy/pkg/compare"
 	"github.com/frain-dev/convoy/util"
 	"github.com/lib/pq"
 )
@@ -37,9 +40,35 @@ type CreateSubscription struct {
 
 	// Rate limit configuration
 	RateLimitConfig *RateLimitConfiguration `json:"rate_limit_config,omitempty"`
+
+	// Debounce configuration
+	DebounceConfig *DebounceConfiguration `json:"debounce_config,omitempty"`
+
+	// Fallback configuration
+	FallbackConfig *FallbackConfiguration `json:"fallback_config,omitempty"`
+
+	// EventTypeVersions pins the version of each event type the subscription
+	// receives, payloads are converted down to the pinned version
+	EventTypeVersions map[string]int `json:"event_type_versions,omitempty"`
 }
 
 func (cs *CreateSubscription) Validate() error {
+	if err := validateEventTypeVersions(cs.EventTypeVersions); err != nil {
+		return err
+	}
+
+	if err := cs.DebounceConfig.validate(); err != nil {
+		return err
+	}
+
+	if err := cs.FallbackConfig.validate(); err != nil {
+		return err
+	}
+
+	if err := cs.FilterConfig.validate(); err != nil {
+		return err
+	}
+
 	return util.Validate(cs)
 }
 
@@ -71,9 +100,35 @@ type UpdateSubscription struct {
 
 	// Rate limit configuration
 	RateLimitConfig *RateLimitConfiguration `json:"rate_limit_config,omitempty"`
+
+	// Debounce configuration
+	DebounceConfig *DebounceConfiguration `json:"debounce_config,omitempty"`
+
+	// Fallback configuration
+	FallbackConfig *FallbackConfiguration `json:"fallback_config,omitempty"`
+
+	// EventTypeVersions pins the version of each event type the subscription
+	// receives, payloads are converted down to the pinned version
+	EventTypeVersions map[string]int `json:"event_type_versions,omitempty"`
 }
 
 func (us *UpdateSubscription) Validate() error {
+	if err := validateEventTypeVersions(us.EventTypeVersions); err != nil {
+		return err
+	}
+
+	if err := us.DebounceConfig.validate(); err != nil {
+		return err
+	}
+
+	if err := us.FallbackConfig.validate(); err != nil {
+		return err
+	}
+
+	if err := us.FilterConfig.validate(); err != nil {
+		return err
+	}
+
 	return util.Validate(us)
 }
 
@@ -106,8 +161,54 @@ func (qs *QueryListSubscription) Transform(r *http.Request) *QueryListSubscripti
 }
 
 type FilterSchema struct {
-	Headers interface{} `json:"header"`
-	Body    interface{} `json:"body"`
+	Headers  interface{} `json:"header"`
+	Body     interface{} `json:"body"`
+	Metadata interface{} `json:"metadata"`
+
+	// Expression is a CEL filter expression, only used in a test schema
+	Expression string `json:"expression,omitempty"`
+
+	// EventType and Source are the sample event type and source id a
+	// test schema's expression is evaluated against
+	EventType string `json:"event_type,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+// Transform converts a test schema into a subscription filter, the
+// header, body and metadata filters must be objects.
+func (fs *FilterSchema) Transform() (datastore.FilterSchema, error) {
+	var err error
+	f := datastore.FilterSchema{Expression: fs.Expression}
+
+	f.Headers, err = toFilterMap("header", fs.Headers)
+	if err != nil {
+		return f, err
+	}
+
+	f.Body, err = toFilterMap("body", fs.Body)
+	if err != nil {
+		return f, err
+	}
+
+	f.Metadata, err = toFilterMap("metadata", fs.Metadata)
+	if err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+func toFilterMap(name string, v interface{}) (datastore.M, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s filter must be an object", name)
+	}
+
+	return m, nil
 }
 
 type TestFilter struct {
@@ -118,6 +219,28 @@ type TestFilter struct {
 	Schema FilterSchema `json:"schema"`
 }
 
+// FilterExplanation is a subscription filter's evaluation against a
+// payload, parts of the filter that aren't set are omitted.
+type FilterExplanation struct {
+	Matched    bool                   `json:"matched"`
+	Body       *compare.Clause        `json:"body,omitempty"`
+	Headers    *compare.Clause        `json:"headers,omitempty"`
+	Metadata   *compare.Clause        `json:"metadata,omitempty"`
+	Expression *ExpressionExplanation `json:"expression,omitempty"`
+	Labels     *LabelsExplanation     `json:"labels,omitempty"`
+}
+
+type ExpressionExplanation struct {
+	Expression string `json:"expression"`
+	Result     bool   `json:"result"`
+	Error      string `json:"error,omitempty"`
+}
+
+type LabelsExplanation struct {
+	Selector datastore.Labels `json:"selector"`
+	Result   bool             `json:"result"`
+}
+
 type AlertConfiguration struct {
 	// Count
 	Count int `json:"count"`
@@ -137,6 +260,113 @@ func (ac *AlertConfiguration) Transform() *datastore.AlertConfiguration {
 	}
 }
 
+// maxDebounceWindow is the longest a debounced delivery can be held back
+const maxDebounceWindow = 24 * 60 * 60
+
+type DebounceConfiguration struct {
+	// Path of the key events are debounced by in the payload, e.g. data.id
+	KeyPath string `json:"key_path"`
+
+	// Debounce window in seconds, a window of zero turns debouncing off
+	Window uint64 `json:"window"`
+
+	// Mode is "last" to send the last event of the window, or "merge" to
+	// send the events of the window merged into one
+	Mode datastore.DebounceMode `json:"mode"`
+}
+
+func (dc *DebounceConfiguration) validate() error {
+	if dc == nil || dc.Window == 0 {
+		return nil
+	}
+
+	if util.IsStringEmpty(dc.KeyPath) {
+		return errors.New("please provide a debounce key path")
+	}
+
+	if dc.Window > maxDebounceWindow {
+		return fmt.Errorf("debounce window can't be longer than %d seconds", maxDebounceWindow)
+	}
+
+	if dc.Mode != "" && !dc.Mode.IsValid() {
+		return fmt.Errorf("unsupported debounce mode %s", dc.Mode)
+	}
+
+	return nil
+}
+
+func (dc *DebounceConfiguration) Transform() *datastore.DebounceConfiguration {
+	if dc == nil || dc.Window == 0 {
+		return nil
+	}
+
+	mode := dc.Mode
+	if mode == "" {
+		mode = datastore.LastDebounceMode
+	}
+
+	return &datastore.DebounceConfiguration{KeyPath: dc.KeyPath, Window: dc.Window, Mode: mode}
+}
+
+type FallbackConfiguration struct {
+	// Endpoint the subscription's failed deliveries are sent to
+	EndpointID string `json:"endpoint_id"`
+
+	// Pub sub destination the subscription's failed deliveries are published
+	// to instead, only sqs, kafka and amqp are supported
+	PubSub *PubSubConfig `json:"pub_sub"`
+
+	// Retry configuration of the fallback deliveries, the project's retry
+	// configuration is used when it's empty
+	RetryConfig *RetryConfiguration `json:"retry_config,omitempty"`
+}
+
+func (fc *FallbackConfiguration) validate() error {
+	if fc == nil {
+		return nil
+	}
+
+	if !util.IsStringEmpty(fc.EndpointID) && fc.PubSub != nil {
+		return errors.New("a fallback can either be an endpoint or a pub sub destination, not both")
+	}
+
+	if fc.PubSub != nil {
+		switch fc.PubSub.Type {
+		case datastore.SqsPubSub, datastore.KafkaPubSub, datastore.AmqpPubSub:
+		default:
+			return fmt.Errorf("unsupported fallback pub sub type %s", fc.PubSub.Type)
+		}
+	}
+
+	return nil
+}
+
+// Transform returns nil when neither a fallback endpoint nor a pub sub
+// destination is set, an empty fallback turns it off.
+func (fc *FallbackConfiguration) Transform() (*datastore.FallbackConfiguration, error) {
+	if fc == nil || (util.IsStringEmpty(fc.EndpointID) && fc.PubSub == nil) {
+		return nil, nil
+	}
+
+	retryConfig, err := fc.RetryConfig.Transform()
+	if err != nil {
+		return nil, err
+	}
+
+	pubSub := fc.PubSub.Transform()
+	if pubSub != nil && pubSub.Workers == 0 {
+		// publishing doesn't use workers, but pub sub configs are
+		// validated the same way as sources'
+		pubSub.Workers = 1
+	}
+
+	return &datastore.FallbackConfiguration{
+		EndpointID:  fc.EndpointID,
+		PubSub:      pubSub,
+		RetryConfig: retryConfig,
+	}, nil
+}
+
 type RetryConfiguration struct {
 	// Retry Strategy type
 	Type datastore.StrategyProvider `json:"type,omitempty" valid:"supported_retry_strategy~please provide a valid retry strategy type"`
@@ -181,6 +411,14 @@ type FilterConfiguration struct {
 	Filter FS `json:"filter"`
 }
 
+func (fc *FilterConfiguration) validate() error {
+	if fc == nil {
+		return nil
+	}
+
+	return datastore.Labels(fc.Filter.Labels).Validate()
+}
+
 func (fc *FilterConfiguration) Transform() *datastore.FilterConfiguration {
 	if fc == nil {
 		return nil
@@ -189,8 +427,11 @@ func (fc *FilterConfiguration) Transform() *datastore.FilterConfiguration {
 	return &datastore.FilterConfiguration{
 		EventTypes: fc.EventTypes,
 		Filter: datastore.FilterSchema{
-			Headers: fc.Filter.Headers,
-			Body:    fc.Filter.Body,
+			Headers:    fc.Filter.Headers,
+			Body:       fc.Filter.Body,
+			Metadata:   fc.Filter.Metadata,
+			Expression: fc.Filter.Expression,
+			Labels:     fc.Filter.Labels,
 		},
 	}
 }
@@ -198,12 +439,27 @@ func (fc *FilterConfiguration) Transform() *datastore.FilterConfiguration {
 type FS struct {
 	Headers datastore.M `json:"headers"`
 	Body    datastore.M `json:"body"`
+
+	// Metadata filters match the metadata attached to an event at
+	// ingest, e.g. the verified claims of a JWT signed request.
+	Metadata datastore.M `json:"metadata"`
+
+	// Expression is a CEL expression over body, headers, event_type and
+	// source, e.g. `body.amount > 1000 && headers["X-Region"] == "eu"`.
+	Expression string `json:"expression"`
+
+	// Labels is a selector e.g. {"region": "eu"}, the subscription only
+	// receives events while its endpoint has all of the labels.
+	Labels map[string]string `json:"labels"`
 }
 
 func (fs *FS) Transform() datastore.FilterSchema {
 	return datastore.FilterSchema{
-		Headers: fs.Headers,
-		Body:    fs.Body,
+		Headers:    fs.Headers,
+		Body:       fs.Body,
+		Metadata:   fs.Metadata,
+		Expression: fs.Expression,
+		Labels:     fs.Labels,
 	}
 }
 
@@ -211,6 +467,13 @@ type FunctionRequest struct {
 	Payload  map[string]any `json:"payload"`
 	Function string         `json:"function"`
 	Type     string         `json:"type"`
+
+	// Version runs a saved version of the subscription or source's
+	// function instead of Function
+	Version int `json:"version"`
+
+	// The subscription or source the version belongs to
+	OwnerID string `json:"owner_id"`
 }
 
 type FunctionResponse struct {
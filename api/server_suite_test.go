@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
 	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
 	"io"
 	"math/rand"
@@ -122,6 +123,7 @@ func buildServer() *ApplicationHandler {
 
 	noopCache := ncache.NewNoopCache()
 	r, _ := rlimiter.NewRedisLimiter(cfg.Redis.BuildDsn())
+	d, _ := dedup.NewRedisStore(cfg.Redis.BuildDsn())
 
 	ah, _ := NewApplicationHandler(
 		&types.APIOptions{
@@ -130,6 +132,7 @@ func buildServer() *ApplicationHandler {
 			Logger: logger,
 			Cache:  noopCache,
 			Rate:   r,
+			Dedup:  d,
 		})
 
 	_ = ah.RegisterPolicy()
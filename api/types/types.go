@@ -4,6 +4,7 @@ import (
 	authz "github.com/Subomi/go-authz"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
 	"github.com/frain-dev/convoy/pkg/log"
@@ -20,4 +21,5 @@ type APIOptions struct {
 	Cache  cache.Cache
 	Authz  *authz.Authz
 	Rate   limiter.RateLimiter
+	Dedup  dedup.Store
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/internal/pkg/cli"
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
+	"github.com/frain-dev/convoy/internal/pkg/inbox"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
 	"github.com/frain-dev/convoy/internal/pkg/loader"
 	"github.com/frain-dev/convoy/internal/pkg/memorystore"
@@ -140,6 +141,7 @@ func startServerComponent(ctx context.Context, a *cli.App) error {
 			Logger: lo,
 			Cache:  a.Cache,
 			Rate:   a.Rate,
+			Dedup:  a.Dedup,
 		})
 	if err != nil {
 		return err
@@ -201,13 +203,26 @@ func startIngestComponent(ctx context.Context, a *cli.App, interval int) error {
 		return err
 	}
 
-	ingest, err := pubsub.NewIngest(ctx, sourceTable, a.Queue, a.Logger, rateLimiter, host)
+	ingest, err := pubsub.NewIngest(ctx, sourceTable, a.Queue, a.Logger, rateLimiter, a.Dedup, projectRepo, host)
 	if err != nil {
 		return err
 	}
 
 	go ingest.Run()
 
+	if cfg.InboundEmail.Port != 0 {
+		var attachments inbox.AttachmentStore
+		if instCfg != nil && instCfg.StoragePolicy != nil {
+			attachments, err = inbox.NewAttachmentStore(instCfg.StoragePolicy)
+			if err != nil {
+				return err
+			}
+		}
+
+		backend := inbox.NewIngest(cfg.InboundEmail.Domain, sourceRepo, projectRepo, a.Queue, rateLimiter, a.Dedup, attachments, a.Logger)
+		inbox.Start(ctx, cfg.InboundEmail.Port, inbox.NewServer(cfg.InboundEmail.Domain, cfg.InboundEmail.MaxMessageSize, backend, a.Logger))
+	}
+
 	return nil
 }
 
@@ -227,6 +242,7 @@ func startWorkerComponent(ctx context.Context, a *cli.App) error {
 	eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB, a.Cache)
 	subRepo := postgres.NewSubscriptionRepo(a.DB, a.Cache)
 	deviceRepo := postgres.NewDeviceRepo(a.DB, a.Cache)
+	eventTypeRepo := postgres.NewEventTypeRepo(a.DB, a.Cache)
 	configRepo := postgres.NewConfigRepo(a.DB)
 
 	counter := &telemetry.EventsCounter{}
@@ -278,7 +294,8 @@ func startWorkerComponent(ctx context.Context, a *cli.App) error {
 		eventDeliveryRepo,
 		a.Queue,
 		subRepo,
-		deviceRepo), newTelemetry)
+		deviceRepo,
+		eventTypeRepo), newTelemetry)
 
 	consumer.RegisterHandlers(convoy.CreateDynamicEventProcessor, task.ProcessDynamicEventCreation(
 		endpointRepo,
@@ -287,7 +304,8 @@ func startWorkerComponent(ctx context.Context, a *cli.App) error {
 		eventDeliveryRepo,
 		a.Queue,
 		subRepo,
-		deviceRepo), newTelemetry)
+		deviceRepo,
+		eventTypeRepo), newTelemetry)
 
 	consumer.RegisterHandlers(convoy.MetaEventProcessor, task.ProcessMetaEvent(projectRepo, metaEventRepo), nil)
 
@@ -299,8 +317,21 @@ func startWorkerComponent(ctx context.Context, a *cli.App) error {
 		a.Queue,
 		subRepo,
 		deviceRepo,
+		eventTypeRepo,
 		subscriptionsTable), newTelemetry)
 
+	consumer.RegisterHandlers(convoy.FallbackDeliveryProcessor, task.ProcessFallbackDelivery(
+		eventDeliveryRepo,
+		eventRepo,
+		subRepo,
+		endpointRepo,
+		projectRepo,
+		a.Queue), newTelemetry)
+
+	consumer.RegisterHandlers(convoy.FallbackPublishProcessor, task.ProcessFallbackPublish(
+		eventDeliveryRepo,
+		subRepo), newTelemetry)
+
 	go task.QueueStuckEventDeliveries(ctx, eventDeliveryRepo, a.Queue)
 
 	go func() {
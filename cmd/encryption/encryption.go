@@ -0,0 +1,110 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func AddEncryptionCommand(a *cli.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Manage the keys event payloads are encrypted with",
+		Annotations: map[string]string{
+			"ShouldBootstrap": "false",
+		},
+	}
+
+	cmd.AddCommand(addRotateCommand(a))
+	cmd.AddCommand(addRewrapCommand())
+
+	return cmd
+}
+
+func addRotateCommand(a *cli.App) *cobra.Command {
+	var projectID string
+
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Create new data keys, existing payloads are re-encrypted by the re-encryption job",
+		Annotations: map[string]string{
+			"ShouldBootstrap": "false",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			k := encryption.Get()
+			if !k.Enabled() {
+				log.Fatal("encryption is not enabled")
+			}
+
+			ctx := context.Background()
+			projectIDs := []string{projectID}
+
+			if len(projectID) > 0 {
+				optedIn, err := k.OptedIn(ctx, projectID)
+				if err != nil {
+					log.WithError(err).Fatalf("failed to find project %s", projectID)
+				}
+
+				if !optedIn {
+					log.Fatalf("project %s doesn't encrypt its payloads", projectID)
+				}
+			}
+
+			if len(projectID) == 0 {
+				projects, err := postgres.NewProjectRepo(a.DB, a.Cache).LoadProjects(ctx, &datastore.ProjectFilter{})
+				if err != nil {
+					log.WithError(err).Fatal("failed to load projects")
+				}
+
+				// only the projects that opted in get data keys
+				projectIDs = projectIDs[:0]
+				for _, p := range projects {
+					if p.Config != nil && p.Config.EncryptPayloads {
+						projectIDs = append(projectIDs, p.UID)
+					}
+				}
+			}
+
+			for _, id := range projectIDs {
+				key, err := k.RotateDataKey(ctx, id)
+				if err != nil {
+					log.WithError(err).Fatalf("failed to rotate the data key of project %s", id)
+				}
+
+				log.Infof("project %s now encrypts with data key version %d", id, key.Version)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project-id", "", "Rotate the data key of this project only")
+	return cmd
+}
+
+func addRewrapCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rewrap",
+		Short: "Wrap the data keys with the current master key after it was rotated",
+		Annotations: map[string]string{
+			"ShouldBootstrap": "false",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			n, err := encryption.Get().RewrapDataKeys(context.Background())
+			if err != nil {
+				if errors.Is(err, encryption.ErrUnknownKek) {
+					log.WithError(err).Fatal("add the master key that wrapped it to the previous keys")
+				}
+				log.WithError(err).Fatalf("failed to rewrap data keys, %d were rewrapped", n)
+			}
+
+			log.Infof("rewrapped %d data keys, the previous master keys can now be removed", n)
+		},
+	}
+
+	return cmd
+}
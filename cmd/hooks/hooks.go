@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
 
 	"github.com/frain-dev/convoy/util"
@@ -27,11 +28,15 @@ import (
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
 	"github.com/frain-dev/convoy/internal/pkg/rdb"
 	"github.com/frain-dev/convoy/internal/pkg/tracer"
 	"github.com/frain-dev/convoy/internal/telemetry"
 	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/transform"
 	redisQueue "github.com/frain-dev/convoy/queue/redis"
 	"github.com/spf13/cobra"
 )
@@ -68,6 +73,14 @@ func PreRun(app *cli.App, db *postgres.Postgres) func(cmd *cobra.Command, args [
 			return err
 		}
 
+		transform.Configure(transform.Config{
+			Timeout:          time.Duration(cfg.Transform.Timeout) * time.Millisecond,
+			MaxMemory:        cfg.Transform.MaxMemory,
+			MaxCallStackSize: cfg.Transform.MaxCallStackSize,
+			MaxConsoleOutput: cfg.Transform.MaxConsoleOutput,
+			Deterministic:    cfg.Transform.Deterministic,
+		})
+
 		app.TracerShutdown, err = tracer.Init(cfg.Tracer, cmd.Name())
 		if err != nil {
 			return err
@@ -135,6 +148,18 @@ func PreRun(app *cli.App, db *postgres.Postgres) func(cmd *cobra.Command, args [
 		hooks.RegisterHook(datastore.EndpointDeleted, endpointListener.AfterDelete)
 		hooks.RegisterHook(datastore.EventDeliveryUpdated, eventDeliveryListener.AfterUpdate)
 
+		functions.Init(postgres.NewFunctionLibraryRepo(postgresDB, ca), postgres.NewFunctionVersionRepo(postgresDB, ca))
+
+		_, err = encryption.Init(cfg.Encryption, postgres.NewDataKeyRepo(postgresDB, ca), projectRepo)
+		if err != nil {
+			return err
+		}
+
+		_, err = claimcheck.Init(cfg.PayloadOffload, storagePolicyFromConfig(cfg))
+		if err != nil {
+			return err
+		}
+
 		if ok := shouldCheckMigration(cmd); ok {
 			err = checkPendingMigrations(db)
 			if err != nil {
@@ -176,6 +201,13 @@ func PreRun(app *cli.App, db *postgres.Postgres) func(cmd *cobra.Command, args [
 
 		app.Rate = rateLimiter
 
+		dedupStore, err := dedup.NewStore(cfg)
+		if err != nil {
+			return err
+		}
+
+		app.Dedup = dedupStore
+
 		// update config singleton with the instance id
 		if _, ok := skipConfigLoadCmd[cmd.Use]; !ok {
 			configRepo := postgres.NewConfigRepo(app.DB)
@@ -250,9 +282,7 @@ func enableProfiling(cfg config.Configuration, cmd *cobra.Command) error {
 	return err
 }
 
-func ensureInstanceConfig(ctx context.Context, a *cli.App, cfg config.Configuration) (*datastore.Configuration, error) {
-	configRepo := postgres.NewConfigRepo(a.DB)
-
+func storagePolicyFromConfig(cfg config.Configuration) *datastore.StoragePolicyConfiguration {
 	s3 := datastore.S3Storage{
 		Prefix:       null.NewString(cfg.StoragePolicy.S3.Prefix, true),
 		Bucket:       null.NewString(cfg.StoragePolicy.S3.Bucket, true),
@@ -267,11 +297,16 @@ func ensureInstanceConfig(ctx context.Context, a *cli.App, cfg config.Configurat
 		Path: null.NewString(cfg.StoragePolicy.OnPrem.Path, true),
 	}
 
-	storagePolicy := &datastore.StoragePolicyConfiguration{
+	return &datastore.StoragePolicyConfiguration{
 		Type:   datastore.StorageType(cfg.StoragePolicy.Type),
 		S3:     &s3,
 		OnPrem: &onPrem,
 	}
+}
+
+func ensureInstanceConfig(ctx context.Context, a *cli.App, cfg config.Configuration) (*datastore.Configuration, error) {
+	configRepo := postgres.NewConfigRepo(a.DB)
+	storagePolicy := storagePolicyFromConfig(cfg)
 
 	retentionPolicy := &datastore.RetentionPolicyConfiguration{
 		Policy:                   cfg.RetentionPolicy.Policy,
@@ -4,6 +4,7 @@ import (
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/internal/pkg/inbox"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
 	"github.com/frain-dev/convoy/internal/pkg/memorystore"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
@@ -18,12 +19,13 @@ import (
 
 func AddIngestCommand(a *cli.App) *cobra.Command {
 	var ingestPort uint32
+	var inboundEmailPort uint32
 	var logLevel string
 	var interval int
 
 	cmd := &cobra.Command{
 		Use:   "ingest",
-		Short: "Ingest webhook events from Pub/Sub streams",
+		Short: "Ingest webhook events from Pub/Sub streams and email",
 		Annotations: map[string]string{
 			"ShouldBootstrap": "false",
 		},
@@ -84,13 +86,28 @@ func AddIngestCommand(a *cli.App) *cobra.Command {
 				return err
 			}
 
-			ingest, err := pubsub.NewIngest(cmd.Context(), sourceTable, a.Queue, lo, rateLimiter, host)
+			ingest, err := pubsub.NewIngest(cmd.Context(), sourceTable, a.Queue, lo, rateLimiter, a.Dedup, projectRepo, host)
 			if err != nil {
 				return err
 			}
 
 			go ingest.Run()
 
+			if cfg.InboundEmail.Port != 0 {
+				var attachments inbox.AttachmentStore
+				if instCfg != nil && instCfg.StoragePolicy != nil {
+					attachments, err = inbox.NewAttachmentStore(instCfg.StoragePolicy)
+					if err != nil {
+						return err
+					}
+				}
+
+				backend := inbox.NewIngest(cfg.InboundEmail.Domain, sourceRepo, projectRepo, a.Queue, rateLimiter, a.Dedup, attachments, lo)
+				inbox.Start(cmd.Context(), cfg.InboundEmail.Port, inbox.NewServer(cfg.InboundEmail.Domain, cfg.InboundEmail.MaxMessageSize, backend, lo))
+
+				a.Logger.Infof("Receiving email for email sources on port %d", cfg.InboundEmail.Port)
+			}
+
 			srv := server.NewServer(cfg.Server.HTTP.IngestPort, func() {})
 			mux := chi.NewMux()
 			mux.Handle("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{Registry: metrics.Reg()}))
@@ -105,6 +122,7 @@ func AddIngestCommand(a *cli.App) *cobra.Command {
 	}
 
 	cmd.Flags().Uint32Var(&ingestPort, "ingest-port", 5009, "Ingest port")
+	cmd.Flags().Uint32Var(&inboundEmailPort, "inbound-email-port", 0, "Port to receive email for email sources on")
 	cmd.Flags().StringVar(&logLevel, "log-level", "", "ingest log level")
 	cmd.Flags().IntVar(&interval, "interval", 10, "the time interval, measured in seconds, at which the database should be polled for new pub sub sources")
 
@@ -134,5 +152,14 @@ func buildCliFlagConfiguration(cmd *cobra.Command) (*config.Configuration, error
 
 	c.Server.HTTP.IngestPort = ingestPort
 
+	inboundEmailPort, err := cmd.Flags().GetUint32("inbound-email-port")
+	if err != nil {
+		return nil, err
+	}
+
+	if inboundEmailPort != 0 {
+		c.InboundEmail.Port = inboundEmailPort
+	}
+
 	return c, nil
 }
@@ -8,9 +8,11 @@ import (
 	"github.com/frain-dev/convoy/cmd/bootstrap"
 
 	configCmd "github.com/frain-dev/convoy/cmd/config"
+	"github.com/frain-dev/convoy/cmd/encryption"
 	"github.com/frain-dev/convoy/cmd/hooks"
 	"github.com/frain-dev/convoy/cmd/ingest"
 	"github.com/frain-dev/convoy/cmd/migrate"
+	"github.com/frain-dev/convoy/cmd/partition"
 	"github.com/frain-dev/convoy/cmd/retry"
 	"github.com/frain-dev/convoy/cmd/server"
 	"github.com/frain-dev/convoy/cmd/stream"
@@ -124,6 +126,8 @@ func main() {
 	c.AddCommand(ingest.AddIngestCommand(app))
 	c.AddCommand(bootstrap.AddBootstrapCommand(app))
 	c.AddCommand(agent.AddAgentCommand(app))
+	c.AddCommand(encryption.AddEncryptionCommand(app))
+	c.AddCommand(partition.AddPartitionCommand(app))
 
 	if err := c.Execute(); err != nil {
 		slog.Fatal(err)
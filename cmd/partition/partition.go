@@ -0,0 +1,131 @@
+package partition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func AddPartitionCommand(a *cli.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "partition",
+		Short: "Inspect and manage the partitions of the events and event deliveries tables",
+		Annotations: map[string]string{
+			"ShouldBootstrap": "false",
+		},
+	}
+
+	cmd.AddCommand(addListCommand(a))
+	cmd.AddCommand(addCreateCommand(a))
+
+	return cmd
+}
+
+func addListCommand(a *cli.App) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the partitions with their range, estimated rows and size",
+		Annotations: map[string]string{
+			"ShouldBootstrap": "false",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			partitions, err := postgres.NewPartitionRepo(a.DB).LoadPartitions(context.Background())
+			if err != nil {
+				log.WithError(err).Fatal("failed to load partitions")
+			}
+
+			if format == "json" {
+				data, err := json.MarshalIndent(partitions, "", "    ")
+				if err != nil {
+					log.WithError(err).Fatal("failed to print partitions")
+				}
+
+				fmt.Println(string(data))
+				return
+			}
+
+			printPartitions(partitions)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format, table or json")
+	return cmd
+}
+
+func addCreateCommand(a *cli.App) *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create the partitions of the next days, the worker does this every hour",
+		Annotations: map[string]string{
+			"ShouldBootstrap": "false",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if days <= 0 {
+				days = config.DefaultPartitionPremakeDays
+			}
+
+			until := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, days+1)
+			created, err := postgres.NewPartitionRepo(a.DB).CreatePartitions(context.Background(), until)
+			if err != nil {
+				log.WithError(err).Fatalf("failed to create partitions, %d were created", len(created))
+			}
+
+			log.Infof("created %d partitions", len(created))
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", config.DefaultPartitionPremakeDays, "Number of days to create partitions for")
+	return cmd
+}
+
+func printPartitions(partitions []datastore.Partition) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tPARTITION\tFROM\tTO\tROWS\tSIZE")
+
+	for _, p := range partitions {
+		from, to := bound(p.From, "MINVALUE"), bound(p.To, "MAXVALUE")
+		if p.IsDefault {
+			from, to = "DEFAULT", "DEFAULT"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", p.Table, p.Name, from, to, p.Rows, size(p.Size))
+	}
+
+	_ = w.Flush()
+}
+
+func bound(t *time.Time, unbounded string) string {
+	if t == nil {
+		return unbounded
+	}
+	return t.UTC().Format(time.DateOnly)
+}
+
+func size(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
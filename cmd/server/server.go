@@ -134,6 +134,7 @@ func startConvoyServer(a *cli.App) error {
 			Logger: lo,
 			Cache:  a.Cache,
 			Rate:   a.Rate,
+			Dedup:  a.Dedup,
 		})
 	if err != nil {
 		return err
@@ -154,6 +155,8 @@ func startConvoyServer(a *cli.App) error {
 	s.RegisterTask("30 * * * *", convoy.ScheduleQueue, convoy.MonitorTwitterSources)
 	s.RegisterTask("0 0 * * *", convoy.ScheduleQueue, convoy.RetentionPolicies)
 	s.RegisterTask("0 * * * *", convoy.ScheduleQueue, convoy.TokenizeSearch)
+	s.RegisterTask("15 * * * *", convoy.ScheduleQueue, convoy.ReEncryptPayloads)
+	s.RegisterTask("45 * * * *", convoy.ScheduleQueue, convoy.ManagePartitions)
 
 	// Start scheduler
 	s.Start()
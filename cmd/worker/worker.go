@@ -146,7 +146,9 @@ func AddWorkerCommand(a *cli.App) *cobra.Command {
 			eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB, a.Cache)
 			subRepo := postgres.NewSubscriptionRepo(a.DB, a.Cache)
 			deviceRepo := postgres.NewDeviceRepo(a.DB, a.Cache)
+			eventTypeRepo := postgres.NewEventTypeRepo(a.DB, a.Cache)
 			configRepo := postgres.NewConfigRepo(a.DB)
+			partitionRepo := postgres.NewPartitionRepo(a.DB)
 
 			rd, err := rdb.NewClient(cfg.Redis.BuildDsn())
 			if err != nil {
@@ -210,7 +212,8 @@ func AddWorkerCommand(a *cli.App) *cobra.Command {
 				eventDeliveryRepo,
 				a.Queue,
 				subRepo,
-				deviceRepo), newTelemetry)
+				deviceRepo,
+				eventTypeRepo), newTelemetry)
 
 			consumer.RegisterHandlers(convoy.RetryEventProcessor, task.ProcessRetryEventDelivery(
 				endpointRepo,
@@ -227,6 +230,7 @@ func AddWorkerCommand(a *cli.App) *cobra.Command {
 				a.Queue,
 				subRepo,
 				deviceRepo,
+				eventTypeRepo,
 				subscriptionsTable), newTelemetry)
 
 			consumer.RegisterHandlers(convoy.CreateDynamicEventProcessor, task.ProcessDynamicEventCreation(
@@ -236,13 +240,29 @@ func AddWorkerCommand(a *cli.App) *cobra.Command {
 				eventDeliveryRepo,
 				a.Queue,
 				subRepo,
-				deviceRepo), newTelemetry)
+				deviceRepo,
+				eventTypeRepo), newTelemetry)
+
+			consumer.RegisterHandlers(convoy.FallbackDeliveryProcessor, task.ProcessFallbackDelivery(
+				eventDeliveryRepo,
+				eventRepo,
+				subRepo,
+				endpointRepo,
+				projectRepo,
+				a.Queue,
+			), newTelemetry)
+
+			consumer.RegisterHandlers(convoy.FallbackPublishProcessor, task.ProcessFallbackPublish(
+				eventDeliveryRepo,
+				subRepo,
+			), newTelemetry)
 
 			consumer.RegisterHandlers(convoy.RetentionPolicies, task.RetentionPolicies(
 				configRepo,
 				projectRepo,
 				eventRepo,
 				eventDeliveryRepo,
+				partitionRepo,
 				rd,
 			), nil)
 
@@ -259,6 +279,8 @@ func AddWorkerCommand(a *cli.App) *cobra.Command {
 			consumer.RegisterHandlers(convoy.NotificationProcessor, task.ProcessNotifications(sc), nil)
 			consumer.RegisterHandlers(convoy.MetaEventProcessor, task.ProcessMetaEvent(projectRepo, metaEventRepo), nil)
 			consumer.RegisterHandlers(convoy.DeleteArchivedTasksProcessor, task.DeleteArchivedTasks(a.Queue, rd), nil)
+			consumer.RegisterHandlers(convoy.ReEncryptPayloads, task.ReEncryptPayloads(projectRepo, eventRepo, eventDeliveryRepo, rd), nil)
+			consumer.RegisterHandlers(convoy.ManagePartitions, task.ManagePartitions(partitionRepo, rd), nil)
 
 			// start worker
 			lo.Infof("Starting Convoy workers...")
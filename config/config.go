@@ -17,12 +17,14 @@ import (
 )
 
 const (
-	MaxResponseSizeKb                 = 50    // in kilobytes
-	MaxResponseSize                   = 51200 // in bytes
+	MaxResponseSizeKb                 = 1024    // in kilobytes
+	MaxResponseSize                   = 1048576 // in bytes
 	DefaultHost                       = "localhost:5005"
 	DefaultSearchTokenizationInterval = 1
 	DefaultCacheTTL                   = time.Minute * 10
 	DefaultAPIVersion                 = "2024-04-01"
+	DefaultPayloadOffloadThreshold    = 65536 // in bytes
+	DefaultPartitionPremakeDays       = 7
 )
 
 var cfgSingleton atomic.Value
@@ -156,6 +158,15 @@ type HTTPServerConfiguration struct {
 	SocketPort  uint32 `json:"socket_port" envconfig:"SOCKET_PORT"`
 	DomainPort  uint32 `json:"domain_port" envconfig:"DOMAIN_PORT"`
 	HttpProxy   string `json:"proxy" envconfig:"HTTP_PROXY"`
+
+	// TrustedProxies are the ip addresses and CIDR blocks of the proxies
+	// whose X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Client-Cert
+	// headers are trusted.
+	TrustedProxies []string `json:"trusted_proxies" envconfig:"CONVOY_TRUSTED_PROXIES"`
+
+	// SSLRequestClientCert makes the server ask clients for a certificate
+	// during the TLS handshake so sources can verify it.
+	SSLRequestClientCert bool `json:"ssl_request_client_cert" envconfig:"CONVOY_SSL_REQUEST_CLIENT_CERT"`
 }
 
 type PrometheusConfiguration struct {
@@ -229,6 +240,38 @@ type SMTPConfiguration struct {
 	ReplyTo  string `json:"reply-to" envconfig:"CONVOY_SMTP_REPLY_TO"`
 }
 
+// InboundEmailConfiguration configures the SMTP listener that receives
+// mail for email sources.
+type InboundEmailConfiguration struct {
+	// Port is the port mail is accepted on, the listener is only started
+	// when it is set.
+	Port uint32 `json:"port" envconfig:"CONVOY_INBOUND_EMAIL_PORT"`
+
+	// Domain is the domain email sources receive mail at, mail for other
+	// domains is rejected when it is set.
+	Domain string `json:"domain" envconfig:"CONVOY_INBOUND_EMAIL_DOMAIN"`
+
+	MaxMessageSize int64 `json:"max_message_size" envconfig:"CONVOY_INBOUND_EMAIL_MAX_MESSAGE_SIZE"`
+}
+
+// TransformConfiguration limits the js functions sources and subscriptions
+// use to transform events, unset limits use the defaults.
+type TransformConfiguration struct {
+	// Timeout is the most time in milliseconds a function may run for.
+	Timeout uint64 `json:"timeout" envconfig:"CONVOY_TRANSFORM_TIMEOUT"`
+
+	// MaxMemory is how many bytes the heap may grow by while a function
+	// runs.
+	MaxMemory uint64 `json:"max_memory" envconfig:"CONVOY_TRANSFORM_MAX_MEMORY"`
+
+	MaxCallStackSize int `json:"max_call_stack_size" envconfig:"CONVOY_TRANSFORM_MAX_CALL_STACK_SIZE"`
+	MaxConsoleOutput int `json:"max_console_output" envconfig:"CONVOY_TRANSFORM_MAX_CONSOLE_OUTPUT"`
+
+	// Deterministic fixes Date.now to the event's creation time and
+	// seeds Math.random.
+	Deterministic bool `json:"deterministic" envconfig:"CONVOY_TRANSFORM_DETERMINISTIC"`
+}
+
 type LoggerConfiguration struct {
 	Level string `json:"level" envconfig:"CONVOY_LOGGER_LEVEL"`
 }
@@ -260,6 +303,47 @@ type RetentionPolicyConfiguration struct {
 	IsRetentionPolicyEnabled bool   `json:"enabled" envconfig:"CONVOY_RETENTION_POLICY_ENABLED"`
 }
 
+// EncryptionConfiguration holds the master key used to wrap the data
+// keys that encrypt event payloads at rest.
+type EncryptionConfiguration struct {
+	Enabled bool `json:"enabled" envconfig:"CONVOY_ENCRYPTION_ENABLED"`
+
+	// Key is the base64 encoded 32 byte master key, KeyFile is read
+	// instead when it is empty.
+	Key     string `json:"key" envconfig:"CONVOY_ENCRYPTION_KEY"`
+	KeyFile string `json:"key_file" envconfig:"CONVOY_ENCRYPTION_KEY_FILE"`
+
+	// PreviousKeys are master keys that were rotated out, they are only
+	// used to unwrap data keys that haven't been rewrapped yet.
+	PreviousKeys []string `json:"previous_keys" envconfig:"CONVOY_ENCRYPTION_PREVIOUS_KEYS"`
+}
+
+// PayloadOffloadConfiguration moves payloads larger than the threshold to
+// the storage policy's object store, only a reference to them is kept in
+// the database.
+type PayloadOffloadConfiguration struct {
+	Enabled bool `json:"enabled" envconfig:"CONVOY_PAYLOAD_OFFLOAD_ENABLED"`
+
+	// Threshold is the size in bytes above which payloads are offloaded,
+	// it defaults to DefaultPayloadOffloadThreshold. It has to be below
+	// the max response size and the projects' max ingest size for any
+	// payload to be offloaded.
+	Threshold uint64 `json:"threshold" envconfig:"CONVOY_PAYLOAD_OFFLOAD_THRESHOLD"`
+}
+
+// PartitionConfiguration controls the daily partitions of the events and
+// event deliveries tables.
+type PartitionConfiguration struct {
+	// PremakeDays is how many days of partitions are created ahead, it
+	// defaults to DefaultPartitionPremakeDays.
+	PremakeDays int `json:"premake_days" envconfig:"CONVOY_PARTITION_PREMAKE_DAYS"`
+
+	// DetachExpired detaches the partitions past the retention policy
+	// instead of dropping them, so they can be archived before they're
+	// dropped by hand.
+	DetachExpired bool `json:"detach_expired" envconfig:"CONVOY_PARTITION_DETACH_EXPIRED"`
+}
+
 type AnalyticsConfiguration struct {
 	IsEnabled bool `json:"enabled" envconfig:"CONVOY_ANALYTICS_ENABLED"`
 }
@@ -374,6 +458,8 @@ type Configuration struct {
 	Server              ServerConfiguration        `json:"server"`
 	MaxResponseSize     uint64                     `json:"max_response_size" envconfig:"CONVOY_MAX_RESPONSE_SIZE"`
 	SMTP                SMTPConfiguration          `json:"smtp"`
+	InboundEmail        InboundEmailConfiguration  `json:"inbound_email"`
+	Transform           TransformConfiguration     `json:"transform"`
 	Environment         string                     `json:"env" envconfig:"CONVOY_ENV"`
 	Logger              LoggerConfiguration        `json:"logger"`
 	Tracer              TracerConfiguration        `json:"tracer"`
@@ -384,6 +470,9 @@ type Configuration struct {
 	RetentionPolicy    RetentionPolicyConfiguration `json:"retention_policy"`
   Analytics           AnalyticsConfiguration     `json:"analytics"`
 	StoragePolicy       StoragePolicyConfiguration `json:"storage_policy"`
+	Encryption          EncryptionConfiguration    `json:"encryption"`
+	PayloadOffload      PayloadOffloadConfiguration `json:"payload_offload"`
+	Partition           PartitionConfiguration     `json:"partition"`
 	ConsumerPoolSize    int                        `json:"consumer_pool_size" envconfig:"CONVOY_CONSUMER_POOL_SIZE"`
 	EnableProfiling     bool                       `json:"enable_profiling" envconfig:"CONVOY_ENABLE_PROFILING"`
 	Metrics             MetricsConfiguration       `json:"metrics" envconfig:"CONVOY_METRICS"`
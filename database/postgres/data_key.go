@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrDataKeyNotCreated = errors.New("data key could not be created")
+	ErrDataKeyNotUpdated = errors.New("data key could not be updated")
+)
+
+const (
+	retireActiveDataKey = `
+	UPDATE convoy.project_data_keys SET status = $2, updated_at = NOW()
+	WHERE project_id = $1 AND status = $3;
+	`
+
+	// the version is taken from the project's latest, a concurrent
+	// rotation fails on the unique index rather than reusing a version.
+	createDataKey = `
+	INSERT INTO convoy.project_data_keys (id, project_id, version, wrapped_key, kek_id, status)
+	SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+	FROM convoy.project_data_keys WHERE project_id = $2
+	RETURNING version, created_at, updated_at;
+	`
+
+	updateWrappedDataKey = `
+	UPDATE convoy.project_data_keys SET wrapped_key = $3, kek_id = $4, updated_at = NOW()
+	WHERE id = $1 AND project_id = $2;
+	`
+
+	baseFetchDataKey = `
+	SELECT id, project_id, version, wrapped_key, kek_id, status, created_at, updated_at
+	FROM convoy.project_data_keys
+	`
+
+	fetchActiveDataKey = baseFetchDataKey + ` WHERE project_id = $1 AND status = $2;`
+
+	fetchDataKeyByID = baseFetchDataKey + ` WHERE project_id = $1 AND id = $2;`
+
+	fetchDataKeysNotWrappedBy = baseFetchDataKey + ` WHERE kek_id <> $1 ORDER BY id;`
+)
+
+type dataKeyRepo struct {
+	db    *sqlx.DB
+	cache cache.Cache
+}
+
+func NewDataKeyRepo(db database.Database, cache cache.Cache) datastore.DataKeyRepository {
+	return &dataKeyRepo{db: db.GetDB(), cache: cache}
+}
+
+func (d *dataKeyRepo) CreateDataKey(ctx context.Context, key *datastore.DataKey) error {
+	tx, err := d.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	_, err = tx.ExecContext(ctx, retireActiveDataKey, key.ProjectID, datastore.RetiredDataKeyStatus, datastore.ActiveDataKeyStatus)
+	if err != nil {
+		return err
+	}
+
+	key.Status = datastore.ActiveDataKeyStatus
+	err = tx.QueryRowxContext(ctx, createDataKey, key.UID, key.ProjectID, key.WrappedKey, key.KekID, key.Status).
+		Scan(&key.Version, &key.CreatedAt, &key.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDataKeyNotCreated
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *dataKeyRepo) FindActiveDataKey(ctx context.Context, projectID string) (*datastore.DataKey, error) {
+	key := &datastore.DataKey{}
+	err := d.db.QueryRowxContext(ctx, fetchActiveDataKey, projectID, datastore.ActiveDataKeyStatus).StructScan(key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrDataKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (d *dataKeyRepo) FindDataKeyByID(ctx context.Context, projectID string, id string) (*datastore.DataKey, error) {
+	key := &datastore.DataKey{}
+	err := d.db.QueryRowxContext(ctx, fetchDataKeyByID, projectID, id).StructScan(key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrDataKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (d *dataKeyRepo) LoadDataKeysNotWrappedBy(ctx context.Context, kekID string) ([]datastore.DataKey, error) {
+	keys := make([]datastore.DataKey, 0)
+	err := d.db.SelectContext(ctx, &keys, fetchDataKeysNotWrappedBy, kekID)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (d *dataKeyRepo) UpdateWrappedKey(ctx context.Context, key *datastore.DataKey) error {
+	r, err := d.db.ExecContext(ctx, updateWrappedDataKey, key.UID, key.ProjectID, key.WrappedKey, key.KekID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrDataKeyNotUpdated
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func initEncryption(t *testing.T, db database.Database, enabled bool, key string) *encryption.Keyring {
+	k, err := encryption.Init(config.EncryptionConfiguration{Enabled: enabled, Key: key}, NewDataKeyRepo(db, nil), NewProjectRepo(db, nil))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = encryption.Init(config.EncryptionConfiguration{}, nil, nil)
+	})
+
+	return k
+}
+
+// optInToEncryption makes the project encrypt its payloads.
+func optInToEncryption(t *testing.T, db database.Database, projectID string) {
+	projectRepo := NewProjectRepo(db, nil)
+
+	project, err := projectRepo.FetchProjectByID(context.Background(), projectID)
+	require.NoError(t, err)
+
+	project.Config.EncryptPayloads = true
+	require.NoError(t, projectRepo.UpdateProject(context.Background(), project))
+}
+
+func newEncryptionKey(t *testing.T) string {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func Test_CreateDataKey(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	project := seedProject(t, db)
+	repo := NewDataKeyRepo(db, nil)
+	ctx := context.Background()
+
+	_, err := repo.FindActiveDataKey(ctx, project.UID)
+	require.ErrorIs(t, err, datastore.ErrDataKeyNotFound)
+
+	first := &datastore.DataKey{UID: ulid.Make().String(), ProjectID: project.UID, WrappedKey: []byte("first"), KekID: "kek-1"}
+	require.NoError(t, repo.CreateDataKey(ctx, first))
+	require.Equal(t, 1, first.Version)
+
+	second := &datastore.DataKey{UID: ulid.Make().String(), ProjectID: project.UID, WrappedKey: []byte("second"), KekID: "kek-2"}
+	require.NoError(t, repo.CreateDataKey(ctx, second))
+	require.Equal(t, 2, second.Version)
+
+	active, err := repo.FindActiveDataKey(ctx, project.UID)
+	require.NoError(t, err)
+	require.Equal(t, second.UID, active.UID)
+
+	retired, err := repo.FindDataKeyByID(ctx, project.UID, first.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.RetiredDataKeyStatus, retired.Status)
+
+	stale, err := repo.LoadDataKeysNotWrappedBy(ctx, "kek-2")
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	require.Equal(t, first.UID, stale[0].UID)
+
+	stale[0].WrappedKey, stale[0].KekID = []byte("rewrapped"), "kek-2"
+	require.NoError(t, repo.UpdateWrappedKey(ctx, &stale[0]))
+
+	stale, err = repo.LoadDataKeysNotWrappedBy(ctx, "kek-2")
+	require.NoError(t, err)
+	require.Empty(t, stale)
+}
+
+func Test_EncryptedEvent(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventRepo := NewEventRepo(db, nil)
+	event := generateEvent(t, db)
+	ctx := context.Background()
+
+	optInToEncryption(t, db, event.ProjectID)
+	k := initEncryption(t, db, true, newEncryptionKey(t))
+
+	require.NoError(t, eventRepo.CreateEvent(ctx, event))
+
+	// an event whose data key is gone can't be re-encrypted
+	lost := generateEvent(t, db)
+	lost.ProjectID = event.ProjectID
+	require.NoError(t, eventRepo.CreateEvent(ctx, lost))
+
+	_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.events SET raw = 'enc:v1:missing:AAAA' WHERE id = $1`, lost.UID)
+	require.NoError(t, err)
+
+	var raw string
+	var data []byte
+	err = db.GetDB().QueryRowxContext(ctx, `SELECT raw, data FROM convoy.events WHERE id = $1`, event.UID).Scan(&raw, &data)
+	require.NoError(t, err)
+	require.True(t, encryption.IsEncrypted(raw))
+	require.True(t, encryption.IsEncrypted(string(data)))
+
+	newEvent, err := eventRepo.FindEventByID(ctx, event.ProjectID, event.UID)
+	require.NoError(t, err)
+	require.Equal(t, event.Raw, newEvent.Raw)
+	require.JSONEq(t, string(event.Data), string(newEvent.Data))
+
+	_, err = k.RotateDataKey(ctx, event.ProjectID)
+	require.NoError(t, err)
+
+	batch, err := eventRepo.ReEncryptEvents(ctx, event.ProjectID, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), batch.ReEncrypted)
+	require.Equal(t, []string{lost.UID}, batch.Failed)
+
+	prefix, err := k.ActivePrefix(ctx, event.ProjectID)
+	require.NoError(t, err)
+
+	err = db.GetDB().QueryRowxContext(ctx, `SELECT raw FROM convoy.events WHERE id = $1`, event.UID).Scan(&raw)
+	require.NoError(t, err)
+	require.Contains(t, raw, prefix)
+
+	// the next batch starts after the cursor
+	batch, err = eventRepo.ReEncryptEvents(ctx, event.ProjectID, batch.Cursor, 10)
+	require.NoError(t, err)
+	require.Zero(t, batch.Fetched)
+}
+
+func Test_EncryptedEventDelivery(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	key := newEncryptionKey(t)
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+
+	optInToEncryption(t, db, project.UID)
+	initEncryption(t, db, true, key)
+
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db, nil)
+	ctx := context.Background()
+
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+	ed.DeliveryAttempts[0].ResponseData = `{"email": "user@example.com"}`
+	ed.DeliveryAttempts[0].RequestHeader = datastore.HttpHeader{"X-Customer-Email": "user@example.com"}
+	require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+	// the delivery passed in is left as it is
+	require.Equal(t, `{"name": "10x"}`, ed.Metadata.Raw)
+
+	var metadata, attempts string
+	err := db.GetDB().QueryRowxContext(ctx, `SELECT metadata, attempts FROM convoy.event_deliveries WHERE id = $1`, ed.UID).Scan(&metadata, &attempts)
+	require.NoError(t, err)
+	require.NotContains(t, metadata, "10x")
+	require.NotContains(t, attempts, "user@example.com")
+
+	dbEventDelivery, err := edRepo.FindEventDeliveryByID(ctx, project.UID, ed.UID)
+	require.NoError(t, err)
+	require.Equal(t, ed.Metadata.Raw, dbEventDelivery.Metadata.Raw)
+	require.JSONEq(t, string(ed.Metadata.Data), string(dbEventDelivery.Metadata.Data))
+	require.Equal(t, ed.DeliveryAttempts[0].ResponseData, dbEventDelivery.DeliveryAttempts[0].ResponseData)
+	require.Equal(t, ed.DeliveryAttempts[0].RequestHeader, dbEventDelivery.DeliveryAttempts[0].RequestHeader)
+
+	// turning encryption off decrypts the payloads on re-encryption
+	initEncryption(t, db, false, key)
+
+	batch, err := edRepo.ReEncryptEventDeliveries(ctx, project.UID, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), batch.ReEncrypted)
+
+	err = db.GetDB().QueryRowxContext(ctx, `SELECT metadata, attempts FROM convoy.event_deliveries WHERE id = $1`, ed.UID).Scan(&metadata, &attempts)
+	require.NoError(t, err)
+	require.Contains(t, metadata, "10x")
+	require.Contains(t, attempts, "user@example.com")
+}
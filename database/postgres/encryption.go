@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+)
+
+// staleCiphertext matches a column that isn't encrypted with the key whose
+// prefix is the parameter, or that is encrypted at all when the prefix is
+// empty i.e. encryption was turned off.
+const staleCiphertext = `((%[2]s <> '' AND %[1]s <> '' AND %[1]s NOT LIKE %[2]s || '%%') OR (%[2]s = '' AND %[1]s LIKE 'enc:%%'))`
+
+func staleCiphertextFilter(column, prefix string) string {
+	return fmt.Sprintf(staleCiphertext, column, prefix)
+}
+
+// jsonObject is the jsonb value when it's an object and an empty object
+// otherwise, so it can be expanded with jsonb_each.
+func jsonObject(value string) string {
+	return fmt.Sprintf(`(CASE WHEN jsonb_typeof(%[1]s) = 'object' THEN %[1]s ELSE '{}'::jsonb END)`, value)
+}
+
+// encryptEvent returns a copy of event with its payloads encrypted, the
+// event itself is left as it is since callers keep using it.
+func encryptEvent(ctx context.Context, event *datastore.Event) (*datastore.Event, error) {
+	k := encryption.Get()
+	if !k.Enabled() {
+		return event, nil
+	}
+
+	var err error
+	e := *event
+
+	e.Data, err = k.Encrypt(ctx, e.ProjectID, e.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Raw, err = k.EncryptString(ctx, e.ProjectID, e.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	e.OriginalBody, err = k.EncryptString(ctx, e.ProjectID, e.OriginalBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func decryptEvent(ctx context.Context, projectID string, event *datastore.Event) error {
+	k := encryption.Get()
+
+	var err error
+	event.Data, err = k.Decrypt(ctx, projectID, event.Data)
+	if err != nil {
+		return err
+	}
+
+	event.Raw, err = k.DecryptString(ctx, projectID, event.Raw)
+	if err != nil {
+		return err
+	}
+
+	event.OriginalBody, err = k.DecryptString(ctx, projectID, event.OriginalBody)
+	return err
+}
+
+// encryptDeliveryPayloads returns copies of a delivery's metadata and
+// attempts with their payloads encrypted. The data is stored as a json
+// string once encrypted since the metadata column is jsonb.
+func encryptDeliveryPayloads(ctx context.Context, projectID string, metadata *datastore.Metadata, attempts datastore.DeliveryAttempts) (*datastore.Metadata, datastore.DeliveryAttempts, error) {
+	k := encryption.Get()
+	if !k.Enabled() {
+		return metadata, attempts, nil
+	}
+
+	if metadata != nil {
+		m := *metadata
+
+		if len(m.Data) > 0 && !isEncryptedData(m.Data) {
+			data, err := k.Encrypt(ctx, projectID, m.Data)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			m.Data, err = json.Marshal(string(data))
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var err error
+		m.Raw, err = k.EncryptString(ctx, projectID, m.Raw)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		metadata = &m
+	}
+
+	if len(attempts) > 0 {
+		encrypted := make(datastore.DeliveryAttempts, len(attempts))
+		for i, attempt := range attempts {
+			var err error
+			attempt.ResponseData, err = k.EncryptString(ctx, projectID, attempt.ResponseData)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			attempt.RequestHeader, err = transformHeaderValues(attempt.RequestHeader, func(v string) (string, error) {
+				return k.EncryptString(ctx, projectID, v)
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			attempt.ResponseHeader, err = transformHeaderValues(attempt.ResponseHeader, func(v string) (string, error) {
+				return k.EncryptString(ctx, projectID, v)
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			encrypted[i] = attempt
+		}
+		attempts = encrypted
+	}
+
+	return metadata, attempts, nil
+}
+
+func decryptDeliveryPayloads(ctx context.Context, projectID string, metadata *datastore.Metadata, attempts datastore.DeliveryAttempts) error {
+	k := encryption.Get()
+
+	if metadata != nil {
+		if isEncryptedData(metadata.Data) {
+			var value string
+			err := json.Unmarshal(metadata.Data, &value)
+			if err != nil {
+				return err
+			}
+
+			metadata.Data, err = k.Decrypt(ctx, projectID, []byte(value))
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		metadata.Raw, err = k.DecryptString(ctx, projectID, metadata.Raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	decrypt := func(v string) (string, error) {
+		return k.DecryptString(ctx, projectID, v)
+	}
+
+	for i := range attempts {
+		var err error
+		attempts[i].ResponseData, err = k.DecryptString(ctx, projectID, attempts[i].ResponseData)
+		if err != nil {
+			return err
+		}
+
+		attempts[i].RequestHeader, err = transformHeaderValues(attempts[i].RequestHeader, decrypt)
+		if err != nil {
+			return err
+		}
+
+		attempts[i].ResponseHeader, err = transformHeaderValues(attempts[i].ResponseHeader, decrypt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformHeaderValues returns a copy of the headers with fn applied to
+// their values, the names are kept as they are.
+func transformHeaderValues(headers datastore.HttpHeader, fn func(string) (string, error)) (datastore.HttpHeader, error) {
+	if len(headers) == 0 {
+		return headers, nil
+	}
+
+	transformed := make(datastore.HttpHeader, len(headers))
+	for name, value := range headers {
+		v, err := fn(value)
+		if err != nil {
+			return nil, err
+		}
+		transformed[name] = v
+	}
+
+	return transformed, nil
+}
+
+func decryptEventDelivery(ctx context.Context, projectID string, delivery *datastore.EventDelivery) error {
+	return decryptDeliveryPayloads(ctx, projectID, delivery.Metadata, delivery.DeliveryAttempts)
+}
+
+func isEncryptedData(data json.RawMessage) bool {
+	return len(data) > 0 && data[0] == '"' && encryption.IsEncrypted(string(data[1:]))
+}
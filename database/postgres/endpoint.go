@@ -32,12 +32,12 @@ const (
 		id, name, status, secrets, owner_id, url, description, http_timeout,
 		rate_limit, rate_limit_duration, advanced_signatures, slack_webhook_url,
 		support_email, app_id, project_id, authentication_type, authentication_type_api_key_header_name,
-		authentication_type_api_key_header_value
+		authentication_type_api_key_header_value, event_type_versions, labels
 	)
 	VALUES
 	  (
 		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
-		$14, $15, $16, $17, $18
+		$14, $15, $16, $17, $18, $19, $20
 	  );
 	`
 
@@ -46,7 +46,7 @@ const (
 	e.id, e.name, e.status, e.owner_id,
 	e.url, e.description, e.http_timeout,
 	e.rate_limit, e.rate_limit_duration, e.advanced_signatures,
-	e.slack_webhook_url, e.support_email, e.app_id,
+	e.slack_webhook_url, e.support_email, e.app_id, e.event_type_versions, e.labels,
 	e.project_id, e.secrets, e.created_at, e.updated_at,
 	e.authentication_type AS "authentication.type",
 	e.authentication_type_api_key_header_name AS "authentication.api_key.header_name",
@@ -63,10 +63,12 @@ const (
 
 	fetchEndpointsByOwnerId = baseEndpointFetch + ` AND e.project_id = $1 AND e.owner_id = $2 GROUP BY e.id ORDER BY e.id;`
 
+	fetchEndpointsByLabels = baseEndpointFetch + ` AND e.project_id = $1 AND e.labels @> $2 GROUP BY e.id ORDER BY e.id;`
+
 	fetchEndpointByTargetURL = `
     SELECT e.id, e.name, e.status, e.owner_id, e.url,
     e.description, e.http_timeout, e.rate_limit, e.rate_limit_duration,
-    e.advanced_signatures, e.slack_webhook_url, e.support_email,
+    e.advanced_signatures, e.slack_webhook_url, e.support_email, e.event_type_versions, e.labels,
     e.app_id, e.project_id, e.secrets, e.created_at, e.updated_at,
     e.authentication_type AS "authentication.type",
     e.authentication_type_api_key_header_name AS "authentication.api_key.header_name",
@@ -82,6 +84,7 @@ const (
 	slack_webhook_url = $12, support_email = $13,
 	authentication_type = $14, authentication_type_api_key_header_name = $15,
 	authentication_type_api_key_header_value = $16, secrets = $17,
+	event_type_versions = $18, labels = $19,
 	updated_at = NOW()
 	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
 	`
@@ -91,7 +94,7 @@ const (
 	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL RETURNING
 	id, name, status, owner_id, url,
     description, http_timeout, rate_limit, rate_limit_duration,
-    advanced_signatures, slack_webhook_url, support_email,
+    advanced_signatures, slack_webhook_url, support_email, event_type_versions, labels,
     app_id, project_id, secrets, created_at, updated_at,
     authentication_type AS "authentication.type",
     authentication_type_api_key_header_name AS "authentication.api_key.header_name",
@@ -104,7 +107,7 @@ const (
 	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL RETURNING
 	id, name, status, owner_id, url,
     description, http_timeout, rate_limit, rate_limit_duration,
-    advanced_signatures, slack_webhook_url, support_email,
+    advanced_signatures, slack_webhook_url, support_email, event_type_versions, labels,
     app_id, project_id, secrets, created_at, updated_at,
     authentication_type AS "authentication.type",
     authentication_type_api_key_header_name AS "authentication.api_key.header_name",
@@ -131,7 +134,7 @@ const (
 	e.id, e.name, e.status, e.owner_id,
 	e.url, e.description, e.http_timeout,
 	e.rate_limit, e.rate_limit_duration, e.advanced_signatures,
-	e.slack_webhook_url, e.support_email, e.app_id,
+	e.slack_webhook_url, e.support_email, e.app_id, e.event_type_versions, e.labels,
 	e.project_id, e.secrets, e.created_at, e.updated_at,
 	e.authentication_type AS "authentication.type",
 	e.authentication_type_api_key_header_name AS "authentication.api_key.header_name",
@@ -197,6 +200,7 @@ func (e *endpointRepo) CreateEndpoint(ctx context.Context, endpoint *datastore.E
 		endpoint.Description, endpoint.HttpTimeout, endpoint.RateLimit, endpoint.RateLimitDuration,
 		endpoint.AdvancedSignatures, endpoint.SlackWebhookURL, endpoint.SupportEmail, endpoint.AppID,
 		projectID, ac.Type, ac.ApiKey.HeaderName, ac.ApiKey.HeaderValue,
+		endpoint.EventTypeVersions, endpoint.Labels,
 	}
 
 	result, err := e.db.ExecContext(ctx, createEndpoint, args...)
@@ -281,6 +285,15 @@ func (e *endpointRepo) FindEndpointsByOwnerID(ctx context.Context, projectID str
 	return e.scanEndpoints(rows)
 }
 
+func (e *endpointRepo) FindEndpointsByLabels(ctx context.Context, projectID string, selector datastore.Labels) ([]datastore.Endpoint, error) {
+	rows, err := e.db.QueryxContext(ctx, fetchEndpointsByLabels, projectID, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.scanEndpoints(rows)
+}
+
 func (e *endpointRepo) UpdateEndpoint(ctx context.Context, endpoint *datastore.Endpoint, projectID string) error {
 	ac := endpoint.GetAuthConfig()
 
@@ -288,6 +301,7 @@ func (e *endpointRepo) UpdateEndpoint(ctx context.Context, endpoint *datastore.E
 		endpoint.Description, endpoint.HttpTimeout, endpoint.RateLimit, endpoint.RateLimitDuration,
 		endpoint.AdvancedSignatures, endpoint.SlackWebhookURL, endpoint.SupportEmail,
 		ac.Type, ac.ApiKey.HeaderName, ac.ApiKey.HeaderValue, endpoint.Secrets,
+		endpoint.EventTypeVersions, endpoint.Labels,
 	)
 	if err != nil {
 		return err
@@ -409,6 +423,7 @@ func (e *endpointRepo) LoadEndpointsPaged(ctx context.Context, projectId string,
 		"cursor":       pageable.Cursor(),
 		"endpoint_ids": filter.EndpointIDs,
 		"name":         q,
+		"labels":       filter.Labels,
 	}
 
 	var query, filterQuery string
@@ -422,6 +437,10 @@ func (e *endpointRepo) LoadEndpointsPaged(ctx context.Context, projectId string,
 		filterQuery = ` AND e.id IN (:endpoint_ids)`
 	}
 
+	if len(filter.Labels) > 0 {
+		filterQuery += ` AND e.labels @> :labels`
+	}
+
 	query = fmt.Sprintf(query, baseFetchEndpointsPaged, filterQuery)
 	query, args, err := sqlx.Named(query, arg)
 	if err != nil {
@@ -237,6 +237,36 @@ func Test_LoadEndpointsPaged(t *testing.T) {
 	require.True(t, len(endpoints) == 7)
 }
 
+func Test_LoadEndpointsPagedByLabels(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	endpointRepo := NewEndpointRepo(db, nil)
+
+	project := seedProject(t, db)
+
+	for i := 0; i < 5; i++ {
+		endpoint := generateEndpoint(project)
+		endpoint.Labels = datastore.Labels{"region": "us"}
+		if i < 2 {
+			endpoint.Labels = datastore.Labels{"region": "eu", "tier": "enterprise"}
+		}
+
+		err := endpointRepo.CreateEndpoint(context.Background(), endpoint, project.UID)
+		require.NoError(t, err)
+	}
+
+	endpoints, _, err := endpointRepo.LoadEndpointsPaged(context.Background(), project.UID, &datastore.Filter{Labels: datastore.Labels{"region": "eu"}}, datastore.Pageable{
+		PerPage: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		require.Equal(t, datastore.Labels{"region": "eu", "tier": "enterprise"}, endpoint.Labels)
+	}
+}
+
 func Test_FindEndpointsByID(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -407,6 +437,42 @@ func Test_FindEndpointsByOwnerID(t *testing.T) {
 	}
 }
 
+func Test_FindEndpointsByLabels(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	endpointRepo := NewEndpointRepo(db, nil)
+
+	project := seedProject(t, db)
+	endpointMap := map[string]*datastore.Endpoint{}
+	for i := 0; i < 5; i++ {
+		endpoint := generateEndpoint(project)
+
+		if i < 3 {
+			endpoint.Labels = datastore.Labels{"region": "eu", "tier": "enterprise"}
+			endpointMap[endpoint.UID] = endpoint
+		} else {
+			endpoint.Labels = datastore.Labels{"region": "eu"}
+		}
+
+		err := endpointRepo.CreateEndpoint(context.Background(), endpoint, project.UID)
+		require.NoError(t, err)
+	}
+
+	dbEndpoints, err := endpointRepo.FindEndpointsByLabels(context.Background(), project.UID, datastore.Labels{"tier": "enterprise"})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(dbEndpoints))
+
+	for _, dbEndpoint := range dbEndpoints {
+		_, ok := endpointMap[dbEndpoint.UID]
+		require.True(t, ok)
+	}
+
+	dbEndpoints, err = endpointRepo.FindEndpointsByLabels(context.Background(), project.UID, datastore.Labels{"region": "us"})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(dbEndpoints))
+}
+
 func Test_CountProjectEndpoints(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
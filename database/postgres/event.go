@@ -1,8 +1,10 @@
 package postgres
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
 	"github.com/frain-dev/convoy/util"
 	"github.com/jmoiron/sqlx"
 )
@@ -24,8 +27,9 @@ const (
 	createEvent = `
 	INSERT INTO convoy.events (id,event_type,endpoints,project_id,
 	                           source_id,headers,raw,data,url_query_params,
-	                           idempotency_key,is_duplicate_event,acknowledged_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	                           idempotency_key,is_duplicate_event,acknowledged_at,metadata,original_body,
+	                           payload_reference,payload,event_type_version,redacted)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 
 	createEventEndpoints = `
@@ -34,7 +38,10 @@ const (
 
 	fetchEventById = `
 	SELECT id, event_type, endpoints, project_id,
-    raw, data, headers, is_duplicate_event,
+    raw, data, headers, is_duplicate_event, metadata,
+	COALESCE(original_body, '') AS original_body,
+	COALESCE(payload_reference, '') AS payload_reference,
+	event_type_version, redacted,
 	COALESCE(source_id, '') AS source_id,
 	COALESCE(idempotency_key, '') AS idempotency_key,
 	COALESCE(url_query_params, '') AS url_query_params,
@@ -62,7 +69,9 @@ const (
 	COALESCE(ev.source_id, '') AS source_id,
 	COALESCE(ev.idempotency_key, '') AS idempotency_key,
 	COALESCE(ev.url_query_params, '') AS url_query_params,
-	ev.headers, ev.raw, ev.data, ev.created_at,
+	ev.headers, ev.raw, ev.data, ev.metadata, ev.redacted, ev.created_at,
+	COALESCE(ev.original_body, '') AS original_body,
+	COALESCE(ev.payload_reference, '') AS payload_reference,
 	ev.updated_at, ev.deleted_at,ev.acknowledged_at,
 	COALESCE(s.id, '') AS "source_metadata.id",
 	COALESCE(s.name, '') AS "source_metadata.name"
@@ -82,15 +91,16 @@ const (
 	SELECT COUNT(DISTINCT(ev.id)) FROM convoy.events ev
 	LEFT JOIN convoy.events_endpoints ee ON ee.event_id = ev.id
 	LEFT JOIN convoy.endpoints e ON ee.endpoint_id = e.id
-	WHERE ev.project_id = $1 AND (e.id = $2 OR $2 = '' )
-	AND (ev.source_id = $3 OR $3 = '') AND ev.created_at >= $4 AND ev.created_at <= $5 AND ev.deleted_at IS NULL;
+	WHERE ev.project_id = :project_id AND (e.id = :endpoint_id OR :endpoint_id = '' )
+	AND (ev.source_id = :source_id OR :source_id = '') AND ev.created_at >= :start_date AND ev.created_at <= :end_date AND ev.deleted_at IS NULL
 	`
 
 	baseEventsPaged = `
 	SELECT ev.id, ev.project_id,
 	ev.id AS event_type, ev.is_duplicate_event,
 	COALESCE(ev.source_id, '') AS source_id,
-	ev.headers, ev.raw, ev.data, ev.created_at,
+	ev.headers, ev.raw, ev.data, ev.metadata, ev.redacted, ev.created_at,
+	COALESCE(ev.payload_reference, '') AS payload_reference,
 	COALESCE(idempotency_key, '') AS idempotency_key,
 	COALESCE(url_query_params, '') AS url_query_params,
 	ev.updated_at, ev.deleted_at,ev.acknowledged_at,
@@ -106,7 +116,7 @@ const (
 	SELECT ev.id, ev.project_id,
 	ev.id AS event_type, ev.is_duplicate_event,
 	COALESCE(ev.source_id, '') AS source_id,
-	ev.headers, ev.raw, ev.data, ev.created_at,ev.acknowledged_at,
+	ev.headers, ev.raw, ev.data, ev.redacted, ev.created_at,ev.acknowledged_at,
 	COALESCE(idempotency_key, '') AS idempotency_key,
 	COALESCE(url_query_params, '') AS url_query_params,
 	ev.updated_at, ev.deleted_at,
@@ -151,6 +161,9 @@ const (
 
 	searchFilter = ` AND search_token @@ websearch_to_tsquery('simple',:query) `
 
+	// uses the GIN index on the payload, see payloadFilterQuery.
+	eventPayloadFilter = ` AND ev.payload @@ CAST(:%s AS jsonpath) `
+
 	baseCountPrevEvents = `
 	SELECT COUNT(DISTINCT(ev.id)) AS COUNT
 	FROM convoy.events ev
@@ -172,13 +185,19 @@ const (
 	AND deleted_at IS NULL
 	`
 
+	// events_endpoints has no foreign key to the partitioned events table,
+	// its rows are deleted with their events.
 	hardDeleteProjectEvents = `
+	WITH deleted AS (
 	DELETE FROM convoy.events WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3
 	AND deleted_at IS NULL AND NOT EXISTS (
     SELECT 1
     FROM convoy.event_deliveries
     WHERE event_id = convoy.events.id
     )
+	RETURNING id
+	)
+	DELETE FROM convoy.events_endpoints WHERE event_id IN (SELECT id FROM deleted)
 	`
 
 	hardDeleteTokenizedEvents = `
@@ -192,6 +211,26 @@ const (
     `
 )
 
+var (
+	// raw is empty for some events, so the start of data is checked when
+	// it is.
+	fetchEventsToReEncrypt = `
+	SELECT id, project_id, COALESCE(raw, '') AS raw, data, COALESCE(original_body, '') AS original_body
+	FROM convoy.events
+	WHERE project_id = $1 AND deleted_at IS NULL
+	AND id > $4
+	AND ` + staleCiphertextFilter(`COALESCE(NULLIF(raw, ''), encode(substring(data from 1 for 64), 'escape'), '')`, "$2") + `
+	ORDER BY id
+	LIMIT $3
+	FOR UPDATE SKIP LOCKED;
+	`
+
+	updateEventPayload = `
+	UPDATE convoy.events SET raw = $3, data = $4, original_body = $5, payload = $6
+	WHERE id = $1 AND project_id = $2;
+	`
+)
+
 type eventRepo struct {
 	db    *sqlx.DB
 	cache cache.Cache
@@ -217,6 +256,18 @@ func (e *eventRepo) CreateEvent(ctx context.Context, event *datastore.Event) err
 		defer rollbackTx(tx)
 	}
 
+	payload, err := encryptEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	var payloadReference *string
+	if !util.IsStringEmpty(event.PayloadReference) {
+		// offloaded payloads are only kept in the object store
+		payloadReference = &event.PayloadReference
+		payload = &datastore.Event{Data: []byte{}, OriginalBody: payload.OriginalBody}
+	}
+
 	_, err = tx.ExecContext(ctx, createEvent,
 		event.UID,
 		event.EventType,
@@ -224,12 +275,18 @@ func (e *eventRepo) CreateEvent(ctx context.Context, event *datastore.Event) err
 		event.ProjectID,
 		sourceID,
 		event.Headers,
-		event.Raw,
-		event.Data,
+		payload.Raw,
+		payload.Data,
 		event.URLQueryParams,
 		event.IdempotencyKey,
 		event.IsDuplicateEvent,
 		event.AcknowledgedAt,
+		event.Metadata,
+		payload.OriginalBody,
+		payloadReference,
+		payloadJSON(payload.Data),
+		event.EventTypeVersion,
+		event.Redacted,
 	)
 	if err != nil {
 		return err
@@ -271,6 +328,12 @@ func (e *eventRepo) FindEventByID(ctx context.Context, projectID string, id stri
 
 		return nil, err
 	}
+
+	err = decryptEvent(ctx, projectID, event)
+	if err != nil {
+		return nil, err
+	}
+
 	return event, nil
 }
 
@@ -296,6 +359,11 @@ func (e *eventRepo) FindEventsByIDs(ctx context.Context, projectID string, ids [
 			return nil, err
 		}
 
+		err = decryptEvent(ctx, projectID, &event)
+		if err != nil {
+			return nil, err
+		}
+
 		events = append(events, event)
 	}
 
@@ -358,7 +426,22 @@ func (e *eventRepo) CountEvents(ctx context.Context, projectID string, filter *d
 	var count int64
 	startDate, endDate := getCreatedDateFilter(filter.SearchParams.CreatedAtStart, filter.SearchParams.CreatedAtEnd)
 
-	err := e.db.QueryRowxContext(ctx, countEvents, projectID, filter.EndpointID, filter.SourceID, startDate, endDate).Scan(&count)
+	arg := map[string]interface{}{
+		"project_id":  projectID,
+		"endpoint_id": filter.EndpointID,
+		"source_id":   filter.SourceID,
+		"start_date":  startDate,
+		"end_date":    endDate,
+	}
+
+	query := countEvents + payloadFilterQuery(eventPayloadFilter, filter.SearchParams.PayloadFilters, arg)
+	query, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return count, err
+	}
+
+	query = e.db.Rebind(query)
+	err = e.db.QueryRowxContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return count, err
 	}
@@ -411,6 +494,8 @@ func (e *eventRepo) LoadEventsPaged(ctx context.Context, projectID string, filte
 		base = baseEventsSearch
 	}
 
+	filterQuery += payloadFilterQuery(eventPayloadFilter, filter.SearchParams.PayloadFilters, arg)
+
 	preOrder := filter.Pageable.SortOrder()
 	if filter.Pageable.Direction == datastore.Prev {
 		preOrder = reverseOrder(preOrder)
@@ -443,6 +528,11 @@ func (e *eventRepo) LoadEventsPaged(ctx context.Context, projectID string, filte
 			return nil, datastore.PaginationData{}, err
 		}
 
+		err = decryptEvent(ctx, projectID, &data)
+		if err != nil {
+			return nil, datastore.PaginationData{}, err
+		}
+
 		events = append(events, data)
 	}
 
@@ -553,10 +643,75 @@ func (e *eventRepo) CopyRows(ctx context.Context, projectID string, interval int
 	return tx.Commit()
 }
 
+func (e *eventRepo) ReEncryptEvents(ctx context.Context, projectID, cursor string, limit int) (*datastore.ReEncryptBatch, error) {
+	batch := &datastore.ReEncryptBatch{Cursor: cursor}
+
+	k := encryption.Get()
+	if k == nil {
+		return batch, nil
+	}
+
+	prefix, err := k.ActivePrefix(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := e.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer rollbackTx(tx)
+
+	events := make([]datastore.Event, 0)
+	err = tx.SelectContext(ctx, &events, fetchEventsToReEncrypt, projectID, prefix, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		event := &events[i]
+		batch.Cursor = event.UID
+
+		// a row that can't be decrypted mustn't hold up the rest
+		err = decryptEvent(ctx, projectID, event)
+		if err != nil {
+			batch.Failed = append(batch.Failed, event.UID)
+			continue
+		}
+
+		payload, err := encryptEvent(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, updateEventPayload, event.UID, projectID, payload.Raw, payload.Data, payload.OriginalBody, payloadJSON(payload.Data))
+		if err != nil {
+			return nil, err
+		}
+
+		batch.ReEncrypted++
+	}
+
+	batch.Fetched = len(events)
+	return batch, tx.Commit()
+}
+
 func (e *eventRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
 	return exportRecords(ctx, e.db, "convoy.events", projectID, createdAt, w)
 }
 
+// payloadJSON returns data for the payload column that payload filters
+// match against. It's null when data isn't json e.g. when it's encrypted or
+// offloaded, or when it holds a \u0000 which jsonb doesn't allow.
+func payloadJSON(data []byte) *string {
+	if !json.Valid(data) || bytes.Contains(data, []byte(`\u0000`)) {
+		return nil
+	}
+
+	s := string(data)
+	return &s
+}
+
 func getCreatedDateFilter(startDate, endDate int64) (time.Time, time.Time) {
 	return time.Unix(startDate, 0), time.Unix(endDate, 0)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/database/hooks"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/util"
 	"github.com/jmoiron/sqlx"
@@ -33,17 +34,18 @@ var (
 	ErrEventDeliveryNotCreated         = errors.New("event delivery could not be created")
 	ErrEventDeliveryStatusNotUpdated   = errors.New("event delivery status could not be updated")
 	ErrEventDeliveryAttemptsNotUpdated = errors.New("event delivery attempts could not be updated")
+	ErrEventDeliveryMetadataNotUpdated = errors.New("event delivery metadata could not be updated")
 	ErrEventDeliveriesNotDeleted       = errors.New("event deliveries could not be deleted")
 )
 
 const (
 	createEventDelivery = `
-    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,attempts,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16);
+    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,attempts,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at,event_type_version,debounce_key,fallback_for_id)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19);
     `
 	createEventDeliveries = `
-    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,attempts,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at)
-    VALUES (:id, :project_id, :event_id, :endpoint_id, :device_id, :subscription_id, :headers, :attempts, :status, :metadata, :cli_metadata, :description, :url_query_params, :idempotency_key, :event_type, :acknowledged_at);
+    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,attempts,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at,event_type_version,debounce_key,fallback_for_id)
+    VALUES (:id, :project_id, :event_id, :endpoint_id, :device_id, :subscription_id, :headers, :attempts, :status, :metadata, :cli_metadata, :description, :url_query_params, :idempotency_key, :event_type, :acknowledged_at, :event_type_version, :debounce_key, :fallback_for_id);
     `
 
 	baseFetchEventDelivery = `
@@ -52,7 +54,9 @@ const (
         ed.headers,ed.attempts,ed.status,ed.metadata,ed.cli_metadata,
         COALESCE(ed.url_query_params, '') AS url_query_params,
         COALESCE(ed.idempotency_key, '') AS idempotency_key,
-        ed.description,ed.created_at,ed.updated_at,ed.acknowledged_at,
+        ed.description,ed.created_at,ed.updated_at,ed.acknowledged_at,ed.event_type_version,
+        COALESCE(ed.debounce_key, '') AS debounce_key,
+        COALESCE(ed.fallback_for_id, '') AS fallback_for_id,
         COALESCE(ed.event_type,'') AS "event_type",
         COALESCE(ed.device_id,'') AS "device_id",
         COALESCE(ed.endpoint_id,'') AS "endpoint_id",
@@ -117,12 +121,15 @@ const (
         COALESCE(event_type,'') AS "event_type",
         COALESCE(device_id,'') AS "device_id",
         COALESCE(endpoint_id,'') AS "endpoint_id",
+        COALESCE(fallback_for_id,'') AS "fallback_for_id",
         acknowledged_at
     FROM convoy.event_deliveries
 	WHERE deleted_at IS NULL
     AND project_id = $1 AND id = $2
     `
 
+	deliveryPayloadFilter = ` AND ed.metadata->'data' @@ CAST(:%s AS jsonpath) `
+
 	baseEventDeliveryFilter = ` AND (ed.project_id = :project_id OR :project_id = '')
 	AND (ed.event_id = :event_id OR :event_id = '')
     AND (ed.event_type = :event_type OR :event_type = '')
@@ -168,6 +175,7 @@ const (
         COALESCE(event_type,'') AS "event_type",
         COALESCE(device_id,'') AS "device_id",
         COALESCE(endpoint_id,'') AS "endpoint_id",
+        COALESCE(fallback_for_id,'') AS "fallback_for_id",
         acknowledged_at
     FROM convoy.event_deliveries ed
     `
@@ -189,10 +197,13 @@ const (
     `
 
 	fetchStuckEventDeliveries = `
-    SELECT id, project_id
+    SELECT id, project_id,
+        COALESCE(endpoint_id,'') AS "endpoint_id",
+        COALESCE(fallback_for_id,'') AS "fallback_for_id"
     FROM convoy.event_deliveries
 	WHERE status = $1
 	  AND created_at <= now() - make_interval(secs := 30)
+	  AND (debounce_key IS NULL OR (metadata->>'next_send_time')::TIMESTAMPTZ <= now() - make_interval(secs := 30))
       AND deleted_at IS NULL
     FOR UPDATE SKIP LOCKED
     LIMIT 1000;
@@ -208,6 +219,14 @@ const (
 
 	updateEventDeliveriesStatus = `
     UPDATE convoy.event_deliveries SET status = ?, description = ?, updated_at = NOW() WHERE (project_id = ? OR ? = '')AND id IN (?) AND deleted_at IS NULL;
+    `
+
+	// supersedes the scheduled deliveries of a debounce key, the
+	// superseded deliveries are returned so they can be coalesced
+	supersedeEventDeliveries = `
+    UPDATE convoy.event_deliveries SET status = $5, description = $6, updated_at = NOW()
+    WHERE project_id = $1 AND subscription_id = $2 AND debounce_key = $3 AND status = $4 AND deleted_at IS NULL
+    RETURNING id, project_id, event_id, subscription_id, metadata, created_at;
     `
 
 	updateEventDeliveryAttempts = `
@@ -223,6 +242,62 @@ const (
     `
 )
 
+var (
+	// the partitioned table can't have a unique index on fallback_for_id,
+	// concurrent fallbacks for a delivery are serialised with a lock
+	// held until the transaction ends.
+	lockFallbackDelivery = `SELECT pg_advisory_xact_lock(hashtext('fallback:' || $1));`
+
+	fallbackDeliveryExists = `SELECT EXISTS (SELECT 1 FROM convoy.event_deliveries WHERE fallback_for_id = $1);`
+
+	fetchEventDeliveriesToReEncrypt = `
+    SELECT id, project_id, metadata, attempts
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND deleted_at IS NULL
+    AND id > $4
+    AND (` + staleCiphertextFilter(`COALESCE(NULLIF(metadata->>'raw', ''), metadata->>'data', '')`, "$2") + `
+    OR EXISTS (
+        SELECT 1 FROM jsonb_array_elements(COALESCE(attempts, '[]')) a
+        WHERE ` + staleCiphertextFilter(`COALESCE(a->>'response_data', '')`, "$2") + `
+        OR EXISTS (
+            SELECT 1 FROM jsonb_each_text(` + jsonObject(`a->'request_http_header'`) + ` || ` + jsonObject(`a->'response_http_header'`) + `) h
+            WHERE ` + staleCiphertextFilter(`h.value`, "$2") + `
+        )
+    ))
+    ORDER BY id
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED;
+    `
+
+	updateEventDeliveryMetadata = `
+    UPDATE convoy.event_deliveries SET metadata = $1, updated_at = NOW() WHERE id = $2 AND project_id = $3 AND deleted_at IS NULL;
+    `
+
+	updateEventDeliveryPayload = `
+    UPDATE convoy.event_deliveries SET metadata = $3, attempts = $4
+    WHERE id = $1 AND project_id = $2;
+    `
+)
+
+func checkFallbackDelivery(ctx context.Context, tx *sqlx.Tx, fallbackForID string) error {
+	_, err := tx.ExecContext(ctx, lockFallbackDelivery, fallbackForID)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	err = tx.QueryRowxContext(ctx, fallbackDeliveryExists, fallbackForID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return datastore.ErrDuplicateFallbackDelivery
+	}
+
+	return nil
+}
+
 func NewEventDeliveryRepo(db database.Database, cache cache.Cache) datastore.EventDeliveryRepository {
 	return &eventDeliveryRepo{db: db.GetDB(), hook: db.GetHook(), cache: cache}
 }
@@ -248,12 +323,25 @@ func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, delivery *d
 		defer rollbackTx(tx)
 	}
 
+	if !util.IsStringEmpty(delivery.FallbackForID) {
+		err = checkFallbackDelivery(ctx, tx, delivery.FallbackForID)
+		if err != nil {
+			return err
+		}
+	}
+
+	metadata, attempts, err := encryptDeliveryPayloads(ctx, delivery.ProjectID, delivery.Metadata, delivery.DeliveryAttempts)
+	if err != nil {
+		return err
+	}
+
 	result, err := tx.ExecContext(
 		ctx, createEventDelivery, delivery.UID, delivery.ProjectID,
 		delivery.EventID, endpointID, deviceID,
-		delivery.SubscriptionID, delivery.Headers, delivery.DeliveryAttempts, delivery.Status,
-		delivery.Metadata, delivery.CLIMetadata, delivery.Description, delivery.URLQueryParams, delivery.IdempotencyKey, delivery.EventType,
-		delivery.AcknowledgedAt,
+		delivery.SubscriptionID, delivery.Headers, attempts, delivery.Status,
+		metadata, delivery.CLIMetadata, delivery.Description, delivery.URLQueryParams, delivery.IdempotencyKey, delivery.EventType,
+		delivery.AcknowledgedAt, delivery.EventTypeVersion, nullableValue(delivery.DebounceKey),
+		nullableValue(delivery.FallbackForID),
 	)
 	if err != nil {
 		return err
@@ -275,6 +363,15 @@ func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, delivery *d
 	return tx.Commit()
 }
 
+// nullableValue stores an empty string as NULL.
+func nullableValue(v string) *string {
+	if util.IsStringEmpty(v) {
+		return nil
+	}
+
+	return &v
+}
+
 // CreateEventDeliveries creates event deliveries in bulk
 func (e *eventDeliveryRepo) CreateEventDeliveries(ctx context.Context, deliveries []*datastore.EventDelivery) error {
 	values := make([]map[string]interface{}, 0, len(deliveries))
@@ -291,23 +388,31 @@ func (e *eventDeliveryRepo) CreateEventDeliveries(ctx context.Context, deliverie
 			deviceID = &delivery.DeviceID
 		}
 
+		metadata, attempts, err := encryptDeliveryPayloads(ctx, delivery.ProjectID, delivery.Metadata, delivery.DeliveryAttempts)
+		if err != nil {
+			return err
+		}
+
 		values = append(values, map[string]interface{}{
-			"id":               delivery.UID,
-			"project_id":       delivery.ProjectID,
-			"event_id":         delivery.EventID,
-			"endpoint_id":      endpointID,
-			"device_id":        deviceID,
-			"subscription_id":  delivery.SubscriptionID,
-			"headers":          delivery.Headers,
-			"attempts":         delivery.DeliveryAttempts,
-			"status":           delivery.Status,
-			"metadata":         delivery.Metadata,
-			"cli_metadata":     delivery.CLIMetadata,
-			"description":      delivery.Description,
-			"url_query_params": delivery.URLQueryParams,
-			"idempotency_key":  delivery.IdempotencyKey,
-			"event_type":       delivery.EventType,
-			"acknowledged_at":  delivery.AcknowledgedAt,
+			"id":                 delivery.UID,
+			"project_id":         delivery.ProjectID,
+			"event_id":           delivery.EventID,
+			"endpoint_id":        endpointID,
+			"device_id":          deviceID,
+			"subscription_id":    delivery.SubscriptionID,
+			"headers":            delivery.Headers,
+			"attempts":           attempts,
+			"status":             delivery.Status,
+			"metadata":           metadata,
+			"cli_metadata":       delivery.CLIMetadata,
+			"description":        delivery.Description,
+			"url_query_params":   delivery.URLQueryParams,
+			"idempotency_key":    delivery.IdempotencyKey,
+			"event_type":         delivery.EventType,
+			"acknowledged_at":    delivery.AcknowledgedAt,
+			"event_type_version": delivery.EventTypeVersion,
+			"debounce_key":       nullableValue(delivery.DebounceKey),
+			"fallback_for_id":    nullableValue(delivery.FallbackForID),
 		})
 	}
 
@@ -364,6 +469,11 @@ func (e *eventDeliveryRepo) FindEventDeliveryByID(ctx context.Context, projectID
 		return nil, err
 	}
 
+	err = decryptEventDelivery(ctx, projectID, eventDelivery)
+	if err != nil {
+		return nil, err
+	}
+
 	return eventDelivery, nil
 }
 
@@ -377,6 +487,11 @@ func (e *eventDeliveryRepo) FindEventDeliveryByIDSlim(ctx context.Context, proje
 		return nil, err
 	}
 
+	err = decryptEventDelivery(ctx, projectID, eventDelivery)
+	if err != nil {
+		return nil, err
+	}
+
 	return eventDelivery, nil
 }
 
@@ -404,6 +519,11 @@ func (e *eventDeliveryRepo) FindEventDeliveriesByIDs(ctx context.Context, projec
 			return nil, err
 		}
 
+		err = decryptEventDelivery(ctx, projectID, &ed)
+		if err != nil {
+			return nil, err
+		}
+
 		eventDeliveries = append(eventDeliveries, ed)
 	}
 
@@ -427,6 +547,11 @@ func (e *eventDeliveryRepo) FindEventDeliveriesByEventID(ctx context.Context, pr
 			return nil, err
 		}
 
+		err = decryptEventDelivery(ctx, projectID, &ed)
+		if err != nil {
+			return nil, err
+		}
+
 		eventDeliveries = append(eventDeliveries, ed)
 	}
 
@@ -495,6 +620,29 @@ func (e *eventDeliveryRepo) UpdateStatusOfEventDelivery(ctx context.Context, pro
 	return nil
 }
 
+func (e *eventDeliveryRepo) UpdateEventDeliveryMetadata(ctx context.Context, projectID string, delivery datastore.EventDelivery) error {
+	metadata, _, err := encryptDeliveryPayloads(ctx, projectID, delivery.Metadata, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := e.db.ExecContext(ctx, updateEventDeliveryMetadata, metadata, delivery.UID, projectID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrEventDeliveryMetadataNotUpdated
+	}
+
+	return nil
+}
+
 func (e *eventDeliveryRepo) UpdateStatusOfEventDeliveries(ctx context.Context, projectID string, ids []string, status datastore.EventDeliveryStatus) error {
 	query, args, err := sqlx.In(updateEventDeliveriesStatus, status, "", projectID, projectID, ids)
 	if err != nil {
@@ -520,6 +668,53 @@ func (e *eventDeliveryRepo) UpdateStatusOfEventDeliveries(ctx context.Context, p
 	return nil
 }
 
+func (e *eventDeliveryRepo) SupersedeEventDeliveries(ctx context.Context, projectID, subscriptionID, debounceKey, supersededBy string) ([]datastore.EventDelivery, error) {
+	var q sqlx.QueryerContext = e.db
+	if tx, ok := ctx.Value(TransactionCtx).(*sqlx.Tx); ok && tx != nil {
+		q = tx
+	}
+
+	rows, err := q.QueryxContext(ctx, supersedeEventDeliveries, projectID, subscriptionID, debounceKey,
+		datastore.ScheduledEventStatus, datastore.SupersededEventStatus, fmt.Sprintf("superseded by event delivery %s", supersededBy))
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	for rows.Next() {
+		var delivery datastore.EventDelivery
+		err = rows.StructScan(&delivery)
+		if err != nil {
+			return nil, err
+		}
+
+		err = decryptEventDelivery(ctx, projectID, &delivery)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (e *eventDeliveryRepo) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := e.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	err = fn(context.WithValue(ctx, TransactionCtx, tx))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (e *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, projectID, deviceId string, searchParams datastore.SearchParams) ([]datastore.EventDelivery, error) {
 	eventDeliveries := make([]datastore.EventDelivery, 0)
 
@@ -539,6 +734,11 @@ func (e *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, pr
 			return nil, err
 		}
 
+		err = decryptEventDelivery(ctx, projectID, &ed)
+		if err != nil {
+			return nil, err
+		}
+
 		eventDeliveries = append(eventDeliveries, ed)
 	}
 
@@ -548,7 +748,12 @@ func (e *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, pr
 func (e *eventDeliveryRepo) UpdateEventDeliveryWithAttempt(ctx context.Context, projectID string, delivery datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
 	delivery.DeliveryAttempts = append(delivery.DeliveryAttempts, attempt)
 
-	result, err := e.db.ExecContext(ctx, updateEventDeliveryAttempts, delivery.DeliveryAttempts, delivery.Status, delivery.Metadata, delivery.LatencySeconds, delivery.UID, projectID)
+	metadata, attempts, err := encryptDeliveryPayloads(ctx, projectID, delivery.Metadata, delivery.DeliveryAttempts)
+	if err != nil {
+		return err
+	}
+
+	result, err := e.db.ExecContext(ctx, updateEventDeliveryAttempts, attempts, delivery.Status, metadata, delivery.LatencySeconds, delivery.UID, projectID)
 	if err != nil {
 		return err
 	}
@@ -676,6 +881,8 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 		filterQuery += ` AND ed.subscription_id = :subscription_id`
 	}
 
+	filterQuery += payloadFilterQuery(deliveryPayloadFilter, params.PayloadFilters, arg)
+
 	preOrder := pageable.SortOrder()
 	if pageable.Direction == datastore.Prev {
 		preOrder = reverseOrder(preOrder)
@@ -707,6 +914,11 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 			return nil, datastore.PaginationData{}, err
 		}
 
+		err = decryptDeliveryPayloads(ctx, projectID, ed.Metadata, ed.DeliveryAttempts)
+		if err != nil {
+			return nil, datastore.PaginationData{}, err
+		}
+
 		eventDeliveriesP = append(eventDeliveriesP, ed)
 	}
 
@@ -723,18 +935,21 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 		}
 
 		eventDeliveries = append(eventDeliveries, datastore.EventDelivery{
-			UID:            ev.UID,
-			ProjectID:      ev.ProjectID,
-			EventID:        ev.EventID,
-			EndpointID:     ev.EndpointID,
-			DeviceID:       ev.DeviceID,
-			SubscriptionID: ev.SubscriptionID,
-			IdempotencyKey: ev.IdempotencyKey,
-			Headers:        ev.Headers,
-			URLQueryParams: ev.URLQueryParams,
-			Latency:        ev.Latency,
-			LatencySeconds: ev.LatencySeconds,
-			EventType:      ev.EventType,
+			UID:              ev.UID,
+			ProjectID:        ev.ProjectID,
+			EventID:          ev.EventID,
+			EndpointID:       ev.EndpointID,
+			DeviceID:         ev.DeviceID,
+			SubscriptionID:   ev.SubscriptionID,
+			IdempotencyKey:   ev.IdempotencyKey,
+			Headers:          ev.Headers,
+			URLQueryParams:   ev.URLQueryParams,
+			Latency:          ev.Latency,
+			LatencySeconds:   ev.LatencySeconds,
+			EventType:        ev.EventType,
+			EventTypeVersion: ev.EventTypeVersion,
+			DebounceKey:      ev.DebounceKey,
+			FallbackForID:    ev.FallbackForID,
 			Endpoint: &datastore.Endpoint{
 				UID:          ev.Endpoint.UID.ValueOrZero(),
 				ProjectID:    ev.Endpoint.ProjectID.ValueOrZero(),
@@ -892,6 +1107,59 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesIntervals(ctx context.Context, pr
 	return intervals, nil
 }
 
+func (e *eventDeliveryRepo) ReEncryptEventDeliveries(ctx context.Context, projectID, cursor string, limit int) (*datastore.ReEncryptBatch, error) {
+	batch := &datastore.ReEncryptBatch{Cursor: cursor}
+
+	k := encryption.Get()
+	if k == nil {
+		return batch, nil
+	}
+
+	prefix, err := k.ActivePrefix(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := e.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer rollbackTx(tx)
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	err = tx.SelectContext(ctx, &deliveries, fetchEventDeliveriesToReEncrypt, projectID, prefix, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		batch.Cursor = delivery.UID
+
+		// a row that can't be decrypted mustn't hold up the rest
+		err = decryptEventDelivery(ctx, projectID, delivery)
+		if err != nil {
+			batch.Failed = append(batch.Failed, delivery.UID)
+			continue
+		}
+
+		metadata, attempts, err := encryptDeliveryPayloads(ctx, projectID, delivery.Metadata, delivery.DeliveryAttempts)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, updateEventDeliveryPayload, delivery.UID, projectID, metadata, attempts)
+		if err != nil {
+			return nil, err
+		}
+
+		batch.ReEncrypted++
+	}
+
+	batch.Fetched = len(deliveries)
+	return batch, tx.Commit()
+}
+
 func (e *eventDeliveryRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
 	return exportRecords(ctx, e.db, "convoy.event_deliveries", projectID, createdAt, w)
 }
@@ -988,6 +1256,10 @@ type EventDeliveryPaginated struct {
 	LatencySeconds float64             `json:"latency_seconds" db:"latency_seconds"`
 	EventType      datastore.EventType `json:"event_type,omitempty" db:"event_type"`
 
+	EventTypeVersion null.Int `json:"event_type_version,omitempty" db:"event_type_version"`
+	DebounceKey      string   `json:"debounce_key,omitempty" db:"debounce_key"`
+	FallbackForID    string   `json:"fallback_for_id,omitempty" db:"fallback_for_id"`
+
 	Endpoint *EndpointMetadata `json:"endpoint_metadata,omitempty" db:"endpoint_metadata"`
 	Event    *EventMetadata    `json:"event_metadata,omitempty" db:"event_metadata"`
 	Source   *SourceMetadata   `json:"source_metadata,omitempty" db:"source_metadata"`
@@ -1049,6 +1321,20 @@ func getCountEventPrevRowQuery(sortOrder string) string {
 	return countPrevEventDeliveries
 }
 
+// payloadFilterQuery returns a condition from query for each filter, their
+// paths are added to arg. The payloads are matched with @@ since ? is a
+// bindvar, it's also indexed by jsonb_path_ops.
+func payloadFilterQuery(query string, filters []datastore.PayloadFilter, arg map[string]interface{}) string {
+	var b strings.Builder
+	for i, f := range filters {
+		name := fmt.Sprintf("payload_filter_%d", i)
+		arg[name] = f.JSONPath()
+		b.WriteString(fmt.Sprintf(query, name))
+	}
+
+	return b.String()
+}
+
 func reverseOrder(sortOrder string) string {
 	switch sortOrder {
 	case "ASC":
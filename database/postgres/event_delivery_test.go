@@ -285,6 +285,88 @@ func Test_eventDeliveryRepo_UpdateStatusOfEventDeliveries(t *testing.T) {
 	}
 }
 
+func Test_eventDeliveryRepo_SupersedeEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	scheduled := generateEventDelivery(project, endpoint, event, device, sub)
+	scheduled.Status = datastore.ScheduledEventStatus
+	scheduled.DebounceKey = "profile.updated:p_1"
+
+	// deliveries that already went out aren't superseded
+	sent := generateEventDelivery(project, endpoint, event, device, sub)
+	sent.DebounceKey = "profile.updated:p_1"
+
+	other := generateEventDelivery(project, endpoint, event, device, sub)
+	other.Status = datastore.ScheduledEventStatus
+	other.DebounceKey = "profile.updated:p_2"
+
+	edRepo := NewEventDeliveryRepo(db, nil)
+	require.NoError(t, edRepo.CreateEventDeliveries(context.Background(), []*datastore.EventDelivery{scheduled, sent, other}))
+
+	superseded, err := edRepo.SupersedeEventDeliveries(context.Background(), project.UID, sub.UID, "profile.updated:p_1", "next-delivery")
+	require.NoError(t, err)
+	require.Len(t, superseded, 1)
+	require.Equal(t, scheduled.UID, superseded[0].UID)
+	require.JSONEq(t, string(scheduled.Metadata.Data), string(superseded[0].Metadata.Data))
+
+	dbEventDeliveries, err := edRepo.FindEventDeliveriesByIDs(context.Background(), project.UID, []string{scheduled.UID, sent.UID, other.UID})
+	require.NoError(t, err)
+
+	statuses := map[string]datastore.EventDeliveryStatus{}
+	for _, d := range dbEventDeliveries {
+		statuses[d.UID] = d.Status
+	}
+
+	require.Equal(t, datastore.SupersededEventStatus, statuses[scheduled.UID])
+	require.Equal(t, datastore.SuccessEventStatus, statuses[sent.UID])
+	require.Equal(t, datastore.ScheduledEventStatus, statuses[other.UID])
+}
+
+func Test_eventDeliveryRepo_RunInTransaction(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	scheduled := generateEventDelivery(project, endpoint, event, device, sub)
+	scheduled.Status = datastore.ScheduledEventStatus
+	scheduled.DebounceKey = "profile.updated:p_1"
+
+	edRepo := NewEventDeliveryRepo(db, nil)
+	require.NoError(t, edRepo.CreateEventDeliveries(context.Background(), []*datastore.EventDelivery{scheduled}))
+
+	// the supersede is rolled back with the failed insert
+	next := generateEventDelivery(project, endpoint, event, device, sub)
+	err := edRepo.RunInTransaction(context.Background(), func(ctx context.Context) error {
+		superseded, err := edRepo.SupersedeEventDeliveries(ctx, project.UID, sub.UID, "profile.updated:p_1", next.UID)
+		require.NoError(t, err)
+		require.Len(t, superseded, 1)
+
+		return edRepo.CreateEventDeliveries(ctx, []*datastore.EventDelivery{next, next})
+	})
+	require.Error(t, err)
+
+	dbEventDelivery, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, scheduled.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.ScheduledEventStatus, dbEventDelivery.Status)
+
+	_, err = edRepo.FindEventDeliveryByID(context.Background(), project.UID, next.UID)
+	require.ErrorIs(t, err, datastore.ErrEventDeliveryNotFound)
+}
+
 func Test_eventDeliveryRepo_FindDiscardedEventDeliveries(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -525,3 +607,78 @@ func Test_eventDeliveryRepo_LoadEventDeliveriesPaged(t *testing.T) {
 	require.Equal(t, 1, len(filteredDeliveries))
 	require.Equal(t, ed.UID, filteredDeliveries[0].UID)
 }
+
+func Test_eventDeliveryRepo_LoadEventDeliveriesPaged_PayloadFilters(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db, nil)
+
+	matching := generateEventDelivery(project, endpoint, event, device, sub)
+	matching.Metadata.Data = []byte(`{"customer": {"email": "ada@example.com"}}`)
+	matching.Metadata.Raw = string(matching.Metadata.Data)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), matching))
+
+	other := generateEventDelivery(project, endpoint, event, device, sub)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), other))
+
+	filter, err := datastore.ParsePayloadFilter("customer.email=ada@example.com")
+	require.NoError(t, err)
+
+	deliveries, _, err := edRepo.LoadEventDeliveriesPaged(
+		context.Background(), project.UID, nil, "", "", nil,
+		datastore.SearchParams{
+			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+			CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+			PayloadFilters: []datastore.PayloadFilter{filter},
+		},
+		datastore.Pageable{
+			PerPage: 10,
+		},
+		"", "",
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, len(deliveries))
+	require.Equal(t, matching.UID, deliveries[0].UID)
+}
+
+func Test_eventDeliveryRepo_CreateFallbackEventDelivery(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	failed := generateEventDelivery(project, endpoint, event, device, sub)
+	failed.Status = datastore.FailureEventStatus
+
+	edRepo := NewEventDeliveryRepo(db, nil)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), failed))
+
+	fallback := generateEventDelivery(project, endpoint, event, device, sub)
+	fallback.EndpointID = ""
+	fallback.FallbackForID = failed.UID
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), fallback))
+
+	dbFallback, err := edRepo.FindEventDeliveryByIDSlim(context.Background(), project.UID, fallback.UID)
+	require.NoError(t, err)
+	require.Equal(t, failed.UID, dbFallback.FallbackForID)
+	require.True(t, dbFallback.IsPubSubFallback())
+
+	// a delivery only has one fallback
+	duplicate := generateEventDelivery(project, endpoint, event, device, sub)
+	duplicate.FallbackForID = failed.UID
+	require.ErrorIs(t, edRepo.CreateEventDelivery(context.Background(), duplicate), datastore.ErrDuplicateFallbackDelivery)
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/pkg/httpheader"
@@ -40,6 +41,26 @@ func Test_CreateEvent(t *testing.T) {
 	require.Equal(t, event, newEvent)
 }
 
+func Test_CreateOffloadedEvent(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventRepo := NewEventRepo(db, nil)
+	event := generateEvent(t, db)
+	event.PayloadReference = "s3://bucket/payloads/" + event.UID
+	ctx := context.Background()
+
+	require.NoError(t, eventRepo.CreateEvent(ctx, event))
+
+	newEvent, err := eventRepo.FindEventByID(ctx, event.ProjectID, event.UID)
+	require.NoError(t, err)
+	require.Equal(t, event.PayloadReference, newEvent.PayloadReference)
+
+	// only the reference is kept
+	require.Empty(t, newEvent.Raw)
+	require.Empty(t, newEvent.Data)
+}
+
 func Test_FindEventByID(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -380,6 +401,145 @@ func Test_LoadEventsPaged(t *testing.T) {
 	}
 }
 
+func Test_LoadEventsPaged_PayloadFilters(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	project := seedProject(t, db)
+	eventRepo := NewEventRepo(db, nil)
+
+	payloads := []string{
+		`{"order": {"id": 123, "status": "paid"}, "items": [{"sku": "a"}]}`,
+		`{"order": {"id": "123", "status": "pending"}, "items": [{"sku": "b"}]}`,
+		`{"order": {"id": 456, "status": "paid"}}`,
+		`not json`,
+	}
+
+	ids := make([]string, len(payloads))
+	for i, payload := range payloads {
+		event := &datastore.Event{
+			UID:       ulid.Make().String(),
+			EventType: "test-event",
+			ProjectID: project.UID,
+			Headers:   httpheader.HTTPHeader{},
+			Raw:       payload,
+			Data:      []byte(payload),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		require.NoError(t, eventRepo.CreateEvent(context.Background(), event))
+		ids[i] = event.UID
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		want    []string
+	}{
+		{name: "number_matches_string_form", filters: []string{"order.id=123"}, want: ids[:2]},
+		{name: "quoted_string", filters: []string{`order.id="123"`}, want: ids[1:2]},
+		{name: "array_elements", filters: []string{"$.items.sku=b"}, want: ids[1:2]},
+		{name: "all_filters_match", filters: []string{"order.status=paid", "order.id=456"}, want: ids[2:3]},
+		{name: "no_match", filters: []string{"order.id=789"}, want: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filters := make([]datastore.PayloadFilter, 0, len(tc.filters))
+			for _, s := range tc.filters {
+				f, err := datastore.ParsePayloadFilter(s)
+				require.NoError(t, err)
+				filters = append(filters, f)
+			}
+
+			events, _, err := eventRepo.LoadEventsPaged(context.Background(), project.UID, &datastore.Filter{
+				SearchParams: datastore.SearchParams{
+					CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+					CreatedAtEnd:   time.Now().Add(5 * time.Minute).Unix(),
+					PayloadFilters: filters,
+				},
+				Pageable: datastore.Pageable{
+					PerPage:    10,
+					Direction:  datastore.Next,
+					NextCursor: datastore.DefaultCursor,
+				},
+			})
+			require.NoError(t, err)
+
+			var got []string
+			for _, e := range events {
+				got = append(got, e.UID)
+			}
+			require.ElementsMatch(t, tc.want, got)
+
+			count, err := eventRepo.CountEvents(context.Background(), project.UID, &datastore.Filter{
+				SearchParams: datastore.SearchParams{
+					CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+					CreatedAtEnd:   time.Now().Add(5 * time.Minute).Unix(),
+					PayloadFilters: filters,
+				},
+			})
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.want)), count)
+		})
+	}
+}
+
+func Test_LoadEventsPaged_SearchWithPayloadFilters(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	project := seedProject(t, db)
+	eventRepo := NewEventRepo(db, nil)
+	ctx := context.Background()
+
+	payloads := []string{
+		`{"kind": "invoice", "status": "paid"}`,
+		`{"kind": "invoice", "status": "pending"}`,
+		`{"kind": "refund", "status": "paid"}`,
+	}
+
+	ids := make([]string, len(payloads))
+	for i, payload := range payloads {
+		event := &datastore.Event{
+			UID:       ulid.Make().String(),
+			EventType: "test-event",
+			ProjectID: project.UID,
+			Headers:   httpheader.HTTPHeader{},
+			Raw:       payload,
+			Data:      []byte(payload),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		require.NoError(t, eventRepo.CreateEvent(ctx, event))
+		ids[i] = event.UID
+	}
+
+	require.NoError(t, eventRepo.CopyRows(ctx, project.UID, config.DefaultSearchTokenizationInterval))
+
+	filter, err := datastore.ParsePayloadFilter("status=paid")
+	require.NoError(t, err)
+
+	events, _, err := eventRepo.LoadEventsPaged(ctx, project.UID, &datastore.Filter{
+		Query: "invoice",
+		SearchParams: datastore.SearchParams{
+			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+			CreatedAtEnd:   time.Now().Add(5 * time.Minute).Unix(),
+			PayloadFilters: []datastore.PayloadFilter{filter},
+		},
+		Pageable: datastore.Pageable{
+			PerPage:    10,
+			Direction:  datastore.Next,
+			NextCursor: datastore.DefaultCursor,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, ids[0], events[0].UID)
+}
+
 func Test_SoftDeleteProjectEvents(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrEventTypeNotCreated = errors.New("event type could not be created")
+	ErrEventTypeNotUpdated = errors.New("event type could not be updated")
+	ErrEventTypeNotDeleted = errors.New("event type could not be deleted")
+)
+
+const (
+	createEventType = `
+	INSERT INTO convoy.event_types (id, project_id, name, description, json_schema, examples, deprecated, version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	updateEventType = `
+	UPDATE convoy.event_types SET
+	  name = $3,
+	  description = $4,
+	  json_schema = $5,
+	  examples = $6,
+	  deprecated = $7,
+	  updated_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+	`
+
+	// keeps the latest version's schema in step with the event type
+	updateLatestEventTypeVersion = `
+	UPDATE convoy.event_type_versions SET json_schema = $3
+	WHERE event_type_id = $1 AND version = $2;
+	`
+
+	deleteEventType = `
+	UPDATE convoy.event_types SET deleted_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+	`
+
+	baseFetchEventType = `
+	SELECT id, project_id, name, description, json_schema, examples, deprecated, version, created_at, updated_at
+	FROM convoy.event_types
+	WHERE project_id = $1 AND deleted_at IS NULL
+	`
+
+	fetchEventTypeByID = baseFetchEventType + ` AND id = $2;`
+
+	fetchEventTypeByName = baseFetchEventType + ` AND name = $2;`
+
+	fetchEventTypes = baseFetchEventType + ` ORDER BY name;`
+
+	createEventTypeVersion = `
+	INSERT INTO convoy.event_type_versions (project_id, event_type_id, version, json_schema, down_converter)
+	VALUES ($1, $2, $3, $4, $5);
+	`
+
+	updateEventTypeToVersion = `
+	UPDATE convoy.event_types SET
+	  json_schema = $3,
+	  examples = $4,
+	  version = $5,
+	  updated_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND version = $5 - 1 AND deleted_at IS NULL;
+	`
+
+	fetchEventTypeVersions = `
+	SELECT project_id, event_type_id, version, json_schema, down_converter, created_at
+	FROM convoy.event_type_versions
+	WHERE project_id = $1 AND event_type_id = $2
+	ORDER BY version DESC;
+	`
+)
+
+type eventTypeRepo struct {
+	db    *sqlx.DB
+	cache cache.Cache
+}
+
+func NewEventTypeRepo(db database.Database, cache cache.Cache) datastore.EventTypeRepository {
+	return &eventTypeRepo{db: db.GetDB(), cache: cache}
+}
+
+func (e *eventTypeRepo) CreateEventType(ctx context.Context, eventType *datastore.ProjectEventType) error {
+	tx, err := e.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if eventType.Version == 0 {
+		eventType.Version = 1
+	}
+
+	r, err := tx.ExecContext(ctx, createEventType, eventType.UID, eventType.ProjectID, eventType.Name,
+		eventType.Description, jsonSchemaValue(eventType.JSONSchema), eventType.Examples, eventType.Deprecated, eventType.Version)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return datastore.ErrDuplicateEventTypeName
+		}
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrEventTypeNotCreated
+	}
+
+	_, err = tx.ExecContext(ctx, createEventTypeVersion, eventType.ProjectID, eventType.UID,
+		eventType.Version, jsonSchemaValue(eventType.JSONSchema), "")
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (e *eventTypeRepo) UpdateEventType(ctx context.Context, projectID string, eventType *datastore.ProjectEventType) error {
+	tx, err := e.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	r, err := tx.ExecContext(ctx, updateEventType, eventType.UID, projectID, eventType.Name,
+		eventType.Description, jsonSchemaValue(eventType.JSONSchema), eventType.Examples, eventType.Deprecated)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return datastore.ErrDuplicateEventTypeName
+		}
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrEventTypeNotUpdated
+	}
+
+	_, err = tx.ExecContext(ctx, updateLatestEventTypeVersion, eventType.UID, eventType.Version, jsonSchemaValue(eventType.JSONSchema))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (e *eventTypeRepo) DeleteEventType(ctx context.Context, projectID string, id string) error {
+	r, err := e.db.ExecContext(ctx, deleteEventType, id, projectID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrEventTypeNotDeleted
+	}
+
+	return nil
+}
+
+func (e *eventTypeRepo) FindEventTypeByID(ctx context.Context, projectID string, id string) (*datastore.ProjectEventType, error) {
+	return e.findEventType(ctx, fetchEventTypeByID, projectID, id)
+}
+
+func (e *eventTypeRepo) FindEventTypeByName(ctx context.Context, projectID string, name string) (*datastore.ProjectEventType, error) {
+	return e.findEventType(ctx, fetchEventTypeByName, projectID, name)
+}
+
+func (e *eventTypeRepo) findEventType(ctx context.Context, query string, args ...interface{}) (*datastore.ProjectEventType, error) {
+	eventType := &datastore.ProjectEventType{}
+	err := e.db.QueryRowxContext(ctx, query, args...).StructScan(eventType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrEventTypeNotFound
+		}
+
+		return nil, err
+	}
+
+	return eventType, nil
+}
+
+func (e *eventTypeRepo) LoadEventTypes(ctx context.Context, projectID string) ([]datastore.ProjectEventType, error) {
+	eventTypes := make([]datastore.ProjectEventType, 0)
+	err := e.db.SelectContext(ctx, &eventTypes, fetchEventTypes, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventTypes, nil
+}
+
+func (e *eventTypeRepo) CreateEventTypeVersion(ctx context.Context, eventType *datastore.ProjectEventType, version *datastore.EventTypeVersion) error {
+	tx, err := e.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	_, err = tx.ExecContext(ctx, createEventTypeVersion, version.ProjectID, version.EventTypeID,
+		version.Version, jsonSchemaValue(version.JSONSchema), version.DownConverter)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return datastore.ErrEventTypeVersionConflict
+		}
+		return err
+	}
+
+	// only moves the event type on from the version before this one, so
+	// versions added concurrently can't both become the latest
+	r, err := tx.ExecContext(ctx, updateEventTypeToVersion, eventType.UID, eventType.ProjectID,
+		jsonSchemaValue(version.JSONSchema), eventType.Examples, version.Version)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return datastore.ErrEventTypeVersionConflict
+	}
+
+	return tx.Commit()
+}
+
+func (e *eventTypeRepo) LoadEventTypeVersions(ctx context.Context, projectID string, eventTypeID string) ([]datastore.EventTypeVersion, error) {
+	versions := make([]datastore.EventTypeVersion, 0)
+	err := e.db.SelectContext(ctx, &versions, fetchEventTypeVersions, projectID, eventTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// jsonSchemaValue stores a missing schema as NULL.
+func jsonSchemaValue(schema json.RawMessage) interface{} {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	return []byte(schema)
+}
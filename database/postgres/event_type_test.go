@@ -0,0 +1,88 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EventTypes(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	ctx := context.Background()
+	project := seedProject(t, db)
+	eventTypeRepo := NewEventTypeRepo(db, nil)
+
+	eventType := &datastore.ProjectEventType{
+		UID:         ulid.Make().String(),
+		ProjectID:   project.UID,
+		Name:        "invoice.paid",
+		Description: "An invoice was paid",
+		Examples:    datastore.EventTypeExamples{json.RawMessage(`{"id":"inv_1"}`)},
+	}
+	require.NoError(t, eventTypeRepo.CreateEventType(ctx, eventType))
+
+	// names are unique in a project
+	duplicate := *eventType
+	duplicate.UID = ulid.Make().String()
+	require.Equal(t, datastore.ErrDuplicateEventTypeName, eventTypeRepo.CreateEventType(ctx, &duplicate))
+
+	found, err := eventTypeRepo.FindEventTypeByName(ctx, project.UID, "invoice.paid")
+	require.NoError(t, err)
+	require.Empty(t, found.JSONSchema)
+	require.Len(t, found.Examples, 1)
+
+	eventType.JSONSchema = json.RawMessage(`{"type":"object","required":["id"]}`)
+	eventType.Deprecated = true
+	require.NoError(t, eventTypeRepo.UpdateEventType(ctx, project.UID, eventType))
+
+	found, err = eventTypeRepo.FindEventTypeByID(ctx, project.UID, eventType.UID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(eventType.JSONSchema), string(found.JSONSchema))
+	require.True(t, found.Deprecated)
+
+	eventTypes, err := eventTypeRepo.LoadEventTypes(ctx, project.UID)
+	require.NoError(t, err)
+	require.Len(t, eventTypes, 1)
+
+	next := *found
+	next.Version = 2
+	next.JSONSchema = json.RawMessage(`{"type":"object","required":["id","total"]}`)
+	version := &datastore.EventTypeVersion{
+		ProjectID:     project.UID,
+		EventTypeID:   eventType.UID,
+		Version:       2,
+		JSONSchema:    next.JSONSchema,
+		DownConverter: `function transform(payload) { delete payload.total; return payload }`,
+	}
+	require.NoError(t, eventTypeRepo.CreateEventTypeVersion(ctx, &next, version))
+
+	// the version was already added
+	require.Equal(t, datastore.ErrEventTypeVersionConflict, eventTypeRepo.CreateEventTypeVersion(ctx, &next, version))
+
+	found, err = eventTypeRepo.FindEventTypeByID(ctx, project.UID, eventType.UID)
+	require.NoError(t, err)
+	require.Equal(t, 2, found.Version)
+	require.JSONEq(t, string(next.JSONSchema), string(found.JSONSchema))
+
+	versions, err := eventTypeRepo.LoadEventTypeVersions(ctx, project.UID, eventType.UID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, 2, versions[0].Version)
+	require.Equal(t, version.DownConverter, versions[0].DownConverter)
+	require.JSONEq(t, string(eventType.JSONSchema), string(versions[1].JSONSchema))
+
+	require.NoError(t, eventTypeRepo.DeleteEventType(ctx, project.UID, eventType.UID))
+
+	_, err = eventTypeRepo.FindEventTypeByName(ctx, project.UID, "invoice.paid")
+	require.True(t, errors.Is(err, datastore.ErrEventTypeNotFound))
+}
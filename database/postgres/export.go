@@ -94,7 +94,8 @@ func querybatch(ctx context.Context, db *sqlx.DB, q, projectID, lastID string, c
 	var record json.RawMessage
 	records := make([]byte, 0, 1000)
 
-	// scan the first record and append it without appending a comma
+	// scan the first record and append it without appending a comma,
+	// unless it follows the records of a previous batch
 	if rows.Next() {
 		numDocs++
 		err = rows.Scan(&record)
@@ -102,6 +103,9 @@ func querybatch(ctx context.Context, db *sqlx.DB, q, projectID, lastID string, c
 			return 0, "", err
 		}
 
+		if lastID != "" {
+			records = append(records, commaJSON...)
+		}
 		records = append(records, record...)
 	}
 
@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	ErrFunctionLibraryNotCreated = errors.New("function library could not be created")
+	ErrFunctionLibraryNotUpdated = errors.New("function library could not be updated")
+	ErrFunctionLibraryNotDeleted = errors.New("function library could not be deleted")
+	ErrFunctionVersionNotCreated = errors.New("function version could not be created")
+)
+
+const (
+	createFunctionLibrary = `
+	INSERT INTO convoy.function_libraries (id, project_id, name, function, version)
+	VALUES ($1, $2, $3, $4, $5);
+	`
+
+	updateFunctionLibrary = `
+	UPDATE convoy.function_libraries SET
+	  name = $3,
+	  function = $4,
+	  version = $5,
+	  updated_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+	`
+
+	fetchFunctionLibraryForUpdate = `
+	SELECT function, version FROM convoy.function_libraries
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL
+	FOR UPDATE;
+	`
+
+	deleteFunctionLibrary = `
+	UPDATE convoy.function_libraries SET deleted_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+	`
+
+	baseFetchFunctionLibrary = `
+	SELECT id, project_id, name, function, version, created_at, updated_at
+	FROM convoy.function_libraries
+	WHERE project_id = $1 AND deleted_at IS NULL
+	`
+
+	fetchFunctionLibraryByID = baseFetchFunctionLibrary + ` AND id = $2;`
+
+	fetchFunctionLibraries = baseFetchFunctionLibrary + ` ORDER BY name;`
+
+	// the version is taken from the owner's latest, a concurrent change
+	// fails on the unique index rather than reusing a version.
+	createFunctionVersion = `
+	INSERT INTO convoy.function_versions (id, project_id, owner_type, owner_id, function, version)
+	SELECT $1, $2, $3, $4, $5, COALESCE(MAX(version), 0) + 1
+	FROM convoy.function_versions WHERE owner_type = $3 AND owner_id = $4
+	RETURNING version, created_at;
+	`
+
+	baseFetchFunctionVersion = `
+	SELECT id, project_id, owner_type, owner_id, version, function, created_at
+	FROM convoy.function_versions
+	WHERE project_id = $1 AND owner_type = $2 AND owner_id = $3
+	`
+
+	fetchFunctionVersion = baseFetchFunctionVersion + ` AND version = $4;`
+
+	fetchFunctionVersions = baseFetchFunctionVersion + ` ORDER BY version DESC;`
+)
+
+type functionLibraryRepo struct {
+	db    *sqlx.DB
+	cache cache.Cache
+}
+
+func NewFunctionLibraryRepo(db database.Database, cache cache.Cache) datastore.FunctionLibraryRepository {
+	return &functionLibraryRepo{db: db.GetDB(), cache: cache}
+}
+
+// CreateFunctionLibrary saves library with its function as the first
+// version, library.Version is set to it.
+func (f *functionLibraryRepo) CreateFunctionLibrary(ctx context.Context, library *datastore.FunctionLibrary) error {
+	tx, err := f.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	library.Version, err = createLibraryVersion(ctx, tx, library.ProjectID, library.UID, library.Function)
+	if err != nil {
+		return err
+	}
+
+	r, err := tx.ExecContext(ctx, createFunctionLibrary, library.UID, library.ProjectID, library.Name, library.Function, library.Version)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return datastore.ErrDuplicateFunctionLibraryName
+		}
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrFunctionLibraryNotCreated
+	}
+
+	return tx.Commit()
+}
+
+// UpdateFunctionLibrary saves library, a new version is recorded when its
+// function changed and library.Version is set to the latest version.
+func (f *functionLibraryRepo) UpdateFunctionLibrary(ctx context.Context, projectID string, library *datastore.FunctionLibrary) error {
+	tx, err := f.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	var function string
+	err = tx.QueryRowxContext(ctx, fetchFunctionLibraryForUpdate, library.UID, projectID).Scan(&function, &library.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFunctionLibraryNotUpdated
+		}
+
+		return err
+	}
+
+	if function != library.Function {
+		library.Version, err = createLibraryVersion(ctx, tx, projectID, library.UID, library.Function)
+		if err != nil {
+			return err
+		}
+	}
+
+	r, err := tx.ExecContext(ctx, updateFunctionLibrary, library.UID, projectID, library.Name, library.Function, library.Version)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return datastore.ErrDuplicateFunctionLibraryName
+		}
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrFunctionLibraryNotUpdated
+	}
+
+	return tx.Commit()
+}
+
+func createLibraryVersion(ctx context.Context, tx *sqlx.Tx, projectID, libraryID, function string) (int, error) {
+	var version int
+	var createdAt time.Time
+
+	err := tx.QueryRowxContext(ctx, createFunctionVersion, ulid.Make().String(), projectID,
+		datastore.FunctionLibraryOwner, libraryID, function,
+	).Scan(&version, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrFunctionVersionNotCreated
+		}
+
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (f *functionLibraryRepo) DeleteFunctionLibrary(ctx context.Context, projectID string, id string) error {
+	r, err := f.db.ExecContext(ctx, deleteFunctionLibrary, id, projectID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return ErrFunctionLibraryNotDeleted
+	}
+
+	return nil
+}
+
+func (f *functionLibraryRepo) FindFunctionLibraryByID(ctx context.Context, projectID string, id string) (*datastore.FunctionLibrary, error) {
+	library := &datastore.FunctionLibrary{}
+	err := f.db.QueryRowxContext(ctx, fetchFunctionLibraryByID, projectID, id).StructScan(library)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrFunctionLibraryNotFound
+		}
+
+		return nil, err
+	}
+
+	return library, nil
+}
+
+func (f *functionLibraryRepo) LoadFunctionLibraries(ctx context.Context, projectID string) ([]datastore.FunctionLibrary, error) {
+	libraries := make([]datastore.FunctionLibrary, 0)
+	err := f.db.SelectContext(ctx, &libraries, fetchFunctionLibraries, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return libraries, nil
+}
+
+type functionVersionRepo struct {
+	db    *sqlx.DB
+	cache cache.Cache
+}
+
+func NewFunctionVersionRepo(db database.Database, cache cache.Cache) datastore.FunctionVersionRepository {
+	return &functionVersionRepo{db: db.GetDB(), cache: cache}
+}
+
+func (f *functionVersionRepo) CreateFunctionVersion(ctx context.Context, version *datastore.FunctionVersion) error {
+	err := f.db.QueryRowxContext(ctx, createFunctionVersion, version.UID, version.ProjectID,
+		version.OwnerType, version.OwnerID, version.Function,
+	).Scan(&version.Version, &version.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFunctionVersionNotCreated
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (f *functionVersionRepo) FindFunctionVersion(ctx context.Context, projectID string, ownerType datastore.FunctionOwnerType, ownerID string, version int) (*datastore.FunctionVersion, error) {
+	v := &datastore.FunctionVersion{}
+	err := f.db.QueryRowxContext(ctx, fetchFunctionVersion, projectID, ownerType, ownerID, version).StructScan(v)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrFunctionVersionNotFound
+		}
+
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (f *functionVersionRepo) LoadFunctionVersions(ctx context.Context, projectID string, ownerType datastore.FunctionOwnerType, ownerID string) ([]datastore.FunctionVersion, error) {
+	versions := make([]datastore.FunctionVersion, 0)
+	err := f.db.SelectContext(ctx, &versions, fetchFunctionVersions, projectID, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
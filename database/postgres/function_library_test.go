@@ -0,0 +1,103 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FunctionLibraries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	ctx := context.Background()
+	project := seedProject(t, db)
+	libraryRepo := NewFunctionLibraryRepo(db, nil)
+	versionRepo := NewFunctionVersionRepo(db, nil)
+
+	library := &datastore.FunctionLibrary{
+		UID:       ulid.Make().String(),
+		ProjectID: project.UID,
+		Name:      "helpers",
+		Function:  `exports.double = function (n) { return n * 2 }`,
+	}
+	require.NoError(t, libraryRepo.CreateFunctionLibrary(ctx, library))
+	require.Equal(t, 1, library.Version)
+
+	// names are unique in a project, the version of the library that
+	// wasn't created is rolled back with it
+	duplicate := *library
+	duplicate.UID = ulid.Make().String()
+	require.Equal(t, datastore.ErrDuplicateFunctionLibraryName, libraryRepo.CreateFunctionLibrary(ctx, &duplicate))
+
+	versions, err := versionRepo.LoadFunctionVersions(ctx, project.UID, datastore.FunctionLibraryOwner, duplicate.UID)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	library.Function = `exports.double = function (n) { return n + n }`
+	require.NoError(t, libraryRepo.UpdateFunctionLibrary(ctx, project.UID, library))
+	require.Equal(t, 2, library.Version)
+
+	// renaming the library doesn't record a version
+	library.Name = "math"
+	require.NoError(t, libraryRepo.UpdateFunctionLibrary(ctx, project.UID, library))
+	require.Equal(t, 2, library.Version)
+
+	versions, err = versionRepo.LoadFunctionVersions(ctx, project.UID, datastore.FunctionLibraryOwner, library.UID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	found, err := libraryRepo.FindFunctionLibraryByID(ctx, project.UID, library.UID)
+	require.NoError(t, err)
+	require.Equal(t, library.Function, found.Function)
+	require.Equal(t, 2, found.Version)
+
+	libraries, err := libraryRepo.LoadFunctionLibraries(ctx, project.UID)
+	require.NoError(t, err)
+	require.Len(t, libraries, 1)
+
+	require.NoError(t, libraryRepo.DeleteFunctionLibrary(ctx, project.UID, library.UID))
+
+	_, err = libraryRepo.FindFunctionLibraryByID(ctx, project.UID, library.UID)
+	require.True(t, errors.Is(err, datastore.ErrFunctionLibraryNotFound))
+}
+
+func Test_FunctionVersions(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	ctx := context.Background()
+	project := seedProject(t, db)
+	versionRepo := NewFunctionVersionRepo(db, nil)
+	ownerID := ulid.Make().String()
+
+	for _, function := range []string{"function transform(p) { return p }", "function transform(p) { return {} }"} {
+		v := &datastore.FunctionVersion{
+			UID:       ulid.Make().String(),
+			ProjectID: project.UID,
+			OwnerType: datastore.SubscriptionFunctionOwner,
+			OwnerID:   ownerID,
+			Function:  function,
+		}
+		require.NoError(t, versionRepo.CreateFunctionVersion(ctx, v))
+	}
+
+	versions, err := versionRepo.LoadFunctionVersions(ctx, project.UID, datastore.SubscriptionFunctionOwner, ownerID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, 2, versions[0].Version)
+
+	v, err := versionRepo.FindFunctionVersion(ctx, project.UID, datastore.SubscriptionFunctionOwner, ownerID, 1)
+	require.NoError(t, err)
+	require.Equal(t, "function transform(p) { return p }", v.Function)
+
+	_, err = versionRepo.FindFunctionVersion(ctx, project.UID, datastore.SubscriptionFunctionOwner, ownerID, 3)
+	require.True(t, errors.Is(err, datastore.ErrFunctionVersionNotFound))
+}
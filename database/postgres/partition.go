@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	eventsPartitionedTable          = "events"
+	eventDeliveriesPartitionedTable = "event_deliveries"
+
+	partitionDay = 24 * time.Hour
+)
+
+// deliveries are removed before the events they reference.
+var partitionedTables = []string{eventDeliveriesPartitionedTable, eventsPartitionedTable}
+
+const (
+	// the bounds are read back from the partition's bound expression, they're
+	// null for MINVALUE and the default partition.
+	fetchPartitions = `
+	SELECT p.relname AS table_name, c.relname AS name,
+	(regexp_match(pg_get_expr(c.relpartbound, c.oid), 'FROM \(''([^'']+)''\)'))[1]::TIMESTAMPTZ AS range_from,
+	(regexp_match(pg_get_expr(c.relpartbound, c.oid), 'TO \(''([^'']+)''\)'))[1]::TIMESTAMPTZ AS range_to,
+	pg_get_expr(c.relpartbound, c.oid) = 'DEFAULT' AS is_default,
+	GREATEST(c.reltuples, 0)::BIGINT AS rows,
+	pg_total_relation_size(c.oid) AS size
+	FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	JOIN pg_class p ON p.oid = i.inhparent
+	JOIN pg_namespace n ON n.oid = p.relnamespace
+	WHERE n.nspname = 'convoy' AND c.relkind = 'r' AND p.relname IN ('events', 'event_deliveries')
+	ORDER BY p.relname, range_to NULLS LAST, c.relname;
+	`
+
+	createPartition = `CREATE TABLE IF NOT EXISTS convoy.%s PARTITION OF convoy.%s FOR VALUES FROM (%s) TO (%s);`
+
+	// a partition can't be created while the default partition has rows
+	// for its range, they're moved to it with the default detached.
+	defaultPartitionHasRows = `SELECT EXISTS (SELECT 1 FROM convoy.%s WHERE created_at >= $1 AND created_at < $2);`
+
+	detachDefaultPartition = `ALTER TABLE convoy.%s DETACH PARTITION convoy.%s;`
+
+	moveDefaultPartitionRows = `
+	WITH moved AS (DELETE FROM convoy.%s WHERE created_at >= $1 AND created_at < $2 RETURNING *)
+	INSERT INTO convoy.%s SELECT * FROM moved;
+	`
+
+	attachDefaultPartition = `ALTER TABLE convoy.%s ATTACH PARTITION convoy.%s DEFAULT;`
+
+	dropPartition = `DROP TABLE IF EXISTS convoy.%s;`
+
+	detachPartition = `ALTER TABLE convoy.%s DETACH PARTITION convoy.%s;`
+
+	// events_endpoints has no foreign key to the partitioned events table,
+	// its rows are removed with their events' partition.
+	deletePartitionEventsEndpoints = `
+	DELETE FROM convoy.events_endpoints WHERE event_id IN (SELECT id FROM convoy.%s);
+	`
+
+	// deliveries created after the day of their event, e.g. fallbacks, are
+	// in later partitions, they're removed with their events' partition.
+	deletePartitionEventDeliveries = `
+	DELETE FROM convoy.event_deliveries WHERE event_id IN (SELECT id FROM convoy.%s);
+	`
+)
+
+type partitionRepo struct {
+	db *sqlx.DB
+}
+
+func NewPartitionRepo(db database.Database) datastore.PartitionRepository {
+	return &partitionRepo{db: db.GetDB()}
+}
+
+func (p *partitionRepo) CreatePartitions(ctx context.Context, until time.Time) ([]string, error) {
+	partitions, err := p.LoadPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	var errs []error
+	today := time.Now().UTC().Truncate(partitionDay)
+
+	for _, table := range partitionedTables {
+		for day := today; day.Before(until); day = day.Add(partitionDay) {
+			if isPartitioned(partitions, table, day) {
+				continue
+			}
+
+			name := partitionName(table, day)
+			if err = p.createPartition(ctx, table, name, day, defaultPartition(partitions, table)); err != nil {
+				// the other days are still created
+				errs = append(errs, fmt.Errorf("failed to create partition %s: %w", name, err))
+				continue
+			}
+
+			created = append(created, name)
+		}
+	}
+
+	return created, errors.Join(errs...)
+}
+
+// createPartition creates the partition of table for day, the rows the
+// default partition has for day are moved to it.
+func (p *partitionRepo) createPartition(ctx context.Context, table, name string, day time.Time, defaultName string) error {
+	from, to := day, day.Add(partitionDay)
+	query := fmt.Sprintf(createPartition, pq.QuoteIdentifier(name), pq.QuoteIdentifier(table),
+		pq.QuoteLiteral(from.Format(time.RFC3339)), pq.QuoteLiteral(to.Format(time.RFC3339)))
+
+	var hasRows bool
+	if len(defaultName) > 0 {
+		err := p.db.GetContext(ctx, &hasRows, fmt.Sprintf(defaultPartitionHasRows, pq.QuoteIdentifier(defaultName)), from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !hasRows {
+		_, err := p.db.ExecContext(ctx, query)
+		return err
+	}
+
+	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	t, d := pq.QuoteIdentifier(table), pq.QuoteIdentifier(defaultName)
+	for _, q := range []string{
+		fmt.Sprintf(detachDefaultPartition, t, d),
+		query,
+	} {
+		if _, err = tx.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(moveDefaultPartitionRows, d, t), from, to); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(attachDefaultPartition, t, d)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *partitionRepo) LoadPartitions(ctx context.Context) ([]datastore.Partition, error) {
+	partitions := make([]datastore.Partition, 0)
+	err := p.db.SelectContext(ctx, &partitions, fetchPartitions)
+	if err != nil {
+		return nil, err
+	}
+
+	return partitions, nil
+}
+
+func (p *partitionRepo) RemovePartitions(ctx context.Context, expiry time.Time, detach bool) ([]string, error) {
+	partitions, err := p.LoadPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, table := range partitionedTables {
+		for _, partition := range partitions {
+			if partition.Table != table || partition.IsDefault || partition.To == nil || partition.To.After(expiry) {
+				continue
+			}
+
+			if err = p.removePartition(ctx, partition, detach); err != nil {
+				return removed, fmt.Errorf("failed to remove partition %s: %w", partition.Name, err)
+			}
+
+			removed = append(removed, partition.Name)
+		}
+	}
+
+	return removed, nil
+}
+
+func (p *partitionRepo) removePartition(ctx context.Context, partition datastore.Partition, detach bool) error {
+	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	name := pq.QuoteIdentifier(partition.Name)
+	if partition.Table == eventsPartitionedTable {
+		for _, q := range []string{deletePartitionEventsEndpoints, deletePartitionEventDeliveries} {
+			if _, err = tx.ExecContext(ctx, fmt.Sprintf(q, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	query := fmt.Sprintf(dropPartition, name)
+	if detach {
+		query = fmt.Sprintf(detachPartition, pq.QuoteIdentifier(partition.Table), name)
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isPartitioned reports whether the rows of table created on day already
+// have a partition other than the default one.
+func isPartitioned(partitions []datastore.Partition, table string, day time.Time) bool {
+	for _, p := range partitions {
+		if p.Table != table || p.IsDefault || p.To == nil {
+			continue
+		}
+
+		if (p.From == nil || !day.Before(*p.From)) && day.Before(*p.To) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultPartition returns the name of table's default partition, it's
+// empty when table has none.
+func defaultPartition(partitions []datastore.Partition, table string) string {
+	for _, p := range partitions {
+		if p.Table == table && p.IsDefault {
+			return p.Name
+		}
+	}
+
+	return ""
+}
+
+func partitionName(table string, day time.Time) string {
+	return fmt.Sprintf("%s_p%s", table, day.Format("20060102"))
+}
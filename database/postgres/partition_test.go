@@ -0,0 +1,114 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreatePartitions(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	repo := NewPartitionRepo(db)
+	ctx := context.Background()
+
+	today := time.Now().UTC().Truncate(partitionDay)
+	until := today.AddDate(0, 0, 10)
+
+	_, err := repo.CreatePartitions(ctx, until)
+	require.NoError(t, err)
+
+	partitions, err := repo.LoadPartitions(ctx)
+	require.NoError(t, err)
+
+	for _, table := range partitionedTables {
+		for day := today; day.Before(until); day = day.Add(partitionDay) {
+			require.True(t, isPartitioned(partitions, table, day), "%s has no partition for %s", table, day)
+		}
+	}
+
+	// the partitions that exist are left as they are
+	created, err := repo.CreatePartitions(ctx, until)
+	require.NoError(t, err)
+	require.Empty(t, created)
+}
+
+func Test_CreatePartitions_MovesDefaultRows(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	repo := NewPartitionRepo(db)
+	ctx := context.Background()
+
+	// no partition covers the day yet, so the event lands in the default one
+	day := time.Now().UTC().Truncate(partitionDay).AddDate(0, 0, 30)
+	event := generateEvent(t, db)
+	require.NoError(t, NewEventRepo(db, nil).CreateEvent(ctx, event))
+
+	_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.events SET created_at = $1 WHERE id = $2`, day.Add(time.Hour), event.UID)
+	require.NoError(t, err)
+
+	created, err := repo.CreatePartitions(ctx, day.Add(partitionDay))
+	require.NoError(t, err)
+	require.Contains(t, created, partitionName(eventsPartitionedTable, day))
+
+	var count int
+	err = db.GetDB().GetContext(ctx, &count, `SELECT COUNT(*) FROM convoy.`+partitionName(eventsPartitionedTable, day)+` WHERE id = $1`, event.UID)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func Test_LoadPartitions(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	repo := NewPartitionRepo(db)
+	ctx := context.Background()
+
+	day := time.Now().UTC().Truncate(partitionDay).AddDate(0, 0, 2)
+	_, err := repo.CreatePartitions(ctx, day.Add(partitionDay))
+	require.NoError(t, err)
+
+	partitions, err := repo.LoadPartitions(ctx)
+	require.NoError(t, err)
+
+	var hasDefault, hasDay bool
+	for _, p := range partitions {
+		if p.Table != eventsPartitionedTable {
+			continue
+		}
+
+		if p.IsDefault {
+			hasDefault = true
+			require.Nil(t, p.From)
+			require.Nil(t, p.To)
+		}
+
+		if p.From != nil && p.From.Equal(day) {
+			hasDay = true
+			require.True(t, p.To.Equal(day.Add(partitionDay)))
+		}
+	}
+
+	require.True(t, hasDefault)
+	require.True(t, hasDay)
+}
+
+func Test_RemovePartitions(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	repo := NewPartitionRepo(db)
+	ctx := context.Background()
+
+	// no partition only holds rows created before then
+	removed, err := repo.RemovePartitions(ctx, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), false)
+	require.NoError(t, err)
+	require.Empty(t, removed)
+}
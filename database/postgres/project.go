@@ -43,12 +43,16 @@ const (
 		strategy_duration, strategy_retry_count,
 		signature_header, signature_versions, disable_endpoint,
 		meta_events_enabled, meta_events_type, meta_events_event_type,
-		meta_events_url, meta_events_secret, meta_events_pub_sub,ssl_enforce_secure_endpoints, multiple_endpoint_subscriptions
+		meta_events_url, meta_events_secret, meta_events_pub_sub,ssl_enforce_secure_endpoints, multiple_endpoint_subscriptions,
+		ingest_ratelimit_count, ingest_ratelimit_burst, ingest_ratelimit_duration,
+		ingest_quota_count, ingest_quota_period, validate_event_types, redaction,
+		encrypt_payloads
 	  )
 	  VALUES
 		(
 		  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
-		  $14, $15, $16, $17, $18, $19, $20
+		  $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27,
+		  $28
 		);
 	`
 
@@ -73,6 +77,14 @@ const (
 		search_policy = $18,
 		ssl_enforce_secure_endpoints = $19,
 		multiple_endpoint_subscriptions = $20,
+		ingest_ratelimit_count = $21,
+		ingest_ratelimit_burst = $22,
+		ingest_ratelimit_duration = $23,
+		ingest_quota_count = $24,
+		ingest_quota_period = $25,
+		validate_event_types = $26,
+		redaction = $27,
+		encrypt_payloads = $28,
 		updated_at = NOW()
 	WHERE id = $1 AND deleted_at IS NULL;
 	`
@@ -104,6 +116,14 @@ const (
 		COALESCE(c.meta_events_url, '') AS "config.meta_event.url",
 		COALESCE(c.meta_events_secret, '') AS "config.meta_event.secret",
 		c.meta_events_pub_sub AS "config.meta_event.pub_sub",
+		c.ingest_ratelimit_count AS "config.ingest_ratelimit.count",
+		c.ingest_ratelimit_burst AS "config.ingest_ratelimit.burst",
+		c.ingest_ratelimit_duration AS "config.ingest_ratelimit.duration",
+		c.ingest_quota_count AS "config.ingest_quota.count",
+		c.ingest_quota_period AS "config.ingest_quota.period",
+		c.validate_event_types AS "config.validate_event_types",
+		c.redaction AS "config.redaction",
+		c.encrypt_payloads AS "config.encrypt_payloads",
 		p.created_at,
 		p.updated_at,
 		p.deleted_at
@@ -140,6 +160,14 @@ const (
 	COALESCE(c.meta_events_url, '') AS "config.meta_event.url",
 	COALESCE(c.meta_events_secret, '') AS "config.meta_event.secret",
 	c.meta_events_pub_sub AS "config.meta_event.pub_sub",
+	c.ingest_ratelimit_count AS "config.ingest_ratelimit.count",
+	c.ingest_ratelimit_burst AS "config.ingest_ratelimit.burst",
+	c.ingest_ratelimit_duration AS "config.ingest_ratelimit.duration",
+	c.ingest_quota_count AS "config.ingest_quota.count",
+	c.ingest_quota_period AS "config.ingest_quota.period",
+	c.validate_event_types AS "config.validate_event_types",
+	c.redaction AS "config.redaction",
+	c.encrypt_payloads AS "config.encrypt_payloads",
 	p.created_at,
 	p.updated_at,
 	p.deleted_at
@@ -236,6 +264,8 @@ func (p *projectRepo) CreateProject(ctx context.Context, project *datastore.Proj
 	sc := project.Config.GetStrategyConfig()
 	sgc := project.Config.GetSignatureConfig()
 	me := project.Config.GetMetaEventConfig()
+	irl := project.Config.GetIngestRateLimitConfig()
+	iq := project.Config.GetIngestQuotaConfig()
 
 	configID := ulid.Make().String()
 	result, err := tx.ExecContext(ctx, createProjectConfiguration,
@@ -259,6 +289,14 @@ func (p *projectRepo) CreateProject(ctx context.Context, project *datastore.Proj
 		me.PubSub,
 		project.Config.SSL.EnforceSecureEndpoints,
 		project.Config.MultipleEndpointSubscriptions,
+		irl.Count,
+		irl.Burst,
+		irl.Duration,
+		iq.Count,
+		iq.Period,
+		project.Config.ValidateEventTypes,
+		project.Config.Redaction,
+		project.Config.EncryptPayloads,
 	)
 	if err != nil {
 		return err
@@ -358,6 +396,8 @@ func (p *projectRepo) UpdateProject(ctx context.Context, project *datastore.Proj
 	sgc := project.Config.GetSignatureConfig()
 	ssl := project.Config.GetSSLConfig()
 	me := project.Config.GetMetaEventConfig()
+	irl := project.Config.GetIngestRateLimitConfig()
+	iq := project.Config.GetIngestQuotaConfig()
 
 	cRes, err := tx.ExecContext(ctx, updateProjectConfiguration,
 		project.ProjectConfigID,
@@ -380,6 +420,14 @@ func (p *projectRepo) UpdateProject(ctx context.Context, project *datastore.Proj
 		project.Config.SearchPolicy,
 		ssl.EnforceSecureEndpoints,
 		project.Config.MultipleEndpointSubscriptions,
+		irl.Count,
+		irl.Burst,
+		irl.Duration,
+		iq.Count,
+		iq.Period,
+		project.Config.ValidateEventTypes,
+		project.Config.Redaction,
+		project.Config.EncryptPayloads,
 	)
 	if err != nil {
 		return fmt.Errorf("update project config err: %v", err)
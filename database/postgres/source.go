@@ -23,17 +23,20 @@ import (
 const (
 	createSource = `
     INSERT INTO convoy.sources (id,source_verifier_id,name,type,mask_id,provider,is_disabled,forward_headers,project_id,
-                                pub_sub,custom_response_body,custom_response_content_type,idempotency_keys, body_function, header_function)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15);
+                                pub_sub,custom_response_body,custom_response_content_type,idempotency_keys, body_function, header_function,
+                                ip_allowlist, client_ca_cert, event_type_config, sync_forwarding,
+                                ingest_rate_limit, dedup_window)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21);
     `
 
 	createSourceVerifier = `
     INSERT INTO convoy.source_verifiers (
         id,type,basic_username,basic_password,
         api_key_header_name,api_key_header_value,
-        hmac_hash,hmac_header,hmac_secret,hmac_encoding
+        hmac_hash,hmac_header,hmac_secret,hmac_encoding,hmac_tolerance,
+        jwt_algorithm,jwt_key,jwt_jwks_url,jwt_issuer,jwt_audience,jwt_body_hash_claim
     )
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10);
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$17,$11,$12,$13,$14,$15,$16);
     `
 
 	updateSourceById = `
@@ -51,6 +54,12 @@ const (
 	idempotency_keys = $12,
 	body_function = $13,
 	header_function = $14,
+	ip_allowlist = $15,
+	client_ca_cert = $16,
+	event_type_config = $17,
+	sync_forwarding = $18,
+	ingest_rate_limit = $19,
+	dedup_window = $20,
 	updated_at = NOW()
 	WHERE id = $1 AND deleted_at IS NULL ;
 	`
@@ -66,6 +75,13 @@ const (
         hmac_header=$8,
         hmac_secret=$9,
         hmac_encoding=$10,
+        jwt_algorithm=$11,
+        jwt_key=$12,
+        jwt_jwks_url=$13,
+        jwt_issuer=$14,
+        jwt_audience=$15,
+        jwt_body_hash_claim=$16,
+        hmac_tolerance=$17,
 		updated_at = NOW()
 	WHERE id = $1 AND deleted_at IS NULL;
 	`
@@ -84,6 +100,12 @@ const (
 		s.project_id,
 		s.body_function,
 		s.header_function,
+		s.ip_allowlist,
+		COALESCE(s.client_ca_cert, '') AS client_ca_cert,
+		s.event_type_config,
+		s.sync_forwarding,
+		s.ingest_rate_limit,
+		s.dedup_window,
 		COALESCE(s.source_verifier_id, '') AS source_verifier_id,
 		COALESCE(s.custom_response_body, '') AS "custom_response.body",
 		COALESCE(s.custom_response_content_type, '') AS "custom_response.content_type",
@@ -96,6 +118,13 @@ const (
         COALESCE(sv.hmac_header, '') AS "verifier.hmac.header",
         COALESCE(sv.hmac_secret, '') AS "verifier.hmac.secret",
         COALESCE(sv.hmac_encoding, '') AS "verifier.hmac.encoding",
+        COALESCE(sv.hmac_tolerance, 0) AS "verifier.hmac.tolerance",
+        COALESCE(sv.jwt_algorithm, '') AS "verifier.jwt.algorithm",
+        COALESCE(sv.jwt_key, '') AS "verifier.jwt.key",
+        COALESCE(sv.jwt_jwks_url, '') AS "verifier.jwt.jwks_url",
+        COALESCE(sv.jwt_issuer, '') AS "verifier.jwt.issuer",
+        COALESCE(sv.jwt_audience, '') AS "verifier.jwt.audience",
+        COALESCE(sv.jwt_body_hash_claim, '') AS "verifier.jwt.body_hash_claim",
 		s.created_at,
 		s.updated_at
 	FROM convoy.sources AS s
@@ -217,6 +246,7 @@ func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source)
 		hmac   datastore.HMac
 		basic  datastore.BasicAuth
 		apiKey datastore.ApiKey
+		jwt    datastore.JWT
 	)
 
 	switch source.Verifier.Type {
@@ -226,6 +256,8 @@ func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source)
 		basic = *source.Verifier.BasicAuth
 	case datastore.HMacVerifier:
 		hmac = *source.Verifier.HMac
+	case datastore.JWTVerifier:
+		jwt = *source.Verifier.JWT
 	}
 
 	if !util.IsStringEmpty(string(source.Verifier.Type)) {
@@ -235,6 +267,7 @@ func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source)
 		result2, err := tx.ExecContext(
 			ctx, createSourceVerifier, sourceVerifierID, source.Verifier.Type, basic.UserName, basic.Password,
 			apiKey.HeaderName, apiKey.HeaderValue, hmac.Hash, hmac.Header, hmac.Secret, hmac.Encoding,
+			jwt.Algorithm, jwt.Key, jwt.JwksURL, jwt.Issuer, jwt.Audience, jwt.BodyHashClaim, hmac.Tolerance,
 		)
 		if err != nil {
 			return err
@@ -259,6 +292,8 @@ func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source)
 		source.Provider, source.IsDisabled, pq.Array(source.ForwardHeaders), source.ProjectID,
 		source.PubSub, source.CustomResponse.Body, source.CustomResponse.ContentType,
 		source.IdempotencyKeys, source.BodyFunction, source.HeaderFunction,
+		source.IPAllowlist, source.ClientCACert, source.EventTypeConfig, source.SyncForwarding,
+		source.IngestRateLimit, source.DedupWindow,
 	)
 	if err != nil {
 		return err
@@ -299,6 +334,8 @@ func (s *sourceRepo) UpdateSource(ctx context.Context, projectID string, source
 		source.Provider, source.IsDisabled, source.ForwardHeaders, projectID,
 		source.PubSub, source.CustomResponse.Body, source.CustomResponse.ContentType,
 		source.IdempotencyKeys, source.BodyFunction, source.HeaderFunction,
+		source.IPAllowlist, source.ClientCACert, source.EventTypeConfig, source.SyncForwarding,
+		source.IngestRateLimit, source.DedupWindow,
 	)
 	if err != nil {
 		return err
@@ -316,6 +353,7 @@ func (s *sourceRepo) UpdateSource(ctx context.Context, projectID string, source
 		hmac   datastore.HMac
 		basic  datastore.BasicAuth
 		apiKey datastore.ApiKey
+		jwt    datastore.JWT
 	)
 
 	switch source.Verifier.Type {
@@ -325,12 +363,15 @@ func (s *sourceRepo) UpdateSource(ctx context.Context, projectID string, source
 		basic = *source.Verifier.BasicAuth
 	case datastore.HMacVerifier:
 		hmac = *source.Verifier.HMac
+	case datastore.JWTVerifier:
+		jwt = *source.Verifier.JWT
 	}
 
 	if !util.IsStringEmpty(string(source.Verifier.Type)) {
 		result2, err := tx.ExecContext(
 			ctx, updateSourceVerifierById, source.VerifierID, source.Verifier.Type, basic.UserName, basic.Password,
 			apiKey.HeaderName, apiKey.HeaderValue, hmac.Hash, hmac.Header, hmac.Secret, hmac.Encoding,
+			jwt.Algorithm, jwt.Key, jwt.JwksURL, jwt.Issuer, jwt.Audience, jwt.BodyHashClaim, hmac.Tolerance,
 		)
 		if err != nil {
 			return err
@@ -30,9 +30,13 @@ const (
 	retry_config_retry_count,filter_config_event_types,
 	filter_config_filter_headers,filter_config_filter_body,
     filter_config_filter_is_flattened,
-	rate_limit_config_count,rate_limit_config_duration,function
+	rate_limit_config_count,rate_limit_config_duration,function,
+	filter_config_filter_metadata,filter_config_filter_expression,
+	function_version,event_type_versions,
+	debounce_config_key_path,debounce_config_window,debounce_config_mode,
+	fallback_config,filter_config_filter_labels,function_libraries
 	)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19);
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29);
     `
 
 	updateSubscription = `
@@ -52,6 +56,16 @@ const (
 	rate_limit_config_count=$15,
 	rate_limit_config_duration=$16,
 	function=$17,
+	filter_config_filter_metadata=$18,
+	filter_config_filter_expression=$19,
+	function_version=$20,
+	event_type_versions=$21,
+	debounce_config_key_path=$22,
+	debounce_config_window=$23,
+	debounce_config_mode=$24,
+	fallback_config=$25,
+	filter_config_filter_labels=$26,
+	function_libraries=$27,
     updated_at=now()
     WHERE id = $1 AND project_id = $2
 	AND deleted_at IS NULL;
@@ -62,7 +76,8 @@ const (
     s.id,s.name,s.type,
 	s.project_id,
 	s.created_at,
-	s.updated_at, s.function,
+	s.updated_at, s.function, s.function_version, s.event_type_versions, s.function_libraries,
+	s.fallback_config,
 
 	COALESCE(s.endpoint_id,'') AS "endpoint_id",
 	COALESCE(s.device_id,'') AS "device_id",
@@ -77,8 +92,14 @@ const (
 	s.filter_config_filter_headers AS "filter_config.filter.headers",
 	s.filter_config_filter_body AS "filter_config.filter.body",
 	s.filter_config_filter_is_flattened AS "filter_config.filter.is_flattened",
+	s.filter_config_filter_metadata AS "filter_config.filter.metadata",
+	s.filter_config_filter_expression AS "filter_config.filter.expression",
+	s.filter_config_filter_labels AS "filter_config.filter.labels",
 	s.rate_limit_config_count AS "rate_limit_config.count",
 	s.rate_limit_config_duration AS "rate_limit_config.duration",
+	s.debounce_config_key_path AS "debounce_config.key_path",
+	s.debounce_config_window AS "debounce_config.window",
+	s.debounce_config_mode AS "debounce_config.mode",
 
 	COALESCE(em.secrets,'[]') AS "endpoint_metadata.secrets",
 	COALESCE(em.id,'') AS "endpoint_metadata.id",
@@ -118,11 +139,17 @@ const (
 	WHERE s.deleted_at IS NULL `
 
 	fetchSubscriptionsForBroadcast = `
-    select id, type, project_id, endpoint_id, function,
+    select id, type, project_id, endpoint_id, function, function_version, function_libraries, event_type_versions,
     filter_config_event_types AS "filter_config.event_types",
     filter_config_filter_headers AS "filter_config.filter.headers",
 	filter_config_filter_body AS "filter_config.filter.body",
-	filter_config_filter_is_flattened AS "filter_config.filter.is_flattened"
+	filter_config_filter_is_flattened AS "filter_config.filter.is_flattened",
+	filter_config_filter_metadata AS "filter_config.filter.metadata",
+	filter_config_filter_expression AS "filter_config.filter.expression",
+	filter_config_filter_labels AS "filter_config.filter.labels",
+	debounce_config_key_path AS "debounce_config.key_path",
+	debounce_config_window AS "debounce_config.window",
+	debounce_config_mode AS "debounce_config.mode"
     from convoy.subscriptions
     where (ARRAY[$4] <@ filter_config_event_types OR ARRAY['*'] <@ filter_config_event_types)
     AND id > $1
@@ -131,11 +158,18 @@ const (
     ORDER BY id LIMIT $3`
 
 	loadAllSubscriptionsConfiguration = `
-    select name, id, type, project_id, endpoint_id, function, updated_at,
+    select name, id, type, project_id, endpoint_id, function, function_version, function_libraries, event_type_versions, updated_at,
+    COALESCE(source_id,'') AS "source_id",
     filter_config_event_types AS "filter_config.event_types",
     filter_config_filter_headers AS "filter_config.filter.headers",
 	filter_config_filter_body AS "filter_config.filter.body",
-	filter_config_filter_is_flattened AS "filter_config.filter.is_flattened"
+	filter_config_filter_is_flattened AS "filter_config.filter.is_flattened",
+	filter_config_filter_metadata AS "filter_config.filter.metadata",
+	filter_config_filter_expression AS "filter_config.filter.expression",
+	filter_config_filter_labels AS "filter_config.filter.labels",
+	debounce_config_key_path AS "debounce_config.key_path",
+	debounce_config_window AS "debounce_config.window",
+	debounce_config_mode AS "debounce_config.mode"
     from convoy.subscriptions
     where id > ?
     AND project_id IN (?)
@@ -143,11 +177,17 @@ const (
     ORDER BY id LIMIT ?`
 
 	fetchUpdatedSubscriptions = `
-    select name, id, type, project_id, endpoint_id, function, updated_at,
+    select name, id, type, project_id, endpoint_id, function, function_version, function_libraries, event_type_versions, updated_at,
     filter_config_event_types AS "filter_config.event_types",
     filter_config_filter_headers AS "filter_config.filter.headers",
 	filter_config_filter_body AS "filter_config.filter.body",
-	filter_config_filter_is_flattened AS "filter_config.filter.is_flattened"
+	filter_config_filter_is_flattened AS "filter_config.filter.is_flattened",
+	filter_config_filter_metadata AS "filter_config.filter.metadata",
+	filter_config_filter_expression AS "filter_config.filter.expression",
+	filter_config_filter_labels AS "filter_config.filter.labels",
+	debounce_config_key_path AS "debounce_config.key_path",
+	debounce_config_window AS "debounce_config.window",
+	debounce_config_mode AS "debounce_config.mode"
     from convoy.subscriptions
     where updated_at > ?
     AND id > ?
@@ -451,6 +491,7 @@ func (s *subscriptionRepo) CreateSubscription(ctx context.Context, projectID str
 	rc := subscription.GetRetryConfig()
 	fc := subscription.GetFilterConfig()
 	rlc := subscription.GetRateLimitConfig()
+	dc := subscription.GetDebounceConfig()
 
 	var endpointID, sourceID, deviceID *string
 	if !util.IsStringEmpty(subscription.EndpointID) {
@@ -475,6 +516,11 @@ func (s *subscriptionRepo) CreateSubscription(ctx context.Context, projectID str
 		return fmt.Errorf("failed to flatten header filter: %v", err)
 	}
 
+	err = fc.Filter.Metadata.Flatten()
+	if err != nil {
+		return fmt.Errorf("failed to flatten metadata filter: %v", err)
+	}
+
 	fc.Filter.IsFlattened = true // this is just a flag so we can identify old records
 
 	result, err := s.db.ExecContext(
@@ -483,7 +529,10 @@ func (s *subscriptionRepo) CreateSubscription(ctx context.Context, projectID str
 		endpointID, deviceID, sourceID,
 		ac.Count, ac.Threshold, rc.Type, rc.Duration, rc.RetryCount,
 		fc.EventTypes, fc.Filter.Headers, fc.Filter.Body, fc.Filter.IsFlattened,
-		rlc.Count, rlc.Duration, subscription.Function,
+		rlc.Count, rlc.Duration, subscription.Function, fc.Filter.Metadata,
+		fc.Filter.Expression, subscription.FunctionVersion, subscription.EventTypeVersions,
+		dc.KeyPath, dc.Window, dc.Mode, subscription.FallbackConfig, fc.Filter.Labels,
+		subscription.FunctionLibraries,
 	)
 	if err != nil {
 		return err
@@ -524,6 +573,7 @@ func (s *subscriptionRepo) UpdateSubscription(ctx context.Context, projectID str
 	rc := subscription.GetRetryConfig()
 	fc := subscription.GetFilterConfig()
 	rlc := subscription.GetRateLimitConfig()
+	dc := subscription.GetDebounceConfig()
 
 	var sourceID *string
 	if !util.IsStringEmpty(subscription.SourceID) {
@@ -540,6 +590,11 @@ func (s *subscriptionRepo) UpdateSubscription(ctx context.Context, projectID str
 		return fmt.Errorf("failed to flatten header filter: %v", err)
 	}
 
+	err = fc.Filter.Metadata.Flatten()
+	if err != nil {
+		return fmt.Errorf("failed to flatten metadata filter: %v", err)
+	}
+
 	fc.Filter.IsFlattened = true // this is just a flag so we can identify old records
 
 	result, err := s.db.ExecContext(
@@ -547,7 +602,10 @@ func (s *subscriptionRepo) UpdateSubscription(ctx context.Context, projectID str
 		subscription.Name, subscription.EndpointID, sourceID,
 		ac.Count, ac.Threshold, rc.Type, rc.Duration, rc.RetryCount,
 		fc.EventTypes, fc.Filter.Headers, fc.Filter.Body, fc.Filter.IsFlattened,
-		rlc.Count, rlc.Duration, subscription.Function,
+		rlc.Count, rlc.Duration, subscription.Function, fc.Filter.Metadata,
+		fc.Filter.Expression, subscription.FunctionVersion, subscription.EventTypeVersions,
+		dc.KeyPath, dc.Window, dc.Mode, subscription.FallbackConfig, fc.Filter.Labels,
+		subscription.FunctionLibraries,
 	)
 	if err != nil {
 		return err
@@ -869,6 +927,7 @@ var (
 	emptyAlertConfig     = datastore.AlertConfiguration{}
 	emptyRetryConfig     = datastore.RetryConfiguration{}
 	emptyRateLimitConfig = datastore.RateLimitConfiguration{}
+	emptyDebounceConfig  = datastore.DebounceConfiguration{}
 )
 
 func nullifyEmptyConfig(sub *datastore.Subscription) {
@@ -883,6 +942,10 @@ func nullifyEmptyConfig(sub *datastore.Subscription) {
 	if sub.RateLimitConfig != nil && *sub.RateLimitConfig == emptyRateLimitConfig {
 		sub.RateLimitConfig = nil
 	}
+
+	if sub.DebounceConfig != nil && *sub.DebounceConfig == emptyDebounceConfig {
+		sub.DebounceConfig = nil
+	}
 }
 
 func scanSubscriptions(rows *sqlx.Rows) ([]datastore.Subscription, error) {
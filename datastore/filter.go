@@ -1,7 +1,10 @@
 package datastore
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +23,7 @@ type Filter struct {
 	IdempotencyKey string
 	Status         []EventDeliveryStatus
 	SearchParams   SearchParams
+	Labels         Labels
 }
 
 type SourceFilter struct {
@@ -72,3 +76,100 @@ type SearchFilter struct {
 	FilterBy FilterBy
 	Pageable Pageable
 }
+
+var ErrInvalidPayloadFilter = errors.New("payload filters must be in the form path=value e.g. order.id=123")
+
+// PayloadFilter matches the events and deliveries whose payload has Value
+// at Path. A * in the path matches every key, a number is an array index and
+// arrays on the path are searched element by element. Quote a segment to
+// match a key literally e.g. "0" or "*".
+type PayloadFilter struct {
+	Path  []string `json:"path"`
+	Value string   `json:"value"`
+}
+
+// ParsePayloadFilter parses a filter in the form path=value, the path is
+// dot separated and may start with $.
+func ParsePayloadFilter(s string) (PayloadFilter, error) {
+	path, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return PayloadFilter{}, ErrInvalidPayloadFilter
+	}
+
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.TrimPrefix(path, ".")
+	if len(path) == 0 {
+		return PayloadFilter{}, ErrInvalidPayloadFilter
+	}
+
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if len(segment) == 0 {
+			return PayloadFilter{}, ErrInvalidPayloadFilter
+		}
+	}
+
+	return PayloadFilter{Path: segments, Value: value}, nil
+}
+
+// JSONPath returns the filter as an SQL/JSON path predicate. Numbers,
+// booleans and null also match their string form since payloads don't
+// always agree on the type of ids, a quoted value only matches a string.
+func (p PayloadFilter) JSONPath() string {
+	var b strings.Builder
+	b.WriteString("$")
+
+	for _, segment := range p.Path {
+		if key, ok := quotedKey(segment); ok {
+			b.WriteString("." + jsonPathString(key))
+			continue
+		}
+
+		if segment == "*" {
+			b.WriteString(".*")
+			continue
+		}
+
+		if _, err := strconv.ParseUint(segment, 10, 32); err == nil {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+
+		b.WriteString("." + jsonPathString(segment))
+	}
+
+	path := b.String()
+	if v := strings.TrimSpace(p.Value); isJSONScalar(v) {
+		if strings.HasPrefix(v, `"`) {
+			return path + " == " + v
+		}
+
+		return path + " == " + v + " || " + path + " == " + jsonPathString(v)
+	}
+
+	return path + " == " + jsonPathString(p.Value)
+}
+
+// quotedKey returns the key of a segment wrapped in double quotes.
+func quotedKey(segment string) (string, bool) {
+	if len(segment) < 2 || segment[0] != '"' || segment[len(segment)-1] != '"' {
+		return "", false
+	}
+
+	return segment[1 : len(segment)-1], true
+}
+
+func isJSONScalar(s string) bool {
+	if len(s) == 0 || s[0] == '{' || s[0] == '[' {
+		return false
+	}
+
+	return json.Valid([]byte(s))
+}
+
+// jsonPathString quotes s as a path string literal, their escapes are
+// JSON's.
+func jsonPathString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -46,3 +46,68 @@ func Test_FilterBy(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParsePayloadFilter(t *testing.T) {
+	f, err := ParsePayloadFilter("$.order.id=123")
+	require.NoError(t, err)
+	require.Equal(t, PayloadFilter{Path: []string{"order", "id"}, Value: "123"}, f)
+
+	f, err = ParsePayloadFilter("customer.email=a=b")
+	require.NoError(t, err)
+	require.Equal(t, PayloadFilter{Path: []string{"customer", "email"}, Value: "a=b"}, f)
+
+	for _, s := range []string{"order.id", "=1", "$.=1", "order..id=1"} {
+		_, err = ParsePayloadFilter(s)
+		require.ErrorIs(t, err, ErrInvalidPayloadFilter, s)
+	}
+}
+
+func Test_PayloadFilter_JSONPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter PayloadFilter
+		want   string
+	}{
+		{
+			name:   "string",
+			filter: PayloadFilter{Path: []string{"order", "status"}, Value: "paid"},
+			want:   `$."order"."status" == "paid"`,
+		},
+		{
+			name:   "number_matches_its_string_form",
+			filter: PayloadFilter{Path: []string{"order", "id"}, Value: "123"},
+			want:   `$."order"."id" == 123 || $."order"."id" == "123"`,
+		},
+		{
+			name:   "quoted_string",
+			filter: PayloadFilter{Path: []string{"order", "id"}, Value: `"123"`},
+			want:   `$."order"."id" == "123"`,
+		},
+		{
+			name:   "boolean",
+			filter: PayloadFilter{Path: []string{"paid"}, Value: "true"},
+			want:   `$."paid" == true || $."paid" == "true"`,
+		},
+		{
+			name:   "index_and_wildcard",
+			filter: PayloadFilter{Path: []string{"items", "0", "*"}, Value: "a"},
+			want:   `$."items"[0].* == "a"`,
+		},
+		{
+			name:   "quoted_keys",
+			filter: PayloadFilter{Path: []string{"codes", `"0"`, `"*"`}, Value: "a"},
+			want:   `$."codes"."0"."*" == "a"`,
+		},
+		{
+			name:   "escaped",
+			filter: PayloadFilter{Path: []string{`a"b`}, Value: `x" || true`},
+			want:   `$."a\"b" == "x\" || true"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.filter.JSONPath())
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -138,8 +139,9 @@ func IsValidPeriod(period string) bool {
 }
 
 type SearchParams struct {
-	CreatedAtStart int64 `json:"created_at_start" bson:"created_at_start"`
-	CreatedAtEnd   int64 `json:"created_at_end" bson:"created_at_end"`
+	CreatedAtStart int64           `json:"created_at_start" bson:"created_at_start"`
+	CreatedAtEnd   int64           `json:"created_at_end" bson:"created_at_end"`
+	PayloadFilters []PayloadFilter `json:"payload_filters,omitempty" bson:"payload_filters,omitempty"`
 }
 
 type (
@@ -148,6 +150,7 @@ type (
 	SourceType       string
 	SourceProvider   string
 	VerifierType     string
+	EventTypeSource  string
 	EncodingType     string
 	StorageType      string
 	KeyType          string
@@ -164,6 +167,7 @@ const (
 	RestApiSource  SourceType = "rest_api"
 	PubSubSource   SourceType = "pub_sub"
 	DBChangeStream SourceType = "db_change_stream"
+	EmailSource    SourceType = "email"
 )
 
 const (
@@ -185,6 +189,10 @@ const (
 	GithubSourceProvider  SourceProvider = "github"
 	TwitterSourceProvider SourceProvider = "twitter"
 	ShopifySourceProvider SourceProvider = "shopify"
+	StripeSourceProvider  SourceProvider = "stripe"
+	SlackSourceProvider   SourceProvider = "slack"
+	GitlabSourceProvider  SourceProvider = "gitlab"
+	TwilioSourceProvider  SourceProvider = "twilio"
 )
 
 const (
@@ -200,7 +208,8 @@ const (
 
 func (s SourceProvider) IsValid() bool {
 	switch s {
-	case GithubSourceProvider, TwitterSourceProvider, ShopifySourceProvider:
+	case GithubSourceProvider, TwitterSourceProvider, ShopifySourceProvider, StripeSourceProvider,
+		SlackSourceProvider, GitlabSourceProvider, TwilioSourceProvider:
 		return true
 	}
 	return false
@@ -208,7 +217,7 @@ func (s SourceProvider) IsValid() bool {
 
 func (s SourceType) IsValid() bool {
 	switch s {
-	case HTTPSource, RestApiSource, PubSubSource, DBChangeStream:
+	case HTTPSource, RestApiSource, PubSubSource, DBChangeStream, EmailSource:
 		return true
 	}
 	return false
@@ -219,6 +228,14 @@ const (
 	HMacVerifier      VerifierType = "hmac"
 	BasicAuthVerifier VerifierType = "basic_auth"
 	APIKeyVerifier    VerifierType = "api_key"
+	JWTVerifier       VerifierType = "jwt"
+)
+
+const (
+	HeaderEventTypeSource   EventTypeSource = "header"
+	BodyEventTypeSource     EventTypeSource = "body"
+	FunctionEventTypeSource EventTypeSource = "function"
+	ProviderEventTypeSource EventTypeSource = "provider"
 )
 
 const (
@@ -409,6 +426,14 @@ type Endpoint struct {
 	RateLimit         int    `json:"rate_limit" db:"rate_limit"`
 	RateLimitDuration uint64 `json:"rate_limit_duration" db:"rate_limit_duration"`
 
+	// EventTypeVersions pins the version of each event type the endpoint
+	// receives, its subscriptions' pins take precedence.
+	EventTypeVersions EventTypeVersions `json:"event_type_versions,omitempty" db:"event_type_versions"`
+
+	// Labels tag the endpoint e.g. region=eu, they're used to fan out
+	// events and in subscription filters.
+	Labels Labels `json:"labels,omitempty" db:"labels"`
+
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
 	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
@@ -534,6 +559,24 @@ type ProjectConfig struct {
 	Strategy                      *StrategyConfiguration  `json:"strategy" db:"strategy"`
 	Signature                     *SignatureConfiguration `json:"signature" db:"signature"`
 	MetaEvent                     *MetaEventConfiguration `json:"meta_event" db:"meta_event"`
+
+	// IngestRateLimit limits the events accepted by all the project's sources.
+	IngestRateLimit *IngestRateLimitConfiguration `json:"ingest_ratelimit" db:"ingest_ratelimit"`
+
+	// IngestQuota caps the events the project's sources accept in a day or month.
+	IngestQuota *IngestQuotaConfiguration `json:"ingest_quota" db:"ingest_quota"`
+
+	// ValidateEventTypes rejects events whose payload doesn't match the
+	// schema of their type in the event type catalog.
+	ValidateEventTypes bool `json:"validate_event_types" db:"validate_event_types"`
+
+	// Redaction masks PII in the payloads and headers kept for the
+	// project's events and delivery attempts.
+	Redaction RedactionConfiguration `json:"redaction" db:"redaction"`
+
+	// EncryptPayloads encrypts the payloads kept for the project's events
+	// and delivery attempts at rest, when encryption is enabled.
+	EncryptPayloads bool `json:"encrypt_payloads" db:"encrypt_payloads"`
 }
 
 func (p *ProjectConfig) GetRateLimitConfig() RateLimitConfiguration {
@@ -543,6 +586,20 @@ func (p *ProjectConfig) GetRateLimitConfig() RateLimitConfiguration {
 	return RateLimitConfiguration{}
 }
 
+func (p *ProjectConfig) GetIngestRateLimitConfig() IngestRateLimitConfiguration {
+	if p.IngestRateLimit != nil {
+		return *p.IngestRateLimit
+	}
+	return IngestRateLimitConfiguration{}
+}
+
+func (p *ProjectConfig) GetIngestQuotaConfig() IngestQuotaConfiguration {
+	if p.IngestQuota != nil {
+		return *p.IngestQuota
+	}
+	return IngestQuotaConfiguration{}
+}
+
 func (p *ProjectConfig) GetStrategyConfig() StrategyConfiguration {
 	if p.Strategy != nil {
 		return *p.Strategy
@@ -577,6 +634,207 @@ type RateLimitConfiguration struct {
 	Duration uint64 `json:"duration" db:"duration"`
 }
 
+type DebounceMode string
+
+const (
+	// LastDebounceMode sends the last event of the window
+	LastDebounceMode DebounceMode = "last"
+
+	// MergeDebounceMode sends the events of the window merged into one,
+	// fields of later events win
+	MergeDebounceMode DebounceMode = "merge"
+)
+
+func (d DebounceMode) IsValid() bool {
+	switch d {
+	case LastDebounceMode, MergeDebounceMode:
+		return true
+	default:
+		return false
+	}
+}
+
+// DebounceConfiguration coalesces the events a subscription receives for
+// the same key, the key is read from the payload at KeyPath. Only one
+// delivery per key is sent Window seconds after the first event.
+type DebounceConfiguration struct {
+	KeyPath string       `json:"key_path" db:"key_path"`
+	Window  uint64       `json:"window" db:"window"`
+	Mode    DebounceMode `json:"mode" db:"mode"`
+}
+
+// FallbackConfiguration routes a subscription's deliveries that reach
+// Failure to another endpoint in the project or to a pub sub destination,
+// only one of EndpointID and PubSub is set. The fallback deliveries are
+// retried with RetryConfig, or the project's retry config when it's empty.
+type FallbackConfiguration struct {
+	EndpointID  string              `json:"endpoint_id,omitempty"`
+	PubSub      *PubSubConfig       `json:"pub_sub,omitempty"`
+	RetryConfig *RetryConfiguration `json:"retry_config,omitempty"`
+}
+
+func (f *FallbackConfiguration) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	return json.Unmarshal(b, f)
+}
+
+func (f FallbackConfiguration) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// IngestRateLimitConfiguration allows Count events every Duration seconds,
+// up to Burst events are accepted at once after a quiet period.
+type IngestRateLimitConfiguration struct {
+	Count    int    `json:"count" db:"count"`
+	Burst    int    `json:"burst" db:"burst"`
+	Duration uint64 `json:"duration" db:"duration"`
+}
+
+func (i *IngestRateLimitConfiguration) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	var c IngestRateLimitConfiguration
+	err := json.Unmarshal(b, &c)
+	if err != nil {
+		return err
+	}
+
+	*i = c
+	return nil
+}
+
+func (i IngestRateLimitConfiguration) Value() (driver.Value, error) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+type QuotaPeriod string
+
+const (
+	DailyQuotaPeriod   QuotaPeriod = "daily"
+	MonthlyQuotaPeriod QuotaPeriod = "monthly"
+)
+
+type IngestQuotaConfiguration struct {
+	Count  int         `json:"count" db:"count"`
+	Period QuotaPeriod `json:"period" db:"period"`
+}
+
+// Window returns the start and end of the quota period containing t,
+// periods are calendar days or months in UTC.
+func (q IngestQuotaConfiguration) Window(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	if q.Period == MonthlyQuotaPeriod {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// RedactionDetector is a built-in pattern for a common kind of PII.
+type RedactionDetector string
+
+const (
+	EmailRedactionDetector      RedactionDetector = "email"
+	CardNumberRedactionDetector RedactionDetector = "card_number"
+	SSNRedactionDetector        RedactionDetector = "ssn"
+)
+
+var redactionDetectors = map[RedactionDetector]bool{
+	EmailRedactionDetector:      true,
+	CardNumberRedactionDetector: true,
+	SSNRedactionDetector:        true,
+}
+
+type RedactionConfiguration struct {
+	// Paths are the payload fields to mask e.g. $.card.number, a *
+	// matches any key or array element.
+	Paths []string `json:"paths,omitempty"`
+
+	// Headers are the names of the headers to mask.
+	Headers []string `json:"headers,omitempty"`
+
+	// Detectors mask the PII they find anywhere in the payloads.
+	Detectors []RedactionDetector `json:"detectors,omitempty"`
+
+	// Patterns are regular expressions whose matches are masked anywhere
+	// in the payloads.
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+func (r RedactionConfiguration) IsZero() bool {
+	return len(r.Paths) == 0 && len(r.Headers) == 0 && len(r.Detectors) == 0 && len(r.Patterns) == 0
+}
+
+func (r RedactionConfiguration) Validate() error {
+	for _, path := range r.Paths {
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if len(trimmed) == 0 || strings.Contains(trimmed, "..") || strings.HasSuffix(trimmed, ".") {
+			return fmt.Errorf("invalid redaction path %q", path)
+		}
+	}
+
+	for _, header := range r.Headers {
+		if len(strings.TrimSpace(header)) == 0 {
+			return errors.New("redaction header names cannot be empty")
+		}
+	}
+
+	for _, detector := range r.Detectors {
+		if !redactionDetectors[detector] {
+			return fmt.Errorf("unknown redaction detector %q", detector)
+		}
+	}
+
+	for _, pattern := range r.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *RedactionConfiguration) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	var c RedactionConfiguration
+	if err := json.Unmarshal(b, &c); err != nil {
+		return err
+	}
+
+	*r = c
+	return nil
+}
+
+func (r RedactionConfiguration) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
 type StrategyConfiguration struct {
 	Type       StrategyProvider `json:"type" db:"type" valid:"optional~please provide a valid strategy type, in(linear|exponential)~unsupported strategy type"`
 	Duration   uint64           `json:"duration" db:"duration" valid:"optional~please provide a valid duration in seconds,int"`
@@ -660,6 +918,14 @@ var (
 	ErrNoActiveSecret                = errors.New("no active secret found")
 	ErrSecretNotFound                = errors.New("secret not found")
 	ErrMetaEventNotFound             = errors.New("meta event not found")
+	ErrFunctionLibraryNotFound       = errors.New("function library not found")
+	ErrFunctionVersionNotFound       = errors.New("function version not found")
+	ErrDataKeyNotFound               = errors.New("data key not found")
+	ErrDuplicateFunctionLibraryName  = errors.New("a function library with this name already exists")
+	ErrEventTypeNotFound             = errors.New("event type not found")
+	ErrDuplicateEventTypeName        = errors.New("an event type with this name already exists")
+	ErrEventTypeVersionConflict      = errors.New("the event type was changed by another request, please retry")
+	ErrDuplicateFallbackDelivery     = errors.New("the event delivery already has a fallback delivery")
 )
 
 type AppMetadata struct {
@@ -717,11 +983,31 @@ type Event struct {
 	IdempotencyKey   string                `json:"idempotency_key" db:"idempotency_key"`
 	IsDuplicateEvent bool                  `json:"is_duplicate_event" db:"is_duplicate_event"`
 
+	// Metadata holds information about the event gathered at ingest
+	// e.g. the verified claims of a JWT signed request
+	Metadata M `json:"metadata,omitempty" db:"metadata"`
+
 	// Data is an arbitrary JSON value that gets sent as the body of the
 	// webhook to the endpoints
 	Data json.RawMessage `json:"data,omitempty" db:"data"`
 	Raw  string          `json:"raw,omitempty" db:"raw"`
 
+	// OriginalBody is the request body as it was received, it is only set
+	// when the body was converted to JSON from e.g. form data or XML
+	OriginalBody string `json:"original_body,omitempty" db:"original_body"`
+
+	// PayloadReference is where the payload was offloaded to in the object
+	// store, Data and Raw aren't saved when it's set
+	PayloadReference string `json:"payload_reference,omitempty" db:"payload_reference"`
+
+	// EventTypeVersion is the version of the event type the event was
+	// published under, its payload is in that version
+	EventTypeVersion int `json:"event_type_version,omitempty" db:"event_type_version"`
+
+	// Redacted is set when the event was saved with parts of it masked by
+	// the project's redaction rules, it can't be replayed
+	Redacted bool `json:"redacted,omitempty" db:"redacted"`
+
 	AcknowledgedAt null.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at,omitempty" swaggertype:"string"`
 	CreatedAt      time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt      time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
@@ -765,6 +1051,10 @@ const (
 	FailureEventStatus    EventDeliveryStatus = "Failure"
 	SuccessEventStatus    EventDeliveryStatus = "Success"
 	RetryEventStatus      EventDeliveryStatus = "Retry"
+
+	// SupersededEventStatus when a debounced delivery was replaced by a
+	// later one for the same key before its window closed
+	SupersededEventStatus EventDeliveryStatus = "Superseded"
 )
 
 func (e EventDeliveryStatus) IsValid() bool {
@@ -774,7 +1064,8 @@ func (e EventDeliveryStatus) IsValid() bool {
 		DiscardedEventStatus,
 		FailureEventStatus,
 		SuccessEventStatus,
-		RetryEventStatus:
+		RetryEventStatus,
+		SupersededEventStatus:
 		return true
 	default:
 		return false
@@ -801,6 +1092,18 @@ type Metadata struct {
 	IntervalSeconds uint64 `json:"interval_seconds" bson:"interval_seconds"`
 
 	RetryLimit uint64 `json:"retry_limit" bson:"retry_limit"`
+
+	// CoalescedCount is the number of debounced events the delivery
+	// stands for, it's zero when the delivery wasn't debounced.
+	CoalescedCount uint64 `json:"coalesced_count,omitempty" bson:"coalesced_count"`
+
+	// PayloadReference is where the payload was offloaded to in the object
+	// store, Data and Raw aren't saved when it's set.
+	PayloadReference string `json:"payload_reference,omitempty" bson:"payload_reference"`
+
+	// Redacted is set when the payload was masked by the project's
+	// redaction rules, the delivery can't be sent again.
+	Redacted bool `json:"redacted,omitempty" bson:"redacted"`
 }
 
 func (m *Metadata) Scan(value interface{}) error {
@@ -825,6 +1128,13 @@ func (m *Metadata) Value() (driver.Value, error) {
 		return nil, nil
 	}
 
+	// offloaded payloads are only kept in the object store
+	if len(m.PayloadReference) > 0 {
+		c := *m
+		c.Data, c.Raw = nil, ""
+		m = &c
+	}
+
 	b, err := json.Marshal(m)
 	if err != nil {
 		return nil, err
@@ -917,6 +1227,18 @@ type EventDelivery struct {
 	LatencySeconds float64   `json:"latency_seconds" db:"latency_seconds"`
 	EventType      EventType `json:"event_type,omitempty" db:"event_type"`
 
+	// EventTypeVersion is the version of the event type the payload was
+	// sent as, it's only set for event types in the catalog.
+	EventTypeVersion null.Int `json:"event_type_version,omitempty" db:"event_type_version"`
+
+	// DebounceKey groups the deliveries of a debounced subscription, a
+	// scheduled delivery is superseded by a later one with the same key.
+	DebounceKey string `json:"debounce_key,omitempty" db:"debounce_key"`
+
+	// FallbackForID is the delivery this one is the fallback for, it's
+	// created when that delivery reaches Failure.
+	FallbackForID string `json:"fallback_for_id,omitempty" db:"fallback_for_id"`
+
 	Endpoint *Endpoint `json:"endpoint_metadata,omitempty" db:"endpoint_metadata"`
 	Event    *Event    `json:"event_metadata,omitempty" db:"event_metadata"`
 	Source   *Source   `json:"source_metadata,omitempty" db:"source_metadata"`
@@ -933,6 +1255,12 @@ type EventDelivery struct {
 	DeletedAt        null.Time           `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
 }
 
+// IsPubSubFallback reports whether the delivery is published to its
+// subscription's fallback pub sub destination instead of an endpoint.
+func (d *EventDelivery) IsPubSubFallback() bool {
+	return d.FallbackForID != "" && d.EndpointID == ""
+}
+
 func (d *EventDelivery) GetLatencyStartTime() time.Time {
 	if d.AcknowledgedAt.IsZero() {
 		return d.CreatedAt
@@ -1004,6 +1332,18 @@ type Subscription struct {
 	DeviceID   string           `json:"-" db:"device_id"`
 	Function   null.String      `json:"function" db:"function"`
 
+	// FunctionVersion is the version of Function the subscription is
+	// pinned to, Function can't be changed while it is set.
+	FunctionVersion null.Int `json:"function_version" db:"function_version"`
+
+	// FunctionLibraries are the versions of the project's libraries a
+	// pinned function requires, they were the latest when it was pinned.
+	FunctionLibraries FunctionLibraryPins `json:"function_libraries,omitempty" db:"function_libraries"`
+
+	// EventTypeVersions pins the version of each event type the
+	// subscription receives.
+	EventTypeVersions EventTypeVersions `json:"event_type_versions,omitempty" db:"event_type_versions"`
+
 	Source   *Source   `json:"source_metadata" db:"source_metadata"`
 	Endpoint *Endpoint `json:"endpoint_metadata" db:"endpoint_metadata"`
 	Device   *Device   `json:"device_metadata" db:"device_metadata"`
@@ -1013,6 +1353,8 @@ type Subscription struct {
 	RetryConfig     *RetryConfiguration     `json:"retry_config,omitempty" db:"retry_config"`
 	FilterConfig    *FilterConfiguration    `json:"filter_config,omitempty" db:"filter_config"`
 	RateLimitConfig *RateLimitConfiguration `json:"rate_limit_config,omitempty" db:"rate_limit_config"`
+	DebounceConfig  *DebounceConfiguration  `json:"debounce_config,omitempty" db:"debounce_config"`
+	FallbackConfig  *FallbackConfiguration  `json:"fallback_config,omitempty" db:"fallback_config"`
 
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at" swaggertype:"string"`
@@ -1042,8 +1384,9 @@ func (s *Subscription) GetFilterConfig() FilterConfiguration {
 	return FilterConfiguration{
 		EventTypes: []string{},
 		Filter: FilterSchema{
-			Headers: M{},
-			Body:    M{},
+			Headers:  M{},
+			Body:     M{},
+			Metadata: M{},
 		},
 	}
 }
@@ -1055,6 +1398,13 @@ func (s *Subscription) GetRateLimitConfig() RateLimitConfiguration {
 	return RateLimitConfiguration{}
 }
 
+func (s *Subscription) GetDebounceConfig() DebounceConfiguration {
+	if s.DebounceConfig != nil {
+		return *s.DebounceConfig
+	}
+	return DebounceConfiguration{}
+}
+
 type CustomResponse struct {
 	Body        string `json:"body" db:"body"`
 	ContentType string `json:"content_type" db:"content_type"`
@@ -1076,14 +1426,107 @@ type Source struct {
 	ForwardHeaders  pq.StringArray  `json:"forward_headers" db:"forward_headers"`
 	PubSub          *PubSubConfig   `json:"pub_sub" db:"pub_sub"`
 	IdempotencyKeys pq.StringArray  `json:"idempotency_keys" db:"idempotency_keys"`
+	DedupWindow     uint64          `json:"dedup_window" db:"dedup_window"`
 	BodyFunction    *string         `json:"body_function" db:"body_function"`
 	HeaderFunction  *string         `json:"header_function" db:"header_function"`
 
+	// IPAllowlist restricts ingestion to these ip addresses and CIDR blocks,
+	// it is not enforced when empty.
+	IPAllowlist pq.StringArray `json:"ip_allowlist" db:"ip_allowlist"`
+
+	// ClientCACert is the PEM encoded CA used to verify client certificates,
+	// requests without a certificate issued by it are rejected when set.
+	ClientCACert string `json:"client_ca_cert" db:"client_ca_cert"`
+
+	// EventTypeConfig describes where to read an ingested event's type from,
+	// the source's mask id is used when it is not set.
+	EventTypeConfig *EventTypeConfig `json:"event_type_config" db:"event_type_config"`
+
+	// SyncForwarding makes ingest wait for the designated endpoint's
+	// response and relay it to the sender.
+	SyncForwarding *SyncForwardingConfig `json:"sync_forwarding" db:"sync_forwarding"`
+
+	// IngestRateLimit limits the events accepted by this source, it applies
+	// in addition to the project's limit.
+	IngestRateLimit *IngestRateLimitConfiguration `json:"ingest_rate_limit" db:"ingest_rate_limit"`
+
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at" swaggertype:"string"`
 	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
 }
 
+type EventTypeConfig struct {
+	Type EventTypeSource `json:"type" db:"type"`
+
+	// Header is the request header holding the event type.
+	Header string `json:"header,omitempty" db:"header"`
+
+	// Path is a gjson path to the event type in the request body.
+	Path string `json:"path,omitempty" db:"path"`
+
+	// Function is a javascript function named transform that receives
+	// {headers, body} and returns the event type.
+	Function string `json:"function,omitempty" db:"function"`
+}
+
+func (e *EventTypeConfig) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	var ec EventTypeConfig
+	err := json.Unmarshal(b, &ec)
+	if err != nil {
+		return err
+	}
+
+	*e = ec
+	return nil
+}
+
+func (e EventTypeConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+type SyncForwardingConfig struct {
+	EndpointID string `json:"endpoint_id" db:"endpoint_id"`
+
+	// Timeout is how long in seconds to wait for the endpoint before
+	// falling back to delivering the event asynchronously.
+	Timeout uint64 `json:"timeout" db:"timeout"`
+}
+
+func (sf *SyncForwardingConfig) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	var c SyncForwardingConfig
+	err := json.Unmarshal(b, &c)
+	if err != nil {
+		return err
+	}
+
+	*sf = c
+	return nil
+}
+
+func (sf SyncForwardingConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(sf)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
 type PubSubConfig struct {
 	Type    PubSubType          `json:"type" db:"type"`
 	Workers int                 `json:"workers" db:"workers"`
@@ -1218,6 +1661,10 @@ func (h *M) Flatten() error {
 }
 
 func (h *M) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
 	b, ok := value.([]byte)
 	if !ok {
 		return fmt.Errorf("unsupported value type %T", value)
@@ -1247,6 +1694,14 @@ type FilterSchema struct {
 	IsFlattened bool `json:"is_flattened" db:"is_flattened"`
 	Headers     M    `json:"headers" db:"headers"`
 	Body        M    `json:"body" db:"body"`
+	Metadata    M    `json:"metadata" db:"metadata"`
+
+	// Expression is a CEL expression the event must also match, see
+	// the celfilter package for the variables it can use.
+	Expression string `json:"expression,omitempty" db:"expression"`
+
+	// Labels is a selector the subscription's endpoint labels must match.
+	Labels Labels `json:"labels,omitempty" db:"labels"`
 }
 
 type ProviderConfig struct {
@@ -1262,6 +1717,7 @@ type VerifierConfig struct {
 	HMac      *HMac        `json:"hmac" db:"hmac"`
 	BasicAuth *BasicAuth   `json:"basic_auth" db:"basic_auth"`
 	ApiKey    *ApiKey      `json:"api_key" db:"api_key"`
+	JWT       *JWT         `json:"jwt" db:"jwt"`
 }
 
 type HMac struct {
@@ -1269,6 +1725,10 @@ type HMac struct {
 	Hash     string       `json:"hash" db:"hash" valid:"supported_hash,required"`
 	Secret   string       `json:"secret" db:"secret" valid:"required"`
 	Encoding EncodingType `json:"encoding" db:"encoding" valid:"supported_encoding~please provide a valid encoding type,required"`
+
+	// Tolerance is how old, in seconds, a signed timestamp can be for
+	// providers that sign one e.g. stripe and slack, it defaults to 300.
+	Tolerance uint64 `json:"tolerance,omitempty" db:"tolerance"`
 }
 
 type BasicAuth struct {
@@ -1281,6 +1741,15 @@ type ApiKey struct {
 	HeaderName  string `json:"header_name" db:"header_name" valid:"required"`
 }
 
+type JWT struct {
+	Algorithm     string `json:"algorithm" db:"algorithm" valid:"required"`
+	Key           string `json:"key" db:"key"`
+	JwksURL       string `json:"jwks_url" db:"jwks_url"`
+	Issuer        string `json:"issuer" db:"issuer"`
+	Audience      string `json:"audience" db:"audience"`
+	BodyHashClaim string `json:"body_hash_claim" db:"body_hash_claim"`
+}
+
 type Organisation struct {
 	UID            string      `json:"uid" db:"id"`
 	OwnerID        string      `json:"" db:"owner_id"`
@@ -1483,6 +1952,297 @@ type MetaEvent struct {
 	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
 }
 
+// FunctionLibrary is a js module the functions in a project can require
+// by its name.
+type FunctionLibrary struct {
+	UID       string `json:"uid" db:"id"`
+	ProjectID string `json:"project_id" db:"project_id"`
+	Name      string `json:"name" db:"name"`
+	Function  string `json:"function" db:"function"`
+
+	// Version is the library's latest version.
+	Version int `json:"version" db:"version"`
+
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
+	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
+}
+
+type DataKeyStatus string
+
+const (
+	ActiveDataKeyStatus  DataKeyStatus = "active"
+	RetiredDataKeyStatus DataKeyStatus = "retired"
+)
+
+// ReEncryptBatch is a batch of rows moved to their project's active data
+// key, the rows are read in the order of their ids.
+type ReEncryptBatch struct {
+	// Cursor is the id of the batch's last row, the next batch starts
+	// after it.
+	Cursor string
+
+	// Fetched is the number of rows read, fewer than the batch's limit
+	// means no rows are left.
+	Fetched int
+
+	ReEncrypted int64
+
+	// Failed are the ids of the rows that couldn't be decrypted, they're
+	// left as they are.
+	Failed []string
+}
+
+// DataKey is a key that encrypts a project's event payloads at rest. It
+// is stored wrapped by the master key identified by KekID, retired keys
+// are kept to decrypt payloads that haven't been re-encrypted yet.
+type DataKey struct {
+	UID        string        `json:"uid" db:"id"`
+	ProjectID  string        `json:"project_id" db:"project_id"`
+	Version    int           `json:"version" db:"version"`
+	WrappedKey []byte        `json:"-" db:"wrapped_key"`
+	KekID      string        `json:"kek_id" db:"kek_id"`
+	Status     DataKeyStatus `json:"status" db:"status"`
+
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
+}
+
+// Partition is a partition of the events or event deliveries table. The
+// default partition and the legacy one, which holds the rows created before
+// the tables were partitioned, have no lower bound.
+type Partition struct {
+	Table     string     `json:"table" db:"table_name"`
+	Name      string     `json:"name" db:"name"`
+	From      *time.Time `json:"from" db:"range_from"`
+	To        *time.Time `json:"to" db:"range_to"`
+	IsDefault bool       `json:"is_default" db:"is_default"`
+
+	// Rows is Postgres' estimate of the partition's row count.
+	Rows int64 `json:"rows" db:"rows"`
+	Size int64 `json:"size" db:"size"`
+}
+
+type FunctionOwnerType string
+
+const (
+	FunctionLibraryOwner      FunctionOwnerType = "library"
+	SubscriptionFunctionOwner FunctionOwnerType = "subscription"
+	SourceBodyFunctionOwner   FunctionOwnerType = "source_body"
+	SourceHeaderFunctionOwner FunctionOwnerType = "source_header"
+)
+
+// FunctionVersion records a function or library's source every time it
+// changes.
+type FunctionVersion struct {
+	UID       string            `json:"uid" db:"id"`
+	ProjectID string            `json:"project_id" db:"project_id"`
+	OwnerType FunctionOwnerType `json:"owner_type" db:"owner_type"`
+	OwnerID   string            `json:"owner_id" db:"owner_id"`
+	Version   int               `json:"version" db:"version"`
+	Function  string            `json:"function" db:"function"`
+
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
+}
+
+// ProjectEventType describes an event type a project sends, so payloads
+// can be validated and portal users can see what they subscribe to.
+type ProjectEventType struct {
+	UID         string `json:"uid" db:"id"`
+	ProjectID   string `json:"project_id" db:"project_id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+
+	// JSONSchema is the schema the event's payload must match, events of
+	// a type without one are not validated.
+	JSONSchema json.RawMessage   `json:"json_schema,omitempty" db:"json_schema" swaggertype:"object"`
+	Examples   EventTypeExamples `json:"examples" db:"examples" swaggertype:"array,object"`
+	Deprecated bool              `json:"deprecated" db:"deprecated"`
+
+	// Version is the latest version of the event type, the version
+	// producers publish.
+	Version int `json:"version" db:"version"`
+
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
+	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
+}
+
+// EventTypeExamples are sample payloads of an event type.
+type EventTypeExamples []json.RawMessage
+
+func (e *EventTypeExamples) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, e)
+}
+
+func (e EventTypeExamples) Value() (driver.Value, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(e)
+}
+
+// EventTypeVersion is a version of an event type's payload.
+type EventTypeVersion struct {
+	ProjectID   string          `json:"project_id" db:"project_id"`
+	EventTypeID string          `json:"event_type_id" db:"event_type_id"`
+	Version     int             `json:"version" db:"version"`
+	JSONSchema  json.RawMessage `json:"json_schema,omitempty" db:"json_schema" swaggertype:"object"`
+
+	// DownConverter is a js transform function that converts a payload
+	// of this version to the previous version.
+	DownConverter string `json:"down_converter,omitempty" db:"down_converter"`
+
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
+}
+
+// FunctionLibraryPin is the version of a library a pinned function runs.
+type FunctionLibraryPin struct {
+	LibraryID string `json:"library_id"`
+	Version   int    `json:"version"`
+}
+
+// FunctionLibraryPins maps the name libraries are required with to the
+// version pinned for them.
+type FunctionLibraryPins map[string]FunctionLibraryPin
+
+func (f *FunctionLibraryPins) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	return json.Unmarshal(b, f)
+}
+
+func (f FunctionLibraryPins) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(f)
+}
+
+// EventTypeVersions maps event types to the version pinned for them.
+type EventTypeVersions map[string]int
+
+func (e *EventTypeVersions) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	return json.Unmarshal(b, e)
+}
+
+func (e EventTypeVersions) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(e)
+}
+
+// Labels are key/value pairs, as a selector every pair must be present.
+type Labels map[string]string
+
+var labelKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_./-]{0,61}[a-zA-Z0-9])?$`)
+
+// Matches reports whether labels has every label in the selector, an
+// empty selector matches all labels.
+func (l Labels) Matches(labels Labels) bool {
+	for k, v := range l {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l Labels) Validate() error {
+	for k, v := range l {
+		if !labelKeyRegex.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+
+		if len(v) > 255 {
+			return fmt.Errorf("value of label %s is longer than 255 characters", k)
+		}
+	}
+
+	return nil
+}
+
+// ParseLabelSelector parses key=value pairs e.g. region=eu into a selector.
+func ParseLabelSelector(pairs []string) (Labels, error) {
+	selector := Labels{}
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label selector %q, labels should be key=value", pair)
+		}
+
+		selector[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return selector, selector.Validate()
+}
+
+func (l *Labels) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	var labels Labels
+	if err := json.Unmarshal(b, &labels); err != nil {
+		return err
+	}
+
+	// the column defaults to {}, endpoints without labels read back as nil
+	if len(labels) == 0 {
+		labels = nil
+	}
+
+	*l = labels
+	return nil
+}
+
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(l)
+}
+
 type MetaEventPayload struct {
 	EventType string          `json:"event_type"`
 	Data      json.RawMessage `json:"data"`
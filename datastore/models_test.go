@@ -71,3 +71,96 @@ func TestProject_IsOwner(t *testing.T) {
 		})
 	}
 }
+
+func TestIngestQuotaConfiguration_Window(t *testing.T) {
+	now := time.Date(2024, time.January, 31, 18, 30, 0, 0, time.FixedZone("WAT", 3600))
+
+	tt := []struct {
+		name          string
+		quota         IngestQuotaConfiguration
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{
+			name:          "daily",
+			quota:         IngestQuotaConfiguration{Count: 10, Period: DailyQuotaPeriod},
+			expectedStart: time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "monthly",
+			quota:         IngestQuotaConfiguration{Count: 10, Period: MonthlyQuotaPeriod},
+			expectedStart: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "defaults to daily",
+			quota:         IngestQuotaConfiguration{},
+			expectedStart: time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := tc.quota.Window(now)
+			require.Equal(t, tc.expectedStart, start)
+			require.Equal(t, tc.expectedEnd, end)
+		})
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairs    []string
+		selector Labels
+		wantErr  bool
+	}{
+		{
+			name:     "should_parse_labels",
+			pairs:    []string{"region=eu", " tier = enterprise"},
+			selector: Labels{"region": "eu", "tier": "enterprise"},
+		},
+		{
+			name:     "should_parse_empty_value",
+			pairs:    []string{"beta="},
+			selector: Labels{"beta": ""},
+		},
+		{
+			name:    "should_error_without_value",
+			pairs:   []string{"region"},
+			wantErr: true,
+		},
+		{
+			name:    "should_error_for_invalid_key",
+			pairs:   []string{"-region=eu"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseLabelSelector(tt.pairs)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.selector, selector)
+		})
+	}
+}
+
+func TestLabels_Matches(t *testing.T) {
+	labels := Labels{"region": "eu", "tier": "enterprise"}
+
+	require.True(t, Labels{}.Matches(labels))
+	require.True(t, Labels(nil).Matches(nil))
+	require.True(t, Labels{"region": "eu"}.Matches(labels))
+	require.True(t, labels.Matches(labels))
+	require.False(t, Labels{"region": "us"}.Matches(labels))
+	require.False(t, Labels{"region": "eu", "beta": "true"}.Matches(labels))
+	require.False(t, Labels{"region": "eu"}.Matches(nil))
+}
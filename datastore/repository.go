@@ -30,6 +30,11 @@ type EventDeliveryRepository interface {
 	CountDeliveriesByStatus(ctx context.Context, projectID string, status EventDeliveryStatus, params SearchParams) (int64, error)
 	UpdateStatusOfEventDelivery(ctx context.Context, projectID string, eventDelivery EventDelivery, status EventDeliveryStatus) error
 	UpdateStatusOfEventDeliveries(ctx context.Context, projectID string, ids []string, status EventDeliveryStatus) error
+	UpdateEventDeliveryMetadata(ctx context.Context, projectID string, eventDelivery EventDelivery) error
+	SupersedeEventDeliveries(ctx context.Context, projectID, subscriptionID, debounceKey, supersededBy string) ([]EventDelivery, error)
+	// RunInTransaction runs fn in one transaction, the repository's writes
+	// made with the context fn is given are committed when it succeeds.
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	FindDiscardedEventDeliveries(ctx context.Context, projectID, deviceId string, params SearchParams) ([]EventDelivery, error)
 	FindStuckEventDeliveriesByStatus(ctx context.Context, status EventDeliveryStatus) ([]EventDelivery, error)
 	UpdateEventDeliveryWithAttempt(ctx context.Context, projectID string, eventDelivery EventDelivery, attempt DeliveryAttempt) error
@@ -37,6 +42,10 @@ type EventDeliveryRepository interface {
 	DeleteProjectEventDeliveries(ctx context.Context, projectID string, filter *EventDeliveryFilter, hardDelete bool) error
 	LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []EventDeliveryStatus, params SearchParams, pageable Pageable, idempotencyKey, eventType string) ([]EventDelivery, PaginationData, error)
 	LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params SearchParams, period Period) ([]EventInterval, error)
+	// ReEncryptEventDeliveries re-encrypts up to limit of the project's
+	// deliveries after cursor whose payloads aren't encrypted with its
+	// active data key.
+	ReEncryptEventDeliveries(ctx context.Context, projectID, cursor string, limit int) (*ReEncryptBatch, error)
 }
 
 type EventRepository interface {
@@ -52,6 +61,9 @@ type EventRepository interface {
 	FindEventsByIdempotencyKey(ctx context.Context, projectID string, idempotencyKey string) ([]Event, error)
 	FindFirstEventWithIdempotencyKey(ctx context.Context, projectID string, idempotencyKey string) (*Event, error)
 	CopyRows(ctx context.Context, projectID string, interval int) error
+	// ReEncryptEvents re-encrypts up to limit of the project's events
+	// after cursor whose payloads aren't encrypted with its active data key.
+	ReEncryptEvents(ctx context.Context, projectID, cursor string, limit int) (*ReEncryptBatch, error)
 }
 
 type ProjectRepository interface {
@@ -100,6 +112,7 @@ type EndpointRepository interface {
 	FindEndpointsByID(ctx context.Context, ids []string, projectID string) ([]Endpoint, error)
 	FindEndpointsByAppID(ctx context.Context, appID string, projectID string) ([]Endpoint, error)
 	FindEndpointsByOwnerID(ctx context.Context, projectID string, ownerID string) ([]Endpoint, error)
+	FindEndpointsByLabels(ctx context.Context, projectID string, selector Labels) ([]Endpoint, error)
 	FindEndpointByTargetURL(ctx context.Context, projectID string, targetURL string) (*Endpoint, error)
 	UpdateEndpoint(ctx context.Context, endpoint *Endpoint, projectID string) error
 	UpdateEndpointStatus(ctx context.Context, projectID, endpointID string, status EndpointStatus) error
@@ -193,6 +206,58 @@ type MetaEventRepository interface {
 	UpdateMetaEvent(ctx context.Context, projectID string, metaEvent *MetaEvent) error
 }
 
+type FunctionLibraryRepository interface {
+	CreateFunctionLibrary(ctx context.Context, library *FunctionLibrary) error
+	UpdateFunctionLibrary(ctx context.Context, projectID string, library *FunctionLibrary) error
+	DeleteFunctionLibrary(ctx context.Context, projectID string, id string) error
+	FindFunctionLibraryByID(ctx context.Context, projectID string, id string) (*FunctionLibrary, error)
+	LoadFunctionLibraries(ctx context.Context, projectID string) ([]FunctionLibrary, error)
+}
+
+type DataKeyRepository interface {
+	// CreateDataKey saves key as the project's next active data key and
+	// retires the previous one.
+	CreateDataKey(ctx context.Context, key *DataKey) error
+	FindActiveDataKey(ctx context.Context, projectID string) (*DataKey, error)
+	FindDataKeyByID(ctx context.Context, projectID string, id string) (*DataKey, error)
+	// LoadDataKeysNotWrappedBy returns the data keys that aren't wrapped by
+	// the master key with kekID.
+	LoadDataKeysNotWrappedBy(ctx context.Context, kekID string) ([]DataKey, error)
+	UpdateWrappedKey(ctx context.Context, key *DataKey) error
+}
+
+type PartitionRepository interface {
+	// CreatePartitions creates the daily partitions of the events and event
+	// deliveries tables up to the day before until, it returns the names of
+	// the partitions it created. A day that fails doesn't stop the others,
+	// the failures are joined in the error.
+	CreatePartitions(ctx context.Context, until time.Time) ([]string, error)
+	LoadPartitions(ctx context.Context) ([]Partition, error)
+	// RemovePartitions drops the partitions whose rows were all created
+	// before expiry, or detaches them when detach is set.
+	RemovePartitions(ctx context.Context, expiry time.Time, detach bool) ([]string, error)
+}
+
+type FunctionVersionRepository interface {
+	// CreateFunctionVersion saves version as its owner's next version.
+	CreateFunctionVersion(ctx context.Context, version *FunctionVersion) error
+	FindFunctionVersion(ctx context.Context, projectID string, ownerType FunctionOwnerType, ownerID string, version int) (*FunctionVersion, error)
+	LoadFunctionVersions(ctx context.Context, projectID string, ownerType FunctionOwnerType, ownerID string) ([]FunctionVersion, error)
+}
+
+type EventTypeRepository interface {
+	CreateEventType(ctx context.Context, eventType *ProjectEventType) error
+	UpdateEventType(ctx context.Context, projectID string, eventType *ProjectEventType) error
+	DeleteEventType(ctx context.Context, projectID string, id string) error
+	FindEventTypeByID(ctx context.Context, projectID string, id string) (*ProjectEventType, error)
+	FindEventTypeByName(ctx context.Context, projectID string, name string) (*ProjectEventType, error)
+	LoadEventTypes(ctx context.Context, projectID string) ([]ProjectEventType, error)
+	// CreateEventTypeVersion saves version as the event type's latest
+	// version.
+	CreateEventTypeVersion(ctx context.Context, eventType *ProjectEventType, version *EventTypeVersion) error
+	LoadEventTypeVersions(ctx context.Context, projectID string, eventTypeID string) ([]EventTypeVersion, error)
+}
+
 type ExportRepository interface {
 	ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error)
 }
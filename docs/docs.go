@@ -6197,12 +6197,20 @@ const docTemplate = `{
             "enum": [
                 "github",
                 "twitter",
-                "shopify"
+                "shopify",
+                "stripe",
+                "slack",
+                "gitlab",
+                "twilio"
             ],
             "x-enum-varnames": [
                 "GithubSourceProvider",
                 "TwitterSourceProvider",
-                "ShopifySourceProvider"
+                "ShopifySourceProvider",
+                "StripeSourceProvider",
+                "SlackSourceProvider",
+                "GitlabSourceProvider",
+                "TwilioSourceProvider"
             ]
         },
         "datastore.SourceType": {
@@ -6273,13 +6281,15 @@ const docTemplate = `{
                 "noop",
                 "hmac",
                 "basic_auth",
-                "api_key"
+                "api_key",
+                "jwt"
             ],
             "x-enum-varnames": [
                 "NoopVerifier",
                 "HMacVerifier",
                 "BasicAuthVerifier",
-                "APIKeyVerifier"
+                "APIKeyVerifier",
+                "JWTVerifier"
             ]
         },
         "handlers.Stub": {
@@ -163,6 +163,7 @@ func StartConvoyServer(a *cli.App) error {
 			Logger: lo,
 			Cache:  a.Cache,
 			Rate:   a.Rate,
+			Dedup:  a.Dedup,
 		})
 	if err != nil {
 		return err
@@ -184,6 +185,8 @@ func StartConvoyServer(a *cli.App) error {
 	s.RegisterTask("0 0 * * *", convoy.ScheduleQueue, convoy.RetentionPolicies)
 	s.RegisterTask("55 23 * * *", convoy.ScheduleQueue, convoy.DailyAnalytics)
 	s.RegisterTask("0 * * * *", convoy.ScheduleQueue, convoy.TokenizeSearch)
+	s.RegisterTask("15 * * * *", convoy.ScheduleQueue, convoy.ReEncryptPayloads)
+	s.RegisterTask("45 * * * *", convoy.ScheduleQueue, convoy.ManagePartitions)
 
 	// Start scheduler
 	s.Start()
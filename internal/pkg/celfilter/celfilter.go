@@ -0,0 +1,191 @@
+// Package celfilter compiles and evaluates subscription filters written in
+// CEL (https://github.com/google/cel-spec), an alternative to the
+// Mongo-style body and header filters for compound conditions.
+//
+// Expressions are evaluated against these variables:
+//
+//	body       the event payload
+//	headers    the event headers, with a single value per header
+//	event_type the event type
+//	source     the id of the source the event was ingested through
+//
+// e.g. `event_type == "invoice.paid" && body.amount > 1000 && headers["X-Region"] == "eu"`
+package celfilter
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// DefaultCostLimit bounds the work a single evaluation may do, an
+// expression exceeding it fails to evaluate.
+const DefaultCostLimit = 100_000
+
+var ErrNotBoolean = errors.New("filter expression must evaluate to a bool")
+
+var (
+	env     *cel.Env
+	envErr  error
+	envOnce sync.Once
+)
+
+func getEnv() (*cel.Env, error) {
+	envOnce.Do(func() {
+		env, envErr = cel.NewEnv(
+			cel.Variable("body", cel.DynType),
+			cel.Variable("headers", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("event_type", cel.StringType),
+			cel.Variable("source", cel.StringType),
+			// json numbers are decoded as doubles, this lets
+			// `body.amount > 10` compare them to int literals.
+			cel.CrossTypeNumericComparisons(true),
+		)
+	})
+
+	return env, envErr
+}
+
+// Input is what an expression is evaluated against.
+type Input struct {
+	Body      interface{}
+	Headers   map[string]interface{}
+	EventType string
+	Source    string
+}
+
+func (i Input) activation() map[string]interface{} {
+	headers := i.Headers
+	if headers == nil {
+		headers = map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"body":       i.Body,
+		"headers":    headers,
+		"event_type": i.EventType,
+		"source":     i.Source,
+	}
+}
+
+// Compile type checks expression and returns a cost limited program for
+// it. The returned program is safe for concurrent use.
+func Compile(expression string) (cel.Program, error) {
+	e, err := getEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, iss := e.Compile(expression)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+
+	// body and headers are dynamic, so expressions like `body.active`
+	// can only be checked when they are evaluated.
+	out := ast.OutputType()
+	if !out.IsExactType(cel.BoolType) && !out.IsExactType(cel.DynType) {
+		return nil, ErrNotBoolean
+	}
+
+	return e.Program(ast, cel.CostLimit(DefaultCostLimit))
+}
+
+// Validate reports whether expression compiles.
+func Validate(expression string) error {
+	_, err := Compile(expression)
+	return err
+}
+
+// Match evaluates program against in, errors such as a missing key or the
+// cost limit being exceeded are returned with a false match.
+func Match(program cel.Program, in Input) (bool, error) {
+	out, _, err := program.Eval(in.activation())
+	if err != nil {
+		return false, err
+	}
+
+	matched, ok := out.(types.Bool)
+	if !ok {
+		return false, ErrNotBoolean
+	}
+
+	return bool(matched), nil
+}
+
+// DefaultCacheSize is the number of compiled programs DefaultCache keeps.
+const DefaultCacheSize = 1000
+
+type cachedProgram struct {
+	key     [sha256.Size]byte
+	program cel.Program
+}
+
+// Cache holds compiled programs keyed by the hash of their expression, so
+// subscriptions sharing an expression share its program. The least
+// recently used programs are evicted once it holds size of them, updated
+// and deleted expressions are dropped that way.
+type Cache struct {
+	size int
+
+	mu       sync.Mutex
+	order    *list.List
+	programs map[[sha256.Size]byte]*list.Element
+}
+
+func NewCache(size int) *Cache {
+	return &Cache{size: size, order: list.New(), programs: map[[sha256.Size]byte]*list.Element{}}
+}
+
+// DefaultCache is shared by the workers matching events to subscriptions.
+var DefaultCache = NewCache(DefaultCacheSize)
+
+// Program returns the compiled program for expression.
+func (c *Cache) Program(expression string) (cel.Program, error) {
+	key := sha256.Sum256([]byte(expression))
+
+	c.mu.Lock()
+	if el, ok := c.programs[key]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		return el.Value.(*cachedProgram).program, nil
+	}
+	c.mu.Unlock()
+
+	// compiled without the lock, a concurrent compile of the same
+	// expression is harmless
+	program, err := Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile filter expression: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.programs[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*cachedProgram).program, nil
+	}
+
+	c.programs[key] = c.order.PushFront(&cachedProgram{key: key, program: program})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.programs, oldest.Value.(*cachedProgram).key)
+	}
+
+	return program, nil
+}
+
+// Len returns the number of programs in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,161 @@
+package celfilter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	var body interface{}
+	err := json.Unmarshal([]byte(`{"amount": 1500, "currency": "EUR", "customer": {"tier": "gold"}, "tags": ["vip", "b2b"]}`), &body)
+	require.NoError(t, err)
+
+	in := Input{
+		Body:      body,
+		Headers:   map[string]interface{}{"X-Region": "eu"},
+		EventType: "invoice.paid",
+		Source:    "source-1",
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:       "compound condition",
+			expression: `event_type == "invoice.paid" && body.amount > 1000 && headers["X-Region"] == "eu"`,
+			want:       true,
+		},
+		{
+			name:       "nested field",
+			expression: `body.customer.tier in ["gold", "platinum"]`,
+			want:       true,
+		},
+		{
+			name:       "array",
+			expression: `"vip" in body.tags && size(body.tags) == 2`,
+			want:       true,
+		},
+		{
+			name:       "string functions",
+			expression: `event_type.startsWith("invoice.") && source == "source-1"`,
+			want:       true,
+		},
+		{
+			name:       "no match",
+			expression: `body.currency == "USD" || body.amount < 100`,
+			want:       false,
+		},
+		{
+			name:       "has guards missing fields",
+			expression: `has(body.refund) && body.refund.amount > 0`,
+			want:       false,
+		},
+		{
+			name:       "missing field",
+			expression: `body.refund.amount > 0`,
+			wantErr:    true,
+		},
+		{
+			name:       "non boolean field",
+			expression: `body.currency`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.expression)
+			require.NoError(t, err)
+
+			matched, err := Match(program, in)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.False(t, matched)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, matched)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{name: "valid", expression: `body.amount > 10`},
+		{name: "syntax error", expression: `body.amount >`, wantErr: true},
+		{name: "unknown variable", expression: `payload.amount > 10`, wantErr: true},
+		{name: "not a bool", expression: `event_type + "x"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.expression)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMatch_CostLimit(t *testing.T) {
+	program, err := Compile(`body.items.all(a, body.items.all(b, body.items.all(c, a + b + c >= 0)))`)
+	require.NoError(t, err)
+
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = float64(i)
+	}
+
+	_, err = Match(program, Input{Body: map[string]interface{}{"items": items}})
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "cost limit"))
+}
+
+func TestCache_Program(t *testing.T) {
+	c := NewCache(2)
+
+	p1, err := c.Program(`body.a == 1`)
+	require.NoError(t, err)
+
+	p2, err := c.Program(`body.a == 1`)
+	require.NoError(t, err)
+	require.Equal(t, p1, p2)
+
+	// a subscription's expression was updated
+	p3, err := c.Program(`body.a == 2`)
+	require.NoError(t, err)
+
+	matched, err := Match(p3, Input{Body: map[string]interface{}{"a": 2}})
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	_, err = c.Program(`body.a ==`)
+	require.Error(t, err)
+	require.Equal(t, 2, c.Len())
+
+	// the least recently used program is evicted
+	_, err = c.Program(`body.a == 1`)
+	require.NoError(t, err)
+
+	_, err = c.Program(`body.a == 3`)
+	require.NoError(t, err)
+	require.Equal(t, 2, c.Len())
+
+	p4, err := c.Program(`body.a == 1`)
+	require.NoError(t, err)
+	require.Equal(t, p1, p4)
+}
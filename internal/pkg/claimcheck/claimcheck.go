@@ -0,0 +1,495 @@
+// Package claimcheck offloads large event and delivery payloads to the
+// object store of the storage policy, only a reference to where a payload
+// was saved is kept in the database.
+//
+// Large payloads are offloaded when they're ingested, so the queue jobs
+// only carry their reference, and the worker removes these objects once
+// it has saved the event. Events and deliveries are offloaded by the
+// worker when they're saved, the events and deliveries it's processing
+// keep their payload in memory. Deliveries whose payload is the same as
+// their event's share the event's object. Payloads are read from the
+// object store when a delivery is sent and when a single event or delivery
+// is fetched from the API, listings only return the reference.
+//
+// The objects of the projects that encrypt their payloads are encrypted
+// with the project's data key, in segments so they can be read as a
+// stream. The retention policy removes the objects of the events and
+// deliveries it deletes, the objects are named after their ids, which
+// sort by creation time.
+//
+// Since offloaded payloads aren't in the database:
+//
+//   - they aren't tokenized, so searching events doesn't match them.
+//   - filters that look into payloads in SQL don't match them.
+//   - they aren't exported.
+//
+// An event's original body isn't offloaded, and neither are the payloads
+// of dynamic and broadcast events at ingest.
+package claimcheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	objectstore "github.com/frain-dev/convoy/internal/pkg/object-store"
+)
+
+const tmpPayloadDir = "/tmp/convoy/payloads"
+
+// segmentSize is how much of a payload is encrypted at once, an encrypted
+// object has a segment per line.
+const segmentSize = 64 * 1024
+
+// ErrNotConfigured is returned when an offloaded payload is read without a
+// storage policy to read it from.
+var ErrNotConfigured = errors.New("payload offloading is not configured")
+
+// Store offloads payloads larger than its threshold, a nil Store doesn't
+// offload anything.
+type Store struct {
+	enabled   bool
+	threshold uint64
+	dir       string
+	removeTmp bool
+	store     objectstore.ObjectStore
+}
+
+// New returns the Store for the storage policy. When offloading is turned
+// off the Store still reads the payloads that were offloaded before, it's
+// nil if the storage policy can't be used.
+func New(cfg config.PayloadOffloadConfiguration, policy *datastore.StoragePolicyConfiguration) (*Store, error) {
+	s, err := newStore(cfg, policy)
+	if err != nil && !cfg.Enabled {
+		return nil, nil
+	}
+
+	return s, err
+}
+
+func newStore(cfg config.PayloadOffloadConfiguration, policy *datastore.StoragePolicyConfiguration) (*Store, error) {
+	if policy == nil {
+		return nil, errors.New("storage policy is not set")
+	}
+
+	store, err := objectstore.NewObjectStoreClient(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		enabled:   cfg.Enabled,
+		threshold: cfg.Threshold,
+		store:     store,
+	}
+
+	if s.threshold == 0 {
+		s.threshold = config.DefaultPayloadOffloadThreshold
+	}
+
+	switch policy.Type {
+	case datastore.S3:
+		// files are uploaded from /tmp and removed once saved
+		s.dir, s.removeTmp = tmpPayloadDir, true
+	case datastore.OnPrem:
+		if policy.OnPrem == nil || policy.OnPrem.Path.IsZero() {
+			return nil, errors.New("on prem storage path is not set")
+		}
+
+		s.dir = filepath.Join(policy.OnPrem.Path.String, "payloads")
+	}
+
+	return s, nil
+}
+
+var defaultStore atomic.Value
+
+// Init sets the Store the worker and the API use.
+func Init(cfg config.PayloadOffloadConfiguration, policy *datastore.StoragePolicyConfiguration) (*Store, error) {
+	s, err := New(cfg, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultStore.Store(s)
+	return s, nil
+}
+
+// Get returns the Store set with Init, or nil if Init wasn't called.
+func Get() *Store {
+	s, _ := defaultStore.Load().(*Store)
+	return s
+}
+
+// Enabled reports whether new payloads are offloaded.
+func (s *Store) Enabled() bool {
+	return s != nil && s.enabled
+}
+
+func (s *Store) shouldOffload(payload []byte) bool {
+	return s.Enabled() && uint64(len(payload)) > s.threshold
+}
+
+// Save writes payload to the object store and returns its reference, it's
+// encrypted when the project encrypts its payloads.
+func (s *Store) Save(ctx context.Context, projectID, name string, payload []byte) (string, error) {
+	if s == nil {
+		return "", ErrNotConfigured
+	}
+
+	dir := filepath.Join(s.dir, projectID, filepath.Dir(name))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	filename := filepath.Join(s.dir, projectID, name)
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	if s.removeTmp {
+		defer os.Remove(filename)
+	}
+
+	err = writePayload(ctx, f, projectID, payload)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.store.Save(filename); err != nil {
+		return "", err
+	}
+
+	return s.store.Location(filename), nil
+}
+
+// location is the reference of the payload saved as name.
+func (s *Store) location(projectID, name string) string {
+	return s.store.Location(filepath.Join(s.dir, projectID, name))
+}
+
+// writePayload writes payload to w, encrypted a segment per line when the
+// project encrypts its payloads.
+func writePayload(ctx context.Context, w io.Writer, projectID string, payload []byte) error {
+	k := encryption.Get()
+	optedIn, err := k.OptedIn(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !optedIn {
+		_, err = w.Write(payload)
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for len(payload) > 0 {
+		n := min(segmentSize, len(payload))
+
+		segment, err := k.Encrypt(ctx, projectID, payload[:n])
+		if err != nil {
+			return err
+		}
+
+		if _, err = bw.Write(segment); err != nil {
+			return err
+		}
+
+		if err = bw.WriteByte('\n'); err != nil {
+			return err
+		}
+
+		payload = payload[n:]
+	}
+
+	return bw.Flush()
+}
+
+// Open streams the payload saved at reference, encrypted payloads are
+// decrypted a segment at a time.
+func (s *Store) Open(ctx context.Context, projectID, reference string) (io.ReadCloser, error) {
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+
+	rc, err := s.store.Open(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(rc, segmentSize)
+	prefix, err := br.Peek(len(encryption.Prefix))
+	if err != nil && !errors.Is(err, io.EOF) {
+		rc.Close()
+		return nil, err
+	}
+
+	if !encryption.IsEncrypted(string(prefix)) {
+		return &payloadReader{Reader: br, closer: rc}, nil
+	}
+
+	return &payloadReader{
+		Reader: &decryptReader{ctx: ctx, projectID: projectID, k: encryption.Get(), segments: br},
+		closer: rc,
+	}, nil
+}
+
+// Load reads the payload saved at reference.
+func (s *Store) Load(ctx context.Context, projectID, reference string) ([]byte, error) {
+	r, err := s.Open(ctx, projectID, reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open offloaded payload %s: %w", reference, err)
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	if _, err = buf.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("failed to read offloaded payload %s: %w", reference, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Delete removes the payload saved at reference.
+func (s *Store) Delete(_ context.Context, reference string) error {
+	if s == nil {
+		return ErrNotConfigured
+	}
+
+	return s.store.Delete(reference)
+}
+
+// Prune removes the project's payloads that were saved for the events and
+// deliveries created before t, and returns how many were removed.
+func (s *Store) Prune(_ context.Context, projectID string, t time.Time) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	locations, err := s.store.List(filepath.Join(s.dir, projectID))
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+	for _, location := range locations {
+		id, err := ulid.ParseStrict(filepath.Base(location))
+		if err != nil || !ulid.Time(id.Time()).Before(t) {
+			continue
+		}
+
+		if err = s.store.Delete(location); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// OffloadIngest saves the payload of an event that's being ingested when
+// it's over the threshold and returns its reference, the queue job that
+// creates the event carries the reference instead. It returns an empty
+// reference when the payload is kept in the job.
+func (s *Store) OffloadIngest(ctx context.Context, projectID, eventID string, payload []byte) (string, error) {
+	if !s.shouldOffload(payload) {
+		return "", nil
+	}
+
+	return s.Save(ctx, projectID, filepath.Join("ingest", eventID), payload)
+}
+
+// OffloadEvent saves the event's payload to the object store when it's
+// over the threshold and sets its reference, the event keeps its payload.
+func (s *Store) OffloadEvent(ctx context.Context, e *datastore.Event) error {
+	payload := eventPayload(e)
+	if len(e.PayloadReference) > 0 || !s.shouldOffload(payload) {
+		return nil
+	}
+
+	reference, err := s.Save(ctx, e.ProjectID, filepath.Join("events", e.UID), payload)
+	if err != nil {
+		return err
+	}
+
+	e.PayloadReference = reference
+	return nil
+}
+
+// OffloadDelivery is OffloadEvent for deliveries, a delivery with the same
+// payload as its offloaded event gets the event's reference.
+func (s *Store) OffloadDelivery(ctx context.Context, event *datastore.Event, ed *datastore.EventDelivery) error {
+	m := ed.Metadata
+	if m == nil || len(m.PayloadReference) > 0 {
+		return nil
+	}
+
+	payload := deliveryPayload(m)
+	if !s.shouldOffload(payload) {
+		return nil
+	}
+
+	if event != nil && len(event.PayloadReference) > 0 && bytes.Equal(payload, eventPayload(event)) {
+		m.PayloadReference = event.PayloadReference
+		return nil
+	}
+
+	reference, err := s.Save(ctx, ed.ProjectID, filepath.Join("deliveries", ed.UID), payload)
+	if err != nil {
+		return err
+	}
+
+	m.PayloadReference = reference
+	return nil
+}
+
+// ReplaceDelivery saves the delivery's payload again after it was changed
+// e.g. redacted, the delivery's own object is overwritten or removed when
+// the payload is no longer over the threshold. An object shared with its
+// event is left as it is.
+func (s *Store) ReplaceDelivery(ctx context.Context, ed *datastore.EventDelivery) error {
+	m := ed.Metadata
+	if m == nil || len(m.PayloadReference) == 0 {
+		return nil
+	}
+
+	name := filepath.Join("deliveries", ed.UID)
+	previous := m.PayloadReference
+	m.PayloadReference = ""
+
+	err := s.OffloadDelivery(ctx, nil, ed)
+	if err != nil {
+		m.PayloadReference = previous
+		return err
+	}
+
+	if len(m.PayloadReference) > 0 || previous != s.location(ed.ProjectID, name) {
+		return nil
+	}
+
+	return s.Delete(ctx, previous)
+}
+
+// LoadEvent sets the payload of an offloaded event.
+func (s *Store) LoadEvent(ctx context.Context, e *datastore.Event) error {
+	if len(e.PayloadReference) == 0 {
+		return nil
+	}
+
+	payload, err := s.Load(ctx, e.ProjectID, e.PayloadReference)
+	if err != nil {
+		return err
+	}
+
+	e.Data, e.Raw = payload, string(payload)
+	return nil
+}
+
+// LoadDelivery sets the payload of an offloaded delivery.
+func (s *Store) LoadDelivery(ctx context.Context, ed *datastore.EventDelivery) error {
+	if ed.Metadata == nil || len(ed.Metadata.PayloadReference) == 0 {
+		return nil
+	}
+
+	payload, err := s.Load(ctx, ed.ProjectID, ed.Metadata.PayloadReference)
+	if err != nil {
+		return err
+	}
+
+	ed.Metadata.Data, ed.Metadata.Raw = payload, string(payload)
+	return nil
+}
+
+// DeliveryData returns the delivery's data, read from the object store
+// when it was offloaded.
+func (s *Store) DeliveryData(ctx context.Context, projectID string, m *datastore.Metadata) (json.RawMessage, error) {
+	if len(m.PayloadReference) == 0 {
+		return m.Data, nil
+	}
+
+	return s.Load(ctx, projectID, m.PayloadReference)
+}
+
+// DeliveryRaw is DeliveryData for the delivery's raw payload.
+func (s *Store) DeliveryRaw(ctx context.Context, projectID string, m *datastore.Metadata) (string, error) {
+	if len(m.PayloadReference) == 0 {
+		return m.Raw, nil
+	}
+
+	payload, err := s.Load(ctx, projectID, m.PayloadReference)
+	return string(payload), err
+}
+
+// raw is empty for some events and deliveries, their data is saved then.
+func eventPayload(e *datastore.Event) []byte {
+	if len(e.Raw) > 0 {
+		return []byte(e.Raw)
+	}
+	return e.Data
+}
+
+func deliveryPayload(m *datastore.Metadata) []byte {
+	if len(m.Raw) > 0 {
+		return []byte(m.Raw)
+	}
+	return m.Data
+}
+
+type payloadReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (p *payloadReader) Close() error {
+	return p.closer.Close()
+}
+
+// decryptReader decrypts the segments of an encrypted payload as they're
+// read.
+type decryptReader struct {
+	ctx       context.Context
+	projectID string
+	k         *encryption.Keyring
+	segments  *bufio.Reader
+	buf       []byte
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		line, err := d.segments.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return 0, err
+		}
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		d.buf, err = d.k.Decrypt(d.ctx, d.projectID, bytes.TrimSuffix(line, []byte("\n")))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
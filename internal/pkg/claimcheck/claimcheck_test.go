@@ -0,0 +1,294 @@
+package claimcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	"github.com/frain-dev/convoy/mocks"
+)
+
+func newTestStore(t *testing.T, enabled bool) *Store {
+	t.Helper()
+
+	policy := &datastore.StoragePolicyConfiguration{
+		Type:   datastore.OnPrem,
+		OnPrem: &datastore.OnPremStorage{Path: null.StringFrom(t.TempDir())},
+	}
+
+	s, err := New(config.PayloadOffloadConfiguration{Enabled: enabled, Threshold: 16}, policy)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(config.PayloadOffloadConfiguration{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	_, err = New(config.PayloadOffloadConfiguration{Enabled: true}, nil)
+	require.Error(t, err)
+
+	s = newTestStore(t, false)
+	require.NotNil(t, s)
+	require.False(t, s.Enabled())
+}
+
+func TestStore_OffloadEvent(t *testing.T) {
+	s := newTestStore(t, true)
+	ctx := context.Background()
+
+	small := &datastore.Event{UID: "event-1", ProjectID: "project-1", Raw: `{"a":1}`, Data: []byte(`{"a":1}`)}
+	require.NoError(t, s.OffloadEvent(ctx, small))
+	require.Empty(t, small.PayloadReference)
+
+	payload := `{"name":"a large enough payload"}`
+	event := &datastore.Event{UID: "event-2", ProjectID: "project-1", Raw: payload, Data: []byte(payload)}
+	require.NoError(t, s.OffloadEvent(ctx, event))
+	require.NotEmpty(t, event.PayloadReference)
+
+	// the event keeps its payload
+	require.Equal(t, payload, event.Raw)
+
+	saved, err := os.ReadFile(event.PayloadReference)
+	require.NoError(t, err)
+	require.Equal(t, payload, string(saved))
+
+	info, err := os.Stat(event.PayloadReference)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded := &datastore.Event{PayloadReference: event.PayloadReference}
+	require.NoError(t, s.LoadEvent(ctx, loaded))
+	require.Equal(t, payload, loaded.Raw)
+	require.Equal(t, payload, string(loaded.Data))
+}
+
+func TestStore_OffloadDelivery(t *testing.T) {
+	s := newTestStore(t, true)
+	ctx := context.Background()
+
+	payload := `{"name":"a large enough payload"}`
+	event := &datastore.Event{UID: "event-1", ProjectID: "project-1", Raw: payload, Data: []byte(payload)}
+	require.NoError(t, s.OffloadEvent(ctx, event))
+
+	same := &datastore.EventDelivery{UID: "delivery-1", ProjectID: "project-1", Metadata: &datastore.Metadata{Raw: payload, Data: []byte(payload)}}
+	require.NoError(t, s.OffloadDelivery(ctx, event, same))
+	require.Equal(t, event.PayloadReference, same.Metadata.PayloadReference)
+
+	transformed := `{"name":"a transformed payload"}`
+	other := &datastore.EventDelivery{UID: "delivery-2", ProjectID: "project-1", Metadata: &datastore.Metadata{Raw: transformed, Data: []byte(transformed)}}
+	require.NoError(t, s.OffloadDelivery(ctx, event, other))
+	require.NotEmpty(t, other.Metadata.PayloadReference)
+	require.NotEqual(t, event.PayloadReference, other.Metadata.PayloadReference)
+
+	stored := &datastore.Metadata{PayloadReference: other.Metadata.PayloadReference}
+	raw, err := s.DeliveryRaw(ctx, "project-1", stored)
+	require.NoError(t, err)
+	require.Equal(t, transformed, raw)
+
+	data, err := s.DeliveryData(ctx, "project-1", stored)
+	require.NoError(t, err)
+	require.Equal(t, transformed, string(data))
+
+	// the payload isn't saved with the delivery's metadata
+	value, err := other.Metadata.Value()
+	require.NoError(t, err)
+	require.NotContains(t, string(value.([]byte)), "transformed")
+}
+
+func TestStore_ReplaceDelivery(t *testing.T) {
+	s := newTestStore(t, true)
+	ctx := context.Background()
+
+	payload := `{"email":"jane@example.com"}`
+	ed := &datastore.EventDelivery{UID: "delivery-1", ProjectID: "project-1", Metadata: &datastore.Metadata{Raw: payload, Data: []byte(payload)}}
+	require.NoError(t, s.OffloadDelivery(ctx, nil, ed))
+	reference := ed.Metadata.PayloadReference
+	require.NotEmpty(t, reference)
+
+	// the object is overwritten while the payload is over the threshold
+	redacted := `{"email":"[REDACTED]"}`
+	ed.Metadata.Raw, ed.Metadata.Data = redacted, []byte(redacted)
+	require.NoError(t, s.ReplaceDelivery(ctx, ed))
+	require.Equal(t, reference, ed.Metadata.PayloadReference)
+
+	saved, err := os.ReadFile(reference)
+	require.NoError(t, err)
+	require.Equal(t, redacted, string(saved))
+
+	// and removed once it's kept with the delivery
+	ed.Metadata.Raw, ed.Metadata.Data = `{}`, []byte(`{}`)
+	require.NoError(t, s.ReplaceDelivery(ctx, ed))
+	require.Empty(t, ed.Metadata.PayloadReference)
+	require.NoFileExists(t, reference)
+
+	// an object shared with the event is kept
+	event := &datastore.Event{UID: "event-1", ProjectID: "project-1", Raw: payload, Data: []byte(payload)}
+	require.NoError(t, s.OffloadEvent(ctx, event))
+
+	shared := &datastore.EventDelivery{UID: "delivery-2", ProjectID: "project-1", Metadata: &datastore.Metadata{Raw: payload, Data: []byte(payload)}}
+	require.NoError(t, s.OffloadDelivery(ctx, event, shared))
+	require.Equal(t, event.PayloadReference, shared.Metadata.PayloadReference)
+
+	shared.Metadata.Raw, shared.Metadata.Data = `{}`, []byte(`{}`)
+	require.NoError(t, s.ReplaceDelivery(ctx, shared))
+	require.Empty(t, shared.Metadata.PayloadReference)
+	require.FileExists(t, event.PayloadReference)
+}
+
+func TestStore_Disabled(t *testing.T) {
+	ctx := context.Background()
+	payload := `{"name":"a large enough payload"}`
+
+	enabled := newTestStore(t, true)
+	event := &datastore.Event{UID: "event-1", ProjectID: "project-1", Raw: payload}
+	require.NoError(t, enabled.OffloadEvent(ctx, event))
+
+	// offloaded payloads can still be read once offloading is turned off
+	disabled := &Store{store: enabled.store, dir: enabled.dir}
+
+	other := &datastore.Event{UID: "event-2", ProjectID: "project-1", Raw: payload}
+	require.NoError(t, disabled.OffloadEvent(ctx, other))
+	require.Empty(t, other.PayloadReference)
+
+	loaded := &datastore.Event{PayloadReference: event.PayloadReference}
+	require.NoError(t, disabled.LoadEvent(ctx, loaded))
+	require.Equal(t, payload, loaded.Raw)
+
+	var nilStore *Store
+	require.NoError(t, nilStore.OffloadEvent(ctx, other))
+	require.ErrorIs(t, nilStore.LoadEvent(ctx, loaded), ErrNotConfigured)
+}
+
+// initEncryption sets a keyring where only project-1 encrypts its
+// payloads.
+func initEncryption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	keys := map[string]*datastore.DataKey{}
+	repo := mocks.NewMockDataKeyRepository(ctrl)
+	repo.EXPECT().FindActiveDataKey(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, projectID string) (*datastore.DataKey, error) {
+		for _, k := range keys {
+			if k.ProjectID == projectID {
+				return k, nil
+			}
+		}
+		return nil, datastore.ErrDataKeyNotFound
+	})
+	repo.EXPECT().CreateDataKey(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, k *datastore.DataKey) error {
+		keys[k.UID] = k
+		return nil
+	})
+	repo.EXPECT().FindDataKeyByID(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, _, id string) (*datastore.DataKey, error) {
+		return keys[id], nil
+	})
+
+	projects := mocks.NewMockProjectRepository(ctrl)
+	projects.EXPECT().FetchProjectByID(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, id string) (*datastore.Project, error) {
+		return &datastore.Project{UID: id, Config: &datastore.ProjectConfig{EncryptPayloads: id == "project-1"}}, nil
+	})
+
+	_, err = encryption.Init(config.EncryptionConfiguration{Enabled: true, Key: base64.StdEncoding.EncodeToString(key)}, repo, projects)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = encryption.Init(config.EncryptionConfiguration{}, nil, nil)
+	})
+}
+
+func TestStore_Encrypted(t *testing.T) {
+	initEncryption(t)
+
+	s := newTestStore(t, true)
+	ctx := context.Background()
+
+	// spans three segments
+	payload := bytes.Repeat([]byte(`{"email":"user@example.com"}`), 2*segmentSize/28+1)
+
+	reference, err := s.Save(ctx, "project-1", "events/event-1", payload)
+	require.NoError(t, err)
+
+	saved, err := os.ReadFile(reference)
+	require.NoError(t, err)
+	require.NotContains(t, string(saved), "user@example.com")
+	require.Len(t, strings.Split(strings.TrimSuffix(string(saved), "\n"), "\n"), 3)
+
+	loaded, err := s.Load(ctx, "project-1", reference)
+	require.NoError(t, err)
+	require.Equal(t, payload, loaded)
+
+	// the payloads of projects that didn't opt in are saved as they are
+	reference, err = s.Save(ctx, "project-2", "events/event-2", payload)
+	require.NoError(t, err)
+
+	saved, err = os.ReadFile(reference)
+	require.NoError(t, err)
+	require.Equal(t, payload, saved)
+}
+
+func TestStore_Prune(t *testing.T) {
+	s := newTestStore(t, true)
+	ctx := context.Background()
+	now := time.Now()
+
+	expired := ulid.MustNew(ulid.Timestamp(now.Add(-48*time.Hour)), rand.Reader).String()
+	kept := ulid.MustNew(ulid.Timestamp(now), rand.Reader).String()
+
+	var references []string
+	for _, name := range []string{filepath.Join("events", expired), filepath.Join("ingest", expired), filepath.Join("deliveries", kept)} {
+		reference, err := s.Save(ctx, "project-1", name, []byte(`{"id":1}`))
+		require.NoError(t, err)
+		references = append(references, reference)
+	}
+
+	removed, err := s.Prune(ctx, "project-1", now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+
+	for _, reference := range references[:2] {
+		require.NoFileExists(t, reference)
+	}
+	require.FileExists(t, references[2])
+
+	// projects without payloads have nothing to prune
+	removed, err = s.Prune(ctx, "project-2", now)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
+
+func TestStore_OffloadIngest(t *testing.T) {
+	s := newTestStore(t, true)
+	ctx := context.Background()
+
+	reference, err := s.OffloadIngest(ctx, "project-1", "event-1", []byte(`{"a":1}`))
+	require.NoError(t, err)
+	require.Empty(t, reference)
+
+	payload := []byte(`{"name":"a large enough payload"}`)
+	reference, err = s.OffloadIngest(ctx, "project-1", "event-1", payload)
+	require.NoError(t, err)
+	require.Contains(t, reference, filepath.Join("ingest", "event-1"))
+
+	require.NoError(t, s.Delete(ctx, reference))
+	require.NoFileExists(t, reference)
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
 
 	"github.com/frain-dev/convoy/cache"
@@ -20,6 +21,7 @@ type App struct {
 	Logger  log.StdLogger
 	Cache   cache.Cache
 	Rate    limiter.RateLimiter
+	Dedup   dedup.Store
 
 	// TODO(subomi): Let's make this cleaner.
 	TracerShutdown func(context.Context) error
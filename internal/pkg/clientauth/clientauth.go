@@ -0,0 +1,208 @@
+// Package clientauth restricts which clients may send events to an ingest
+// source, either by the client's ip address or by the certificate it
+// presents during the TLS handshake.
+package clientauth
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ForwardedClientCertHeader carries the url escaped PEM encoded client
+// certificate when TLS is terminated by a trusted proxy, e.g. nginx's
+// $ssl_client_escaped_cert.
+const ForwardedClientCertHeader = "X-Forwarded-Client-Cert"
+
+var (
+	ErrIPNotAllowed        = errors.New("client ip address is not allowed")
+	ErrClientCertRequired  = errors.New("a client certificate is required")
+	ErrInvalidClientCert   = errors.New("client certificate is invalid")
+	ErrInvalidCertificates = errors.New("no valid PEM encoded certificates found")
+)
+
+// ParseCIDRs parses a list of ip addresses and CIDR blocks, a bare ip
+// address is treated as a single host network.
+func ParseCIDRs(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address: %s", s)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr block: %s", s)
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return net.ParseIP(host)
+}
+
+// ClientIP returns the address of the client that sent r. X-Forwarded-For is
+// only honoured when the request came through a trusted proxy, in which case
+// the header is walked from right to left and the first address that isn't a
+// trusted proxy is the client.
+func ClientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	ip := remoteIP(r)
+	if ip == nil || !contains(trustedProxies, ip) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// a malformed entry means we can't trust anything to its left
+			break
+		}
+
+		ip = hop
+		if !contains(trustedProxies, hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// VerifyIP checks the client's address against the allowlist, an empty
+// allowlist allows every address.
+func VerifyIP(r *http.Request, allowlist, trustedProxies []*net.IPNet) error {
+	if len(allowlist) == 0 {
+		return nil
+	}
+
+	ip := ClientIP(r, trustedProxies)
+	if ip == nil || !contains(allowlist, ip) {
+		return ErrIPNotAllowed
+	}
+
+	return nil
+}
+
+// ParseCertPool parses one or more PEM encoded CA certificates.
+func ParseCertPool(caPEM string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+		return nil, ErrInvalidCertificates
+	}
+
+	return pool, nil
+}
+
+// VerifyClientCert checks that the client presented a certificate issued by
+// one of the CAs in pool. The certificate is read from the TLS connection or,
+// for requests from a trusted proxy, from ForwardedClientCertHeader.
+func VerifyClientCert(r *http.Request, pool *x509.CertPool, trustedProxies []*net.IPNet) error {
+	certs, err := clientCerts(r, trustedProxies)
+	if err != nil {
+		return err
+	}
+
+	if len(certs) == 0 {
+		return ErrClientCertRequired
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidClientCert, err)
+	}
+
+	return nil
+}
+
+func clientCerts(r *http.Request, trustedProxies []*net.IPNet) ([]*x509.Certificate, error) {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return r.TLS.PeerCertificates, nil
+	}
+
+	header := r.Header.Get(ForwardedClientCertHeader)
+	if len(header) == 0 {
+		return nil, nil
+	}
+
+	ip := remoteIP(r)
+	if ip == nil || !contains(trustedProxies, ip) {
+		// anyone can set the header, only proxies we trust get to vouch for a client
+		return nil, nil
+	}
+
+	raw, err := url.QueryUnescape(header)
+	if err != nil {
+		return nil, ErrInvalidClientCert
+	}
+
+	cert, err := ParseCertificate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*x509.Certificate{cert}, nil
+}
+
+// ParseCertificate parses the first certificate in a PEM encoded string.
+func ParseCertificate(certPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrInvalidClientCert
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidClientCert
+	}
+
+	return cert, nil
+}
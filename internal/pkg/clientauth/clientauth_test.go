@@ -0,0 +1,216 @@
+package clientauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ClientIP(t *testing.T) {
+	trusted, err := ParseCIDRs([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		remoteAddr    string
+		forwardedFor  []string
+		expectedIP    string
+		allowlist     []string
+		expectedError error
+	}{
+		"direct_request": {
+			remoteAddr: "203.0.113.7:4321",
+			expectedIP: "203.0.113.7",
+		},
+		"forwarded_for_ignored_from_untrusted_peer": {
+			remoteAddr:   "203.0.113.7:4321",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "203.0.113.7",
+		},
+		"forwarded_for_from_trusted_proxy": {
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "198.51.100.1",
+		},
+		"spoofed_leftmost_hop_is_skipped": {
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"1.1.1.1, 198.51.100.1, 192.168.1.1"},
+			expectedIP:   "198.51.100.1",
+		},
+		"multiple_headers": {
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"1.1.1.1", "198.51.100.1"},
+			expectedIP:   "198.51.100.1",
+		},
+		"allowed_by_cidr": {
+			remoteAddr: "203.0.113.7:4321",
+			expectedIP: "203.0.113.7",
+			allowlist:  []string{"203.0.113.0/24"},
+		},
+		"denied_by_cidr": {
+			remoteAddr:    "10.0.0.2:4321",
+			forwardedFor:  []string{"198.51.100.1"},
+			expectedIP:    "198.51.100.1",
+			allowlist:     []string{"203.0.113.0/24", "10.0.0.2"},
+			expectedError: ErrIPNotAllowed,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			req, err := http.NewRequest(http.MethodPost, "/ingest/abc", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.forwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+
+			allowlist, err := ParseCIDRs(tc.allowlist)
+			require.NoError(t, err)
+
+			// Act.
+			ip := ClientIP(req, trusted)
+			err = VerifyIP(req, allowlist, trusted)
+
+			// Assert.
+			require.Equal(t, tc.expectedIP, ip.String())
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func Test_ParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs([]string{"10.0.0.0/8", " 2001:db8::1 ", ""})
+	require.NoError(t, err)
+	require.Len(t, nets, 2)
+
+	_, err = ParseCIDRs([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+
+	_, err = ParseCIDRs([]string{"not-an-ip"})
+	require.Error(t, err)
+}
+
+func Test_VerifyClientCert(t *testing.T) {
+	caCert, caKey := newCA(t, "convoy-test-ca")
+	otherCA, otherKey := newCA(t, "other-ca")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	trusted, err := ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	clientCert := newClientCert(t, caCert, caKey)
+	foreignCert := newClientCert(t, otherCA, otherKey)
+
+	tests := map[string]struct {
+		remoteAddr    string
+		tlsCerts      []*x509.Certificate
+		header        *x509.Certificate
+		expectedError error
+	}{
+		"valid_tls_certificate": {
+			remoteAddr: "203.0.113.7:4321",
+			tlsCerts:   []*x509.Certificate{clientCert},
+		},
+		"certificate_from_unknown_ca": {
+			remoteAddr:    "203.0.113.7:4321",
+			tlsCerts:      []*x509.Certificate{foreignCert},
+			expectedError: ErrInvalidClientCert,
+		},
+		"missing_certificate": {
+			remoteAddr:    "203.0.113.7:4321",
+			expectedError: ErrClientCertRequired,
+		},
+		"forwarded_certificate_from_trusted_proxy": {
+			remoteAddr: "10.0.0.2:4321",
+			header:     clientCert,
+		},
+		"forwarded_certificate_from_untrusted_peer": {
+			remoteAddr:    "203.0.113.7:4321",
+			header:        clientCert,
+			expectedError: ErrClientCertRequired,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			req, err := http.NewRequest(http.MethodPost, "/ingest/abc", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = tc.remoteAddr
+
+			if tc.tlsCerts != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: tc.tlsCerts}
+			}
+
+			if tc.header != nil {
+				b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tc.header.Raw})
+				req.Header.Set(ForwardedClientCertHeader, url.QueryEscape(string(b)))
+			}
+
+			// Act.
+			err = VerifyClientCert(req, pool, trusted)
+
+			// Assert.
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func newCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func newClientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "partner"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
@@ -6,13 +6,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"github.com/frain-dev/convoy/datastore"
 	"github.com/tidwall/gjson"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Idempotency interface {
@@ -21,13 +21,14 @@ type Idempotency interface {
 }
 
 type DeDuper struct {
-	ctx       context.Context
-	request   *http.Request
-	eventRepo datastore.EventRepository
+	ctx     context.Context
+	request *http.Request
+	store   Store
+	window  time.Duration
 }
 
-func NewDeDuper(ctx context.Context, request *http.Request, eventRepo datastore.EventRepository) *DeDuper {
-	return &DeDuper{ctx, request, eventRepo}
+func NewDeDuper(ctx context.Context, request *http.Request, store Store, window time.Duration) *DeDuper {
+	return &DeDuper{ctx, request, store, window}
 }
 
 // GenerateChecksum generates a checksum using the provided request input fields
@@ -49,6 +50,8 @@ func (d *DeDuper) GenerateChecksum(source string, input []string) (string, error
 	return checksum, nil
 }
 
+// Exists reports whether the request's checksum was seen within the dedup
+// window, the checksum is recorded so later requests are found.
 func (d *DeDuper) Exists(source, projectId string, input []string) (bool, error) {
 	// extract data from the request
 	parts, err := d.extractDataFromRequest(input)
@@ -64,12 +67,8 @@ func (d *DeDuper) Exists(source, projectId string, input []string) (bool, error)
 	}
 
 	checksum := GenerateChecksum(builder.String())
-	events, err := d.eventRepo.FindEventsByIdempotencyKey(d.ctx, projectId, checksum)
-	if err != nil {
-		return false, err
-	}
 
-	return len(events) > 0, nil
+	return d.store.Seen(d.ctx, Key(projectId, checksum), d.window)
 }
 
 func (d *DeDuper) extractDataFromRequest(input []string) ([]interface{}, error) {
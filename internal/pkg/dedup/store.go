@@ -0,0 +1,82 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/internal/pkg/rdb"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultWindow is how long idempotency keys are remembered for sources
+// without a dedup window.
+const DefaultWindow = 24 * time.Hour
+
+// DuplicateEventHeader is set on the ingest response when the event's
+// idempotency key was seen within the source's dedup window.
+const DuplicateEventHeader = "X-Convoy-Duplicate-Event"
+
+// Store remembers idempotency keys for a window of time.
+type Store interface {
+	// Seen records key for window and reports whether it was already
+	// recorded within the window.
+	Seen(ctx context.Context, key string, window time.Duration) (bool, error)
+
+	// Forget removes key, it is used when an event couldn't be accepted
+	// after its key was recorded so a retry isn't treated as a duplicate.
+	Forget(ctx context.Context, key string) error
+}
+
+func NewStore(cfg config.Configuration) (Store, error) {
+	s, err := NewRedisStore(cfg.Redis.BuildDsn())
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// RedisStore keeps each idempotency key in redis, the key expires at the
+// end of its window.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(addresses []string) (*RedisStore, error) {
+	client, err := rdb.NewClient(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisStore{client: client.Client()}, nil
+}
+
+func (r *RedisStore) Seen(ctx context.Context, key string, window time.Duration) (bool, error) {
+	recorded, err := r.client.SetNX(ctx, key, 1, window).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return !recorded, nil
+}
+
+func (r *RedisStore) Forget(ctx context.Context, key string) error {
+	return r.client.Del(ctx, key).Err()
+}
+
+// Key scopes an idempotency key to a project.
+func Key(projectID, idempotencyKey string) string {
+	return fmt.Sprintf("dedup:%s:%s", projectID, idempotencyKey)
+}
+
+// Window returns a source's dedup window given in seconds, the default
+// window is used when it isn't set.
+func Window(seconds uint64) time.Duration {
+	if seconds == 0 {
+		return DefaultWindow
+	}
+
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package dedup
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func getDSN() []string {
+	port, _ := strconv.Atoi(os.Getenv("TEST_REDIS_PORT"))
+	c := config.RedisConfiguration{
+		Scheme: "redis",
+		Host:   os.Getenv("TEST_REDIS_HOST"),
+		Port:   port,
+	}
+	return c.BuildDsn()
+}
+
+func Test_RedisStore_Seen(t *testing.T) {
+	store, err := NewRedisStore(getDSN())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := Key(ulid.Make().String(), "evt-1")
+
+	seen, err := store.Seen(ctx, key, time.Second)
+	require.NoError(t, err)
+	require.False(t, seen)
+
+	seen, err = store.Seen(ctx, key, time.Second)
+	require.NoError(t, err)
+	require.True(t, seen)
+
+	require.NoError(t, store.Forget(ctx, key))
+
+	seen, err = store.Seen(ctx, key, time.Second)
+	require.NoError(t, err)
+	require.False(t, seen)
+
+	// the key is forgotten once the window has passed
+	time.Sleep(1100 * time.Millisecond)
+
+	seen, err = store.Seen(ctx, key, time.Second)
+	require.NoError(t, err)
+	require.False(t, seen)
+}
@@ -0,0 +1,525 @@
+// Package encryption encrypts event payloads at rest with per-project data
+// keys, which are stored wrapped by a master key.
+//
+// Payloads are encrypted for the projects that opt in with their
+// encrypt_payloads setting. The postgres repositories encrypt an event's
+// data, raw and original body, a delivery's data and raw, and the response
+// bodies and the request and response header values of its attempts when
+// they're saved, and decrypt them when they're loaded. Since the database
+// only sees ciphertext:
+//
+//   - searching events doesn't match encrypted events, as the search
+//     tokens are built from the raw body.
+//   - filters that look into payloads in SQL don't match encrypted rows.
+//   - exported events and deliveries hold the ciphertext, which can only
+//     be decrypted while the project's data keys are kept.
+//   - meta events are sent from the decrypted delivery and aren't
+//     encrypted.
+//
+// Rotating a project's data key or turning encryption on or off, for the
+// instance or the project, leaves existing rows as they are until the
+// re-encryption job moves them to the active key.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/memorystore"
+	"github.com/oklog/ulid/v2"
+)
+
+// Prefix marks an encrypted value, it is followed by the id of the data
+// key and the base64 encoded nonce and ciphertext.
+const Prefix = "enc:v1:"
+
+const keySize = 32
+
+// activeKeyTTL is how long a project's active data key is cached, so
+// rotations made through other instances are picked up.
+const activeKeyTTL = 30 * time.Second
+
+const (
+	activeKeysPrefix = "data_keys"
+	optedInPrefix    = "encrypt_payloads"
+)
+
+var (
+	ErrNotConfigured  = errors.New("payload is encrypted but no encryption key is configured")
+	ErrInvalidKey     = errors.New("encryption key must be 32 bytes encoded in base64")
+	ErrUnknownKek     = errors.New("data key is wrapped by an unknown master key")
+	ErrMalformedValue = errors.New("malformed encrypted value")
+)
+
+type cachedOptIn struct {
+	optedIn   bool
+	expiresAt time.Time
+}
+
+type cachedKey struct {
+	id        string
+	aead      cipher.AEAD
+	expiresAt time.Time
+}
+
+// Keyring encrypts and decrypts a project's payloads. A nil Keyring
+// leaves plaintext as it is and fails to decrypt.
+type Keyring struct {
+	// encrypt is false when encryption is disabled but a master key is
+	// still configured, so existing payloads can be decrypted.
+	encrypt bool
+
+	kekID string
+	keks  map[string]cipher.AEAD
+
+	repo     datastore.DataKeyRepository
+	projects datastore.ProjectRepository
+
+	// active caches the projects' active data keys and whether they
+	// opted in.
+	active *memorystore.Table
+
+	// data keys don't change once created, so they're cached by id
+	// for as long as the process runs.
+	keys sync.Map
+}
+
+// NewKeyring returns nil if encryption is disabled and no master key is
+// configured.
+func NewKeyring(cfg config.EncryptionConfiguration, repo datastore.DataKeyRepository, projects datastore.ProjectRepository) (*Keyring, error) {
+	kek, err := loadKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if kek == nil {
+		if cfg.Enabled {
+			return nil, errors.New("encryption is enabled but neither a key nor a key file is configured")
+		}
+		return nil, nil
+	}
+
+	k := &Keyring{
+		encrypt:  cfg.Enabled,
+		keks:     map[string]cipher.AEAD{},
+		repo:     repo,
+		projects: projects,
+		active:   memorystore.NewTable(),
+	}
+
+	k.kekID, err = k.addKek(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, previous := range cfg.PreviousKeys {
+		key, err := decodeKey(previous)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = k.addKek(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+var defaultKeyring atomic.Value
+
+// Init sets the keyring the repositories encrypt payloads with.
+func Init(cfg config.EncryptionConfiguration, repo datastore.DataKeyRepository, projects datastore.ProjectRepository) (*Keyring, error) {
+	k, err := NewKeyring(cfg, repo, projects)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultKeyring.Store(k)
+	return k, nil
+}
+
+// Get returns the keyring set with Init, or nil if Init wasn't called or
+// encryption isn't configured.
+func Get() *Keyring {
+	k, _ := defaultKeyring.Load().(*Keyring)
+	return k
+}
+
+// IsEncrypted reports whether value was encrypted by a Keyring.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Enabled reports whether new payloads of the projects that opted in are
+// encrypted.
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.encrypt
+}
+
+// OptedIn reports whether the project's new payloads are encrypted.
+func (k *Keyring) OptedIn(ctx context.Context, projectID string) (bool, error) {
+	if !k.Enabled() {
+		return false, nil
+	}
+
+	cacheKey := memorystore.NewKey(optedInPrefix, projectID)
+	if row := k.active.Get(cacheKey); row != nil {
+		if c, ok := row.Value().(*cachedOptIn); ok && time.Now().Before(c.expiresAt) {
+			return c.optedIn, nil
+		}
+	}
+
+	project, err := k.projects.FetchProjectByID(ctx, projectID)
+	if err != nil && !errors.Is(err, datastore.ErrProjectNotFound) {
+		return false, err
+	}
+
+	optedIn := project != nil && project.Config != nil && project.Config.EncryptPayloads
+	k.active.Upsert(cacheKey, &cachedOptIn{optedIn: optedIn, expiresAt: time.Now().Add(activeKeyTTL)})
+
+	return optedIn, nil
+}
+
+// ActivePrefix returns the prefix of values encrypted with the project's
+// active data key, it is empty when the project's payloads aren't
+// encrypted. Only projects that opted in get a data key.
+func (k *Keyring) ActivePrefix(ctx context.Context, projectID string) (string, error) {
+	optedIn, err := k.OptedIn(ctx, projectID)
+	if err != nil || !optedIn {
+		return "", err
+	}
+
+	key, err := k.activeKey(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	return Prefix + key.id + ":", nil
+}
+
+// Encrypt encrypts plaintext with the project's active data key, creating
+// one if the project doesn't have one yet. Empty and already encrypted
+// values, and the values of projects that didn't opt in, are returned as
+// they are.
+func (k *Keyring) Encrypt(ctx context.Context, projectID string, plaintext []byte) ([]byte, error) {
+	if !k.Enabled() || len(plaintext) == 0 || IsEncrypted(string(plaintext)) {
+		return plaintext, nil
+	}
+
+	optedIn, err := k.OptedIn(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !optedIn {
+		return plaintext, nil
+	}
+
+	key, err := k.activeKey(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := key.aead.Seal(nonce, nonce, plaintext, []byte(projectID))
+	return []byte(Prefix + key.id + ":" + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func (k *Keyring) EncryptString(ctx context.Context, projectID string, plaintext string) (string, error) {
+	b, err := k.Encrypt(ctx, projectID, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Decrypt decrypts value with the data key it was encrypted with, values
+// that aren't encrypted are returned as they are.
+func (k *Keyring) Decrypt(ctx context.Context, projectID string, value []byte) ([]byte, error) {
+	if !IsEncrypted(string(value)) {
+		return value, nil
+	}
+
+	if k == nil {
+		return nil, ErrNotConfigured
+	}
+
+	id, encoded, ok := strings.Cut(string(value[len(Prefix):]), ":")
+	if !ok {
+		return nil, ErrMalformedValue
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformedValue
+	}
+
+	aead, err := k.dataKey(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedValue
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload with data key %s: %v", id, err)
+	}
+
+	return plaintext, nil
+}
+
+func (k *Keyring) DecryptString(ctx context.Context, projectID string, value string) (string, error) {
+	b, err := k.Decrypt(ctx, projectID, []byte(value))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// RotateDataKey creates a new active data key for the project. Payloads
+// encrypted with the previous key are decrypted with it until the
+// re-encryption job moves them to the new one.
+func (k *Keyring) RotateDataKey(ctx context.Context, projectID string) (*datastore.DataKey, error) {
+	if k == nil {
+		return nil, errors.New("encryption is not configured")
+	}
+
+	plaintext := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := k.wrap(projectID, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &datastore.DataKey{
+		UID:        ulid.Make().String(),
+		ProjectID:  projectID,
+		WrappedKey: wrapped,
+		KekID:      k.kekID,
+	}
+
+	err = k.repo.CreateDataKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	k.keys.Store(key.UID, aead)
+	k.active.Delete(memorystore.NewKey(activeKeysPrefix, projectID))
+
+	return key, nil
+}
+
+// RewrapDataKeys wraps the data keys that were wrapped by a previous
+// master key with the current one, after which the previous master key
+// can be removed from the configuration.
+func (k *Keyring) RewrapDataKeys(ctx context.Context) (int, error) {
+	if k == nil {
+		return 0, errors.New("encryption is not configured")
+	}
+
+	keys, err := k.repo.LoadDataKeysNotWrappedBy(ctx, k.kekID)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range keys {
+		key := &keys[i]
+
+		plaintext, err := k.unwrap(key)
+		if err != nil {
+			return i, fmt.Errorf("failed to unwrap data key %s: %w", key.UID, err)
+		}
+
+		key.WrappedKey, err = k.wrap(key.ProjectID, plaintext)
+		if err != nil {
+			return i, err
+		}
+		key.KekID = k.kekID
+
+		err = k.repo.UpdateWrappedKey(ctx, key)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+func (k *Keyring) activeKey(ctx context.Context, projectID string) (*cachedKey, error) {
+	cacheKey := memorystore.NewKey(activeKeysPrefix, projectID)
+	if row := k.active.Get(cacheKey); row != nil {
+		if c, ok := row.Value().(*cachedKey); ok && time.Now().Before(c.expiresAt) {
+			return c, nil
+		}
+	}
+
+	key, err := k.repo.FindActiveDataKey(ctx, projectID)
+	if errors.Is(err, datastore.ErrDataKeyNotFound) {
+		key, err = k.RotateDataKey(ctx, projectID)
+		if err != nil {
+			// another instance may have created the project's first
+			// key at the same time.
+			key, err = k.repo.FindActiveDataKey(ctx, projectID)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := k.cacheDataKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cachedKey{id: key.UID, aead: aead, expiresAt: time.Now().Add(activeKeyTTL)}
+	k.active.Upsert(cacheKey, c)
+
+	return c, nil
+}
+
+func (k *Keyring) dataKey(ctx context.Context, projectID, id string) (cipher.AEAD, error) {
+	if aead, ok := k.keys.Load(id); ok {
+		return aead.(cipher.AEAD), nil
+	}
+
+	key, err := k.repo.FindDataKeyByID(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.cacheDataKey(key)
+}
+
+func (k *Keyring) cacheDataKey(key *datastore.DataKey) (cipher.AEAD, error) {
+	if aead, ok := k.keys.Load(key.UID); ok {
+		return aead.(cipher.AEAD), nil
+	}
+
+	plaintext, err := k.unwrap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	k.keys.Store(key.UID, aead)
+	return aead, nil
+}
+
+// the project id is authenticated with the data key, so a wrapped key
+// can't be moved to another project.
+func (k *Keyring) wrap(projectID string, plaintext []byte) ([]byte, error) {
+	kek := k.keks[k.kekID]
+
+	nonce := make([]byte, kek.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return kek.Seal(nonce, nonce, plaintext, []byte(projectID)), nil
+}
+
+func (k *Keyring) unwrap(key *datastore.DataKey) ([]byte, error) {
+	kek, ok := k.keks[key.KekID]
+	if !ok {
+		return nil, ErrUnknownKek
+	}
+
+	if len(key.WrappedKey) < kek.NonceSize() {
+		return nil, ErrMalformedValue
+	}
+
+	nonce, ciphertext := key.WrappedKey[:kek.NonceSize()], key.WrappedKey[kek.NonceSize():]
+	return kek.Open(nil, nonce, ciphertext, []byte(key.ProjectID))
+}
+
+func (k *Keyring) addKek(key []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	id := kekID(key)
+	k.keks[id] = aead
+	return id, nil
+}
+
+// kekID identifies a master key without revealing it, so the key that
+// wrapped a data key can be found after a rotation.
+func kekID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func loadKey(cfg config.EncryptionConfiguration) ([]byte, error) {
+	encoded := cfg.Key
+	if len(encoded) == 0 && len(cfg.KeyFile) > 0 {
+		b, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %v", err)
+		}
+		encoded = string(b)
+	}
+
+	if len(strings.TrimSpace(encoded)) == 0 {
+		return nil, nil
+	}
+
+	return decodeKey(encoded)
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != keySize {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
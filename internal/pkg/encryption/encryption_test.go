@@ -0,0 +1,252 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, keySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// saveDataKeys makes repo keep the data keys it's given in keys.
+func saveDataKeys(repo *mocks.MockDataKeyRepository, keys map[string]*datastore.DataKey) {
+	repo.EXPECT().CreateDataKey(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, key *datastore.DataKey) error {
+		for _, k := range keys {
+			if k.ProjectID == key.ProjectID {
+				k.Status = datastore.RetiredDataKeyStatus
+			}
+		}
+
+		key.Status = datastore.ActiveDataKeyStatus
+		keys[key.UID] = key
+		return nil
+	})
+
+	repo.EXPECT().FindActiveDataKey(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, projectID string) (*datastore.DataKey, error) {
+		for _, k := range keys {
+			if k.ProjectID == projectID && k.Status == datastore.ActiveDataKeyStatus {
+				return k, nil
+			}
+		}
+		return nil, datastore.ErrDataKeyNotFound
+	})
+
+	repo.EXPECT().FindDataKeyByID(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, projectID, id string) (*datastore.DataKey, error) {
+		k, ok := keys[id]
+		if !ok || k.ProjectID != projectID {
+			return nil, datastore.ErrDataKeyNotFound
+		}
+		return k, nil
+	})
+}
+
+// optedInProjects returns a project repository where only the given
+// projects encrypt their payloads.
+func optedInProjects(ctrl *gomock.Controller, projectIDs ...string) *mocks.MockProjectRepository {
+	repo := mocks.NewMockProjectRepository(ctrl)
+	repo.EXPECT().FetchProjectByID(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, id string) (*datastore.Project, error) {
+		for _, projectID := range projectIDs {
+			if projectID == id {
+				return &datastore.Project{UID: id, Config: &datastore.ProjectConfig{EncryptPayloads: true}}, nil
+			}
+		}
+		return &datastore.Project{UID: id, Config: &datastore.ProjectConfig{}}, nil
+	})
+
+	return repo
+}
+
+func TestNewKeyring(t *testing.T) {
+	k, err := NewKeyring(config.EncryptionConfiguration{}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, k)
+
+	_, err = NewKeyring(config.EncryptionConfiguration{Enabled: true}, nil, nil)
+	require.Error(t, err)
+
+	_, err = NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: "c2hvcnQ="}, nil, nil)
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	k, err = NewKeyring(config.EncryptionConfiguration{Key: newKey(t)}, nil, nil)
+	require.NoError(t, err)
+	require.False(t, k.Enabled())
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := map[string]*datastore.DataKey{}
+	repo := mocks.NewMockDataKeyRepository(ctrl)
+	saveDataKeys(repo, keys)
+	projects := optedInProjects(ctrl, "project-1")
+
+	kek := newKey(t)
+	k, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: kek}, repo, projects)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	plaintext := []byte(`{"email":"user@example.com"}`)
+
+	ciphertext, err := k.Encrypt(ctx, "project-1", plaintext)
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(string(ciphertext)))
+	require.NotContains(t, string(ciphertext), "user@example.com")
+
+	// already encrypted values aren't encrypted twice
+	again, err := k.Encrypt(ctx, "project-1", ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, ciphertext, again)
+
+	decrypted, err := k.Decrypt(ctx, "project-1", ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// the project is authenticated with the payload
+	_, err = k.Decrypt(ctx, "project-2", ciphertext)
+	require.Error(t, err)
+
+	// plaintext is returned as it is
+	decrypted, err = k.Decrypt(ctx, "project-1", plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// a keyring that has to load the data key from the repository
+	other, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: kek}, repo, projects)
+	require.NoError(t, err)
+
+	decrypted, err = other.Decrypt(ctx, "project-1", ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	var nilKeyring *Keyring
+	_, err = nilKeyring.Decrypt(ctx, "project-1", ciphertext)
+	require.ErrorIs(t, err, ErrNotConfigured)
+
+	same, err := nilKeyring.Encrypt(ctx, "project-1", plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, same)
+}
+
+func TestKeyring_OptIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// no data key is created for a project that didn't opt in
+	repo := mocks.NewMockDataKeyRepository(ctrl)
+	k, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: newKey(t)}, repo, optedInProjects(ctrl))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	prefix, err := k.ActivePrefix(ctx, "project-1")
+	require.NoError(t, err)
+	require.Empty(t, prefix)
+
+	plaintext, err := k.EncryptString(ctx, "project-1", "secret")
+	require.NoError(t, err)
+	require.Equal(t, "secret", plaintext)
+}
+
+func TestKeyring_RotateDataKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := map[string]*datastore.DataKey{}
+	repo := mocks.NewMockDataKeyRepository(ctrl)
+	saveDataKeys(repo, keys)
+	projects := optedInProjects(ctrl, "project-1")
+
+	k, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: newKey(t)}, repo, projects)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	old, err := k.EncryptString(ctx, "project-1", "secret")
+	require.NoError(t, err)
+
+	oldPrefix, err := k.ActivePrefix(ctx, "project-1")
+	require.NoError(t, err)
+	require.Contains(t, old, oldPrefix)
+
+	_, err = k.RotateDataKey(ctx, "project-1")
+	require.NoError(t, err)
+
+	newPrefix, err := k.ActivePrefix(ctx, "project-1")
+	require.NoError(t, err)
+	require.NotEqual(t, oldPrefix, newPrefix)
+
+	fresh, err := k.EncryptString(ctx, "project-1", "secret")
+	require.NoError(t, err)
+	require.Contains(t, fresh, newPrefix)
+
+	// payloads encrypted with the retired key can still be decrypted
+	plaintext, err := k.DecryptString(ctx, "project-1", old)
+	require.NoError(t, err)
+	require.Equal(t, "secret", plaintext)
+}
+
+func TestKeyring_RewrapDataKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := map[string]*datastore.DataKey{}
+	repo := mocks.NewMockDataKeyRepository(ctrl)
+	saveDataKeys(repo, keys)
+	projects := optedInProjects(ctrl, "project-1")
+
+	previousKek := newKey(t)
+	previous, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: previousKek}, repo, projects)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ciphertext, err := previous.EncryptString(ctx, "project-1", "secret")
+	require.NoError(t, err)
+
+	currentKek := newKey(t)
+	k, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: currentKek, PreviousKeys: []string{previousKek}}, repo, projects)
+	require.NoError(t, err)
+
+	repo.EXPECT().LoadDataKeysNotWrappedBy(gomock.Any(), k.kekID).DoAndReturn(func(_ context.Context, kekID string) ([]datastore.DataKey, error) {
+		var stale []datastore.DataKey
+		for _, key := range keys {
+			if key.KekID != kekID {
+				stale = append(stale, *key)
+			}
+		}
+		return stale, nil
+	})
+	repo.EXPECT().UpdateWrappedKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *datastore.DataKey) error {
+		keys[key.UID].WrappedKey = key.WrappedKey
+		keys[key.UID].KekID = key.KekID
+		return nil
+	})
+
+	n, err := k.RewrapDataKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// the previous master key is no longer needed
+	current, err := NewKeyring(config.EncryptionConfiguration{Enabled: true, Key: currentKek}, repo, projects)
+	require.NoError(t, err)
+
+	plaintext, err := current.DecryptString(ctx, "project-1", ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "secret", plaintext)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -181,7 +182,7 @@ func (ex *Exporter) exportTable(ctx context.Context, table tablename, expDate ti
 		return result, err
 	}
 
-	numDocs, err := repo.ExportRecords(ctx, ex.project.UID, expDate, writer)
+	numDocs, err := ex.exportRecords(ctx, repo, table, expDate, writer)
 	if err != nil {
 		log.WithError(err).Error("failed to export records")
 		return result, err
@@ -193,6 +194,34 @@ func (ex *Exporter) exportTable(ctx context.Context, table tablename, expDate ti
 	return result, nil
 }
 
+// exportRecords writes the records of the table to w, with the project's
+// redaction rules applied to them.
+func (ex *Exporter) exportRecords(ctx context.Context, repo datastore.ExportRepository, table tablename, expDate time.Time, w io.Writer) (int64, error) {
+	rd := redact.ForProject(ex.project)
+	if rd == nil {
+		return repo.ExportRecords(ctx, ex.project.UID, expDate, w)
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		err := redactRecords(pr, w, table, rd)
+		// unblock the repository if the records can't be read
+		_ = pr.CloseWithError(err)
+		done <- err
+	}()
+
+	numDocs, err := repo.ExportRecords(ctx, ex.project.UID, expDate, pw)
+	_ = pw.CloseWithError(err)
+
+	if rerr := <-done; err == nil {
+		err = rerr
+	}
+
+	return numDocs, err
+}
+
 func (ex *Exporter) getExportDir() (string, error) {
 	switch ex.config.StoragePolicy.Type {
 	case datastore.S3:
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
+)
+
+// byteaPrefix starts the hex encoding of bytea columns in exported records.
+const byteaPrefix = `\x`
+
+// redactRecords copies the array of records exported from table from r to w
+// with rd applied to their payloads and headers.
+func redactRecords(r io.Reader, w io.Writer, table tablename, rd *redact.Redactor) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	// nothing is written when there are no records
+	if _, err := dec.Token(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteString(`[`)
+	for i := 0; dec.More(); i++ {
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			return err
+		}
+
+		redactRecord(table, record, rd)
+
+		if i > 0 {
+			buf.Write(commaJSON)
+		}
+
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1) // Encode appends a newline
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		buf.Reset()
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte(`]`))
+	return err
+}
+
+var commaJSON = []byte(`,`)
+
+func redactRecord(table tablename, record map[string]interface{}, rd *redact.Redactor) {
+	redactHeaders(record["headers"], rd)
+
+	switch table {
+	case eventsTable:
+		if data, ok := record["data"].(string); ok {
+			record["data"] = redactBytea(data, rd)
+		}
+
+		redactString(record, "raw", rd)
+		redactString(record, "original_body", rd)
+	case eventDeliveriesTable:
+		if metadata, ok := record["metadata"].(map[string]interface{}); ok {
+			if _, ok := metadata["data"].(string); ok {
+				redactString(metadata, "data", rd)
+			} else if data, ok := metadata["data"]; ok {
+				metadata["data"] = rd.Value(data)
+			}
+
+			redactString(metadata, "raw", rd)
+		}
+
+		attempts, _ := record["attempts"].([]interface{})
+		for _, a := range attempts {
+			attempt, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			redactString(attempt, "response_data", rd)
+			redactHeaders(attempt["request_http_header"], rd)
+			redactHeaders(attempt["response_http_header"], rd)
+		}
+	}
+}
+
+// redactString redacts the string m[key], encrypted values are left as
+// they are.
+func redactString(m map[string]interface{}, key string, rd *redact.Redactor) {
+	s, ok := m[key].(string)
+	if !ok || encryption.IsEncrypted(s) {
+		return
+	}
+
+	m[key] = rd.Text(s)
+}
+
+// redactBytea redacts the payload in a hex encoded bytea value.
+func redactBytea(s string, rd *redact.Redactor) string {
+	if !strings.HasPrefix(s, byteaPrefix) {
+		return s
+	}
+
+	b, err := hex.DecodeString(strings.TrimPrefix(s, byteaPrefix))
+	if err != nil || encryption.IsEncrypted(string(b)) {
+		return s
+	}
+
+	return byteaPrefix + hex.EncodeToString(rd.Payload(b))
+}
+
+// redactHeaders redacts headers with either a single value or a list of
+// values per name.
+func redactHeaders(v interface{}, rd *redact.Redactor) {
+	headers, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, value := range headers {
+		switch t := value.(type) {
+		case string:
+			headers[name] = rd.Header(name, t)
+		case []interface{}:
+			for i, s := range t {
+				if s, ok := s.(string); ok {
+					t[i] = rd.Header(name, s)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
+)
+
+func Test_redactRecords(t *testing.T) {
+	rd, err := redact.New(datastore.RedactionConfiguration{
+		Paths:     []string{"$.card"},
+		Headers:   []string{"Authorization"},
+		Detectors: []datastore.RedactionDetector{datastore.EmailRedactionDetector},
+	})
+	require.NoError(t, err)
+
+	data := `\\x` + hex.EncodeToString([]byte(`{"card":"4111111111111111","id":1}`))
+
+	t.Run("should_redact_events", func(t *testing.T) {
+		in := `[{"uid":"1","data":"` + data + `","raw":"{\"email\":\"ada@example.com\"}","headers":{"Authorization":["Bearer a"]}},` +
+			`{"uid":"2","data":null,"raw":"ok","headers":null}]`
+
+		var out bytes.Buffer
+		require.NoError(t, redactRecords(strings.NewReader(in), &out, eventsTable, rd))
+
+		want := `[{"data":"` + `\\x` + hex.EncodeToString([]byte(`{"card":"[REDACTED]","id":1}`)) + `","headers":{"Authorization":["[REDACTED]"]},"raw":"{\"email\":\"[REDACTED]\"}","uid":"1"},` +
+			`{"data":null,"headers":null,"raw":"ok","uid":"2"}]`
+		require.JSONEq(t, want, out.String())
+	})
+
+	t.Run("should_redact_event_deliveries", func(t *testing.T) {
+		in := `[{"uid":"1","metadata":{"data":{"card":"4111"},"raw":"{\"card\":\"4111\"}","num_trials":1},` +
+			`"attempts":[{"response_data":"ada@example.com","request_http_header":{"Authorization":"Bearer a"}}]}]`
+
+		var out bytes.Buffer
+		require.NoError(t, redactRecords(strings.NewReader(in), &out, eventDeliveriesTable, rd))
+
+		want := `[{"uid":"1","metadata":{"data":{"card":"[REDACTED]"},"raw":"{\"card\":\"[REDACTED]\"}","num_trials":1},` +
+			`"attempts":[{"response_data":"[REDACTED]","request_http_header":{"Authorization":"[REDACTED]"}}]}]`
+		require.JSONEq(t, want, out.String())
+	})
+
+	t.Run("should_write_nothing_without_records", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, redactRecords(strings.NewReader(""), &out, eventsTable, rd))
+		require.Empty(t, out.String())
+	})
+}
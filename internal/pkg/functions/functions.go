@@ -0,0 +1,240 @@
+// Package functions loads the shared libraries a project's functions can
+// require.
+package functions
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/memorystore"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/transform"
+)
+
+// libraryTTL is how long a project's libraries are cached, so changes
+// made through other instances are picked up.
+const libraryTTL = 30 * time.Second
+
+// pinnedCacheSize is the number of pinned library versions kept.
+const pinnedCacheSize = 1000
+
+const librariesPrefix = "libraries"
+
+type cachedLibraries struct {
+	libraries map[string]string
+	expiresAt time.Time
+}
+
+// Libraries caches each project's function libraries.
+type Libraries struct {
+	repo        datastore.FunctionLibraryRepository
+	versionRepo datastore.FunctionVersionRepository
+	table       *memorystore.Table
+	pinned      *pinnedCache
+}
+
+func NewLibraries(repo datastore.FunctionLibraryRepository, versionRepo datastore.FunctionVersionRepository) *Libraries {
+	return &Libraries{
+		repo:        repo,
+		versionRepo: versionRepo,
+		table:       memorystore.NewTable(),
+		pinned:      newPinnedCache(pinnedCacheSize),
+	}
+}
+
+var defaultLibraries atomic.Value
+
+// Init sets the libraries used by NewTransformer.
+func Init(repo datastore.FunctionLibraryRepository, versionRepo datastore.FunctionVersionRepository) *Libraries {
+	l := NewLibraries(repo, versionRepo)
+	defaultLibraries.Store(l)
+	return l
+}
+
+// Get returns the libraries set with Init, or nil if Init wasn't called.
+func Get() *Libraries {
+	l, _ := defaultLibraries.Load().(*Libraries)
+	return l
+}
+
+// Load returns a project's libraries keyed by name.
+func (l *Libraries) Load(ctx context.Context, projectID string) (map[string]string, error) {
+	key := memorystore.NewKey(librariesPrefix, projectID)
+	if row := l.table.Get(key); row != nil {
+		if c, ok := row.Value().(*cachedLibraries); ok && time.Now().Before(c.expiresAt) {
+			return c.libraries, nil
+		}
+	}
+
+	libraries, err := l.repo.LoadFunctionLibraries(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(libraries))
+	for _, library := range libraries {
+		m[library.Name] = library.Function
+	}
+
+	l.table.Upsert(key, &cachedLibraries{libraries: m, expiresAt: time.Now().Add(libraryTTL)})
+	return m, nil
+}
+
+// LoadPinned returns the pinned versions of a project's libraries keyed by
+// name. Versions don't change, so they are cached until they're the least
+// recently used once the cache is full.
+func (l *Libraries) LoadPinned(ctx context.Context, projectID string, pins datastore.FunctionLibraryPins) (map[string]string, error) {
+	m := make(map[string]string, len(pins))
+	for name, pin := range pins {
+		key := fmt.Sprintf("%s:%s:%d", projectID, pin.LibraryID, pin.Version)
+		if function, ok := l.pinned.get(key); ok {
+			m[name] = function
+			continue
+		}
+
+		v, err := l.versionRepo.FindFunctionVersion(ctx, projectID, datastore.FunctionLibraryOwner, pin.LibraryID, pin.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load version %d of library %s: %v", pin.Version, name, err)
+		}
+
+		l.pinned.add(key, v.Function)
+		m[name] = v.Function
+	}
+
+	return m, nil
+}
+
+// Invalidate drops a project's cached libraries.
+func (l *Libraries) Invalidate(projectID string) {
+	l.table.Delete(memorystore.NewKey(librariesPrefix, projectID))
+}
+
+type pinnedVersion struct {
+	key      string
+	function string
+}
+
+// pinnedCache holds pinned library versions, the least recently used are
+// evicted once it holds size of them.
+type pinnedCache struct {
+	size int
+
+	mu       sync.Mutex
+	order    *list.List
+	versions map[string]*list.Element
+}
+
+func newPinnedCache(size int) *pinnedCache {
+	return &pinnedCache{size: size, order: list.New(), versions: map[string]*list.Element{}}
+}
+
+func (c *pinnedCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.versions[key]
+	if !ok {
+		return "", false
+	}
+
+	c.order.MoveToFront(el)
+	return el.Value.(*pinnedVersion).function, true
+}
+
+func (c *pinnedCache) add(key, function string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.versions[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.versions[key] = c.order.PushFront(&pinnedVersion{key: key, function: function})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.versions, oldest.Value.(*pinnedVersion).key)
+	}
+}
+
+// NewTransformer returns a transformer that can require the project's
+// libraries. The libraries are left out if they can't be loaded, so only
+// functions requiring them fail.
+func NewTransformer(ctx context.Context, projectID string) *transform.Transformer {
+	t := transform.NewTransformer().WithProject(projectID)
+
+	l := Get()
+	if l == nil {
+		return t
+	}
+
+	libraries, err := l.Load(ctx, projectID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("failed to load function libraries for project %s", projectID)
+		return t
+	}
+
+	return t.WithLibraries(libraries)
+}
+
+// NewPinnedTransformer returns a transformer that requires the versions of
+// the project's libraries a pinned function was pinned with. Without pins
+// it requires the latest libraries like NewTransformer.
+func NewPinnedTransformer(ctx context.Context, projectID string, pins datastore.FunctionLibraryPins) *transform.Transformer {
+	if pins == nil {
+		return NewTransformer(ctx, projectID)
+	}
+
+	t := transform.NewTransformer().WithProject(projectID)
+
+	l := Get()
+	if l == nil {
+		return t
+	}
+
+	libraries, err := l.LoadPinned(ctx, projectID, pins)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("failed to load pinned function libraries for project %s", projectID)
+		return t
+	}
+
+	return t.WithLibraries(libraries)
+}
+
+// DownConvert converts data from one version of its event type to an
+// earlier one, running the down converter of each version in between from
+// the newest.
+func DownConvert(t *transform.Transformer, versions []datastore.EventTypeVersion, from, to int, data json.RawMessage) (json.RawMessage, error) {
+	converters := make(map[int]string, len(versions))
+	for _, v := range versions {
+		converters[v.Version] = v.DownConverter
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	for v := from; v > to; v-- {
+		converter, ok := converters[v]
+		if !ok || len(converter) == 0 {
+			return nil, fmt.Errorf("version %d has no down converter", v)
+		}
+
+		mutated, _, err := t.Transform(converter, payload)
+		if err != nil {
+			return nil, fmt.Errorf("converting version %d to %d: %v", v, v-1, err)
+		}
+
+		payload = mutated
+	}
+
+	return json.Marshal(payload)
+}
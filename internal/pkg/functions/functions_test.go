@@ -0,0 +1,134 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/transform"
+)
+
+func TestLibraries_Load(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockFunctionLibraryRepository(ctrl)
+	repo.EXPECT().LoadFunctionLibraries(gomock.Any(), "project-1").Times(2).Return([]datastore.FunctionLibrary{
+		{Name: "math", Function: "module.exports.double = function (n) { return n * 2 }"},
+	}, nil)
+
+	l := NewLibraries(repo, nil)
+
+	libraries, err := l.Load(context.Background(), "project-1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"math": "module.exports.double = function (n) { return n * 2 }"}, libraries)
+
+	// served from the cache
+	_, err = l.Load(context.Background(), "project-1")
+	require.NoError(t, err)
+
+	l.Invalidate("project-1")
+
+	_, err = l.Load(context.Background(), "project-1")
+	require.NoError(t, err)
+}
+
+func TestNewTransformer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockFunctionLibraryRepository(ctrl)
+	repo.EXPECT().LoadFunctionLibraries(gomock.Any(), "project-1").Return([]datastore.FunctionLibrary{
+		{Name: "math", Function: "module.exports.double = function (n) { return n * 2 }"},
+	}, nil)
+
+	Init(repo, nil)
+	t.Cleanup(func() { defaultLibraries.Store((*Libraries)(nil)) })
+
+	function := `function transform(payload) { return require("math").double(payload) }`
+	mutated, _, err := NewTransformer(context.Background(), "project-1").Transform(function, 21)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), mutated)
+}
+
+func TestNewPinnedTransformer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	versionRepo := mocks.NewMockFunctionVersionRepository(ctrl)
+	versionRepo.EXPECT().FindFunctionVersion(gomock.Any(), "project-1", datastore.FunctionLibraryOwner, "library-1", 1).Return(&datastore.FunctionVersion{
+		Version:  1,
+		Function: "module.exports.double = function (n) { return n * 2 }",
+	}, nil)
+
+	Init(mocks.NewMockFunctionLibraryRepository(ctrl), versionRepo)
+	t.Cleanup(func() { defaultLibraries.Store((*Libraries)(nil)) })
+
+	pins := datastore.FunctionLibraryPins{"math": {LibraryID: "library-1", Version: 1}}
+	function := `function transform(payload) { return require("math").double(payload) }`
+
+	mutated, _, err := NewPinnedTransformer(context.Background(), "project-1", pins).Transform(function, 21)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), mutated)
+
+	// the pinned version is served from the cache
+	mutated, _, err = NewPinnedTransformer(context.Background(), "project-1", pins).Transform(function, 4)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), mutated)
+}
+
+func TestLibraries_LoadPinned_Evicted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	versionRepo := mocks.NewMockFunctionVersionRepository(ctrl)
+	for _, version := range []int{1, 2} {
+		versionRepo.EXPECT().FindFunctionVersion(gomock.Any(), "project-1", datastore.FunctionLibraryOwner, "library-1", version).Return(&datastore.FunctionVersion{
+			Version:  version,
+			Function: "module.exports.version = " + strconv.Itoa(version),
+		}, nil)
+	}
+
+	// version 1 is loaded again once version 2 evicted it
+	versionRepo.EXPECT().FindFunctionVersion(gomock.Any(), "project-1", datastore.FunctionLibraryOwner, "library-1", 1).Return(&datastore.FunctionVersion{
+		Version:  1,
+		Function: "module.exports.version = 1",
+	}, nil)
+
+	l := NewLibraries(nil, versionRepo)
+	l.pinned = newPinnedCache(1)
+
+	for _, version := range []int{1, 1, 2, 1} {
+		pins := datastore.FunctionLibraryPins{"version": {LibraryID: "library-1", Version: version}}
+		libraries, err := l.LoadPinned(context.Background(), "project-1", pins)
+		require.NoError(t, err)
+		require.Equal(t, "module.exports.version = "+strconv.Itoa(version), libraries["version"])
+	}
+
+	require.Equal(t, 1, l.pinned.order.Len())
+}
+
+func TestDownConvert(t *testing.T) {
+	versions := []datastore.EventTypeVersion{
+		{Version: 3, DownConverter: `function transform(payload) { payload.total = payload.amount.value; delete payload.amount; return payload }`},
+		{Version: 2, DownConverter: `function transform(payload) { payload.cents = payload.total * 100; delete payload.total; return payload }`},
+		{Version: 1},
+	}
+
+	converted, err := DownConvert(transform.NewTransformer(), versions, 3, 1, json.RawMessage(`{"amount":{"value":5}}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"cents":500}`, string(converted))
+
+	converted, err = DownConvert(transform.NewTransformer(), versions, 3, 2, json.RawMessage(`{"amount":{"value":5}}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"total":5}`, string(converted))
+
+	_, err = DownConvert(transform.NewTransformer(), versions[1:], 3, 1, json.RawMessage(`{}`))
+	require.EqualError(t, err, "version 3 has no down converter")
+}
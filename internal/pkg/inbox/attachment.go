@@ -0,0 +1,90 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/frain-dev/convoy/datastore"
+	objectstore "github.com/frain-dev/convoy/internal/pkg/object-store"
+)
+
+const tmpAttachmentDir = "/tmp/convoy/attachments"
+
+// AttachmentStore saves email attachments, events only carry a reference
+// to where the attachment was saved.
+type AttachmentStore interface {
+	Save(ctx context.Context, projectID, eventID string, index int, attachment Attachment) (string, error)
+}
+
+// AttachmentReference describes a saved attachment in an email event.
+type AttachmentReference struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id,omitempty"`
+	Size        int    `json:"size"`
+	Reference   string `json:"reference,omitempty"`
+}
+
+type objectAttachmentStore struct {
+	dir       string
+	removeTmp bool
+	store     objectstore.ObjectStore
+}
+
+// NewAttachmentStore saves attachments with the instance's storage policy.
+func NewAttachmentStore(policy *datastore.StoragePolicyConfiguration) (AttachmentStore, error) {
+	store, err := objectstore.NewObjectStoreClient(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &objectAttachmentStore{store: store}
+	switch policy.Type {
+	case datastore.S3:
+		// files are uploaded from /tmp and removed once saved
+		s.dir, s.removeTmp = tmpAttachmentDir, true
+	case datastore.OnPrem:
+		if policy.OnPrem == nil || policy.OnPrem.Path.IsZero() {
+			return nil, fmt.Errorf("on prem storage path is not set")
+		}
+
+		s.dir = filepath.Join(policy.OnPrem.Path.String, "attachments")
+	}
+
+	return s, nil
+}
+
+func (o *objectAttachmentStore) Save(_ context.Context, projectID, eventID string, index int, attachment Attachment) (string, error) {
+	dir := filepath.Join(o.dir, projectID, eventID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	filename := filepath.Join(dir, fmt.Sprintf("%d-%s", index, attachmentName(attachment.Filename)))
+	if err := os.WriteFile(filename, attachment.Content, 0o644); err != nil {
+		return "", err
+	}
+
+	if o.removeTmp {
+		defer os.Remove(filename)
+	}
+
+	if err := o.store.Save(filename); err != nil {
+		return "", err
+	}
+
+	return o.store.Location(filename), nil
+}
+
+// attachmentName strips any directories from the sender's file name.
+func attachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." || len(name) == 0 {
+		return "attachment"
+	}
+
+	return name
+}
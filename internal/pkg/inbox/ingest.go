@@ -0,0 +1,317 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/internal/pkg/providers"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/util"
+	"github.com/frain-dev/convoy/worker/task"
+)
+
+var (
+	errRateLimited   = &Error{Code: 451, Message: "4.7.0 rate limit exceeded, try again later"}
+	errQuotaExceeded = &Error{Code: 452, Message: "4.3.1 ingest quota exceeded, try again later"}
+	errMalformed     = &Error{Code: 554, Message: "5.6.0 malformed message"}
+)
+
+// Ingest is the Backend that turns mail sent to an email source into
+// events. Mail is routed by the recipient's local part, which is the
+// source's mask id, a +tag suffix is ignored.
+type Ingest struct {
+	domain      string
+	sourceRepo  datastore.SourceRepository
+	projectRepo datastore.ProjectRepository
+	queue       queue.Queuer
+	rateLimiter limiter.RateLimiter
+	dedup       dedup.Store
+	attachments AttachmentStore
+	log         log.StdLogger
+}
+
+// NewIngest creates an email Backend, recipients on other domains are
+// rejected when domain is set. Attachments are only listed in the event
+// when attachments is nil.
+func NewIngest(domain string, sourceRepo datastore.SourceRepository, projectRepo datastore.ProjectRepository, queue queue.Queuer,
+	rateLimiter limiter.RateLimiter, dedupStore dedup.Store, attachments AttachmentStore, log log.StdLogger,
+) *Ingest {
+	return &Ingest{
+		domain:      domain,
+		sourceRepo:  sourceRepo,
+		projectRepo: projectRepo,
+		queue:       queue,
+		rateLimiter: rateLimiter,
+		dedup:       dedupStore,
+		attachments: attachments,
+		log:         log,
+	}
+}
+
+func (i *Ingest) Recipient(ctx context.Context, addr string) error {
+	_, err := i.findSource(ctx, addr)
+	return err
+}
+
+func (i *Ingest) Deliver(ctx context.Context, envelope *Envelope) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	if err = i.rateLimiter.Allow(ctx, cfg.InstanceId, cfg.InstanceIngestRate); err != nil {
+		return errRateLimited
+	}
+
+	msg, err := ParseMessage(envelope.Data)
+	if err != nil {
+		i.log.WithError(err).Debugf("rejected malformed message from %s", envelope.MailFrom)
+		return errMalformed
+	}
+
+	// a message sent to several addresses of one source is ingested once
+	ingested := map[string]bool{}
+	for _, rcpt := range envelope.RcptTo {
+		source, err := i.findSource(ctx, rcpt)
+		if err != nil {
+			return err
+		}
+
+		if ingested[source.UID] {
+			continue
+		}
+
+		metrics.GetDPInstance().IncrementIngestTotal(source)
+		if err = i.ingest(ctx, source, envelope, msg); err != nil {
+			metrics.GetDPInstance().IncrementIngestErrorsTotal(source)
+			return err
+		}
+
+		metrics.GetDPInstance().IncrementIngestConsumedTotal(source)
+		ingested[source.UID] = true
+	}
+
+	return nil
+}
+
+func (i *Ingest) findSource(ctx context.Context, addr string) (*datastore.Source, error) {
+	at := strings.LastIndex(addr, "@")
+	if at < 0 {
+		return nil, ErrUnknownRecipient
+	}
+
+	local, domain := addr[:at], addr[at+1:]
+	if len(i.domain) > 0 && !strings.EqualFold(domain, i.domain) {
+		return nil, ErrUnknownRecipient
+	}
+
+	maskID, _, _ := strings.Cut(local, "+")
+	source, err := i.sourceRepo.FindSourceByMaskID(ctx, maskID)
+	if err != nil {
+		if errors.Is(err, datastore.ErrSourceNotFound) {
+			return nil, ErrUnknownRecipient
+		}
+
+		return nil, err
+	}
+
+	if source.Type != datastore.EmailSource || source.IsDisabled {
+		return nil, ErrUnknownRecipient
+	}
+
+	return source, nil
+}
+
+func (i *Ingest) ingest(ctx context.Context, source *datastore.Source, envelope *Envelope, msg *Message) (err error) {
+	project, err := i.projectRepo.FetchProjectByID(ctx, source.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if err = limiter.AllowIngest(ctx, i.rateLimiter, source, project); err != nil {
+		metrics.GetDPInstance().IncrementIngestRejectedTotal(source, limiter.RateLimitedReason)
+		return errRateLimited
+	}
+
+	// mail servers retry deliveries they didn't see accepted, so messages
+	// are deduplicated by their Message-ID within the source's window.
+	var idempotencyKey string
+	var isDuplicate bool
+	if len(msg.MessageID) > 0 {
+		idempotencyKey = fmt.Sprintf("%s:%s", source.UID, msg.MessageID)
+		dedupKey := dedup.Key(source.ProjectID, idempotencyKey)
+
+		isDuplicate, err = i.dedup.Seen(ctx, dedupKey, dedup.Window(source.DedupWindow))
+		if err != nil {
+			return err
+		}
+
+		if isDuplicate {
+			metrics.GetDPInstance().IncrementIngestDuplicateTotal(source)
+		} else {
+			defer func() {
+				if err == nil {
+					return
+				}
+
+				if forgetErr := i.dedup.Forget(ctx, dedupKey); forgetErr != nil {
+					i.log.WithError(forgetErr).Errorf("failed to forget message id %s", msg.MessageID)
+				}
+			}()
+		}
+	}
+
+	eventID := ulid.Make().String()
+	attachments, err := i.saveAttachments(ctx, source, eventID, msg, isDuplicate)
+	if err != nil {
+		return err
+	}
+
+	data, err := eventData(ctx, source, envelope, msg, attachments)
+	if err != nil {
+		return err
+	}
+
+	// sources without an event type extractor use the mask id
+	eventType := source.MaskID
+	r := &http.Request{Header: http.Header(msg.Header)}
+	if et, err := providers.ExtractEventType(ctx, source.ProjectID, r, source.EventTypeConfig, nil, data); err != nil {
+		i.log.WithError(err).Errorf("failed to extract event type for source %s", source.UID)
+	} else if !util.IsStringEmpty(et) {
+		eventType = et
+	}
+
+	event := &datastore.Event{
+		UID:              eventID,
+		EventType:        datastore.EventType(eventType),
+		SourceID:         source.UID,
+		ProjectID:        source.ProjectID,
+		Raw:              string(data),
+		Data:             data,
+		IsDuplicateEvent: isDuplicate,
+		IdempotencyKey:   idempotencyKey,
+		Headers:          map[string][]string{"X-Convoy-Source-Id": {source.MaskID}},
+		Metadata: datastore.M{
+			"email": datastore.M{
+				"mail_from":   envelope.MailFrom,
+				"rcpt_to":     envelope.RcptTo,
+				"remote_addr": envelope.RemoteAddr,
+			},
+			"attachments": attachments,
+		},
+		AcknowledgedAt: null.TimeFrom(time.Now()),
+	}
+
+	if err = limiter.TakeIngestQuota(ctx, i.rateLimiter, project); err != nil {
+		metrics.GetDPInstance().IncrementIngestRejectedTotal(source, limiter.QuotaExceededReason)
+		return errQuotaExceeded
+	}
+
+	ce := task.CreateEvent{Event: event}
+	err = ce.OffloadPayload(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventByte, err := msgpack.EncodeMsgPack(ce)
+	if err != nil {
+		return err
+	}
+
+	job := &queue.Job{
+		ID:      event.UID,
+		Payload: eventByte,
+	}
+
+	return i.queue.Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, job)
+}
+
+// saveAttachments saves the message's attachments, duplicates and messages
+// received without an attachment store only list them.
+func (i *Ingest) saveAttachments(ctx context.Context, source *datastore.Source, eventID string, msg *Message, isDuplicate bool) ([]AttachmentReference, error) {
+	refs := make([]AttachmentReference, 0, len(msg.Attachments))
+	for n, a := range msg.Attachments {
+		ref := AttachmentReference{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			ContentID:   a.ContentID,
+			Size:        len(a.Content),
+		}
+
+		if i.attachments != nil && !isDuplicate {
+			location, err := i.attachments.Save(ctx, source.ProjectID, eventID, n, a)
+			if err != nil {
+				return nil, fmt.Errorf("failed to save attachment: %v", err)
+			}
+
+			ref.Reference = location
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+func eventData(ctx context.Context, source *datastore.Source, envelope *Envelope, msg *Message, attachments []AttachmentReference) (json.RawMessage, error) {
+	var date *time.Time
+	if !msg.Date.IsZero() {
+		date = &msg.Date
+	}
+
+	payload := map[string]interface{}{
+		"message_id":  msg.MessageID,
+		"from":        msg.From,
+		"to":          msg.To,
+		"cc":          msg.Cc,
+		"reply_to":    msg.ReplyTo,
+		"subject":     msg.Subject,
+		"date":        date,
+		"headers":     msg.Header,
+		"text":        msg.Text,
+		"html":        msg.HTML,
+		"attachments": attachments,
+		"envelope": map[string]interface{}{
+			"mail_from": envelope.MailFrom,
+			"rcpt_to":   envelope.RcptTo,
+		},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.BodyFunction == nil || util.IsStringEmpty(*source.BodyFunction) {
+		return data, nil
+	}
+
+	var body interface{}
+	if err = json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+
+	mutated, _, err := functions.NewTransformer(ctx, source.ProjectID).Transform(*source.BodyFunction, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mutated)
+}
@@ -0,0 +1,170 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/worker/task"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (m *memoryStore) Seen(_ context.Context, key string, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.seen[key] {
+		return true, nil
+	}
+
+	m.seen[key] = true
+	return false, nil
+}
+
+func (m *memoryStore) Forget(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.seen, key)
+	return nil
+}
+
+type ingestMocks struct {
+	sourceRepo  *mocks.MockSourceRepository
+	projectRepo *mocks.MockProjectRepository
+	queue       *mocks.MockQueuer
+	rateLimiter *mocks.MockRateLimiter
+}
+
+func provideIngest(t *testing.T) (*Ingest, *ingestMocks) {
+	ctrl := gomock.NewController(t)
+
+	m := &ingestMocks{
+		sourceRepo:  mocks.NewMockSourceRepository(ctrl),
+		projectRepo: mocks.NewMockProjectRepository(ctrl),
+		queue:       mocks.NewMockQueuer(ctrl),
+		rateLimiter: mocks.NewMockRateLimiter(ctrl),
+	}
+
+	i := NewIngest("inbound.convoy.test", m.sourceRepo, m.projectRepo, m.queue, m.rateLimiter,
+		&memoryStore{seen: map[string]bool{}}, nil, log.NewLogger(&strings.Builder{}))
+
+	return i, m
+}
+
+func TestIngest_Recipient(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		dbFn    func(m *ingestMocks)
+		wantErr error
+	}{
+		{
+			name: "should accept email source",
+			addr: "abc+invoices@inbound.convoy.test",
+			dbFn: func(m *ingestMocks) {
+				m.sourceRepo.EXPECT().FindSourceByMaskID(gomock.Any(), "abc").
+					Return(&datastore.Source{UID: "source-1", MaskID: "abc", Type: datastore.EmailSource}, nil)
+			},
+		},
+		{
+			name:    "should reject other domains",
+			addr:    "abc@example.com",
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "should reject http source",
+			addr: "abc@inbound.convoy.test",
+			dbFn: func(m *ingestMocks) {
+				m.sourceRepo.EXPECT().FindSourceByMaskID(gomock.Any(), "abc").
+					Return(&datastore.Source{UID: "source-1", MaskID: "abc", Type: datastore.HTTPSource}, nil)
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "should reject unknown source",
+			addr: "abc@inbound.convoy.test",
+			dbFn: func(m *ingestMocks) {
+				m.sourceRepo.EXPECT().FindSourceByMaskID(gomock.Any(), "abc").Return(nil, datastore.ErrSourceNotFound)
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, m := provideIngest(t)
+			if tt.dbFn != nil {
+				tt.dbFn(m)
+			}
+
+			err := i.Recipient(context.Background(), tt.addr)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestIngest_Deliver(t *testing.T) {
+	err := config.LoadConfig("")
+	require.NoError(t, err)
+
+	i, m := provideIngest(t)
+
+	source := &datastore.Source{UID: "source-1", ProjectID: "project-1", MaskID: "abc", Type: datastore.EmailSource}
+	m.sourceRepo.EXPECT().FindSourceByMaskID(gomock.Any(), "abc").Return(source, nil).Times(2)
+	m.projectRepo.EXPECT().FetchProjectByID(gomock.Any(), "project-1").Return(&datastore.Project{UID: "project-1"}, nil).Times(2)
+	m.rateLimiter.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	var events []*datastore.Event
+	m.queue.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).
+		DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+			var ce task.CreateEvent
+			require.NoError(t, msgpack.DecodeMsgPack(job.Payload, &ce))
+
+			events = append(events, ce.Event)
+			return nil
+		}).Times(2)
+
+	envelope := &Envelope{
+		MailFrom: "alerts@vendor.com",
+		RcptTo:   []string{"abc@inbound.convoy.test"},
+		Data: []byte("From: alerts@vendor.com\n" +
+			"To: abc@inbound.convoy.test\n" +
+			"Subject: Invoice paid\n" +
+			"Message-ID: <1@vendor.com>\n" +
+			"\n" +
+			"Your invoice was paid.\n"),
+	}
+
+	// the second delivery is a retry of the first
+	require.NoError(t, i.Deliver(context.Background(), envelope))
+	require.NoError(t, i.Deliver(context.Background(), envelope))
+
+	require.Len(t, events, 2)
+	require.False(t, events[0].IsDuplicateEvent)
+	require.True(t, events[1].IsDuplicateEvent)
+	require.Equal(t, datastore.EventType("abc"), events[0].EventType)
+	require.Equal(t, "source-1", events[0].SourceID)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[0].Data, &data))
+	require.Equal(t, "Invoice paid", data["subject"])
+	require.Equal(t, "Your invoice was paid.\n", data["text"])
+	require.Equal(t, "1@vendor.com", data["message_id"])
+}
@@ -0,0 +1,167 @@
+package inbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// maxPartDepth limits how deeply nested multipart bodies are read.
+const maxPartDepth = 10
+
+type Address struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"`
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Content     []byte
+}
+
+// Message is a parsed email, only the first text and html parts that
+// aren't attachments are used as the message's body.
+type Message struct {
+	MessageID   string
+	From        []Address
+	To          []Address
+	Cc          []Address
+	ReplyTo     []Address
+	Subject     string
+	Date        time.Time
+	Header      textproto.MIMEHeader
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+func ParseMessage(data []byte) (*Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %v", err)
+	}
+
+	dec := new(mime.WordDecoder)
+	msg := &Message{
+		MessageID: strings.Trim(m.Header.Get("Message-Id"), "<> "),
+		Header:    textproto.MIMEHeader(m.Header),
+	}
+
+	msg.Subject, err = dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		msg.Subject = m.Header.Get("Subject")
+	}
+
+	if date, err := m.Header.Date(); err == nil {
+		msg.Date = date
+	}
+
+	msg.From = addressList(m.Header, "From")
+	msg.To = addressList(m.Header, "To")
+	msg.Cc = addressList(m.Header, "Cc")
+	msg.ReplyTo = addressList(m.Header, "Reply-To")
+
+	if err = msg.readPart(textproto.MIMEHeader(m.Header), m.Body, 0); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (msg *Message) readPart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxPartDepth {
+		return errors.New("message parts are nested too deeply")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to read message part: %v", err)
+			}
+
+			if err = msg.readPart(p.Header, p, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("failed to decode message part: %v", err)
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dparams["filename"]
+	if len(filename) == 0 {
+		filename = params["name"]
+	}
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if disposition != "attachment" && len(filename) == 0 && isText {
+		if mediaType == "text/plain" && len(msg.Text) == 0 {
+			msg.Text = string(content)
+			return nil
+		}
+
+		if mediaType == "text/html" && len(msg.HTML) == 0 {
+			msg.HTML = string(content)
+			return nil
+		}
+	}
+
+	msg.Attachments = append(msg.Attachments, Attachment{
+		Filename:    filename,
+		ContentType: mediaType,
+		ContentID:   strings.Trim(header.Get("Content-Id"), "<> "),
+		Content:     content,
+	})
+
+	return nil
+}
+
+func decodeTransferEncoding(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func addressList(header mail.Header, key string) []Address {
+	list, err := header.AddressList(key)
+	if err != nil {
+		return nil
+	}
+
+	addresses := make([]Address, 0, len(list))
+	for _, a := range list {
+		addresses = append(addresses, Address{Name: a.Name, Address: a.Address})
+	}
+
+	return addresses
+}
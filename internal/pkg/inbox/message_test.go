@@ -0,0 +1,87 @@
+package inbox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         string
+		wantSubject     string
+		wantText        string
+		wantHTML        string
+		wantFrom        []Address
+		wantAttachments []Attachment
+	}{
+		{
+			name: "plain text",
+			message: "From: Vendor <alerts@vendor.com>\r\n" +
+				"To: abc@inbound.convoy.test\r\n" +
+				"Subject: Invoice paid\r\n" +
+				"Message-ID: <1@vendor.com>\r\n" +
+				"\r\n" +
+				"Your invoice was paid.\r\n",
+			wantSubject: "Invoice paid",
+			wantText:    "Your invoice was paid.\r\n",
+			wantFrom:    []Address{{Name: "Vendor", Address: "alerts@vendor.com"}},
+		},
+		{
+			name: "multipart with attachment",
+			message: "From: alerts@vendor.com\r\n" +
+				"To: abc@inbound.convoy.test\r\n" +
+				"Subject: =?UTF-8?B?SW52b2ljZSDinJQ=?=\r\n" +
+				"MIME-Version: 1.0\r\n" +
+				"Content-Type: multipart/mixed; boundary=outer\r\n" +
+				"\r\n" +
+				"--outer\r\n" +
+				"Content-Type: multipart/alternative; boundary=inner\r\n" +
+				"\r\n" +
+				"--inner\r\n" +
+				"Content-Type: text/plain; charset=utf-8\r\n" +
+				"Content-Transfer-Encoding: quoted-printable\r\n" +
+				"\r\n" +
+				"Total =3D 10\r\n" +
+				"--inner\r\n" +
+				"Content-Type: text/html; charset=utf-8\r\n" +
+				"\r\n" +
+				"<p>Total = 10</p>\r\n" +
+				"--inner--\r\n" +
+				"--outer\r\n" +
+				"Content-Type: application/pdf; name=\"invoice.pdf\"\r\n" +
+				"Content-Disposition: attachment; filename=\"invoice.pdf\"\r\n" +
+				"Content-Transfer-Encoding: base64\r\n" +
+				"\r\n" +
+				"JVBERi0x\r\nLjQ=\r\n" +
+				"--outer--\r\n",
+			wantSubject: "Invoice ✔",
+			wantText:    "Total = 10",
+			wantHTML:    "<p>Total = 10</p>",
+			wantFrom:    []Address{{Address: "alerts@vendor.com"}},
+			wantAttachments: []Attachment{
+				{Filename: "invoice.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseMessage([]byte(tt.message))
+			require.NoError(t, err)
+
+			require.Equal(t, tt.wantSubject, msg.Subject)
+			require.Equal(t, tt.wantText, msg.Text)
+			require.Equal(t, tt.wantHTML, msg.HTML)
+			require.Equal(t, tt.wantFrom, msg.From)
+			require.Equal(t, tt.wantAttachments, msg.Attachments)
+		})
+	}
+}
+
+func TestParseMessage_Malformed(t *testing.T) {
+	_, err := ParseMessage([]byte(strings.Repeat("no headers here", 3)))
+	require.Error(t, err)
+}
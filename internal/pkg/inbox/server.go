@@ -0,0 +1,372 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const (
+	// DefaultMaxMessageSize is used when the server isn't given a message
+	// size limit.
+	DefaultMaxMessageSize = 10 << 20
+
+	maxRecipients = 100
+	idleTimeout   = 5 * time.Minute
+)
+
+var ErrServerClosed = errors.New("inbox: server closed")
+
+// Envelope is a message accepted by the server, RcptTo only holds the
+// recipients accepted by the backend.
+type Envelope struct {
+	RemoteAddr string
+	MailFrom   string
+	RcptTo     []string
+	Data       []byte
+}
+
+// Backend decides which recipients mail is accepted for and what is done
+// with accepted messages.
+type Backend interface {
+	// Recipient is called for each RCPT TO command, the recipient is
+	// rejected when an error is returned.
+	Recipient(ctx context.Context, addr string) error
+
+	// Deliver is called once a message has been received for at least
+	// one accepted recipient.
+	Deliver(ctx context.Context, envelope *Envelope) error
+}
+
+// Error is an SMTP reply, backends return it to control the reply code
+// sent to the client.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+var (
+	ErrUnknownRecipient = &Error{Code: 550, Message: "5.1.1 mailbox unavailable"}
+	ErrTryAgainLater    = &Error{Code: 451, Message: "4.3.0 temporary failure, try again later"}
+)
+
+// Server is a receive-only SMTP server, it doesn't relay mail and
+// expects TLS to be terminated in front of it.
+type Server struct {
+	// Domain is announced in the greeting.
+	Domain         string
+	MaxMessageSize int64
+	Backend        Backend
+	Logger         log.StdLogger
+
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+func NewServer(domain string, maxMessageSize int64, backend Backend, logger log.StdLogger) *Server {
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultMaxMessageSize
+	}
+
+	if len(domain) == 0 {
+		domain = "localhost"
+	}
+
+	return &Server{
+		Domain:         domain,
+		MaxMessageSize: maxMessageSize,
+		Backend:        backend,
+		Logger:         logger,
+	}
+}
+
+// ListenAndServe listens on addr and serves connections until the server
+// is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+
+			if closed {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Close()
+}
+
+type session struct {
+	server   *Server
+	conn     net.Conn
+	text     *textproto.Conn
+	helo     bool
+	envelope *Envelope
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	ss := &session{server: s, conn: conn, text: textproto.NewConn(conn)}
+	ss.reply(220, fmt.Sprintf("%s ESMTP Convoy", s.Domain))
+
+	for {
+		_ = conn.SetDeadline(time.Now().Add(idleTimeout))
+
+		line, err := ss.text.ReadLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.Logger.WithError(err).Debug("failed to read smtp command")
+			}
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		if quit := ss.handle(strings.ToUpper(cmd), strings.TrimSpace(arg)); quit {
+			return
+		}
+	}
+}
+
+func (ss *session) handle(cmd, arg string) bool {
+	switch cmd {
+	case "HELO", "EHLO":
+		if len(arg) == 0 {
+			ss.reply(501, "5.5.4 domain required")
+			return false
+		}
+
+		ss.helo = true
+		ss.envelope = nil
+
+		if cmd == "HELO" {
+			ss.reply(250, ss.server.Domain)
+			return false
+		}
+
+		ss.reply(250, ss.server.Domain, "8BITMIME", "PIPELINING", fmt.Sprintf("SIZE %d", ss.server.MaxMessageSize))
+	case "MAIL":
+		ss.mail(arg)
+	case "RCPT":
+		ss.rcpt(arg)
+	case "DATA":
+		ss.data()
+	case "RSET":
+		ss.envelope = nil
+		ss.reply(250, "2.0.0 OK")
+	case "NOOP":
+		ss.reply(250, "2.0.0 OK")
+	case "VRFY":
+		ss.reply(252, "2.5.0 cannot verify user")
+	case "QUIT":
+		ss.reply(221, "2.0.0 bye")
+		return true
+	default:
+		ss.reply(502, "5.5.2 command not implemented")
+	}
+
+	return false
+}
+
+func (ss *session) mail(arg string) {
+	if !ss.helo {
+		ss.reply(503, "5.5.1 send HELO or EHLO first")
+		return
+	}
+
+	if ss.envelope != nil {
+		ss.reply(503, "5.5.1 nested MAIL command")
+		return
+	}
+
+	from, params, ok := parsePath(arg, "FROM:")
+	if !ok {
+		ss.reply(501, "5.5.4 syntax: MAIL FROM:<address>")
+		return
+	}
+
+	for _, p := range params {
+		k, v, _ := strings.Cut(p, "=")
+		if strings.EqualFold(k, "SIZE") {
+			size, err := strconv.ParseInt(v, 10, 64)
+			if err == nil && size > ss.server.MaxMessageSize {
+				ss.reply(552, "5.3.4 message too big")
+				return
+			}
+		}
+	}
+
+	ss.envelope = &Envelope{RemoteAddr: ss.conn.RemoteAddr().String(), MailFrom: from}
+	ss.reply(250, "2.1.0 OK")
+}
+
+func (ss *session) rcpt(arg string) {
+	if ss.envelope == nil {
+		ss.reply(503, "5.5.1 send MAIL first")
+		return
+	}
+
+	to, _, ok := parsePath(arg, "TO:")
+	if !ok || len(to) == 0 {
+		ss.reply(501, "5.5.4 syntax: RCPT TO:<address>")
+		return
+	}
+
+	if len(ss.envelope.RcptTo) >= maxRecipients {
+		ss.reply(452, "4.5.3 too many recipients")
+		return
+	}
+
+	if err := ss.server.Backend.Recipient(context.Background(), to); err != nil {
+		ss.replyError(err)
+		return
+	}
+
+	ss.envelope.RcptTo = append(ss.envelope.RcptTo, to)
+	ss.reply(250, "2.1.5 OK")
+}
+
+func (ss *session) data() {
+	if ss.envelope == nil || len(ss.envelope.RcptTo) == 0 {
+		ss.reply(503, "5.5.1 send RCPT first")
+		return
+	}
+
+	ss.reply(354, "end data with <CR><LF>.<CR><LF>")
+
+	// the dot reader has to be drained even when the message is too big,
+	// or the rest of the message is read as commands.
+	r := ss.text.DotReader()
+	data, err := io.ReadAll(io.LimitReader(r, ss.server.MaxMessageSize+1))
+	if err != nil {
+		ss.envelope = nil
+		ss.reply(451, "4.3.0 failed to read message")
+		return
+	}
+
+	if int64(len(data)) > ss.server.MaxMessageSize {
+		_, _ = io.Copy(io.Discard, r)
+		ss.envelope = nil
+		ss.reply(552, "5.3.4 message too big")
+		return
+	}
+
+	envelope := ss.envelope
+	envelope.Data = data
+	ss.envelope = nil
+
+	if err = ss.server.Backend.Deliver(context.Background(), envelope); err != nil {
+		ss.replyError(err)
+		return
+	}
+
+	ss.reply(250, "2.0.0 OK: queued")
+}
+
+func (ss *session) replyError(err error) {
+	var smtpErr *Error
+	if errors.As(err, &smtpErr) {
+		ss.reply(smtpErr.Code, smtpErr.Message)
+		return
+	}
+
+	ss.server.Logger.WithError(err).Error("inbox backend failed")
+	ss.reply(ErrTryAgainLater.Code, ErrTryAgainLater.Message)
+}
+
+func (ss *session) reply(code int, lines ...string) {
+	for i, line := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+
+		if err := ss.text.PrintfLine("%d%s%s", code, sep, line); err != nil {
+			return
+		}
+	}
+}
+
+// parsePath parses the argument to MAIL FROM or RCPT TO, an empty address
+// is returned for the null reverse path.
+func parsePath(arg, prefix string) (string, []string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", nil, false
+	}
+
+	end := strings.Index(arg, ">")
+	if end < 0 {
+		return "", nil, false
+	}
+
+	return arg[1:end], strings.Fields(arg[end+1:]), true
+}
+
+// Start serves mail for email sources on port in the background until ctx
+// is done.
+func Start(ctx context.Context, port uint32, server *Server) {
+	go func() {
+		err := server.ListenAndServe(fmt.Sprintf(":%d", port))
+		if err != nil && !errors.Is(err, ErrServerClosed) {
+			server.Logger.WithError(err).Fatal("failed to start inbound email listener")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+}
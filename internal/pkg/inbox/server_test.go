@@ -0,0 +1,105 @@
+package inbox
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	mu        sync.Mutex
+	envelopes []*Envelope
+}
+
+func (f *fakeBackend) Recipient(_ context.Context, addr string) error {
+	if !strings.HasSuffix(addr, "@inbound.convoy.test") {
+		return ErrUnknownRecipient
+	}
+
+	return nil
+}
+
+func (f *fakeBackend) Deliver(_ context.Context, envelope *Envelope) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.envelopes = append(f.envelopes, envelope)
+	return nil
+}
+
+func startTestServer(t *testing.T, maxMessageSize int64) (string, *fakeBackend) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	backend := &fakeBackend{}
+	srv := NewServer("inbound.convoy.test", maxMessageSize, backend, log.NewLogger(&strings.Builder{}))
+
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	return l.Addr().String(), backend
+}
+
+func TestServer(t *testing.T) {
+	message := "From: alerts@vendor.com\r\n" +
+		"To: abc@inbound.convoy.test\r\n" +
+		"Subject: Invoice paid\r\n" +
+		"\r\n" +
+		"Your invoice was paid.\r\n" +
+		".leading dot\r\n"
+
+	tests := []struct {
+		name           string
+		to             []string
+		maxMessageSize int64
+		wantCode       int
+		wantRcptTo     []string
+	}{
+		{
+			name:       "should deliver message",
+			to:         []string{"abc@inbound.convoy.test"},
+			wantRcptTo: []string{"abc@inbound.convoy.test"},
+		},
+		{
+			name:     "should reject unknown recipient",
+			to:       []string{"abc@example.com"},
+			wantCode: 550,
+		},
+		{
+			name:           "should reject message that is too big",
+			to:             []string{"abc@inbound.convoy.test"},
+			maxMessageSize: 16,
+			wantCode:       552,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, backend := startTestServer(t, tt.maxMessageSize)
+
+			err := smtp.SendMail(addr, nil, "alerts@vendor.com", tt.to, []byte(message))
+			if tt.wantCode != 0 {
+				var smtpErr *textproto.Error
+				require.ErrorAs(t, err, &smtpErr)
+				require.Equal(t, tt.wantCode, smtpErr.Code)
+				require.Empty(t, backend.envelopes)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, backend.envelopes, 1)
+			require.Equal(t, "alerts@vendor.com", backend.envelopes[0].MailFrom)
+			require.Equal(t, tt.wantRcptTo, backend.envelopes[0].RcptTo)
+
+			// the message is received with its line endings normalised
+			require.Equal(t, strings.ReplaceAll(message, "\r\n", "\n"), string(backend.envelopes[0].Data))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
+)
+
+const (
+	// RateLimitedReason and QuotaExceededReason are the reasons ingest
+	// limit rejections are reported with.
+	RateLimitedReason   = "rate_limited"
+	QuotaExceededReason = "quota_exceeded"
+)
+
+// IngestLimitError is returned when an event is rejected by a source's or
+// project's ingest rate limit or by the project's ingest quota.
+type IngestLimitError struct {
+	Reason  string
+	message string
+	delay   time.Duration
+	err     error
+}
+
+func (e *IngestLimitError) Error() string {
+	return e.message
+}
+
+func (e *IngestLimitError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter returns how long the sender should wait before trying again,
+// the limit's window when the limiter doesn't say.
+func (e *IngestLimitError) RetryAfter() time.Duration {
+	if delay := rlimiter.GetRetryAfter(e.err); delay > 0 {
+		return delay
+	}
+
+	return e.delay
+}
+
+// AllowIngest applies the source's ingest rate limit and then the
+// project's, so a noisy source is held back without using up the rest of
+// the project's limit.
+func AllowIngest(ctx context.Context, rl RateLimiter, source *datastore.Source, project *datastore.Project) error {
+	if limit := source.IngestRateLimit; limit != nil {
+		err := rl.AllowWithBurst(ctx, "ingest:source:"+source.UID, limit.Count, limit.Burst, int(limit.Duration))
+		if err != nil {
+			return &IngestLimitError{
+				Reason:  RateLimitedReason,
+				message: "source rate limit exceeded",
+				delay:   time.Duration(limit.Duration) * time.Second,
+				err:     err,
+			}
+		}
+	}
+
+	if project.Config != nil && project.Config.IngestRateLimit != nil {
+		limit := project.Config.IngestRateLimit
+		err := rl.AllowWithBurst(ctx, "ingest:project:"+project.UID, limit.Count, limit.Burst, int(limit.Duration))
+		if err != nil {
+			return &IngestLimitError{
+				Reason:  RateLimitedReason,
+				message: "project rate limit exceeded",
+				delay:   time.Duration(limit.Duration) * time.Second,
+				err:     err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// TakeIngestQuota counts an event against the project's ingest quota.
+// Events aren't counted when the project has no quota.
+func TakeIngestQuota(ctx context.Context, rl RateLimiter, project *datastore.Project) error {
+	var quota datastore.IngestQuotaConfiguration
+	if project.Config != nil {
+		quota = project.Config.GetIngestQuotaConfig()
+	}
+
+	if quota.Count == 0 {
+		return nil
+	}
+
+	windowStart, windowEnd := quota.Window(time.Now())
+	err := rl.TakeQuota(ctx, IngestQuotaKey(project.UID, quota.Period, windowStart), quota.Count, windowEnd)
+	if err != nil {
+		return &IngestLimitError{
+			Reason:  QuotaExceededReason,
+			message: "project ingest quota exceeded",
+			delay:   time.Until(windowEnd),
+			err:     err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+)
+
+func TestAllowIngest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rl := mocks.NewMockRateLimiter(ctrl)
+	ctx := context.Background()
+
+	source := &datastore.Source{UID: "source-1", IngestRateLimit: &datastore.IngestRateLimitConfiguration{Count: 10, Burst: 20, Duration: 60}}
+	project := &datastore.Project{UID: "project-1", Config: &datastore.ProjectConfig{
+		IngestRateLimit: &datastore.IngestRateLimitConfiguration{Count: 100, Burst: 100, Duration: 30},
+	}}
+
+	rl.EXPECT().AllowWithBurst(ctx, "ingest:source:source-1", 10, 20, 60).Return(nil)
+	rl.EXPECT().AllowWithBurst(ctx, "ingest:project:project-1", 100, 100, 30).Return(nil)
+	require.NoError(t, AllowIngest(ctx, rl, source, project))
+
+	// the project's limit isn't used up by a source that's held back
+	rl.EXPECT().AllowWithBurst(ctx, "ingest:source:source-1", 10, 20, 60).Return(errors.New("rate limit exceeded"))
+	err := AllowIngest(ctx, rl, source, project)
+
+	var limitErr *IngestLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, RateLimitedReason, limitErr.Reason)
+	require.Equal(t, "source rate limit exceeded", limitErr.Error())
+	require.Equal(t, time.Minute, limitErr.RetryAfter())
+
+	rl.EXPECT().AllowWithBurst(ctx, "ingest:source:source-1", 10, 20, 60).Return(nil)
+	rl.EXPECT().AllowWithBurst(ctx, "ingest:project:project-1", 100, 100, 30).Return(errors.New("rate limit exceeded"))
+	err = AllowIngest(ctx, rl, source, project)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, "project rate limit exceeded", limitErr.Error())
+}
+
+func TestTakeIngestQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rl := mocks.NewMockRateLimiter(ctrl)
+	ctx := context.Background()
+
+	// projects without a quota aren't counted
+	require.NoError(t, TakeIngestQuota(ctx, rl, &datastore.Project{UID: "project-1"}))
+
+	project := &datastore.Project{UID: "project-1", Config: &datastore.ProjectConfig{
+		IngestQuota: &datastore.IngestQuotaConfiguration{Count: 5, Period: datastore.DailyQuotaPeriod},
+	}}
+
+	rl.EXPECT().TakeQuota(ctx, gomock.Any(), 5, gomock.Any()).Return(nil)
+	require.NoError(t, TakeIngestQuota(ctx, rl, project))
+
+	rl.EXPECT().TakeQuota(ctx, gomock.Any(), 5, gomock.Any()).Return(errors.New("quota exceeded"))
+	err := TakeIngestQuota(ctx, rl, project)
+
+	var limitErr *IngestLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, QuotaExceededReason, limitErr.Reason)
+	require.Positive(t, limitErr.RetryAfter())
+}
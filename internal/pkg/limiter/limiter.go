@@ -2,7 +2,11 @@ package limiter
 
 import (
 	"context"
+	"fmt"
+	"time"
+
 	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
 	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
 )
 
@@ -10,6 +14,18 @@ type RateLimiter interface {
 	// Allow rate limits outgoing events to endpoints based on a rate in a specified time duration by the endpoint id
 	Allow(ctx context.Context, key string, rate int) error
 	AllowWithDuration(ctx context.Context, key string, rate int, duration int) error
+
+	// AllowWithBurst limits key to rate requests every duration seconds, up to burst
+	// requests can be made at once after the key has been idle
+	AllowWithBurst(ctx context.Context, key string, rate int, burst int, duration int) error
+
+	// TakeQuota counts a request against a quota of limit requests, the count
+	// is discarded at expiresAt. It returns an error once limit has been reached,
+	// callers skip it when there's no limit
+	TakeQuota(ctx context.Context, key string, limit int, expiresAt time.Time) error
+
+	// QuotaUsage returns the number of requests counted against key
+	QuotaUsage(ctx context.Context, key string) (int, error)
 }
 
 func NewLimiter(cfg config.Configuration) (RateLimiter, error) {
@@ -20,3 +36,14 @@ func NewLimiter(cfg config.Configuration) (RateLimiter, error) {
 
 	return r, nil
 }
+
+// IngestQuotaKey is the key a project's ingest quota is counted under for
+// the quota window that starts at windowStart. The period is part of the
+// key, so a day's count isn't carried over when it's changed to monthly.
+func IngestQuotaKey(projectID string, period datastore.QuotaPeriod, windowStart time.Time) string {
+	if period != datastore.MonthlyQuotaPeriod {
+		period = datastore.DailyQuotaPeriod
+	}
+
+	return fmt.Sprintf("ingest_quota:%s:%s:%s", projectID, period, windowStart.UTC().Format("20060102"))
+}
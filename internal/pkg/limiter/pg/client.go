@@ -2,14 +2,38 @@ package pg
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"time"
+
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
-var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+var (
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrQuotaExceeded     = errors.New("quota exceeded")
+)
+
+const (
+	takeQuota = `
+	INSERT INTO convoy.token_bucket (key, rate, tokens, expires_at)
+	VALUES ($1, $2, 1, $3)
+	ON CONFLICT (key) DO UPDATE SET
+		tokens = CASE WHEN token_bucket.expires_at < NOW() THEN 1 ELSE token_bucket.tokens + 1 END,
+		expires_at = CASE WHEN token_bucket.expires_at < NOW() THEN excluded.expires_at ELSE token_bucket.expires_at END,
+		rate = excluded.rate,
+		updated_at = NOW()
+	WHERE excluded.rate <= 0 OR token_bucket.tokens < excluded.rate OR token_bucket.expires_at < NOW()
+	RETURNING tokens;
+	`
+
+	fetchQuotaUsage = `
+	SELECT tokens FROM convoy.token_bucket WHERE key = $1 AND expires_at > NOW();
+	`
+)
 
 type SlidingWindowRateLimiter struct {
 	db database.Database
@@ -27,6 +51,42 @@ func (p *SlidingWindowRateLimiter) AllowWithDuration(ctx context.Context, key st
 	return p.takeToken(ctx, key, rate, bucketSize)
 }
 
+// AllowWithBurst allows up to the larger of rate and burst requests in each
+// window, the token bucket can't spread a burst over the window.
+func (p *SlidingWindowRateLimiter) AllowWithBurst(ctx context.Context, key string, rate int, burst int, duration int) error {
+	if burst > rate {
+		rate = burst
+	}
+
+	return p.takeToken(ctx, key, rate, duration)
+}
+
+func (p *SlidingWindowRateLimiter) TakeQuota(ctx context.Context, key string, limit int, expiresAt time.Time) error {
+	var tokens int
+	err := p.db.GetDB().QueryRowxContext(ctx, takeQuota, key, limit, expiresAt).Scan(&tokens)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrQuotaExceeded
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *SlidingWindowRateLimiter) QuotaUsage(ctx context.Context, key string) (int, error) {
+	var tokens int
+	err := p.db.GetDB().QueryRowxContext(ctx, fetchQuotaUsage, key).Scan(&tokens)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return tokens, nil
+}
+
 // TakeToken is a sliding window rate limiter that tries to take a token from the bucket
 //
 // Creates the bucket if it doesn't exist and returns false if it is not successful.
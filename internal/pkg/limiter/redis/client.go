@@ -7,11 +7,34 @@ import (
 
 	"github.com/frain-dev/convoy/internal/pkg/rdb"
 	"github.com/go-redis/redis_rate/v10"
+	"github.com/redis/go-redis/v9"
 )
 
-var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+var (
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrQuotaExceeded     = errors.New("quota exceeded")
+)
+
+// takeQuota increments the quota counter unless it has reached the limit,
+// the counter's expiry is set when it is created. -1 is returned when the
+// quota has been exhausted, a limit of 0 never is.
+var takeQuota = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+if limit > 0 and count >= limit then
+	return -1
+end
+
+count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("EXPIREAT", KEYS[1], ARGV[2])
+end
+
+return count
+`)
 
 type RedisLimiter struct {
+	client  redis.UniversalClient
 	limiter *redis_rate.Limiter
 }
 
@@ -22,7 +45,7 @@ func NewRedisLimiter(addresses []string) (*RedisLimiter, error) {
 	}
 
 	c := redis_rate.NewLimiter(client.Client())
-	r := &RedisLimiter{limiter: c}
+	r := &RedisLimiter{client: client.Client(), limiter: c}
 
 	return r, nil
 }
@@ -75,6 +98,61 @@ func (r *RedisLimiter) AllowWithDuration(ctx context.Context, key string, limit
 	return nil
 }
 
+func (r *RedisLimiter) AllowWithBurst(ctx context.Context, key string, limit int, burst int, duration int) error {
+	if limit == 0 || duration == 0 {
+		return nil
+	}
+
+	if burst < limit {
+		burst = limit
+	}
+
+	l := redis_rate.Limit{
+		Period: time.Second * time.Duration(duration),
+		Rate:   limit,
+		Burst:  burst,
+	}
+
+	result, err := r.limiter.Allow(ctx, key, l)
+	if err != nil {
+		return err
+	}
+
+	if result.Allowed == 0 {
+		return &RedisLimiterError{
+			delay: result.RetryAfter,
+			err:   ErrRateLimitExceeded,
+		}
+	}
+
+	return nil
+}
+
+func (r *RedisLimiter) TakeQuota(ctx context.Context, key string, limit int, expiresAt time.Time) error {
+	count, err := takeQuota.Run(ctx, r.client, []string{key}, limit, expiresAt.Unix()).Int()
+	if err != nil {
+		return err
+	}
+
+	if count < 0 {
+		return &RedisLimiterError{
+			delay: time.Until(expiresAt),
+			err:   ErrQuotaExceeded,
+		}
+	}
+
+	return nil
+}
+
+func (r *RedisLimiter) QuotaUsage(ctx context.Context, key string) (int, error) {
+	count, err := r.client.Get(ctx, key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return count, err
+}
+
 type RedisLimiterError struct {
 	delay time.Duration
 	err   error
@@ -67,3 +67,31 @@ func Test_RateLimitAllow(t *testing.T) {
 		})
 	}
 }
+
+func Test_TakeQuota(t *testing.T) {
+	dsn := getDSN()
+
+	limiter, err := NewRedisLimiter(dsn)
+	require.NoError(t, err)
+
+	key := ulid.Make().String()
+	expiresAt := time.Now().Add(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		err = limiter.TakeQuota(context.Background(), key, 2, expiresAt)
+		require.NoError(t, err)
+	}
+
+	err = limiter.TakeQuota(context.Background(), key, 2, expiresAt)
+	require.Error(t, err)
+	require.ErrorIs(t, GetRawError(err), ErrQuotaExceeded)
+	require.Greater(t, GetRetryAfter(err), time.Duration(0))
+
+	used, err := limiter.QuotaUsage(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, 2, used)
+
+	used, err = limiter.QuotaUsage(context.Background(), ulid.Make().String())
+	require.NoError(t, err)
+	require.Equal(t, 0, used)
+}
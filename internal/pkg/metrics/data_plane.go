@@ -13,6 +13,7 @@ var once sync.Once
 const (
 	projectLabel = "project"
 	sourceLabel  = "source"
+	reasonLabel  = "reason"
 )
 
 // Metrics for the data plane
@@ -21,6 +22,8 @@ type Metrics struct {
 	IngestTotal          *prometheus.CounterVec
 	IngestConsumedTotal  *prometheus.CounterVec
 	IngestErrorsTotal    *prometheus.CounterVec
+	IngestRejectedTotal  *prometheus.CounterVec
+	IngestDuplicateTotal *prometheus.CounterVec
 	EventDeliveryLatency *prometheus.HistogramVec
 }
 
@@ -34,11 +37,13 @@ func GetDPInstance() *Metrics {
 func newMetrics(pr prometheus.Registerer) *Metrics {
 	m := InitMetrics()
 
-	if m.IsEnabled && m.IngestTotal != nil && m.IngestConsumedTotal != nil && m.IngestErrorsTotal != nil {
+	if m.IsEnabled && m.IngestTotal != nil && m.IngestConsumedTotal != nil && m.IngestErrorsTotal != nil && m.IngestRejectedTotal != nil && m.IngestDuplicateTotal != nil {
 		pr.MustRegister(
 			m.IngestTotal,
 			m.IngestConsumedTotal,
 			m.IngestErrorsTotal,
+			m.IngestRejectedTotal,
+			m.IngestDuplicateTotal,
 			m.EventDeliveryLatency,
 		)
 	}
@@ -83,6 +88,20 @@ func InitMetrics() *Metrics {
 			},
 			[]string{projectLabel, sourceLabel},
 		),
+		IngestRejectedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "convoy_ingest_rejected",
+				Help: "Total number of ingest requests rejected by a source's client restrictions, rate limits or quotas",
+			},
+			[]string{projectLabel, sourceLabel, reasonLabel},
+		),
+		IngestDuplicateTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "convoy_ingest_duplicate",
+				Help: "Total number of ingested events whose idempotency key was seen within the source's dedup window",
+			},
+			[]string{projectLabel, sourceLabel},
+		),
 		EventDeliveryLatency: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "convoy_end_to_end_latency",
@@ -122,3 +141,17 @@ func (m *Metrics) IncrementIngestErrorsTotal(source *datastore.Source) {
 	}
 	m.IngestErrorsTotal.With(prometheus.Labels{projectLabel: source.ProjectID, sourceLabel: source.UID}).Inc()
 }
+
+func (m *Metrics) IncrementIngestRejectedTotal(source *datastore.Source, reason string) {
+	if !m.IsEnabled {
+		return
+	}
+	m.IngestRejectedTotal.With(prometheus.Labels{projectLabel: source.ProjectID, sourceLabel: source.UID, reasonLabel: reason}).Inc()
+}
+
+func (m *Metrics) IncrementIngestDuplicateTotal(source *datastore.Source) {
+	if !m.IsEnabled {
+		return
+	}
+	m.IngestDuplicateTotal.With(prometheus.Labels{projectLabel: source.ProjectID, sourceLabel: source.UID}).Inc()
+}
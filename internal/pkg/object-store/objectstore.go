@@ -2,12 +2,26 @@ package objectstore
 
 import (
 	"errors"
+	"io"
 
 	"github.com/frain-dev/convoy/datastore"
 )
 
 type ObjectStore interface {
 	Save(string) error
+
+	// Location returns where a file saved with Save can be found.
+	Location(string) string
+
+	// Open reads the file at a location returned by Location.
+	Open(string) (io.ReadCloser, error)
+
+	// List returns the locations of the files saved from under a
+	// directory.
+	List(string) ([]string, error)
+
+	// Delete removes the file at a location returned by Location.
+	Delete(string) error
 }
 
 type ObjectStoreOptions struct {
@@ -1,7 +1,11 @@
 package objectstore
 
 import (
+	"errors"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 
 	"github.com/frain-dev/convoy/pkg/log"
 )
@@ -25,3 +29,39 @@ func (o *OnPremClient) Save(filename string) error {
 	log.Printf("Successfully saved %q \n", filename)
 	return nil
 }
+
+func (o *OnPremClient) Location(filename string) string {
+	return filename
+}
+
+func (o *OnPremClient) Open(location string) (io.ReadCloser, error) {
+	return os.Open(location)
+}
+
+func (o *OnPremClient) List(dir string) ([]string, error) {
+	var locations []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			locations = append(locations, path)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return locations, err
+}
+
+func (o *OnPremClient) Delete(location string) error {
+	err := os.Remove(location)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
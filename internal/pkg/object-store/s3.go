@@ -1,6 +1,8 @@
 package objectstore
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/frain-dev/convoy/pkg/log"
 )
@@ -45,21 +48,10 @@ func (s3 *S3Client) Save(filename string) error {
 
 	defer file.Close()
 
-	name := filename
-
-	if util.IsStringEmpty(s3.opts.Prefix) {
-		names := strings.Split(filename, "/tmp/")
-		if len(names) > 1 {
-			name = names[1]
-		}
-	} else {
-		name = strings.Replace(filename, "/tmp", s3.opts.Prefix, 1)
-	}
-
 	uploader := s3manager.NewUploader(s3.session)
 	_, err = uploader.Upload(&s3manager.UploadInput{
 		Bucket: aws.String(s3.opts.Bucket),
-		Key:    aws.String(name),
+		Key:    aws.String(s3.key(filename)),
 		Body:   file,
 	})
 
@@ -71,3 +63,80 @@ func (s3 *S3Client) Save(filename string) error {
 	log.Printf("Successfully saved %q to %q\n", filename, s3.opts.Bucket)
 	return nil
 }
+
+func (s3 *S3Client) Location(filename string) string {
+	return fmt.Sprintf("s3://%s/%s", s3.opts.Bucket, s3.key(filename))
+}
+
+func (s3 *S3Client) Open(location string) (io.ReadCloser, error) {
+	bucket, key, err := parseLocation(location)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := awss3.New(s3.session).GetObject(&awss3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+func (s3 *S3Client) List(dir string) ([]string, error) {
+	prefix := strings.TrimSuffix(s3.key(dir), "/") + "/"
+
+	var locations []string
+	err := awss3.New(s3.session).ListObjectsV2Pages(&awss3.ListObjectsV2Input{
+		Bucket: aws.String(s3.opts.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *awss3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			locations = append(locations, fmt.Sprintf("s3://%s/%s", s3.opts.Bucket, aws.StringValue(object.Key)))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+func (s3 *S3Client) Delete(location string) error {
+	bucket, key, err := parseLocation(location)
+	if err != nil {
+		return err
+	}
+
+	_, err = awss3.New(s3.session).DeleteObject(&awss3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func parseLocation(location string) (bucket, key string, err error) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if !ok || !strings.HasPrefix(location, "s3://") {
+		return "", "", fmt.Errorf("invalid s3 location %q", location)
+	}
+
+	return bucket, key, nil
+}
+
+// key is the object key a file under /tmp is uploaded to.
+func (s3 *S3Client) key(filename string) string {
+	if util.IsStringEmpty(s3.opts.Prefix) {
+		names := strings.Split(filename, "/tmp/")
+		if len(names) > 1 {
+			return names[1]
+		}
+
+		return filename
+	}
+
+	return strings.Replace(filename, "/tmp", s3.opts.Prefix, 1)
+}
@@ -0,0 +1,152 @@
+package providers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/crc"
+	"github.com/frain-dev/convoy/pkg/verifier"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	Register(&github{})
+	Register(&twitter{})
+	Register(&shopify{})
+	Register(&stripe{})
+	Register(&slack{})
+	Register(&gitlab{})
+	Register(&twilio{})
+}
+
+// base provides the defaults for providers without a crc check or
+// challenge handshake.
+type base struct{}
+
+func (base) Crc(*datastore.VerifierConfig) crc.Crc { return nil }
+
+func (base) Challenge(*http.Request, []byte) (*ChallengeResponse, bool) { return nil, false }
+
+type github struct{ base }
+
+func (github) Name() datastore.SourceProvider { return datastore.GithubSourceProvider }
+
+func (github) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewGithubVerifier(secret(cfg))
+}
+
+func (github) EventType(r *http.Request, _ []byte) string {
+	return r.Header.Get("X-GitHub-Event")
+}
+
+type twitter struct{ base }
+
+func (twitter) Name() datastore.SourceProvider { return datastore.TwitterSourceProvider }
+
+func (twitter) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewTwitterVerifier(secret(cfg))
+}
+
+func (twitter) Crc(cfg *datastore.VerifierConfig) crc.Crc {
+	return crc.NewTwitterCrc(secret(cfg))
+}
+
+// EventType returns the account activity event key, e.g. tweet_create_events.
+func (twitter) EventType(_ *http.Request, payload []byte) string {
+	var eventType string
+	gjson.ParseBytes(payload).ForEach(func(key, _ gjson.Result) bool {
+		if strings.HasSuffix(key.String(), "_events") {
+			eventType = key.String()
+			return false
+		}
+		return true
+	})
+
+	return eventType
+}
+
+type shopify struct{ base }
+
+func (shopify) Name() datastore.SourceProvider { return datastore.ShopifySourceProvider }
+
+func (shopify) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewShopifyVerifier(secret(cfg))
+}
+
+func (shopify) EventType(r *http.Request, _ []byte) string {
+	return r.Header.Get("X-Shopify-Topic")
+}
+
+type stripe struct{ base }
+
+func (stripe) Name() datastore.SourceProvider { return datastore.StripeSourceProvider }
+
+func (stripe) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewStripeVerifier(secret(cfg), tolerance(cfg))
+}
+
+func (stripe) EventType(_ *http.Request, payload []byte) string {
+	return gjson.GetBytes(payload, "type").String()
+}
+
+type slack struct{ base }
+
+func (slack) Name() datastore.SourceProvider { return datastore.SlackSourceProvider }
+
+func (slack) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewSlackVerifier(secret(cfg), tolerance(cfg))
+}
+
+// Challenge answers the url_verification handshake the Events API
+// sends when a request url is configured.
+func (slack) Challenge(_ *http.Request, payload []byte) (*ChallengeResponse, bool) {
+	if gjson.GetBytes(payload, "type").String() != "url_verification" {
+		return nil, false
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"challenge": gjson.GetBytes(payload, "challenge").String(),
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	return &ChallengeResponse{ContentType: "application/json", Body: body}, true
+}
+
+// EventType prefers the inner event type of event_callback envelopes.
+func (slack) EventType(_ *http.Request, payload []byte) string {
+	if t := gjson.GetBytes(payload, "event.type").String(); t != "" {
+		return t
+	}
+
+	return gjson.GetBytes(payload, "type").String()
+}
+
+type gitlab struct{ base }
+
+func (gitlab) Name() datastore.SourceProvider { return datastore.GitlabSourceProvider }
+
+func (gitlab) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewGitlabVerifier(secret(cfg))
+}
+
+func (gitlab) EventType(r *http.Request, _ []byte) string {
+	return r.Header.Get("X-Gitlab-Event")
+}
+
+type twilio struct{ base }
+
+func (twilio) Name() datastore.SourceProvider { return datastore.TwilioSourceProvider }
+
+func (twilio) Verifier(cfg *datastore.VerifierConfig) verifier.Verifier {
+	return verifier.NewTwilioVerifier(secret(cfg))
+}
+
+// EventType reads the Event Streams type, regular Twilio webhooks
+// don't carry one.
+func (twilio) EventType(_ *http.Request, payload []byte) string {
+	return gjson.GetBytes(payload, "0.type").String()
+}
@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+	"github.com/tidwall/gjson"
+)
+
+var ErrProviderNotConfigured = errors.New("the source does not have a provider to read the event type from")
+
+// ExtractEventType reads the event type of an ingested request as described
+// by cfg. payload is the request body after it has been converted to JSON.
+// An empty string is returned when cfg is nil or the event type isn't
+// present, callers fall back to the source's mask id. Event type functions
+// can require the function libraries of the project with projectID.
+func ExtractEventType(ctx context.Context, projectID string, r *http.Request, cfg *datastore.EventTypeConfig, provider Provider, payload []byte) (string, error) {
+	if cfg == nil {
+		return "", nil
+	}
+
+	switch cfg.Type {
+	case datastore.HeaderEventTypeSource:
+		return r.Header.Get(cfg.Header), nil
+	case datastore.BodyEventTypeSource:
+		return gjson.GetBytes(payload, cfg.Path).String(), nil
+	case datastore.FunctionEventTypeSource:
+		return eventTypeFromFunction(ctx, projectID, r, cfg.Function, payload)
+	case datastore.ProviderEventTypeSource:
+		if provider == nil {
+			return "", ErrProviderNotConfigured
+		}
+
+		return provider.EventType(r, payload), nil
+	default:
+		return "", fmt.Errorf("unsupported event type source %s", cfg.Type)
+	}
+}
+
+func eventTypeFromFunction(ctx context.Context, projectID string, r *http.Request, function string, payload []byte) (string, error) {
+	headers := make(map[string]interface{}, len(r.Header))
+	for k, v := range r.Header {
+		headers[k] = strings.Join(v, ",")
+	}
+
+	arg := map[string]interface{}{
+		"headers": headers,
+		"body":    gjson.ParseBytes(payload).Value(),
+	}
+
+	value, _, err := functions.NewTransformer(ctx, projectID).Transform(function, arg)
+	if err != nil {
+		return "", err
+	}
+
+	if value == nil {
+		return "", nil
+	}
+
+	eventType, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("the event type function should return a string, got %T", value)
+	}
+
+	return eventType, nil
+}
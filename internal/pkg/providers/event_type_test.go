@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExtractEventType(t *testing.T) {
+	github, _ := Get(datastore.GithubSourceProvider)
+
+	tests := map[string]struct {
+		cfg       *datastore.EventTypeConfig
+		provider  Provider
+		payload   string
+		eventType string
+		wantErr   bool
+	}{
+		"not_configured": {
+			payload: `{"type":"invoice.paid"}`,
+		},
+		"header": {
+			cfg:       &datastore.EventTypeConfig{Type: datastore.HeaderEventTypeSource, Header: "X-Event-Type"},
+			payload:   `{}`,
+			eventType: "user.created",
+		},
+		"body_path": {
+			cfg:       &datastore.EventTypeConfig{Type: datastore.BodyEventTypeSource, Path: "data.event"},
+			payload:   `{"data":{"event":"order.paid"}}`,
+			eventType: "order.paid",
+		},
+		"missing_body_path": {
+			cfg:     &datastore.EventTypeConfig{Type: datastore.BodyEventTypeSource, Path: "data.event"},
+			payload: `{"data":{}}`,
+		},
+		"function": {
+			cfg: &datastore.EventTypeConfig{
+				Type:     datastore.FunctionEventTypeSource,
+				Function: `function transform(req) { return req.body.resource + "." + req.headers["X-Action"] }`,
+			},
+			payload:   `{"resource":"invoice"}`,
+			eventType: "invoice.deleted",
+		},
+		"function_returning_non_string": {
+			cfg: &datastore.EventTypeConfig{
+				Type:     datastore.FunctionEventTypeSource,
+				Function: `function transform(req) { return 42 }`,
+			},
+			payload: `{}`,
+			wantErr: true,
+		},
+		"provider": {
+			cfg:       &datastore.EventTypeConfig{Type: datastore.ProviderEventTypeSource},
+			provider:  github,
+			payload:   `{}`,
+			eventType: "push",
+		},
+		"provider_not_set": {
+			cfg:     &datastore.EventTypeConfig{Type: datastore.ProviderEventTypeSource},
+			payload: `{}`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/ingest/abc", strings.NewReader(tc.payload))
+			require.NoError(t, err)
+			req.Header.Set("X-Event-Type", "user.created")
+			req.Header.Set("X-Action", "deleted")
+			req.Header.Set("X-GitHub-Event", "push")
+
+			eventType, err := ExtractEventType(context.Background(), "project-id", req, tc.cfg, tc.provider, []byte(tc.payload))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.eventType, eventType)
+		})
+	}
+}
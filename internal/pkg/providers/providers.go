@@ -0,0 +1,109 @@
+// Package providers holds the registry of predefined source providers.
+// Each provider declares how requests from it are verified, how it performs
+// CRC or in-band challenge handshakes and how the event type is extracted
+// from its requests. The ingest handlers look providers up here, adding a
+// provider requires a datastore.SourceProvider value and registering it.
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/crc"
+	"github.com/frain-dev/convoy/pkg/verifier"
+)
+
+// Provider describes a predefined webhook source provider.
+type Provider interface {
+	// Name is the identifier stored on datastore.Source.Provider.
+	Name() datastore.SourceProvider
+
+	// Verifier builds the request verifier for a source using this provider.
+	Verifier(cfg *datastore.VerifierConfig) verifier.Verifier
+
+	// Crc returns the handler for the provider's CRC check, or nil when
+	// the provider doesn't perform one.
+	Crc(cfg *datastore.VerifierConfig) crc.Crc
+
+	// Challenge inspects an ingested request, when it is a handshake
+	// rather than an event it returns the response to send back.
+	Challenge(r *http.Request, payload []byte) (*ChallengeResponse, bool)
+
+	// EventType extracts the event type from a request, it returns an
+	// empty string when the type cannot be determined.
+	EventType(r *http.Request, payload []byte) string
+}
+
+// ChallengeResponse is written back to the caller when a provider
+// sends a handshake request to the ingest url.
+type ChallengeResponse struct {
+	ContentType string
+	Body        []byte
+}
+
+var registry = struct {
+	sync.RWMutex
+	providers map[datastore.SourceProvider]Provider
+}{providers: map[datastore.SourceProvider]Provider{}}
+
+// Register adds p to the registry. It panics if p's name isn't one of
+// the datastore.SourceProvider values or it has already been registered.
+func Register(p Provider) {
+	if !p.Name().IsValid() {
+		panic(fmt.Sprintf("providers: unknown provider %s", p.Name()))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.providers[p.Name()]; ok {
+		panic(fmt.Sprintf("providers: provider %s registered twice", p.Name()))
+	}
+
+	registry.providers[p.Name()] = p
+}
+
+// Get returns the provider registered under name.
+func Get(name datastore.SourceProvider) (Provider, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	p, ok := registry.providers[name]
+	return p, ok
+}
+
+// Names returns the names of all registered providers, sorted.
+func Names() []datastore.SourceProvider {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]datastore.SourceProvider, 0, len(registry.providers))
+	for name := range registry.providers {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func secret(cfg *datastore.VerifierConfig) string {
+	if cfg == nil || cfg.HMac == nil {
+		return ""
+	}
+
+	return cfg.HMac.Secret
+}
+
+// tolerance is the age the signed timestamp of a request can have, the
+// verifiers use their default when it isn't set.
+func tolerance(cfg *datastore.VerifierConfig) time.Duration {
+	if cfg == nil || cfg.HMac == nil {
+		return 0
+	}
+
+	return time.Duration(cfg.HMac.Tolerance) * time.Second
+}
@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Registry(t *testing.T) {
+	for _, name := range []datastore.SourceProvider{
+		datastore.GithubSourceProvider,
+		datastore.TwitterSourceProvider,
+		datastore.ShopifySourceProvider,
+		datastore.StripeSourceProvider,
+		datastore.SlackSourceProvider,
+		datastore.GitlabSourceProvider,
+		datastore.TwilioSourceProvider,
+	} {
+		p, ok := Get(name)
+		require.True(t, ok, name)
+		require.Equal(t, name, p.Name())
+		require.True(t, name.IsValid())
+		require.NotNil(t, p.Verifier(&datastore.VerifierConfig{HMac: &datastore.HMac{Secret: "Convoy"}}))
+	}
+
+	_, ok := Get("unknown")
+	require.False(t, ok)
+	require.False(t, datastore.SourceProvider("unknown").IsValid())
+
+	require.Panics(t, func() { Register(&github{}) })
+}
+
+func Test_Crc(t *testing.T) {
+	p, _ := Get(datastore.TwitterSourceProvider)
+	require.NotNil(t, p.Crc(&datastore.VerifierConfig{HMac: &datastore.HMac{Secret: "Convoy"}}))
+
+	p, _ = Get(datastore.GithubSourceProvider)
+	require.Nil(t, p.Crc(nil))
+}
+
+func Test_SlackChallenge(t *testing.T) {
+	p, _ := Get(datastore.SlackSourceProvider)
+
+	res, ok := p.Challenge(nil, []byte(`{"token":"abc","challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P","type":"url_verification"}`))
+	require.True(t, ok)
+	require.Equal(t, "application/json", res.ContentType)
+	require.JSONEq(t, `{"challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"}`, string(res.Body))
+
+	_, ok = p.Challenge(nil, []byte(`{"type":"event_callback","event":{"type":"app_mention"}}`))
+	require.False(t, ok)
+}
+
+func Test_EventType(t *testing.T) {
+	tests := map[string]struct {
+		provider  datastore.SourceProvider
+		headers   map[string]string
+		payload   string
+		eventType string
+	}{
+		"github": {
+			provider:  datastore.GithubSourceProvider,
+			headers:   map[string]string{"X-GitHub-Event": "push"},
+			payload:   `{}`,
+			eventType: "push",
+		},
+		"shopify": {
+			provider:  datastore.ShopifySourceProvider,
+			headers:   map[string]string{"X-Shopify-Topic": "orders/create"},
+			payload:   `{}`,
+			eventType: "orders/create",
+		},
+		"gitlab": {
+			provider:  datastore.GitlabSourceProvider,
+			headers:   map[string]string{"X-Gitlab-Event": "Push Hook"},
+			payload:   `{}`,
+			eventType: "Push Hook",
+		},
+		"stripe": {
+			provider:  datastore.StripeSourceProvider,
+			payload:   `{"id":"evt_1","type":"invoice.paid"}`,
+			eventType: "invoice.paid",
+		},
+		"slack_event_callback": {
+			provider:  datastore.SlackSourceProvider,
+			payload:   `{"type":"event_callback","event":{"type":"app_mention"}}`,
+			eventType: "app_mention",
+		},
+		"twitter": {
+			provider:  datastore.TwitterSourceProvider,
+			payload:   `{"for_user_id":"2244994945","tweet_create_events":[]}`,
+			eventType: "tweet_create_events",
+		},
+		"twilio_without_type": {
+			provider:  datastore.TwilioSourceProvider,
+			payload:   `From=%2B1234&Body=Hello`,
+			eventType: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "URL", strings.NewReader(tc.payload))
+			require.NoError(t, err)
+
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			p, ok := Get(tc.provider)
+			require.True(t, ok)
+			require.Equal(t, tc.eventType, p.EventType(req, []byte(tc.payload)))
+		})
+	}
+}
+
+func Test_Tolerance(t *testing.T) {
+	require.Equal(t, time.Duration(0), tolerance(nil))
+	require.Equal(t, time.Duration(0), tolerance(&datastore.VerifierConfig{HMac: &datastore.HMac{}}))
+	require.Equal(t, 10*time.Minute, tolerance(&datastore.VerifierConfig{HMac: &datastore.HMac{Tolerance: 600}}))
+}
@@ -82,6 +82,39 @@ func (k *Amqp) Verify() error {
 
 }
 
+// Publish sends payload to the queue, or to the bound exchange with the
+// routing key when there's one. headers are sent as message headers.
+func (k *Amqp) Publish(ctx context.Context, payload []byte, headers map[string]string) error {
+	conn, err := k.dialer()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	exchange, routingKey := "", k.Cfg.Queue
+	if k.Cfg.BoundExchange != nil && *k.Cfg.BoundExchange != "" {
+		exchange, routingKey = *k.Cfg.BoundExchange, k.Cfg.RoutingKey
+	}
+
+	table := make(amqp.Table, len(headers))
+	for key, value := range headers {
+		table[key] = value
+	}
+
+	return ch.PublishWithContext(ctx, exchange, routingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Headers:      table,
+		Body:         payload,
+	})
+}
+
 func (k *Amqp) consume() {
 	conn, err := k.dialer()
 	if err != nil {
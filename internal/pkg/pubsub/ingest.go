@@ -10,8 +10,10 @@ import (
 	"time"
 
 	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
-	"github.com/frain-dev/convoy/pkg/transform"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/datastore"
@@ -35,13 +37,15 @@ type Ingest struct {
 	ticker      *time.Ticker
 	queue       queue.Queuer
 	rateLimiter limiter.RateLimiter
+	dedup       dedup.Store
+	projectRepo datastore.ProjectRepository
 	sources     map[memorystore.Key]*PubSubSource
 	table       *memorystore.Table
 	log         log.StdLogger
 	instanceId  string
 }
 
-func NewIngest(ctx context.Context, table *memorystore.Table, queue queue.Queuer, log log.StdLogger, rateLimiter limiter.RateLimiter, instanceId string) (*Ingest, error) {
+func NewIngest(ctx context.Context, table *memorystore.Table, queue queue.Queuer, log log.StdLogger, rateLimiter limiter.RateLimiter, dedupStore dedup.Store, projectRepo datastore.ProjectRepository, instanceId string) (*Ingest, error) {
 	ctx = context.WithValue(ctx, ingestCtx, nil)
 	i := &Ingest{
 		ctx:         ctx,
@@ -49,6 +53,8 @@ func NewIngest(ctx context.Context, table *memorystore.Table, queue queue.Queuer
 		table:       table,
 		queue:       queue,
 		rateLimiter: rateLimiter,
+		dedup:       dedupStore,
+		projectRepo: projectRepo,
 		instanceId:  instanceId,
 		sources:     make(map[memorystore.Key]*PubSubSource),
 		ticker:      time.NewTicker(time.Duration(1) * time.Second),
@@ -131,9 +137,21 @@ func (i *Ingest) run() error {
 	return nil
 }
 
-func (i *Ingest) handler(_ context.Context, source *datastore.Source, msg string, metadata []byte) error {
+func (i *Ingest) handler(ctx context.Context, source *datastore.Source, msg string, metadata []byte) (err error) {
 	defer handlePanic(source)
 
+	// a message rejected by the ingest limits fails like any other, the
+	// brokers that don't acknowledge failed messages deliver it again
+	project, err := i.projectRepo.FetchProjectByID(ctx, source.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if err = limiter.AllowIngest(ctx, i.rateLimiter, source, project); err != nil {
+		metrics.GetDPInstance().IncrementIngestRejectedTotal(source, limiter.RateLimitedReason)
+		return err
+	}
+
 	// unmarshal to an interface{} struct
 	var raw any
 	if err := json.Unmarshal([]byte(msg), &raw); err != nil {
@@ -151,7 +169,7 @@ func (i *Ingest) handler(_ context.Context, source *datastore.Source, msg string
 
 	var payload any
 	if source.BodyFunction != nil && !util.IsStringEmpty(*source.BodyFunction) {
-		t := transform.NewTransformer()
+		t := functions.NewTransformer(ctx, source.ProjectID)
 		p, _, err := t.Transform(*source.BodyFunction, raw)
 		if err != nil {
 			return err
@@ -201,7 +219,7 @@ func (i *Ingest) handler(_ context.Context, source *datastore.Source, msg string
 
 	headers := map[string]string{}
 	if source.HeaderFunction != nil && !util.IsStringEmpty(*source.HeaderFunction) {
-		t := transform.NewTransformer()
+		t := functions.NewTransformer(ctx, source.ProjectID)
 		h, _, transErr := t.Transform(*source.HeaderFunction, headerMap)
 		if transErr != nil {
 			return transErr
@@ -231,6 +249,37 @@ func (i *Ingest) handler(_ context.Context, source *datastore.Source, msg string
 
 	//fmt.Printf("payload: %s\n %+v\n %+v\n\n", source.Name, payload, headers)
 
+	// Messages redelivered within the source's dedup window are dropped,
+	// the key is forgotten if the message can't be queued so it's retried.
+	if !util.IsStringEmpty(convoyEvent.IdempotencyKey) {
+		dedupKey := dedup.Key(source.ProjectID, convoyEvent.IdempotencyKey)
+		seen, seenErr := i.dedup.Seen(ctx, dedupKey, dedup.Window(source.DedupWindow))
+		if seenErr != nil {
+			return seenErr
+		}
+
+		if seen {
+			metrics.GetDPInstance().IncrementIngestDuplicateTotal(source)
+			i.log.Infof("duplicate message with idempotency key %s from source %s will not be sent", convoyEvent.IdempotencyKey, source.UID)
+			return nil
+		}
+
+		defer func() {
+			if err == nil {
+				return
+			}
+
+			if forgetErr := i.dedup.Forget(ctx, dedupKey); forgetErr != nil {
+				i.log.WithError(forgetErr).Errorf("failed to forget idempotency key %s", convoyEvent.IdempotencyKey)
+			}
+		}()
+	}
+
+	if err = limiter.TakeIngestQuota(ctx, i.rateLimiter, project); err != nil {
+		metrics.GetDPInstance().IncrementIngestRejectedTotal(source, limiter.QuotaExceededReason)
+		return err
+	}
+
 	messageType := headers[ConvoyMessageTypeHeader]
 	switch messageType {
 	case "single":
@@ -255,6 +304,11 @@ func (i *Ingest) handler(_ context.Context, source *datastore.Source, msg string
 			return err
 		}
 
+		err := ce.OffloadPayload(ctx)
+		if err != nil {
+			return err
+		}
+
 		eventByte, err := msgpack.EncodeMsgPack(ce)
 		if err != nil {
 			return err
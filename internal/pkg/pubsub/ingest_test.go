@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	"github.com/frain-dev/convoy/internal/pkg/memorystore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+)
+
+func TestIngest_Handler_IngestLimits(t *testing.T) {
+	ctx := context.Background()
+	msg := `{"endpoint_id":"endpoint-1","event_type":"invoice.paid","data":{"id":1}}`
+	metadata, err := msgpack.EncodeMsgPack(map[string]string{})
+	require.NoError(t, err)
+
+	source := &datastore.Source{
+		UID:             "source-1",
+		ProjectID:       "project-1",
+		IngestRateLimit: &datastore.IngestRateLimitConfiguration{Count: 1, Burst: 1, Duration: 60},
+	}
+	project := &datastore.Project{UID: "project-1", Config: &datastore.ProjectConfig{
+		IngestQuota: &datastore.IngestQuotaConfiguration{Count: 1, Period: datastore.DailyQuotaPeriod},
+	}}
+
+	tests := []struct {
+		name    string
+		dbFn    func(rl *mocks.MockRateLimiter, q *mocks.MockQueuer)
+		wantErr bool
+	}{
+		{
+			name: "should_queue_message_within_limits",
+			dbFn: func(rl *mocks.MockRateLimiter, q *mocks.MockQueuer) {
+				rl.EXPECT().AllowWithBurst(gomock.Any(), "ingest:source:source-1", 1, 1, 60).Return(nil)
+				rl.EXPECT().TakeQuota(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil)
+				q.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "should_reject_rate_limited_message",
+			dbFn: func(rl *mocks.MockRateLimiter, q *mocks.MockQueuer) {
+				rl.EXPECT().AllowWithBurst(gomock.Any(), "ingest:source:source-1", 1, 1, 60).Return(errors.New("rate limit exceeded"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "should_reject_message_over_quota",
+			dbFn: func(rl *mocks.MockRateLimiter, q *mocks.MockQueuer) {
+				rl.EXPECT().AllowWithBurst(gomock.Any(), "ingest:source:source-1", 1, 1, 60).Return(nil)
+				rl.EXPECT().TakeQuota(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(errors.New("quota exceeded"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rl := mocks.NewMockRateLimiter(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			projectRepo.EXPECT().FetchProjectByID(gomock.Any(), "project-1").Return(project, nil)
+			tt.dbFn(rl, q)
+
+			i, err := NewIngest(ctx, memorystore.NewTable(), q, log.NewLogger(io.Discard), rl, nil, projectRepo, "")
+			require.NoError(t, err)
+
+			err = i.handler(ctx, source, msg, metadata)
+			if tt.wantErr {
+				var limitErr *limiter.IngestLimitError
+				require.ErrorAs(t, err, &limitErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -110,6 +110,36 @@ func (k *Kafka) Verify() error {
 
 }
 
+// Publish writes payload to the topic, headers are sent as message headers
+func (k *Kafka) Publish(ctx context.Context, payload []byte, headers map[string]string) error {
+	dialer, err := k.dialer()
+	if err != nil {
+		return err
+	}
+
+	transport := &kafka.Transport{
+		DialTimeout: dialer.Timeout,
+		SASL:        dialer.SASLMechanism,
+		TLS:         dialer.TLS,
+	}
+	defer transport.CloseIdleConnections()
+
+	w := &kafka.Writer{
+		Addr:      kafka.TCP(k.Cfg.Brokers...),
+		Topic:     k.Cfg.TopicName,
+		Balancer:  &kafka.LeastBytes{},
+		Transport: transport,
+	}
+	defer w.Close()
+
+	m := kafka.Message{Value: payload, Headers: make([]kafka.Header, 0, len(headers))}
+	for key, value := range headers {
+		m.Headers = append(m.Headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	return w.WriteMessages(ctx, m)
+}
+
 func (k *Kafka) consume() {
 	dialer, err := k.dialer()
 	if err != nil {
@@ -79,6 +79,40 @@ func (s *Sqs) Verify() error {
 	return nil
 }
 
+// Publish sends payload to the queue, headers are sent as message attributes
+func (s *Sqs) Publish(ctx context.Context, payload []byte, headers map[string]string) error {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(s.Cfg.DefaultRegion),
+		Credentials: credentials.NewStaticCredentials(s.Cfg.AccessKeyID, s.Cfg.SecretKey, ""),
+	})
+	if err != nil {
+		return err
+	}
+
+	svc := sqs.New(sess)
+	url, err := svc.GetQueueUrlWithContext(ctx, &sqs.GetQueueUrlInput{
+		QueueName: &s.Cfg.QueueName,
+	})
+	if err != nil {
+		return err
+	}
+
+	attributes := make(map[string]*sqs.MessageAttributeValue, len(headers))
+	for k, v := range headers {
+		attributes[k] = &sqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(v),
+		}
+	}
+
+	_, err = svc.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		QueueUrl:          url.QueueUrl,
+		MessageBody:       aws.String(string(payload)),
+		MessageAttributes: attributes,
+	})
+	return err
+}
+
 func (s *Sqs) consume() {
 	sess, err := session.NewSession(&aws.Config{
 		Region:      aws.String(s.Cfg.DefaultRegion),
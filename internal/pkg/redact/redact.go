@@ -0,0 +1,465 @@
+// Package redact masks PII in the payloads and headers convoy keeps, using
+// the redaction rules of a project.
+//
+// The rules are applied to an event's data, raw and original body and
+// headers, and to the response bodies and headers of delivery attempts
+// when they're saved, to the records written by the exporter, and to the
+// events, deliveries and attempts returned by the API. The payload sent to
+// an endpoint is never redacted. Events are saved redacted and a delivery's
+// payload is kept as it was sent until the delivery succeeds or fails for
+// good, events and deliveries the rules masked are marked as redacted and
+// can't be replayed, retried or force resent since they'd send the masked
+// payload.
+//
+// Paths select payload fields e.g. $.customer.email, the leading $. is
+// optional, a * matches any key or array element and arrays are traversed
+// when a path names a key, so $.items.card matches the card of every item.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/memorystore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+// Mask replaces the redacted values.
+const Mask = "[REDACTED]"
+
+type pattern struct {
+	re *regexp.Regexp
+
+	// valid reports whether a match is really PII, it's nil when every
+	// match is.
+	valid func(string) bool
+}
+
+var detectors = map[datastore.RedactionDetector]pattern{
+	datastore.EmailRedactionDetector:      {re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	datastore.CardNumberRedactionDetector: {re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: isCardNumber},
+	datastore.SSNRedactionDetector:        {re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+}
+
+// Redactor applies a project's redaction rules, a nil Redactor leaves
+// everything as it is.
+type Redactor struct {
+	paths    [][]string
+	headers  map[string]bool
+	patterns []pattern
+}
+
+// New returns the Redactor for cfg, or nil when cfg has no rules.
+func New(cfg datastore.RedactionConfiguration) (*Redactor, error) {
+	if cfg.IsZero() {
+		return nil, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{headers: make(map[string]bool, len(cfg.Headers))}
+
+	for _, p := range cfg.Paths {
+		p = strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+		r.paths = append(r.paths, strings.Split(p, "."))
+	}
+
+	for _, h := range cfg.Headers {
+		r.headers[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+
+	for _, d := range cfg.Detectors {
+		r.patterns = append(r.patterns, detectors[d])
+	}
+
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", p, err)
+		}
+		r.patterns = append(r.patterns, pattern{re: re})
+	}
+
+	return r, nil
+}
+
+// redactors caches the compiled Redactor of each project with the rules
+// it was compiled from, it's compiled again when the rules change.
+var redactors = memorystore.NewTable()
+
+type compiled struct {
+	cfg datastore.RedactionConfiguration
+	r   *Redactor
+}
+
+// ForProject returns the Redactor for the project's redaction rules, or nil
+// when it has none.
+func ForProject(p *datastore.Project) *Redactor {
+	if p == nil || p.Config == nil {
+		return nil
+	}
+
+	key := memorystore.NewKey("redaction", p.UID)
+	if row := redactors.Get(key); row != nil {
+		if c, ok := row.Value().(*compiled); ok && sameConfig(c.cfg, p.Config.Redaction) {
+			return c.r
+		}
+	}
+
+	r, err := New(p.Config.Redaction)
+	if err != nil {
+		// the rules are validated when they're saved, so this is unexpected
+		log.WithError(err).Errorf("failed to load the redaction rules of project %s", p.UID)
+		return nil
+	}
+
+	redactors.Upsert(key, &compiled{cfg: copyConfig(p.Config.Redaction), r: r})
+	return r
+}
+
+// Invalidate drops the cached Redactor of the project, it's called when
+// the project's rules are updated.
+func Invalidate(projectID string) {
+	redactors.Delete(memorystore.NewKey("redaction", projectID))
+}
+
+func sameConfig(a, b datastore.RedactionConfiguration) bool {
+	return slices.Equal(a.Paths, b.Paths) && slices.Equal(a.Headers, b.Headers) &&
+		slices.Equal(a.Detectors, b.Detectors) && slices.Equal(a.Patterns, b.Patterns)
+}
+
+func copyConfig(cfg datastore.RedactionConfiguration) datastore.RedactionConfiguration {
+	c := cfg
+	c.Paths = append([]string(nil), cfg.Paths...)
+	c.Headers = append([]string(nil), cfg.Headers...)
+	c.Detectors = append([]datastore.RedactionDetector(nil), cfg.Detectors...)
+	c.Patterns = append([]string(nil), cfg.Patterns...)
+	return c
+}
+
+// Payload redacts b, the paths are only applied when it's JSON. b is
+// returned as it is when nothing was redacted.
+func (r *Redactor) Payload(b []byte) []byte {
+	if r == nil || len(b) == 0 {
+		return b
+	}
+
+	if !json.Valid(b) {
+		s, changed := r.maskText(string(b))
+		if !changed {
+			return b
+		}
+		return []byte(s)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return b
+	}
+
+	v, changed := r.value(v)
+	if !changed {
+		return b
+	}
+
+	out, err := marshal(v)
+	if err != nil {
+		return b
+	}
+
+	return out
+}
+
+// Text is Payload for strings.
+func (r *Redactor) Text(s string) string {
+	if r == nil || len(s) == 0 {
+		return s
+	}
+
+	return string(r.Payload([]byte(s)))
+}
+
+// Value redacts a decoded JSON value, maps and slices are changed in place.
+func (r *Redactor) Value(v interface{}) interface{} {
+	if r == nil {
+		return v
+	}
+
+	v, _ = r.value(v)
+	return v
+}
+
+// Header redacts the value of the header name.
+func (r *Redactor) Header(name, value string) string {
+	if r == nil {
+		return value
+	}
+
+	if r.headers[strings.ToLower(name)] {
+		return Mask
+	}
+
+	s, _ := r.maskText(value)
+	return s
+}
+
+// Headers returns a redacted copy of h.
+func (r *Redactor) Headers(h httpheader.HTTPHeader) httpheader.HTTPHeader {
+	c, _ := r.maskHeaders(h)
+	return c
+}
+
+func (r *Redactor) maskHeaders(h httpheader.HTTPHeader) (httpheader.HTTPHeader, bool) {
+	if r == nil || h == nil {
+		return h, false
+	}
+
+	var changed bool
+	c := make(httpheader.HTTPHeader, len(h))
+	for name, values := range h {
+		redacted := make([]string, len(values))
+		for i, value := range values {
+			redacted[i] = r.Header(name, value)
+			changed = changed || redacted[i] != value
+		}
+		c[name] = redacted
+	}
+
+	return c, changed
+}
+
+// AttemptHeaders returns a redacted copy of the headers of an attempt.
+func (r *Redactor) AttemptHeaders(h datastore.HttpHeader) datastore.HttpHeader {
+	if r == nil || h == nil {
+		return h
+	}
+
+	c := make(datastore.HttpHeader, len(h))
+	for name, value := range h {
+		c[name] = r.Header(name, value)
+	}
+
+	return c
+}
+
+// Event returns a redacted copy of e, it's marked as redacted when the
+// rules masked any of it.
+func (r *Redactor) Event(e *datastore.Event) *datastore.Event {
+	if r == nil || e == nil {
+		return e
+	}
+
+	var headersChanged bool
+	c := *e
+	c.Data = r.Payload(e.Data)
+	c.Raw = r.Text(e.Raw)
+	c.OriginalBody = r.Text(e.OriginalBody)
+	c.Headers, headersChanged = r.maskHeaders(e.Headers)
+	c.Redacted = e.Redacted || headersChanged || !bytes.Equal(c.Data, e.Data) ||
+		c.Raw != e.Raw || c.OriginalBody != e.OriginalBody
+
+	return &c
+}
+
+// Attempt returns a redacted copy of a.
+func (r *Redactor) Attempt(a datastore.DeliveryAttempt) datastore.DeliveryAttempt {
+	if r == nil {
+		return a
+	}
+
+	a.ResponseData = r.Text(a.ResponseData)
+	a.RequestHeader = r.AttemptHeaders(a.RequestHeader)
+	a.ResponseHeader = r.AttemptHeaders(a.ResponseHeader)
+
+	return a
+}
+
+// Metadata returns a copy of m with the delivery's payload redacted, it's
+// marked as redacted when the rules masked any of it.
+func (r *Redactor) Metadata(m *datastore.Metadata) *datastore.Metadata {
+	if r == nil || m == nil {
+		return m
+	}
+
+	c := *m
+	c.Data = r.Payload(m.Data)
+	c.Raw = r.Text(m.Raw)
+	c.Redacted = m.Redacted || !bytes.Equal(c.Data, m.Data) || c.Raw != m.Raw
+
+	return &c
+}
+
+// Delivery returns a redacted copy of ed. Deliveries are saved with the
+// payload sent to the endpoint until they're finished, so it's used to
+// mask them when they're read.
+func (r *Redactor) Delivery(ed *datastore.EventDelivery) *datastore.EventDelivery {
+	if r == nil || ed == nil {
+		return ed
+	}
+
+	c := *ed
+	c.Headers = r.Headers(ed.Headers)
+	c.Event = r.Event(ed.Event)
+
+	c.Metadata = r.Metadata(ed.Metadata)
+
+	if ed.DeliveryAttempts != nil {
+		c.DeliveryAttempts = make(datastore.DeliveryAttempts, len(ed.DeliveryAttempts))
+		for i, a := range ed.DeliveryAttempts {
+			c.DeliveryAttempts[i] = r.Attempt(a)
+		}
+	}
+
+	return &c
+}
+
+func (r *Redactor) value(v interface{}) (interface{}, bool) {
+	var changed bool
+	for _, path := range r.paths {
+		var ok bool
+		v, ok = maskPath(v, path)
+		changed = changed || ok
+	}
+
+	v, ok := r.maskValues(v)
+	return v, changed || ok
+}
+
+// maskPath masks the values of v path selects.
+func maskPath(v interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return Mask, true
+	}
+
+	var changed bool
+	key, rest := path[0], path[1:]
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if key != "*" && key != k {
+				continue
+			}
+
+			var ok bool
+			t[k], ok = maskPath(child, rest)
+			changed = changed || ok
+		}
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+		for i, child := range t {
+			var ok bool
+			switch {
+			case key == "*":
+				t[i], ok = maskPath(child, rest)
+			case err == nil:
+				if i != index {
+					continue
+				}
+				t[i], ok = maskPath(child, rest)
+			default:
+				// the key is looked up in every element
+				t[i], ok = maskPath(child, path)
+			}
+			changed = changed || ok
+		}
+	}
+
+	return v, changed
+}
+
+// maskValues applies the patterns to the strings and numbers in v.
+func (r *Redactor) maskValues(v interface{}) (interface{}, bool) {
+	if len(r.patterns) == 0 {
+		return v, false
+	}
+
+	var changed bool
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			var ok bool
+			t[k], ok = r.maskValues(child)
+			changed = changed || ok
+		}
+	case []interface{}:
+		for i, child := range t {
+			var ok bool
+			t[i], ok = r.maskValues(child)
+			changed = changed || ok
+		}
+	case string:
+		return r.maskText(t)
+	case json.Number:
+		// e.g. a card number sent as a number
+		if _, ok := r.maskText(t.String()); ok {
+			return Mask, true
+		}
+	}
+
+	return v, changed
+}
+
+func (r *Redactor) maskText(s string) (string, bool) {
+	var changed bool
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.valid != nil && !p.valid(match) {
+				return match
+			}
+
+			changed = true
+			return Mask
+		})
+	}
+
+	return s, changed
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// isCardNumber reports whether the digits of s pass the Luhn check.
+func isCardNumber(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		n++
+	}
+
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,219 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+)
+
+func TestNew(t *testing.T) {
+	r, err := New(datastore.RedactionConfiguration{})
+	require.NoError(t, err)
+	require.Nil(t, r)
+
+	_, err = New(datastore.RedactionConfiguration{Patterns: []string{"("}})
+	require.Error(t, err)
+
+	_, err = New(datastore.RedactionConfiguration{Detectors: []datastore.RedactionDetector{"phone"}})
+	require.Error(t, err)
+}
+
+func TestRedactor_Payload(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  datastore.RedactionConfiguration
+		in   string
+		want string
+	}{
+		{
+			name: "should_mask_path",
+			cfg:  datastore.RedactionConfiguration{Paths: []string{"$.card.number"}},
+			in:   `{"card": {"number": "4111 1111 1111 1111", "brand": "visa"}}`,
+			want: `{"card": {"number": "[REDACTED]", "brand": "visa"}}`,
+		},
+		{
+			name: "should_mask_path_without_prefix",
+			cfg:  datastore.RedactionConfiguration{Paths: []string{"customer"}},
+			in:   `{"customer": {"name": "ada"}, "amount": 10}`,
+			want: `{"customer": "[REDACTED]", "amount": 10}`,
+		},
+		{
+			name: "should_mask_every_array_element",
+			cfg:  datastore.RedactionConfiguration{Paths: []string{"$.items.email"}},
+			in:   `{"items": [{"email": "a"}, {"email": "b", "id": 1}]}`,
+			want: `{"items": [{"email": "[REDACTED]"}, {"email": "[REDACTED]", "id": 1}]}`,
+		},
+		{
+			name: "should_mask_array_index",
+			cfg:  datastore.RedactionConfiguration{Paths: []string{"$.items.1"}},
+			in:   `{"items": ["a", "b"]}`,
+			want: `{"items": ["a", "[REDACTED]"]}`,
+		},
+		{
+			name: "should_mask_wildcard",
+			cfg:  datastore.RedactionConfiguration{Paths: []string{"$.*.secret"}},
+			in:   `{"a": {"secret": 1}, "b": {"secret": 2, "id": 3}}`,
+			want: `{"a": {"secret": "[REDACTED]"}, "b": {"secret": "[REDACTED]", "id": 3}}`,
+		},
+		{
+			name: "should_detect_email",
+			cfg:  datastore.RedactionConfiguration{Detectors: []datastore.RedactionDetector{datastore.EmailRedactionDetector}},
+			in:   `{"note": "contact ada@example.com today", "id": "abc"}`,
+			want: `{"note": "contact [REDACTED] today", "id": "abc"}`,
+		},
+		{
+			name: "should_detect_card_numbers",
+			cfg:  datastore.RedactionConfiguration{Detectors: []datastore.RedactionDetector{datastore.CardNumberRedactionDetector}},
+			in:   `{"card": "4111-1111-1111-1111", "raw": 4111111111111111, "order": "1234567890123"}`,
+			want: `{"card": "[REDACTED]", "raw": "[REDACTED]", "order": "1234567890123"}`,
+		},
+		{
+			name: "should_detect_ssn",
+			cfg:  datastore.RedactionConfiguration{Detectors: []datastore.RedactionDetector{datastore.SSNRedactionDetector}},
+			in:   `{"ssn": "123-45-6789"}`,
+			want: `{"ssn": "[REDACTED]"}`,
+		},
+		{
+			name: "should_apply_custom_pattern",
+			cfg:  datastore.RedactionConfiguration{Patterns: []string{`sk_live_[a-z0-9]+`}},
+			in:   `{"key": "sk_live_abc123"}`,
+			want: `{"key": "[REDACTED]"}`,
+		},
+		{
+			name: "should_apply_patterns_to_text",
+			cfg:  datastore.RedactionConfiguration{Detectors: []datastore.RedactionDetector{datastore.EmailRedactionDetector}},
+			in:   `email=ada@example.com&name=ada`,
+			want: `email=[REDACTED]&name=ada`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.cfg)
+			require.NoError(t, err)
+
+			got := r.Payload([]byte(tt.in))
+			if tt.in[0] == '{' {
+				require.JSONEq(t, tt.want, string(got))
+				return
+			}
+
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRedactor_Payload_Unchanged(t *testing.T) {
+	r, err := New(datastore.RedactionConfiguration{Paths: []string{"$.email"}})
+	require.NoError(t, err)
+
+	// payloads without redacted fields keep their formatting
+	in := `{ "amount":  10.50, "tags": ["<a>"] }`
+	require.Equal(t, in, string(r.Payload([]byte(in))))
+
+	var nilRedactor *Redactor
+	require.Equal(t, `{"email": "a"}`, string(nilRedactor.Payload([]byte(`{"email": "a"}`))))
+}
+
+func TestRedactor_Event(t *testing.T) {
+	r, err := New(datastore.RedactionConfiguration{
+		Paths:     []string{"$.email"},
+		Headers:   []string{"Authorization"},
+		Detectors: []datastore.RedactionDetector{datastore.SSNRedactionDetector},
+	})
+	require.NoError(t, err)
+
+	event := &datastore.Event{
+		Data:    []byte(`{"email":"ada@example.com"}`),
+		Raw:     `{"email":"ada@example.com"}`,
+		Headers: httpheader.HTTPHeader{"authorization": {"Bearer token"}, "X-Ssn": {"123-45-6789"}, "X-Id": {"1"}},
+	}
+
+	redacted := r.Event(event)
+	require.JSONEq(t, `{"email":"[REDACTED]"}`, string(redacted.Data))
+	require.JSONEq(t, `{"email":"[REDACTED]"}`, redacted.Raw)
+	require.Equal(t, []string{Mask}, redacted.Headers["authorization"])
+	require.Equal(t, []string{Mask}, redacted.Headers["X-Ssn"])
+	require.Equal(t, []string{"1"}, redacted.Headers["X-Id"])
+	require.True(t, redacted.Redacted)
+
+	// the event is left as it is
+	require.JSONEq(t, `{"email":"ada@example.com"}`, string(event.Data))
+	require.Equal(t, []string{"Bearer token"}, event.Headers["authorization"])
+	require.False(t, event.Redacted)
+
+	// events the rules don't mask aren't marked
+	unmasked := r.Event(&datastore.Event{Data: []byte(`{"id":1}`), Headers: httpheader.HTTPHeader{"X-Id": {"1"}}})
+	require.False(t, unmasked.Redacted)
+}
+
+func TestRedactor_Attempt(t *testing.T) {
+	r, err := New(datastore.RedactionConfiguration{
+		Headers:   []string{"set-cookie"},
+		Detectors: []datastore.RedactionDetector{datastore.EmailRedactionDetector},
+	})
+	require.NoError(t, err)
+
+	attempt := r.Attempt(datastore.DeliveryAttempt{
+		ResponseData:   `{"user": "ada@example.com"}`,
+		RequestHeader:  datastore.HttpHeader{"Content-Type": "application/json"},
+		ResponseHeader: datastore.HttpHeader{"Set-Cookie": "session=1"},
+	})
+
+	require.JSONEq(t, `{"user": "[REDACTED]"}`, attempt.ResponseData)
+	require.Equal(t, "application/json", attempt.RequestHeader["Content-Type"])
+	require.Equal(t, Mask, attempt.ResponseHeader["Set-Cookie"])
+}
+
+func TestForProject(t *testing.T) {
+	project := &datastore.Project{
+		UID:    "project-1",
+		Config: &datastore.ProjectConfig{Redaction: datastore.RedactionConfiguration{Paths: []string{"$.email"}}},
+	}
+
+	r := ForProject(project)
+	require.NotNil(t, r)
+	require.Same(t, r, ForProject(project))
+
+	// the rules changed, so they're compiled again
+	project.Config.Redaction.Paths = []string{"$.name"}
+	updated := ForProject(project)
+	require.NotSame(t, r, updated)
+	require.JSONEq(t, `{"name":"[REDACTED]","email":"ada@example.com"}`, string(updated.Payload([]byte(`{"name":"ada","email":"ada@example.com"}`))))
+
+	Invalidate(project.UID)
+	require.NotSame(t, updated, ForProject(project))
+
+	project.Config.Redaction = datastore.RedactionConfiguration{}
+	require.Nil(t, ForProject(project))
+}
+
+func TestRedactor_Metadata(t *testing.T) {
+	r, err := New(datastore.RedactionConfiguration{Paths: []string{"$.email"}})
+	require.NoError(t, err)
+
+	m := &datastore.Metadata{
+		Data:       []byte(`{"email":"ada@example.com"}`),
+		Raw:        `{"email":"ada@example.com"}`,
+		RetryLimit: 3,
+	}
+
+	redacted := r.Metadata(m)
+	require.JSONEq(t, `{"email":"[REDACTED]"}`, string(redacted.Data))
+	require.JSONEq(t, `{"email":"[REDACTED]"}`, redacted.Raw)
+	require.Equal(t, uint64(3), redacted.RetryLimit)
+	require.True(t, redacted.Redacted)
+	require.JSONEq(t, `{"email":"ada@example.com"}`, string(m.Data))
+
+	require.False(t, r.Metadata(&datastore.Metadata{Data: []byte(`{"id":1}`)}).Redacted)
+}
+
+func Test_isCardNumber(t *testing.T) {
+	require.True(t, isCardNumber("4111111111111111"))
+	require.True(t, isCardNumber("5500 0000 0000 0004"))
+	require.False(t, isCardNumber("4111111111111112"))
+	require.False(t, isCardNumber("0000"))
+}
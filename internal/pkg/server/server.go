@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -82,6 +83,12 @@ func (s *Server) Listen() {
 }
 
 func (s *Server) ListenAndServeTLS(certFile, keyFile string) {
+	cfg, err := config.Get()
+	if err == nil && cfg.Server.HTTP.SSLRequestClientCert {
+		// certificates are verified against each source's CA at ingest
+		s.s.TLSConfig = &tls.Config{ClientAuth: tls.RequestClientCert}
+	}
+
 	go func() {
 		//service connections
 		err := s.s.ListenAndServeTLS(certFile, keyFile)
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/gorilla/websocket"
 )
@@ -224,9 +225,15 @@ func (c *Client) ResendEventDeliveries(ctx context.Context, since time.Time, eve
 	}
 
 	for _, ed := range eds {
+		data, err := claimcheck.Get().DeliveryData(ctx, ed.ProjectID, ed.Metadata)
+		if err != nil {
+			log.WithError(err).WithField("event_delivery_id", ed.UID).Error("failed to load event delivery payload")
+			continue
+		}
+
 		events <- &CLIEvent{
 			UID:        ed.UID,
-			Data:       ed.Metadata.Data,
+			Data:       data,
 			Headers:    ed.Headers,
 			EventType:  ed.CLIMetadata.EventType,
 			EndpointID: ed.EndpointID,
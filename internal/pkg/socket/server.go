@@ -11,6 +11,7 @@ import (
 	"github.com/frain-dev/convoy/util"
 	"github.com/hibiken/asynq"
 
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/gorilla/websocket"
@@ -161,9 +162,15 @@ func (h *Hub) EventDeliveryCLiHandler(r *Repo) func(context.Context, *asynq.Task
 			return nil
 		}
 
+		payload, err := claimcheck.Get().DeliveryData(ctx, ed.ProjectID, ed.Metadata)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to load the payload of event delivery - %s", data.EventDeliveryID)
+			return &EndpointError{Err: err, delay: time.Second * 5}
+		}
+
 		events <- &CLIEvent{
 			UID:        ed.UID,
-			Data:       ed.Metadata.Data,
+			Data:       payload,
 			Headers:    ed.Headers,
 			EventType:  ed.CLIMetadata.EventType,
 			EndpointID: ed.EndpointID,
@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -53,6 +54,20 @@ func (mr *MockRateLimiterMockRecorder) Allow(ctx, key, rate any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimiter)(nil).Allow), ctx, key, rate)
 }
 
+// AllowWithBurst mocks base method.
+func (m *MockRateLimiter) AllowWithBurst(ctx context.Context, key string, rate, burst, duration int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowWithBurst", ctx, key, rate, burst, duration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AllowWithBurst indicates an expected call of AllowWithBurst.
+func (mr *MockRateLimiterMockRecorder) AllowWithBurst(ctx, key, rate, burst, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowWithBurst", reflect.TypeOf((*MockRateLimiter)(nil).AllowWithBurst), ctx, key, rate, burst, duration)
+}
+
 // AllowWithDuration mocks base method.
 func (m *MockRateLimiter) AllowWithDuration(ctx context.Context, key string, rate, duration int) error {
 	m.ctrl.T.Helper()
@@ -66,3 +81,32 @@ func (mr *MockRateLimiterMockRecorder) AllowWithDuration(ctx, key, rate, duratio
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowWithDuration", reflect.TypeOf((*MockRateLimiter)(nil).AllowWithDuration), ctx, key, rate, duration)
 }
+
+// QuotaUsage mocks base method.
+func (m *MockRateLimiter) QuotaUsage(ctx context.Context, key string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuotaUsage", ctx, key)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuotaUsage indicates an expected call of QuotaUsage.
+func (mr *MockRateLimiterMockRecorder) QuotaUsage(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuotaUsage", reflect.TypeOf((*MockRateLimiter)(nil).QuotaUsage), ctx, key)
+}
+
+// TakeQuota mocks base method.
+func (m *MockRateLimiter) TakeQuota(ctx context.Context, key string, limit int, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeQuota", ctx, key, limit, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TakeQuota indicates an expected call of TakeQuota.
+func (mr *MockRateLimiterMockRecorder) TakeQuota(ctx, key, limit, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeQuota", reflect.TypeOf((*MockRateLimiter)(nil).TakeQuota), ctx, key, limit, expiresAt)
+}
@@ -392,6 +392,64 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesPaged(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesPaged", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesPaged), ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType)
 }
 
+// ReEncryptEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) ReEncryptEventDeliveries(ctx context.Context, projectID, cursor string, limit int) (*datastore.ReEncryptBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReEncryptEventDeliveries", ctx, projectID, cursor, limit)
+	ret0, _ := ret[0].(*datastore.ReEncryptBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReEncryptEventDeliveries indicates an expected call of ReEncryptEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) ReEncryptEventDeliveries(ctx, projectID, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReEncryptEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ReEncryptEventDeliveries), ctx, projectID, cursor, limit)
+}
+
+// RunInTransaction mocks base method.
+func (m *MockEventDeliveryRepository) RunInTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTransaction indicates an expected call of RunInTransaction.
+func (mr *MockEventDeliveryRepositoryMockRecorder) RunInTransaction(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTransaction", reflect.TypeOf((*MockEventDeliveryRepository)(nil).RunInTransaction), ctx, fn)
+}
+
+// SupersedeEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) SupersedeEventDeliveries(ctx context.Context, projectID, subscriptionID, debounceKey, supersededBy string) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupersedeEventDeliveries", ctx, projectID, subscriptionID, debounceKey, supersededBy)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SupersedeEventDeliveries indicates an expected call of SupersedeEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) SupersedeEventDeliveries(ctx, projectID, subscriptionID, debounceKey, supersededBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupersedeEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).SupersedeEventDeliveries), ctx, projectID, subscriptionID, debounceKey, supersededBy)
+}
+
+// UpdateEventDeliveryMetadata mocks base method.
+func (m *MockEventDeliveryRepository) UpdateEventDeliveryMetadata(ctx context.Context, projectID string, eventDelivery datastore.EventDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEventDeliveryMetadata", ctx, projectID, eventDelivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEventDeliveryMetadata indicates an expected call of UpdateEventDeliveryMetadata.
+func (mr *MockEventDeliveryRepositoryMockRecorder) UpdateEventDeliveryMetadata(ctx, projectID, eventDelivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEventDeliveryMetadata", reflect.TypeOf((*MockEventDeliveryRepository)(nil).UpdateEventDeliveryMetadata), ctx, projectID, eventDelivery)
+}
+
 // UpdateEventDeliveryWithAttempt mocks base method.
 func (m *MockEventDeliveryRepository) UpdateEventDeliveryWithAttempt(ctx context.Context, projectID string, eventDelivery datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
 	m.ctrl.T.Helper()
@@ -634,6 +692,21 @@ func (mr *MockEventRepositoryMockRecorder) LoadEventsPaged(ctx, projectID, f any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventsPaged", reflect.TypeOf((*MockEventRepository)(nil).LoadEventsPaged), ctx, projectID, f)
 }
 
+// ReEncryptEvents mocks base method.
+func (m *MockEventRepository) ReEncryptEvents(ctx context.Context, projectID, cursor string, limit int) (*datastore.ReEncryptBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReEncryptEvents", ctx, projectID, cursor, limit)
+	ret0, _ := ret[0].(*datastore.ReEncryptBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReEncryptEvents indicates an expected call of ReEncryptEvents.
+func (mr *MockEventRepositoryMockRecorder) ReEncryptEvents(ctx, projectID, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReEncryptEvents", reflect.TypeOf((*MockEventRepository)(nil).ReEncryptEvents), ctx, projectID, cursor, limit)
+}
+
 // MockProjectRepository is a mock of ProjectRepository interface.
 type MockProjectRepository struct {
 	ctrl     *gomock.Controller
@@ -1277,6 +1350,21 @@ func (mr *MockEndpointRepositoryMockRecorder) FindEndpointsByID(ctx, ids, projec
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEndpointsByID", reflect.TypeOf((*MockEndpointRepository)(nil).FindEndpointsByID), ctx, ids, projectID)
 }
 
+// FindEndpointsByLabels mocks base method.
+func (m *MockEndpointRepository) FindEndpointsByLabels(ctx context.Context, projectID string, selector datastore.Labels) ([]datastore.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEndpointsByLabels", ctx, projectID, selector)
+	ret0, _ := ret[0].([]datastore.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEndpointsByLabels indicates an expected call of FindEndpointsByLabels.
+func (mr *MockEndpointRepositoryMockRecorder) FindEndpointsByLabels(ctx, projectID, selector any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEndpointsByLabels", reflect.TypeOf((*MockEndpointRepository)(nil).FindEndpointsByLabels), ctx, projectID, selector)
+}
+
 // FindEndpointsByOwnerID mocks base method.
 func (m *MockEndpointRepository) FindEndpointsByOwnerID(ctx context.Context, projectID, ownerID string) ([]datastore.Endpoint, error) {
 	m.ctrl.T.Helper()
@@ -2418,6 +2506,471 @@ func (mr *MockMetaEventRepositoryMockRecorder) UpdateMetaEvent(ctx, projectID, m
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetaEvent", reflect.TypeOf((*MockMetaEventRepository)(nil).UpdateMetaEvent), ctx, projectID, metaEvent)
 }
 
+// MockFunctionLibraryRepository is a mock of FunctionLibraryRepository interface.
+type MockFunctionLibraryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFunctionLibraryRepositoryMockRecorder
+}
+
+// MockFunctionLibraryRepositoryMockRecorder is the mock recorder for MockFunctionLibraryRepository.
+type MockFunctionLibraryRepositoryMockRecorder struct {
+	mock *MockFunctionLibraryRepository
+}
+
+// NewMockFunctionLibraryRepository creates a new mock instance.
+func NewMockFunctionLibraryRepository(ctrl *gomock.Controller) *MockFunctionLibraryRepository {
+	mock := &MockFunctionLibraryRepository{ctrl: ctrl}
+	mock.recorder = &MockFunctionLibraryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFunctionLibraryRepository) EXPECT() *MockFunctionLibraryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateFunctionLibrary mocks base method.
+func (m *MockFunctionLibraryRepository) CreateFunctionLibrary(ctx context.Context, library *datastore.FunctionLibrary) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFunctionLibrary", ctx, library)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFunctionLibrary indicates an expected call of CreateFunctionLibrary.
+func (mr *MockFunctionLibraryRepositoryMockRecorder) CreateFunctionLibrary(ctx, library any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFunctionLibrary", reflect.TypeOf((*MockFunctionLibraryRepository)(nil).CreateFunctionLibrary), ctx, library)
+}
+
+// DeleteFunctionLibrary mocks base method.
+func (m *MockFunctionLibraryRepository) DeleteFunctionLibrary(ctx context.Context, projectID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFunctionLibrary", ctx, projectID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFunctionLibrary indicates an expected call of DeleteFunctionLibrary.
+func (mr *MockFunctionLibraryRepositoryMockRecorder) DeleteFunctionLibrary(ctx, projectID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFunctionLibrary", reflect.TypeOf((*MockFunctionLibraryRepository)(nil).DeleteFunctionLibrary), ctx, projectID, id)
+}
+
+// FindFunctionLibraryByID mocks base method.
+func (m *MockFunctionLibraryRepository) FindFunctionLibraryByID(ctx context.Context, projectID, id string) (*datastore.FunctionLibrary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFunctionLibraryByID", ctx, projectID, id)
+	ret0, _ := ret[0].(*datastore.FunctionLibrary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFunctionLibraryByID indicates an expected call of FindFunctionLibraryByID.
+func (mr *MockFunctionLibraryRepositoryMockRecorder) FindFunctionLibraryByID(ctx, projectID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFunctionLibraryByID", reflect.TypeOf((*MockFunctionLibraryRepository)(nil).FindFunctionLibraryByID), ctx, projectID, id)
+}
+
+// LoadFunctionLibraries mocks base method.
+func (m *MockFunctionLibraryRepository) LoadFunctionLibraries(ctx context.Context, projectID string) ([]datastore.FunctionLibrary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadFunctionLibraries", ctx, projectID)
+	ret0, _ := ret[0].([]datastore.FunctionLibrary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadFunctionLibraries indicates an expected call of LoadFunctionLibraries.
+func (mr *MockFunctionLibraryRepositoryMockRecorder) LoadFunctionLibraries(ctx, projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadFunctionLibraries", reflect.TypeOf((*MockFunctionLibraryRepository)(nil).LoadFunctionLibraries), ctx, projectID)
+}
+
+// UpdateFunctionLibrary mocks base method.
+func (m *MockFunctionLibraryRepository) UpdateFunctionLibrary(ctx context.Context, projectID string, library *datastore.FunctionLibrary) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFunctionLibrary", ctx, projectID, library)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFunctionLibrary indicates an expected call of UpdateFunctionLibrary.
+func (mr *MockFunctionLibraryRepositoryMockRecorder) UpdateFunctionLibrary(ctx, projectID, library any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFunctionLibrary", reflect.TypeOf((*MockFunctionLibraryRepository)(nil).UpdateFunctionLibrary), ctx, projectID, library)
+}
+
+// MockDataKeyRepository is a mock of DataKeyRepository interface.
+type MockDataKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataKeyRepositoryMockRecorder
+}
+
+// MockDataKeyRepositoryMockRecorder is the mock recorder for MockDataKeyRepository.
+type MockDataKeyRepositoryMockRecorder struct {
+	mock *MockDataKeyRepository
+}
+
+// NewMockDataKeyRepository creates a new mock instance.
+func NewMockDataKeyRepository(ctrl *gomock.Controller) *MockDataKeyRepository {
+	mock := &MockDataKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockDataKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataKeyRepository) EXPECT() *MockDataKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDataKey mocks base method.
+func (m *MockDataKeyRepository) CreateDataKey(ctx context.Context, key *datastore.DataKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDataKey indicates an expected call of CreateDataKey.
+func (mr *MockDataKeyRepositoryMockRecorder) CreateDataKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataKey", reflect.TypeOf((*MockDataKeyRepository)(nil).CreateDataKey), ctx, key)
+}
+
+// FindActiveDataKey mocks base method.
+func (m *MockDataKeyRepository) FindActiveDataKey(ctx context.Context, projectID string) (*datastore.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveDataKey", ctx, projectID)
+	ret0, _ := ret[0].(*datastore.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveDataKey indicates an expected call of FindActiveDataKey.
+func (mr *MockDataKeyRepositoryMockRecorder) FindActiveDataKey(ctx, projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveDataKey", reflect.TypeOf((*MockDataKeyRepository)(nil).FindActiveDataKey), ctx, projectID)
+}
+
+// FindDataKeyByID mocks base method.
+func (m *MockDataKeyRepository) FindDataKeyByID(ctx context.Context, projectID, id string) (*datastore.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDataKeyByID", ctx, projectID, id)
+	ret0, _ := ret[0].(*datastore.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDataKeyByID indicates an expected call of FindDataKeyByID.
+func (mr *MockDataKeyRepositoryMockRecorder) FindDataKeyByID(ctx, projectID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDataKeyByID", reflect.TypeOf((*MockDataKeyRepository)(nil).FindDataKeyByID), ctx, projectID, id)
+}
+
+// LoadDataKeysNotWrappedBy mocks base method.
+func (m *MockDataKeyRepository) LoadDataKeysNotWrappedBy(ctx context.Context, kekID string) ([]datastore.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadDataKeysNotWrappedBy", ctx, kekID)
+	ret0, _ := ret[0].([]datastore.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadDataKeysNotWrappedBy indicates an expected call of LoadDataKeysNotWrappedBy.
+func (mr *MockDataKeyRepositoryMockRecorder) LoadDataKeysNotWrappedBy(ctx, kekID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadDataKeysNotWrappedBy", reflect.TypeOf((*MockDataKeyRepository)(nil).LoadDataKeysNotWrappedBy), ctx, kekID)
+}
+
+// UpdateWrappedKey mocks base method.
+func (m *MockDataKeyRepository) UpdateWrappedKey(ctx context.Context, key *datastore.DataKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWrappedKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWrappedKey indicates an expected call of UpdateWrappedKey.
+func (mr *MockDataKeyRepositoryMockRecorder) UpdateWrappedKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWrappedKey", reflect.TypeOf((*MockDataKeyRepository)(nil).UpdateWrappedKey), ctx, key)
+}
+
+// MockPartitionRepository is a mock of PartitionRepository interface.
+type MockPartitionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPartitionRepositoryMockRecorder
+}
+
+// MockPartitionRepositoryMockRecorder is the mock recorder for MockPartitionRepository.
+type MockPartitionRepositoryMockRecorder struct {
+	mock *MockPartitionRepository
+}
+
+// NewMockPartitionRepository creates a new mock instance.
+func NewMockPartitionRepository(ctrl *gomock.Controller) *MockPartitionRepository {
+	mock := &MockPartitionRepository{ctrl: ctrl}
+	mock.recorder = &MockPartitionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPartitionRepository) EXPECT() *MockPartitionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePartitions mocks base method.
+func (m *MockPartitionRepository) CreatePartitions(ctx context.Context, until time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePartitions", ctx, until)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePartitions indicates an expected call of CreatePartitions.
+func (mr *MockPartitionRepositoryMockRecorder) CreatePartitions(ctx, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePartitions", reflect.TypeOf((*MockPartitionRepository)(nil).CreatePartitions), ctx, until)
+}
+
+// LoadPartitions mocks base method.
+func (m *MockPartitionRepository) LoadPartitions(ctx context.Context) ([]datastore.Partition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPartitions", ctx)
+	ret0, _ := ret[0].([]datastore.Partition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPartitions indicates an expected call of LoadPartitions.
+func (mr *MockPartitionRepositoryMockRecorder) LoadPartitions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPartitions", reflect.TypeOf((*MockPartitionRepository)(nil).LoadPartitions), ctx)
+}
+
+// RemovePartitions mocks base method.
+func (m *MockPartitionRepository) RemovePartitions(ctx context.Context, expiry time.Time, detach bool) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePartitions", ctx, expiry, detach)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemovePartitions indicates an expected call of RemovePartitions.
+func (mr *MockPartitionRepositoryMockRecorder) RemovePartitions(ctx, expiry, detach any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePartitions", reflect.TypeOf((*MockPartitionRepository)(nil).RemovePartitions), ctx, expiry, detach)
+}
+
+// MockFunctionVersionRepository is a mock of FunctionVersionRepository interface.
+type MockFunctionVersionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFunctionVersionRepositoryMockRecorder
+}
+
+// MockFunctionVersionRepositoryMockRecorder is the mock recorder for MockFunctionVersionRepository.
+type MockFunctionVersionRepositoryMockRecorder struct {
+	mock *MockFunctionVersionRepository
+}
+
+// NewMockFunctionVersionRepository creates a new mock instance.
+func NewMockFunctionVersionRepository(ctrl *gomock.Controller) *MockFunctionVersionRepository {
+	mock := &MockFunctionVersionRepository{ctrl: ctrl}
+	mock.recorder = &MockFunctionVersionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFunctionVersionRepository) EXPECT() *MockFunctionVersionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateFunctionVersion mocks base method.
+func (m *MockFunctionVersionRepository) CreateFunctionVersion(ctx context.Context, version *datastore.FunctionVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFunctionVersion", ctx, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFunctionVersion indicates an expected call of CreateFunctionVersion.
+func (mr *MockFunctionVersionRepositoryMockRecorder) CreateFunctionVersion(ctx, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFunctionVersion", reflect.TypeOf((*MockFunctionVersionRepository)(nil).CreateFunctionVersion), ctx, version)
+}
+
+// FindFunctionVersion mocks base method.
+func (m *MockFunctionVersionRepository) FindFunctionVersion(ctx context.Context, projectID string, ownerType datastore.FunctionOwnerType, ownerID string, version int) (*datastore.FunctionVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFunctionVersion", ctx, projectID, ownerType, ownerID, version)
+	ret0, _ := ret[0].(*datastore.FunctionVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFunctionVersion indicates an expected call of FindFunctionVersion.
+func (mr *MockFunctionVersionRepositoryMockRecorder) FindFunctionVersion(ctx, projectID, ownerType, ownerID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFunctionVersion", reflect.TypeOf((*MockFunctionVersionRepository)(nil).FindFunctionVersion), ctx, projectID, ownerType, ownerID, version)
+}
+
+// LoadFunctionVersions mocks base method.
+func (m *MockFunctionVersionRepository) LoadFunctionVersions(ctx context.Context, projectID string, ownerType datastore.FunctionOwnerType, ownerID string) ([]datastore.FunctionVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadFunctionVersions", ctx, projectID, ownerType, ownerID)
+	ret0, _ := ret[0].([]datastore.FunctionVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadFunctionVersions indicates an expected call of LoadFunctionVersions.
+func (mr *MockFunctionVersionRepositoryMockRecorder) LoadFunctionVersions(ctx, projectID, ownerType, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadFunctionVersions", reflect.TypeOf((*MockFunctionVersionRepository)(nil).LoadFunctionVersions), ctx, projectID, ownerType, ownerID)
+}
+
+// MockEventTypeRepository is a mock of EventTypeRepository interface.
+type MockEventTypeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventTypeRepositoryMockRecorder
+}
+
+// MockEventTypeRepositoryMockRecorder is the mock recorder for MockEventTypeRepository.
+type MockEventTypeRepositoryMockRecorder struct {
+	mock *MockEventTypeRepository
+}
+
+// NewMockEventTypeRepository creates a new mock instance.
+func NewMockEventTypeRepository(ctrl *gomock.Controller) *MockEventTypeRepository {
+	mock := &MockEventTypeRepository{ctrl: ctrl}
+	mock.recorder = &MockEventTypeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventTypeRepository) EXPECT() *MockEventTypeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateEventType mocks base method.
+func (m *MockEventTypeRepository) CreateEventType(ctx context.Context, eventType *datastore.ProjectEventType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEventType", ctx, eventType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEventType indicates an expected call of CreateEventType.
+func (mr *MockEventTypeRepositoryMockRecorder) CreateEventType(ctx, eventType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEventType", reflect.TypeOf((*MockEventTypeRepository)(nil).CreateEventType), ctx, eventType)
+}
+
+// CreateEventTypeVersion mocks base method.
+func (m *MockEventTypeRepository) CreateEventTypeVersion(ctx context.Context, eventType *datastore.ProjectEventType, version *datastore.EventTypeVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEventTypeVersion", ctx, eventType, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEventTypeVersion indicates an expected call of CreateEventTypeVersion.
+func (mr *MockEventTypeRepositoryMockRecorder) CreateEventTypeVersion(ctx, eventType, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEventTypeVersion", reflect.TypeOf((*MockEventTypeRepository)(nil).CreateEventTypeVersion), ctx, eventType, version)
+}
+
+// DeleteEventType mocks base method.
+func (m *MockEventTypeRepository) DeleteEventType(ctx context.Context, projectID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEventType", ctx, projectID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEventType indicates an expected call of DeleteEventType.
+func (mr *MockEventTypeRepositoryMockRecorder) DeleteEventType(ctx, projectID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventType", reflect.TypeOf((*MockEventTypeRepository)(nil).DeleteEventType), ctx, projectID, id)
+}
+
+// FindEventTypeByID mocks base method.
+func (m *MockEventTypeRepository) FindEventTypeByID(ctx context.Context, projectID, id string) (*datastore.ProjectEventType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventTypeByID", ctx, projectID, id)
+	ret0, _ := ret[0].(*datastore.ProjectEventType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventTypeByID indicates an expected call of FindEventTypeByID.
+func (mr *MockEventTypeRepositoryMockRecorder) FindEventTypeByID(ctx, projectID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventTypeByID", reflect.TypeOf((*MockEventTypeRepository)(nil).FindEventTypeByID), ctx, projectID, id)
+}
+
+// FindEventTypeByName mocks base method.
+func (m *MockEventTypeRepository) FindEventTypeByName(ctx context.Context, projectID, name string) (*datastore.ProjectEventType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventTypeByName", ctx, projectID, name)
+	ret0, _ := ret[0].(*datastore.ProjectEventType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventTypeByName indicates an expected call of FindEventTypeByName.
+func (mr *MockEventTypeRepositoryMockRecorder) FindEventTypeByName(ctx, projectID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventTypeByName", reflect.TypeOf((*MockEventTypeRepository)(nil).FindEventTypeByName), ctx, projectID, name)
+}
+
+// LoadEventTypeVersions mocks base method.
+func (m *MockEventTypeRepository) LoadEventTypeVersions(ctx context.Context, projectID, eventTypeID string) ([]datastore.EventTypeVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEventTypeVersions", ctx, projectID, eventTypeID)
+	ret0, _ := ret[0].([]datastore.EventTypeVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEventTypeVersions indicates an expected call of LoadEventTypeVersions.
+func (mr *MockEventTypeRepositoryMockRecorder) LoadEventTypeVersions(ctx, projectID, eventTypeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventTypeVersions", reflect.TypeOf((*MockEventTypeRepository)(nil).LoadEventTypeVersions), ctx, projectID, eventTypeID)
+}
+
+// LoadEventTypes mocks base method.
+func (m *MockEventTypeRepository) LoadEventTypes(ctx context.Context, projectID string) ([]datastore.ProjectEventType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEventTypes", ctx, projectID)
+	ret0, _ := ret[0].([]datastore.ProjectEventType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEventTypes indicates an expected call of LoadEventTypes.
+func (mr *MockEventTypeRepositoryMockRecorder) LoadEventTypes(ctx, projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventTypes", reflect.TypeOf((*MockEventTypeRepository)(nil).LoadEventTypes), ctx, projectID)
+}
+
+// UpdateEventType mocks base method.
+func (m *MockEventTypeRepository) UpdateEventType(ctx context.Context, projectID string, eventType *datastore.ProjectEventType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEventType", ctx, projectID, eventType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEventType indicates an expected call of UpdateEventType.
+func (mr *MockEventTypeRepositoryMockRecorder) UpdateEventType(ctx, projectID, eventType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEventType", reflect.TypeOf((*MockEventTypeRepository)(nil).UpdateEventType), ctx, projectID, eventType)
+}
+
 // MockExportRepository is a mock of ExportRepository interface.
 type MockExportRepository struct {
 	ctrl     *gomock.Controller
@@ -8,6 +8,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/pkg/flatten"
 )
 
 var ErrTrailingDollarOpNotAllowed = errors.New("invalid filter syntax, found trailing $")
@@ -30,6 +33,14 @@ func init() {
 		"$and":   and,
 		"$exist": exist,
 		"$regex": regex,
+
+		"$contains":   contains,
+		"$startsWith": startsWith,
+		"$endsWith":   endsWith,
+		"$ieq":        ieq,
+		"$size":       size,
+		"$elemMatch":  elemMatch,
+		"$not":        not,
 	}
 }
 
@@ -81,6 +92,11 @@ func compare(payload map[string]interface{}, filter map[string]interface{}) (boo
 		}
 
 		payloadVal, ok := payload[key]
+		if !ok && hasArrayOperator(filterVal) {
+			// arrays of objects are flattened into indexed keys
+			payloadVal, ok = collectArray(payload, key)
+		}
+
 		if !ok {
 			if key == "$or" || key == "$and" {
 				check, err := cmp[key](payload, filterVal)
@@ -107,7 +123,7 @@ func compare(payload map[string]interface{}, filter map[string]interface{}) (boo
 					continue
 				}
 
-				check, err := cmp[vk](payloadVal, vv)
+				check, err := apply(vk, payloadVal, vv)
 				if err != nil {
 					return false, err
 				}
@@ -153,6 +169,38 @@ func compare(payload map[string]interface{}, filter map[string]interface{}) (boo
 	return passReduced, nil
 }
 
+// apply runs the operator op against the payload value.
+func apply(op string, payload, filter interface{}) (bool, error) {
+	fn, ok := cmp[op]
+	if !ok {
+		return false, fmt.Errorf("%s is not a valid operator", op)
+	}
+
+	return fn(payload, filter)
+}
+
+// applyAll checks that the payload value matches every operator in filter,
+// like {"$gt": 1, "$lt": 5}.
+func applyAll(payload, filter interface{}) (bool, error) {
+	ops, ok := filter.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("filter %v is not a valid operator expression", filter)
+	}
+
+	for op, v := range ops {
+		check, err := apply(op, payload, v)
+		if err != nil {
+			return false, err
+		}
+
+		if !check {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 func regex(payload, filter interface{}) (bool, error) {
 	f, ok := filter.(string)
 	if !ok {
@@ -177,15 +225,17 @@ func regex(payload, filter interface{}) (bool, error) {
 }
 
 func gte(payload, filter interface{}) (bool, error) {
+	if c, ok := compareTimes(payload, filter); ok {
+		return c >= 0, nil
+	}
+
 	p, ok := toFloat64(payload)
 	if !ok {
-		fmt.Printf("payload %v is not a valid number\n", payload)
 		return false, nil
 	}
 
 	f, ok := toFloat64(filter)
 	if !ok {
-		fmt.Printf("filter %v is not a valid number\n", filter)
 		return false, nil
 	}
 
@@ -193,15 +243,17 @@ func gte(payload, filter interface{}) (bool, error) {
 }
 
 func gt(payload, filter interface{}) (bool, error) {
+	if c, ok := compareTimes(payload, filter); ok {
+		return c > 0, nil
+	}
+
 	p, ok := toFloat64(payload)
 	if !ok {
-		fmt.Printf("payload %v is not a valid number\n", payload)
 		return false, fmt.Errorf("payload %v is not a valid number\n", payload)
 	}
 
 	f, ok := toFloat64(filter)
 	if !ok {
-		fmt.Printf("filter %v is not a valid number\n", filter)
 		return false, fmt.Errorf("filter %v is not a valid number\n", filter)
 	}
 
@@ -375,6 +427,210 @@ func exist(payload, filter interface{}) (bool, error) {
 	return b == want, nil
 }
 
+// contains checks whether the payload string contains the filter string.
+func contains(payload, filter interface{}) (bool, error) {
+	return matchString(payload, filter, strings.Contains)
+}
+
+// startsWith checks whether the payload string starts with the filter string.
+func startsWith(payload, filter interface{}) (bool, error) {
+	return matchString(payload, filter, strings.HasPrefix)
+}
+
+// endsWith checks whether the payload string ends with the filter string.
+func endsWith(payload, filter interface{}) (bool, error) {
+	return matchString(payload, filter, strings.HasSuffix)
+}
+
+// ieq checks whether the payload and filter strings are equal ignoring case,
+// other values are compared like $eq.
+func ieq(payload, filter interface{}) (bool, error) {
+	p, pok := payload.(string)
+	f, fok := filter.(string)
+	if pok && fok {
+		return strings.EqualFold(p, f), nil
+	}
+
+	return eq(payload, filter)
+}
+
+func matchString(payload, filter interface{}, match func(s, substr string) bool) (bool, error) {
+	f, ok := filter.(string)
+	if !ok {
+		return false, fmt.Errorf("filter %v is not a valid string", filter)
+	}
+
+	// payloads of another type don't match
+	p, ok := payload.(string)
+	if !ok {
+		return false, nil
+	}
+
+	return match(p, f), nil
+}
+
+// size checks the length of an array.
+func size(payload, filter interface{}) (bool, error) {
+	f, ok := toFloat64(filter)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return false, fmt.Errorf("filter %v is not a valid array size", filter)
+	}
+
+	p, ok := payload.([]interface{})
+	if !ok {
+		return false, nil
+	}
+
+	return len(p) == int(f), nil
+}
+
+// elemMatch checks whether any item in an array matches the filter. The
+// filter is a query for arrays of objects, and an operator expression like
+// {"$gt": 5} for arrays of strings and numbers.
+func elemMatch(payload, filter interface{}) (bool, error) {
+	f, ok := filter.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("filter %v is not valid json", filter)
+	}
+
+	p, ok := payload.([]interface{})
+	if !ok {
+		return false, nil
+	}
+
+	var query flatten.M
+	for _, item := range p {
+		if _, ok := item.(map[string]interface{}); !ok {
+			check, err := applyAll(item, f)
+			if err != nil {
+				return false, err
+			}
+
+			if check {
+				return true, nil
+			}
+
+			continue
+		}
+
+		if query == nil {
+			var err error
+			query, err = flatten.Flatten(f)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		flat, err := flatten.Flatten(item)
+		if err != nil {
+			return false, err
+		}
+
+		check, err := compare(flat, query)
+		if err != nil {
+			return false, err
+		}
+
+		if check {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// not inverts an operator expression, like {"$not": {"$regex": "^test"}}.
+func not(payload, filter interface{}) (bool, error) {
+	chk, err := applyAll(payload, filter)
+	return !chk, err
+}
+
+// compareTimes compares payload and filter when both are RFC3339 times.
+func compareTimes(payload, filter interface{}) (int, bool) {
+	p, ok := payload.(string)
+	if !ok {
+		return 0, false
+	}
+
+	f, ok := filter.(string)
+	if !ok {
+		return 0, false
+	}
+
+	pt, err := time.Parse(time.RFC3339Nano, p)
+	if err != nil {
+		return 0, false
+	}
+
+	ft, err := time.Parse(time.RFC3339Nano, f)
+	if err != nil {
+		return 0, false
+	}
+
+	return pt.Compare(ft), true
+}
+
+// hasArrayOperator reports whether filter uses an operator that's applied
+// to a whole array.
+func hasArrayOperator(filter interface{}) bool {
+	f, ok := filter.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, hasSize := f["$size"]
+	_, hasElemMatch := f["$elemMatch"]
+	return hasSize || hasElemMatch
+}
+
+// collectArray rebuilds an array of objects from a flattened payload, where
+// each item's fields are stored under key.<index>.<field>.
+func collectArray(payload map[string]interface{}, key string) ([]interface{}, bool) {
+	prefix := key + "."
+	items := map[int]map[string]interface{}{}
+	n := 0
+
+	for k, v := range payload {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		index, field, _ := strings.Cut(k[len(prefix):], ".")
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 {
+			continue
+		}
+
+		item, ok := items[i]
+		if !ok {
+			item = map[string]interface{}{}
+			items[i] = item
+		}
+
+		if len(field) > 0 {
+			item[field] = v
+		}
+
+		if i+1 > n {
+			n = i + 1
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, false
+	}
+
+	// only objects are flattened into indexed keys, other items were dropped
+	arr := make([]interface{}, 0, len(items))
+	for i := 0; i < n; i++ {
+		if item, ok := items[i]; ok {
+			arr = append(arr, item)
+		}
+	}
+
+	return arr, true
+}
+
 // toFloat64 converts interface{} value to float64 if value is numeric else return false
 func toFloat64(v interface{}) (float64, bool) {
 	var f float64
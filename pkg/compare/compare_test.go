@@ -568,6 +568,10 @@ func TestCompare(t *testing.T) {
 			if !jsonEqual(matched, tt.want) {
 				t.Errorf("mismatch:\ngot:  %+v\nwant: %+v", matched, tt.want)
 			}
+
+			explained, err := Explain(p, f)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, explained.Result)
 		})
 	}
 }
@@ -1074,3 +1078,257 @@ func BenchmarkCompareRegex(b *testing.B) {
 		}
 	}
 }
+
+func TestCompareOperators(t *testing.T) {
+	payload := map[string]interface{}{
+		"event":      "invoice.paid",
+		"email":      "Dev@Convoy.io",
+		"created_at": "2024-03-01T10:00:00Z",
+		"amount":     50,
+		"tags":       []interface{}{"billing", "stripe"},
+		"scores":     []interface{}{1, 5, 9},
+		"items": []interface{}{
+			map[string]interface{}{"name": "hoodie", "qty": 1},
+			map[string]interface{}{"name": "sticker", "qty": 10, "meta": map[string]interface{}{"color": "red"}},
+		},
+		"customer": map[string]interface{}{
+			"email":     "Ada@Example.com",
+			"signed_up": "2023-12-24T08:30:00Z",
+			"address":   map[string]interface{}{"city": "Lagos", "country": "NG"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filter  map[string]interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "contains",
+			filter: map[string]interface{}{"event": map[string]interface{}{"$contains": "paid"}},
+			want:   true,
+		},
+		{
+			name:   "contains - no match",
+			filter: map[string]interface{}{"event": map[string]interface{}{"$contains": "failed"}},
+			want:   false,
+		},
+		{
+			name:   "startsWith",
+			filter: map[string]interface{}{"event": map[string]interface{}{"$startsWith": "invoice."}},
+			want:   true,
+		},
+		{
+			name:   "endsWith",
+			filter: map[string]interface{}{"event": map[string]interface{}{"$endsWith": ".created"}},
+			want:   false,
+		},
+		{
+			name:   "endsWith - number payload",
+			filter: map[string]interface{}{"amount": map[string]interface{}{"$endsWith": "0"}},
+			want:   false,
+		},
+		{
+			name:   "ieq",
+			filter: map[string]interface{}{"email": map[string]interface{}{"$ieq": "dev@convoy.io"}},
+			want:   true,
+		},
+		{
+			name:   "size - strings",
+			filter: map[string]interface{}{"tags": map[string]interface{}{"$size": 2}},
+			want:   true,
+		},
+		{
+			name:   "size - objects",
+			filter: map[string]interface{}{"items": map[string]interface{}{"$size": 3}},
+			want:   false,
+		},
+		{
+			name: "elemMatch - objects",
+			filter: map[string]interface{}{
+				"items": map[string]interface{}{
+					"$elemMatch": map[string]interface{}{
+						"name": "sticker",
+						"qty":  map[string]interface{}{"$gte": 5},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "elemMatch - nested object",
+			filter: map[string]interface{}{
+				"items": map[string]interface{}{
+					"$elemMatch": map[string]interface{}{
+						"meta": map[string]interface{}{"color": "red"},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "elemMatch - no item matches every condition",
+			filter: map[string]interface{}{
+				"items": map[string]interface{}{
+					"$elemMatch": map[string]interface{}{
+						"name": "hoodie",
+						"qty":  map[string]interface{}{"$gte": 5},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name:   "elemMatch - numbers",
+			filter: map[string]interface{}{"scores": map[string]interface{}{"$elemMatch": map[string]interface{}{"$gt": 8}}},
+			want:   true,
+		},
+		{
+			name:   "not",
+			filter: map[string]interface{}{"event": map[string]interface{}{"$not": map[string]interface{}{"$startsWith": "customer."}}},
+			want:   true,
+		},
+		{
+			name:   "not - match",
+			filter: map[string]interface{}{"amount": map[string]interface{}{"$not": map[string]interface{}{"$gt": 10}}},
+			want:   false,
+		},
+		{
+			name:   "time - after",
+			filter: map[string]interface{}{"created_at": map[string]interface{}{"$gt": "2024-02-29T23:59:59+01:00"}},
+			want:   true,
+		},
+		{
+			name:   "time - before",
+			filter: map[string]interface{}{"created_at": map[string]interface{}{"$lt": "2024-03-01T10:00:00Z"}},
+			want:   false,
+		},
+		{
+			name: "nested - contains",
+			filter: map[string]interface{}{
+				"customer": map[string]interface{}{
+					"address": map[string]interface{}{"city": map[string]interface{}{"$contains": "ago"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "nested - ieq and time",
+			filter: map[string]interface{}{
+				"customer": map[string]interface{}{
+					"email":     map[string]interface{}{"$ieq": "ada@example.com"},
+					"signed_up": map[string]interface{}{"$lt": "2024-01-01T00:00:00Z"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "nested - not",
+			filter: map[string]interface{}{
+				"customer": map[string]interface{}{
+					"address": map[string]interface{}{"country": map[string]interface{}{"$not": map[string]interface{}{"$eq": "NG"}}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "and",
+			filter: map[string]interface{}{
+				"$and": []interface{}{
+					map[string]interface{}{"event": map[string]interface{}{"$startsWith": "invoice."}},
+					map[string]interface{}{"customer.address.city": map[string]interface{}{"$ieq": "lagos"}},
+					map[string]interface{}{"tags": map[string]interface{}{"$size": 2}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "and - one condition fails",
+			filter: map[string]interface{}{
+				"$and": []interface{}{
+					map[string]interface{}{"event": map[string]interface{}{"$endsWith": ".paid"}},
+					map[string]interface{}{"created_at": map[string]interface{}{"$gt": "2024-03-02T00:00:00Z"}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "or",
+			filter: map[string]interface{}{
+				"$or": []interface{}{
+					map[string]interface{}{"event": map[string]interface{}{"$contains": "refund"}},
+					map[string]interface{}{
+						"items": map[string]interface{}{
+							"$elemMatch": map[string]interface{}{"name": "sticker", "qty": map[string]interface{}{"$gt": 5}},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "or - no condition matches",
+			filter: map[string]interface{}{
+				"$or": []interface{}{
+					map[string]interface{}{"email": map[string]interface{}{"$startsWith": "ops@"}},
+					map[string]interface{}{"amount": map[string]interface{}{"$not": map[string]interface{}{"$lt": 100}}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "and inside or",
+			filter: map[string]interface{}{
+				"$or": []interface{}{
+					map[string]interface{}{"event": "invoice.failed"},
+					map[string]interface{}{
+						"$and": []interface{}{
+							map[string]interface{}{"customer.address.country": "NG"},
+							map[string]interface{}{"customer.signed_up": map[string]interface{}{"$gte": "2023-12-24T08:30:00Z"}},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name:    "size - invalid filter",
+			filter:  map[string]interface{}{"tags": map[string]interface{}{"$size": "two"}},
+			wantErr: true,
+		},
+		{
+			name:    "not - invalid operator",
+			filter:  map[string]interface{}{"amount": map[string]interface{}{"$not": map[string]interface{}{"$unknown": 1}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := flatten.Flatten(payload)
+			require.NoError(t, err)
+
+			f, err := flatten.Flatten(tt.filter)
+			require.NoError(t, err)
+
+			// filters are stored flattened, so they're also compared after a round trip
+			b, err := json.Marshal(f)
+			require.NoError(t, err)
+
+			var stored flatten.M
+			require.NoError(t, json.Unmarshal(b, &stored))
+
+			for _, filter := range []flatten.M{f, stored} {
+				matched, err := Compare(p, filter)
+				if tt.wantErr {
+					require.Error(t, err)
+					continue
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, tt.want, matched)
+			}
+		})
+	}
+}
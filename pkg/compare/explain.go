@@ -0,0 +1,193 @@
+package compare
+
+import (
+	"sort"
+	"strings"
+)
+
+// Clause is one evaluated condition of a filter. Clauses whose path isn't
+// in the payload are skipped, they neither pass nor fail the filter.
+type Clause struct {
+	// Path is the flattened payload path the clause reads
+	Path string `json:"path,omitempty"`
+
+	// Operator is the operator applied, $and for the filter itself
+	Operator string `json:"operator"`
+
+	// Expected is the filter's value for the operator
+	Expected interface{} `json:"expected,omitempty"`
+
+	// Actual is the value read from the payload
+	Actual interface{} `json:"actual,omitempty"`
+
+	Result  bool      `json:"result"`
+	Skipped bool      `json:"skipped,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Clauses []*Clause `json:"clauses,omitempty"`
+}
+
+// Explain evaluates filter against payload like Compare and returns the
+// evaluation of each of its clauses. The root clause's result is the
+// result Compare returns, a clause that fails to evaluate doesn't match.
+func Explain(payload map[string]interface{}, filter map[string]interface{}) (*Clause, error) {
+	return explain(payload, filter)
+}
+
+func explain(payload map[string]interface{}, filter map[string]interface{}) (*Clause, error) {
+	root := &Clause{Operator: "$and"}
+
+	// clauses are sorted so explanations of the same filter are stable
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pass []bool
+	for _, key := range keys {
+		filterVal := filter[key]
+		slen := len(key)
+
+		if key[slen-1] == '$' && key[slen-2] == '.' {
+			return nil, ErrTrailingDollarOpNotAllowed
+		}
+
+		if strings.Contains(key, "$.") {
+			clause, err := explainWildcard(payload, key, filterVal)
+			if err != nil {
+				return nil, err
+			}
+
+			root.Clauses = append(root.Clauses, clause)
+			pass = append(pass, clause.Result)
+		}
+
+		payloadVal, ok := payload[key]
+		if !ok && hasArrayOperator(filterVal) {
+			payloadVal, ok = collectArray(payload, key)
+		}
+
+		if !ok {
+			if key == "$or" || key == "$and" {
+				clause, err := explainLogical(payload, key, filterVal)
+				if err != nil {
+					return nil, err
+				}
+
+				root.Clauses = append(root.Clauses, clause)
+				pass = append(pass, clause.Result)
+				continue
+			}
+
+			// the wildcard clause above already covers $ keys
+			if !strings.Contains(key, "$.") {
+				root.Clauses = append(root.Clauses, &Clause{Path: key, Expected: filterVal, Skipped: true})
+			}
+			continue
+		}
+
+		switch v := filterVal.(type) {
+		case map[string]interface{}:
+			ops := make([]string, 0, len(v))
+			for op := range v {
+				ops = append(ops, op)
+			}
+			sort.Strings(ops)
+
+			for _, op := range ops {
+				clause := &Clause{Path: key, Operator: op, Expected: v[op], Actual: payloadVal}
+
+				var check bool
+				var err error
+				if op == "$exist" {
+					check, err = cmp["$exist"](payload, map[string]interface{}{key: v[op]})
+				} else {
+					check, err = apply(op, payloadVal, v[op])
+				}
+
+				setResult(clause, check, err)
+				root.Clauses = append(root.Clauses, clause)
+				pass = append(pass, clause.Result)
+			}
+
+		default:
+			op := "$eq"
+			if _, ok := payloadVal.([]interface{}); ok {
+				op = "$in"
+			}
+
+			clause := &Clause{Path: key, Operator: op, Expected: filterVal, Actual: payloadVal}
+			check, err := cmp[op](payloadVal, filterVal)
+			setResult(clause, check, err)
+
+			root.Clauses = append(root.Clauses, clause)
+			pass = append(pass, clause.Result)
+		}
+	}
+
+	// a filter whose clauses were all skipped doesn't match, see compare
+	root.Result = len(filter) == 0 || len(pass) > 0
+	for _, p := range pass {
+		root.Result = root.Result && p
+	}
+
+	return root, nil
+}
+
+// explainWildcard explains a key with $ array segments, it matches when
+// any of the array items matches.
+func explainWildcard(payload map[string]interface{}, key string, filterVal interface{}) (*Clause, error) {
+	clause := &Clause{Path: key, Operator: "$any", Expected: filterVal}
+
+	possibleKeys, err := genCombos(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, newKey := range possibleKeys {
+		if _, ok := payload[newKey]; !ok {
+			continue
+		}
+
+		c, err := explain(payload, map[string]interface{}{newKey: filterVal})
+		if err != nil {
+			return nil, err
+		}
+
+		clause.Clauses = append(clause.Clauses, c)
+		clause.Result = clause.Result || c.Result
+	}
+
+	return clause, nil
+}
+
+func explainLogical(payload map[string]interface{}, op string, filterVal interface{}) (*Clause, error) {
+	clause := &Clause{Operator: op}
+
+	// evaluated with the operator itself so invalid conditions are reported
+	check, err := cmp[op](payload, filterVal)
+	setResult(clause, check, err)
+	if err != nil {
+		return clause, nil
+	}
+
+	for _, value := range filterVal.([]interface{}) {
+		c, err := explain(payload, value.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+
+		clause.Clauses = append(clause.Clauses, c)
+	}
+
+	return clause, nil
+}
+
+func setResult(clause *Clause, check bool, err error) {
+	if err != nil {
+		clause.Error = strings.TrimSpace(err.Error())
+		return
+	}
+
+	clause.Result = check
+}
@@ -0,0 +1,70 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/pkg/flatten"
+)
+
+func TestExplain(t *testing.T) {
+	payload, err := flatten.Flatten(map[string]interface{}{
+		"event": "invoice.paid",
+		"invoice": map[string]interface{}{
+			"amount":   500,
+			"currency": "EUR",
+		},
+		"tags": []interface{}{"vip"},
+	})
+	require.NoError(t, err)
+
+	filter, err := flatten.Flatten(map[string]interface{}{
+		"event": "invoice.paid",
+		"invoice": map[string]interface{}{
+			"amount": map[string]interface{}{"$gte": 1000},
+		},
+		"tags":     "vip",
+		"customer": "cus_1",
+		"$or": []interface{}{
+			map[string]interface{}{"invoice.currency": "USD"},
+			map[string]interface{}{"invoice.currency": "EUR"},
+		},
+	})
+	require.NoError(t, err)
+
+	explained, err := Explain(payload, filter)
+	require.NoError(t, err)
+
+	require.False(t, explained.Result)
+	require.Equal(t, []*Clause{
+		{
+			Operator: "$or",
+			Result:   true,
+			Clauses: []*Clause{
+				{Operator: "$and", Clauses: []*Clause{{Path: "invoice.currency", Operator: "$eq", Expected: "USD", Actual: "EUR"}}},
+				{Operator: "$and", Result: true, Clauses: []*Clause{{Path: "invoice.currency", Operator: "$eq", Expected: "EUR", Actual: "EUR", Result: true}}},
+			},
+		},
+		{Path: "customer", Expected: "cus_1", Skipped: true},
+		{Path: "event", Operator: "$eq", Expected: "invoice.paid", Actual: "invoice.paid", Result: true},
+		{Path: "invoice.amount", Operator: "$gte", Expected: 1000, Actual: 500},
+		{Path: "tags", Operator: "$in", Expected: "vip", Actual: []interface{}{"vip"}, Result: true},
+	}, explained.Clauses)
+
+	matched, err := Compare(payload, filter)
+	require.NoError(t, err)
+	require.Equal(t, matched, explained.Result)
+}
+
+func TestExplain_Errors(t *testing.T) {
+	payload := flatten.M{"event": "invoice.paid"}
+
+	explained, err := Explain(payload, flatten.M{"event": map[string]interface{}{"$unknown": 1}})
+	require.NoError(t, err)
+	require.False(t, explained.Result)
+	require.Equal(t, "$unknown is not a valid operator", explained.Clauses[0].Error)
+
+	_, err = Explain(payload, flatten.M{"event.$": 1})
+	require.ErrorIs(t, err, ErrTrailingDollarOpNotAllowed)
+}
@@ -23,6 +23,14 @@ var operators = map[string]struct{}{
 	"$and":   {},
 	"$exist": {},
 	"$regex": {},
+
+	"$contains":   {},
+	"$startsWith": {},
+	"$endsWith":   {},
+	"$ieq":        {},
+	"$size":       {},
+	"$elemMatch":  {},
+	"$not":        {},
 }
 
 type stackFrame struct {
@@ -551,6 +551,21 @@ func TestFlattenWithOperator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "array of objects operator",
+			given: `{"order":{"items":{"$elemMatch":{"product":{"name":{"$startsWith":"hood"}}}}}}`,
+			want: M{
+				"order.items": M{
+					"$elemMatch": M{
+						"product": M{
+							"name": M{
+								"$startsWith": "hood",
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,132 @@
+// Package jsonschema validates JSON payloads against a JSON Schema.
+//
+// Schemas are draft 2020-12 unless they declare another draft with
+// $schema. References ($ref) are resolved within the schema, references to
+// other documents are rejected.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var ErrEmptySchema = errors.New("schema is empty")
+
+// schemaURL is the location the schema being compiled is loaded from.
+const schemaURL = "mem:///schema.json"
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+// Compile parses and checks a JSON Schema document.
+func Compile(raw json.RawMessage) (*Schema, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, ErrEmptySchema
+	}
+
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	c.AssertFormat = true
+	c.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("loading %s: references to other documents are not supported", url)
+	}
+
+	if err := c.AddResource(schemaURL, bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+
+	s, err := c.Compile(schemaURL)
+	if err != nil {
+		// a schema the meta schema rejects is described like an invalid
+		// payload
+		var se *jsonschema.SchemaError
+		var ve *jsonschema.ValidationError
+		if errors.As(err, &se) && errors.As(se.Err, &ve) {
+			return nil, fmt.Errorf("invalid schema: %v", &ValidationError{Errors: flatten(ve)})
+		}
+
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+
+	return &Schema{schema: s}, nil
+}
+
+// Validate reports every way data doesn't match the schema.
+func (s *Schema) Validate(data json.RawMessage) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+
+	return s.ValidateValue(v)
+}
+
+// ValidateValue is Validate for an already decoded value, numbers must be
+// decoded as float64 or json.Number.
+func (s *Schema) ValidateValue(v interface{}) error {
+	err := s.schema.Validate(v)
+	if err == nil {
+		return nil
+	}
+
+	var ve *jsonschema.ValidationError
+	if errors.As(err, &ve) {
+		errs := flatten(ve)
+		sort.Strings(errs)
+		return &ValidationError{Errors: errs}
+	}
+
+	return &ValidationError{Errors: []string{err.Error()}}
+}
+
+// ValidationError lists the reasons a value didn't match a schema.
+type ValidationError struct {
+	Errors []string
+}
+
+func (v *ValidationError) Error() string {
+	if len(v.Errors) == 1 {
+		return v.Errors[0]
+	}
+
+	var b bytes.Buffer
+	for i, e := range v.Errors {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e)
+	}
+
+	return b.String()
+}
+
+// flatten returns the reasons at the leaves of the error tree, the errors
+// above them only say which keyword they failed under.
+func flatten(ve *jsonschema.ValidationError) []string {
+	if len(ve.Causes) == 0 {
+		return []string{describe(ve)}
+	}
+
+	var errs []string
+	for _, cause := range ve.Causes {
+		errs = append(errs, flatten(cause)...)
+	}
+
+	return errs
+}
+
+// describe prefixes the reason with the path to the value that failed.
+func describe(ve *jsonschema.ValidationError) string {
+	return fmt.Sprintf("$%s: %s", ve.InstanceLocation, ve.Message)
+}
@@ -0,0 +1,123 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"required": ["id", "amount"],
+	"properties": {
+		"id": {"type": "string"},
+		"amount": {"type": "number", "minimum": 0},
+		"currency": {"type": "string", "enum": ["NGN", "USD"]},
+		"lines": {"type": "array", "items": {"type": "object", "required": ["sku"]}}
+	}
+}`
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "valid schema", schema: invoiceSchema},
+		{name: "empty schema", schema: "", wantErr: true},
+		{name: "malformed json", schema: `{"type": `, wantErr: true},
+		{name: "unknown type", schema: `{"type": "money"}`, wantErr: true},
+		{name: "invalid pattern", schema: `{"type": "string", "pattern": "["}`, wantErr: true},
+		{name: "type array", schema: `{"type": ["string", "null"]}`},
+		{name: "local reference", schema: `{"$defs": {"id": {"type": "string"}}, "properties": {"id": {"$ref": "#/$defs/id"}}}`},
+		{name: "remote reference", schema: `{"$ref": "https://example.com/schema.json"}`, wantErr: true},
+		{name: "file reference", schema: `{"$ref": "/etc/passwd"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(json.RawMessage(tt.schema))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSchema_Validate_Keywords(t *testing.T) {
+	s, err := Compile(json.RawMessage(`{
+		"$defs": {"currency": {"enum": ["NGN", "USD"]}},
+		"type": "object",
+		"properties": {
+			"kind": {"const": "invoice"},
+			"note": {"type": ["string", "null"]},
+			"currency": {"$ref": "#/$defs/currency"}
+		}
+	}`))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate(json.RawMessage(`{"kind": "invoice", "note": null, "currency": "USD"}`)))
+
+	var ve *ValidationError
+	err = s.Validate(json.RawMessage(`{"kind": "refund", "note": 1, "currency": "EUR"}`))
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 3)
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := Compile(json.RawMessage(invoiceSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		data       string
+		wantErrors []string
+	}{
+		{
+			name: "valid payload",
+			data: `{"id": "inv_1", "amount": 20, "currency": "NGN", "lines": [{"sku": "a"}]}`,
+		},
+		{
+			name:       "missing required property",
+			data:       `{"id": "inv_1"}`,
+			wantErrors: []string{`$: missing properties: 'amount'`},
+		},
+		{
+			name: "several failures",
+			data: `{"id": 1, "amount": -1, "lines": [{}]}`,
+			wantErrors: []string{
+				"$/amount: must be >= 0 but found -1",
+				"$/id: expected string, but got number",
+				"$/lines/0: missing properties: 'sku'",
+			},
+		},
+		{
+			name:       "invalid json",
+			data:       `{"id": `,
+			wantErrors: []string{"invalid json: unexpected EOF"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(json.RawMessage(tt.data))
+			if len(tt.wantErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				require.Equal(t, tt.wantErrors[0], err.Error())
+				return
+			}
+			require.ElementsMatch(t, tt.wantErrors, ve.Errors)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package normalize converts non-JSON request bodies into JSON so events
+// from form posting and XML providers can be filtered and transformed like
+// any other event.
+//
+// Form and multipart fields become a JSON object keyed by field name, the
+// value is a string or, when a field is repeated, an array of strings.
+// Multipart file parts become objects with filename, content_type, size
+// and the base64 encoded content.
+//
+// XML is mapped as follows:
+//   - the document becomes an object with the root element's name as its only key
+//   - an element with neither attributes nor child elements becomes its text
+//   - attributes become keys prefixed with "@"
+//   - child elements become keys by their local name (namespaces are dropped),
+//     repeated child elements become an array
+//   - the text of an element with attributes or children is stored under "#text"
+//
+// For example <order id="1"><item>a</item><item>b</item></order> becomes
+// {"order":{"@id":"1","item":["a","b"]}}.
+package normalize
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+)
+
+var ErrEmptyXMLDocument = errors.New("xml document has no root element")
+
+// ToJSON converts body to JSON based on its content type. Bodies of any
+// content type other than form, multipart or XML are returned unchanged
+// with converted set to false.
+func ToJSON(contentType string, body []byte) (data []byte, converted bool, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// a missing or malformed content type is treated as JSON
+		return body, false, nil
+	}
+
+	var v interface{}
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		v, err = formToMap(body)
+	case mediaType == "multipart/form-data":
+		v, err = multipartToMap(body, params["boundary"])
+	case mediaType == "application/xml", mediaType == "text/xml", strings.HasSuffix(mediaType, "+xml"):
+		v, err = xmlToMap(body)
+	default:
+		return body, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err = json.Marshal(v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func formToMap(body []byte) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse form body: %v", err)
+	}
+
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if len(v) == 1 {
+			m[k] = v[0]
+			continue
+		}
+
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+func multipartToMap(body []byte, boundary string) (map[string]interface{}, error) {
+	if len(boundary) == 0 {
+		return nil, errors.New("multipart body is missing a boundary")
+	}
+
+	m := map[string]interface{}{}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse multipart body: %v", err)
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse multipart body: %v", err)
+		}
+
+		var value interface{} = string(content)
+		if len(part.FileName()) > 0 {
+			value = map[string]interface{}{
+				"filename":     part.FileName(),
+				"content_type": part.Header.Get("Content-Type"),
+				"size":         len(content),
+				"content":      base64.StdEncoding.EncodeToString(content),
+			}
+		}
+
+		add(m, part.FormName(), value)
+	}
+
+	return m, nil
+}
+
+func xmlToMap(body []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyXMLDocument
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xml body: %v", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			v, err := decodeElement(decoder, start)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse xml body: %v", err)
+			}
+
+			return map[string]interface{}{start.Name.Local: v}, nil
+		}
+	}
+}
+
+func decodeElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	m := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+
+		m["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+
+			add(m, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+
+			if len(s) > 0 {
+				m["#text"] = s
+			}
+
+			return m, nil
+		}
+	}
+}
+
+// add sets key to value, turning the key's value into an array when the
+// key is repeated.
+func add(m map[string]interface{}, key string, value interface{}) {
+	existing, ok := m[key]
+	if !ok {
+		m[key] = value
+		return
+	}
+
+	if values, ok := existing.([]interface{}); ok {
+		m[key] = append(values, value)
+		return
+	}
+
+	m[key] = []interface{}{existing, value}
+}
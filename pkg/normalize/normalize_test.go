@@ -0,0 +1,102 @@
+package normalize
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToJSON(t *testing.T) {
+	tests := map[string]struct {
+		contentType       string
+		body              string
+		expected          string
+		expectedConverted bool
+		wantErr           bool
+	}{
+		"json_is_unchanged": {
+			contentType: "application/json",
+			body:        `{"a":1}`,
+			expected:    `{"a":1}`,
+		},
+		"missing_content_type_is_unchanged": {
+			body:     `{"a":1}`,
+			expected: `{"a":1}`,
+		},
+		"form": {
+			contentType:       "application/x-www-form-urlencoded; charset=utf-8",
+			body:              "From=%2B15551234567&Body=hello+world&tag=a&tag=b",
+			expected:          `{"Body":"hello world","From":"+15551234567","tag":["a","b"]}`,
+			expectedConverted: true,
+		},
+		"invalid_form": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        "a=%zz",
+			wantErr:     true,
+		},
+		"xml": {
+			contentType:       "application/xml",
+			body:              `<?xml version="1.0"?><order id="1" xmlns="urn:shop"><item>a</item><item sku="2">b</item><note/></order>`,
+			expected:          `{"order":{"@id":"1","item":["a",{"#text":"b","@sku":"2"}],"note":""}}`,
+			expectedConverted: true,
+		},
+		"soap_xml": {
+			contentType:       "application/soap+xml",
+			body:              `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Body><Ping>1</Ping></s:Body></s:Envelope>`,
+			expected:          `{"Envelope":{"Body":{"Ping":"1"}}}`,
+			expectedConverted: true,
+		},
+		"invalid_xml": {
+			contentType: "text/xml",
+			body:        `<order><item></order>`,
+			wantErr:     true,
+		},
+		"empty_xml": {
+			contentType: "text/xml",
+			body:        ``,
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, converted, err := ToJSON(tc.contentType, []byte(tc.body))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedConverted, converted)
+			require.JSONEq(t, tc.expected, string(data))
+		})
+	}
+}
+
+func Test_ToJSON_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	require.NoError(t, w.WriteField("event", "signup"))
+	require.NoError(t, w.WriteField("tag", "a"))
+	require.NoError(t, w.WriteField("tag", "b"))
+
+	fw, err := w.CreateFormFile("attachment", "hello.txt")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	data, converted, err := ToJSON(w.FormDataContentType(), body.Bytes())
+	require.NoError(t, err)
+	require.True(t, converted)
+	require.JSONEq(t, `{
+		"event": "signup",
+		"tag": ["a", "b"],
+		"attachment": {"filename": "hello.txt", "content_type": "application/octet-stream", "size": 5, "content": "aGVsbG8="}
+	}`, string(data))
+
+	_, _, err = ToJSON("multipart/form-data", body.Bytes())
+	require.Error(t, err)
+}
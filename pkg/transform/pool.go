@@ -0,0 +1,123 @@
+package transform
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/dop251/goja"
+)
+
+// maxPooledFunctions bounds how many functions have runtimes pooled.
+const maxPooledFunctions = 1024
+
+// vm is a runtime with a transform function loaded. Runtimes are only
+// reused for the function and project they were created with, so a
+// function never sees the globals another one, or the same function of
+// another project, left behind.
+type vm struct {
+	rt        *goja.Runtime
+	printer   *BuffPrinter
+	transform func(interface{}) (interface{}, error)
+}
+
+type poolKey struct {
+	project   string
+	function  [sha256.Size]byte
+	libraries [sha256.Size]byte
+	cfg       Config
+}
+
+type functionPool struct {
+	program *goja.Program
+	vms     sync.Pool
+}
+
+var pools = struct {
+	sync.Mutex
+	m map[poolKey]*functionPool
+}{m: make(map[poolKey]*functionPool)}
+
+func getPool(key poolKey, function string) (*functionPool, error) {
+	pools.Lock()
+	defer pools.Unlock()
+
+	if p, ok := pools.m[key]; ok {
+		return p, nil
+	}
+
+	program, err := goja.Compile("transform.js", function, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pools.m) >= maxPooledFunctions {
+		for k := range pools.m {
+			delete(pools.m, k)
+			break
+		}
+	}
+
+	p := &functionPool{program: program}
+	pools.m[key] = p
+	return p, nil
+}
+
+func (t *Transformer) poolKey(function string) poolKey {
+	return poolKey{project: t.project, function: sha256.Sum256([]byte(function)), libraries: t.librariesSum, cfg: t.cfg}
+}
+
+// acquire returns a runtime with function loaded. Deterministic
+// transformers always get a new runtime, so state kept in globals
+// can't change their output.
+func (t *Transformer) acquire(function string) (*vm, error) {
+	p, err := getPool(t.poolKey(function), function)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.cfg.Deterministic {
+		if v, ok := p.vms.Get().(*vm); ok {
+			v.printer.Reset()
+			return v, nil
+		}
+	}
+
+	rt := goja.New()
+	rt.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+	rt.SetMaxCallStackSize(t.cfg.MaxCallStackSize)
+
+	v := &vm{rt: rt, printer: enableConsole(rt, t.cfg, t.libraries)}
+	err = t.run(rt, func() error {
+		_, err := rt.RunProgram(p.program)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f := rt.Get("transform")
+	if f == nil {
+		return nil, ErrFunctionNotFound
+	}
+
+	err = rt.ExportTo(f, &v.transform)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (t *Transformer) release(function string, v *vm) {
+	if t.cfg.Deterministic {
+		return
+	}
+
+	pools.Lock()
+	p, ok := pools.m[t.poolKey(function)]
+	pools.Unlock()
+
+	if ok {
+		p.vms.Put(v)
+	}
+}
@@ -7,17 +7,20 @@ import (
 
 const newLine = " \r\n"
 
+const truncatedMessage = "... console output truncated"
+
 func NewBufferPrinter() *BuffPrinter {
-	bufferLogger := &strings.Builder{}
-	return &BuffPrinter{
-		Buff: bufferLogger,
-		BuffOutPrint: func(s string) {
-			bufferLogger.WriteString(fmt.Sprintf("%s%s", s, newLine))
-		},
-		BuffErrPrint: func(s string) {
-			bufferLogger.WriteString(fmt.Sprintf("%s%s", s, newLine))
-		},
-	}
+	return NewLimitedBufferPrinter(0)
+}
+
+// NewLimitedBufferPrinter creates a printer that keeps at most max bytes of
+// output, a max of zero keeps everything.
+func NewLimitedBufferPrinter(max int) *BuffPrinter {
+	b := &BuffPrinter{Buff: &strings.Builder{}, max: max}
+	b.BuffOutPrint = b.write
+	b.BuffErrPrint = b.write
+
+	return b
 }
 
 // BuffPrinter implements the console.Printer interface
@@ -26,6 +29,24 @@ type BuffPrinter struct {
 	Buff         *strings.Builder
 	BuffOutPrint func(s string)
 	BuffErrPrint func(s string)
+
+	max       int
+	truncated bool
+}
+
+func (b *BuffPrinter) write(s string) {
+	if b.truncated {
+		return
+	}
+
+	line := fmt.Sprintf("%s%s", s, newLine)
+	if b.max > 0 && b.Buff.Len()+len(line) > b.max {
+		b.Buff.WriteString(truncatedMessage + newLine)
+		b.truncated = true
+		return
+	}
+
+	b.Buff.WriteString(line)
 }
 
 // Log writes s to a buffer.
@@ -46,3 +67,9 @@ func (b *BuffPrinter) Error(s string) {
 func (b *BuffPrinter) Format() []string {
 	return strings.Split(b.Buff.String(), newLine)
 }
+
+// Reset discards the output written so far.
+func (b *BuffPrinter) Reset() {
+	b.Buff.Reset()
+	b.truncated = false
+}
@@ -1,31 +1,167 @@
 package transform
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
-	"github.com/dop251/goja"
-	"github.com/dop251/goja_nodejs/console"
-	"github.com/dop251/goja_nodejs/require"
 	"io"
+	"math/rand"
 	"net/http"
+	"runtime/metrics"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/console"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+var (
+	ErrFunctionNotFound        = errors.New("the transform function is not found, please define it or rename the existing function")
+	ErrMaxExecutionTimeElapsed = errors.New("script execution time limit exceeded")
+	ErrMaxMemoryExceeded       = errors.New("script memory limit exceeded")
+	ErrMaxCallStackExceeded    = errors.New("script call stack size exceeded")
+)
+
+const (
+	DefaultTimeout          = 10 * time.Second
+	DefaultMaxMemory        = 256 << 20
+	DefaultMaxCallStackSize = 1024
+	DefaultMaxConsoleOutput = 64 << 10
+
+	// memoryCheckInterval is how often the heap is sampled while
+	// transforms run.
+	memoryCheckInterval = 10 * time.Millisecond
 )
 
-var ErrFunctionNotFound = errors.New("the transform function is not found, please define it or rename the existing function")
-var ErrMaxExecutionTimeElapsed = errors.New("script execution time elapsed 10 seconds")
+// Config limits the resources a transform may use, zero values are
+// replaced with the defaults.
+type Config struct {
+	// Timeout is the most time a transform may run for before it's
+	// interrupted.
+	Timeout time.Duration
+
+	// MaxMemory is how much a transform may allocate. goja doesn't account
+	// for allocations per runtime, so the heap growth is charged to the
+	// transforms running at the time, see memoryGuard. It guards against
+	// runaway scripts rather than being a precise limit.
+	MaxMemory uint64
+
+	// MaxCallStackSize limits how deep a transform may recurse.
+	MaxCallStackSize int
+
+	// MaxConsoleOutput is the most bytes of console output kept, the
+	// rest is dropped.
+	MaxConsoleOutput int
+
+	// Deterministic fixes the time Date.now returns and seeds
+	// Math.random, so a transform's output only depends on its input.
+	Deterministic bool
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+
+	if c.MaxMemory == 0 {
+		c.MaxMemory = DefaultMaxMemory
+	}
+
+	if c.MaxCallStackSize <= 0 {
+		c.MaxCallStackSize = DefaultMaxCallStackSize
+	}
+
+	if c.MaxConsoleOutput <= 0 {
+		c.MaxConsoleOutput = DefaultMaxConsoleOutput
+	}
+
+	return c
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   = Config{}.withDefaults()
+)
+
+// Configure sets the limits used by transformers created with
+// NewTransformer.
+func Configure(cfg Config) {
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+
+	defaultConfig = cfg.withDefaults()
+}
+
+func getDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
 
 type Transformer struct {
-	rt *goja.Runtime
+	rt      *goja.Runtime
+	cfg     Config
+	now     time.Time
+	project string
+
+	libraries    map[string]string
+	librariesSum [sha256.Size]byte
 }
 
 func NewTransformer() *Transformer {
+	return NewTransformerWithConfig(getDefaultConfig())
+}
+
+func NewTransformerWithConfig(cfg Config) *Transformer {
+	cfg = cfg.withDefaults()
+
 	r := goja.New()
 	r.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
-	return &Transformer{rt: r}
+	r.SetMaxCallStackSize(cfg.MaxCallStackSize)
+
+	return &Transformer{rt: r, cfg: cfg}
+}
+
+// WithTime sets the time Date.now returns when the transformer is
+// deterministic, it defaults to the unix epoch.
+func (t *Transformer) WithTime(now time.Time) *Transformer {
+	t.now = now
+	return t
+}
+
+// WithProject sets the project the transformer runs functions for, the
+// runtimes of a function are only reused within a project.
+func (t *Transformer) WithProject(projectID string) *Transformer {
+	t.project = projectID
+	return t
+}
+
+// WithLibraries sets the modules functions can require, keyed by the name
+// they're required with.
+func (t *Transformer) WithLibraries(libraries map[string]string) *Transformer {
+	names := make([]string, 0, len(libraries))
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(libraries[name]))
+		h.Write([]byte{0})
+	}
+
+	t.libraries = libraries
+	copy(t.librariesSum[:], h.Sum(nil))
+	return t
 }
 
 const url = "https://underscorejs.org/underscore-min.js"
-const deadline = time.Second * 10
 
 func closeWithError(closer io.Closer) {
 	err := closer.Close()
@@ -48,11 +184,10 @@ func (t *Transformer) TransformUsingUnderscoreJs(function string, payload interf
 		return nil, []string{}, err
 	}
 
-	time.AfterFunc(deadline, func() {
-		t.rt.Interrupt(ErrMaxExecutionTimeElapsed)
+	err = t.run(t.rt, func() error {
+		_, err := t.rt.RunString(string(data))
+		return err
 	})
-
-	_, err = t.rt.RunString(string(data))
 	if err != nil {
 		return nil, []string{}, err
 	}
@@ -61,74 +196,253 @@ func (t *Transformer) TransformUsingUnderscoreJs(function string, payload interf
 }
 
 func (t *Transformer) RunStringUnsafe(function string, payload interface{}) (interface{}, []string, error) {
-	new(require.Registry).Enable(t.rt)
-
-	printer := NewBufferPrinter()
-	require.RegisterCoreModule(console.ModuleName,
-		console.RequireWithPrinter(printer))
-
-	console.Enable(t.rt)
+	printer := enableConsole(t.rt, t.cfg, t.libraries)
 
 	err := t.rt.Set("payload", payload)
 	if err != nil {
 		return nil, []string{}, err
 	}
 
-	time.AfterFunc(deadline, func() {
-		t.rt.Interrupt(ErrMaxExecutionTimeElapsed)
+	var value goja.Value
+	err = t.run(t.rt, func() error {
+		var err error
+		value, err = t.rt.RunString(function)
+		return err
 	})
-
-	value, err := t.rt.RunString(function)
 	if err != nil {
 		return nil, []string{}, err
 	}
 
 	l := len(printer.Format())
-
 	return value, printer.Format()[:l-1], err
 }
 
 // Transform mutates the payload by the passed function
 // The output of Transform should be idempotent
 func (t *Transformer) Transform(function string, payload interface{}) (interface{}, []string, error) {
-	new(require.Registry).Enable(t.rt)
+	v, err := t.acquire(function)
+	if err != nil {
+		return nil, []string{}, err
+	}
+
+	var value interface{}
+	err = t.run(v.rt, func() error {
+		var err error
+		value, err = v.transform(payload)
+		return err
+	})
+	if err != nil {
+		// an interrupted runtime may be left in any state
+		return nil, []string{}, err
+	}
+
+	logs := v.printer.Format()
+	t.release(function, v)
+
+	return value, logs[:len(logs)-1], nil
+}
 
-	printer := NewBufferPrinter()
-	require.RegisterCoreModule(console.ModuleName,
-		console.RequireWithPrinter(printer))
+// run calls fn with the transformer's time and memory limits applied to rt.
+func (t *Transformer) run(rt *goja.Runtime, fn func() error) error {
+	rt.ClearInterrupt()
 
-	console.Enable(t.rt)
+	if t.cfg.Deterministic {
+		now := t.now
+		if now.IsZero() {
+			now = time.Unix(0, 0)
+		}
 
-	time.AfterFunc(deadline, func() {
-		t.rt.Interrupt(ErrMaxExecutionTimeElapsed)
+		rt.SetTimeSource(func() time.Time { return now })
+		rt.SetRandSource(rand.New(rand.NewSource(now.UnixNano())).Float64)
+	}
+
+	timer := time.AfterFunc(t.cfg.Timeout, func() {
+		rt.Interrupt(ErrMaxExecutionTimeElapsed)
 	})
+	defer timer.Stop()
 
-	_, err := t.rt.RunString(function)
-	if err != nil {
-		return nil, []string{}, err
+	defer memory.track(rt, t.cfg.MaxMemory)()
+
+	err := fn()
+
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if cause, ok := interrupted.Value().(error); ok {
+			return fmt.Errorf("%w: %v", cause, interrupted)
+		}
 	}
 
-	f := t.rt.Get("transform")
-	if f == nil {
-		return nil, []string{}, ErrFunctionNotFound
+	// goja's stack overflow error has no message
+	var overflow *goja.StackOverflowError
+	if errors.As(err, &overflow) {
+		return ErrMaxCallStackExceeded
 	}
 
-	var transform func(interface{}) (interface{}, error)
-	err = t.rt.ExportTo(f, &transform)
-	if err != nil {
-		return nil, []string{}, err
+	return err
+}
+
+// memoryGuard samples the heap while transforms run. goja doesn't account
+// for allocations per runtime, so each running transform is given a budget
+// of its MaxMemory. Once the heap has grown by more than the budgets of the
+// running transforms, the one that has run the longest, which has been
+// charged for most of the growth, is interrupted. A runaway script is
+// stopped without the transforms running beside it sharing its limit.
+type memoryGuard struct {
+	mu   sync.Mutex
+	runs map[*memoryRun]struct{}
+	stop chan struct{}
+}
+
+type memoryRun struct {
+	rt          *goja.Runtime
+	max         uint64
+	heap        uint64
+	startedAt   time.Time
+	interrupted bool
+}
+
+var memory = &memoryGuard{runs: map[*memoryRun]struct{}{}}
+
+// track charges rt for the heap growth until the returned func is called.
+func (g *memoryGuard) track(rt *goja.Runtime, max uint64) func() {
+	r := &memoryRun{rt: rt, max: max, heap: heapSize(), startedAt: time.Now()}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.runs[r] = struct{}{}
+	if g.stop == nil {
+		g.stop = make(chan struct{})
+		go g.sample(g.stop)
 	}
 
-	time.AfterFunc(deadline, func() {
-		t.rt.Interrupt(ErrMaxExecutionTimeElapsed)
-	})
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
 
-	value, err := transform(payload)
-	if err != nil {
-		return nil, []string{}, err
+		delete(g.runs, r)
+		if len(g.runs) == 0 && g.stop != nil {
+			close(g.stop)
+			g.stop = nil
+		}
 	}
+}
 
-	l := len(printer.Format())
+func (g *memoryGuard) sample(stop <-chan struct{}) {
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
 
-	return value, printer.Format()[:l-1], err
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			g.check(heapSize())
+		}
+	}
+}
+
+func (g *memoryGuard) check(size uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var oldest *memoryRun
+	var budget uint64
+	for r := range g.runs {
+		if r.interrupted {
+			continue
+		}
+
+		budget += r.max
+		if oldest == nil || r.startedAt.Before(oldest.startedAt) {
+			oldest = r
+		}
+	}
+
+	if oldest == nil || size <= oldest.heap {
+		return
+	}
+
+	if size-oldest.heap > budget {
+		oldest.interrupted = true
+		oldest.rt.Interrupt(ErrMaxMemoryExceeded)
+	}
+}
+
+func heapSize() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return sample[0].Value.Uint64()
+}
+
+// enableConsole sets up console and a require without access to the
+// filesystem on rt, the console writes to the returned printer. Libraries
+// are the only modules that can be required besides the built in ones.
+func enableConsole(rt *goja.Runtime, cfg Config, libraries map[string]string) *BuffPrinter {
+	printer := NewLimitedBufferPrinter(cfg.MaxConsoleOutput)
+
+	registry := require.NewRegistry(require.WithLoader(func(string) ([]byte, error) {
+		return nil, require.ModuleFileDoesNotExistError
+	}))
+
+	for name, source := range libraries {
+		registry.RegisterNativeModule(name, libraryLoader(name, source))
+	}
+
+	// registered last so a library can't replace it
+	registry.RegisterNativeModule(console.ModuleName, console.RequireWithPrinter(printer))
+	registry.Enable(rt)
+	console.Enable(rt)
+
+	return printer
+}
+
+// ValidateLibrary checks that a library's source compiles.
+func ValidateLibrary(source string) error {
+	_, err := goja.Compile("library.js", wrapLibrary(source), false)
+	return err
+}
+
+func wrapLibrary(source string) string {
+	return "(function(exports, require, module) {" + source + "\n})"
+}
+
+// libraryLoader runs a library like node runs a commonjs module.
+func libraryLoader(name, source string) require.ModuleLoader {
+	return func(rt *goja.Runtime, module *goja.Object) {
+		wrapper, err := rt.RunScript(name+".js", wrapLibrary(source))
+		if err != nil {
+			throw(rt, err)
+		}
+
+		fn, ok := goja.AssertFunction(wrapper)
+		if !ok {
+			throw(rt, fmt.Errorf("library %s could not be loaded", name))
+		}
+
+		_, err = fn(goja.Undefined(), module.Get("exports"), rt.Get("require"), module)
+		if err != nil {
+			throw(rt, err)
+		}
+	}
+}
+
+// throw raises err in the script that required a library.
+func throw(rt *goja.Runtime, err error) {
+	var exception *goja.Exception
+	var interrupted *goja.InterruptedError
+
+	switch {
+	case errors.As(err, &exception):
+		panic(exception)
+	case errors.As(err, &interrupted):
+		panic(interrupted)
+	default:
+		panic(rt.NewGoError(err))
+	}
 }
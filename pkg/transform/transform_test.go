@@ -1,10 +1,13 @@
 package transform
 
 import (
+	"github.com/dop251/goja"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type Name struct {
@@ -267,3 +270,163 @@ func Benchmark_TransformUsingUnderscoreJs_NewVM(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestTransformLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		function string
+		wantErr  string
+	}{
+		{
+			name:     "timeout",
+			cfg:      Config{Timeout: 100 * time.Millisecond},
+			function: `function transform(payload){ for (;;) {} }`,
+			wantErr:  ErrMaxExecutionTimeElapsed.Error(),
+		},
+		{
+			name:     "call stack",
+			cfg:      Config{MaxCallStackSize: 100},
+			function: `function f(n){ return f(n + 1) } function transform(payload){ return f(0) }`,
+			wantErr:  ErrMaxCallStackExceeded.Error(),
+		},
+		{
+			name:     "memory",
+			cfg:      Config{MaxMemory: 8 << 20},
+			function: `function transform(payload){ const a = []; for (;;) { a.push({payload: payload, i: a.length}) } }`,
+			wantErr:  ErrMaxMemoryExceeded.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewTransformerWithConfig(tt.cfg).Transform(tt.function, map[string]interface{}{"a": 1})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTransformConsoleOutputLimit(t *testing.T) {
+	function := `function transform(payload){
+		for (let i = 0; i < 1000; i++) { console.log("line " + i) }
+		return payload
+	}`
+
+	_, logs, err := NewTransformerWithConfig(Config{MaxConsoleOutput: 64}).Transform(function, map[string]interface{}{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 0", "line 1", "line 2", "line 3", "line 4", "line 5", "line 6", truncatedMessage}, logs)
+}
+
+func TestTransformDeterministic(t *testing.T) {
+	function := `function transform(payload){ return { now: Date.now(), random: Math.random() } }`
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first, _, err := NewTransformerWithConfig(Config{Deterministic: true}).WithTime(now).Transform(function, nil)
+	require.NoError(t, err)
+
+	second, _, err := NewTransformerWithConfig(Config{Deterministic: true}).WithTime(now).Transform(function, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, first, second)
+	require.Equal(t, now.UnixMilli(), first.(map[string]interface{})["now"])
+}
+
+func TestTransformPooledRuntimes(t *testing.T) {
+	counter := `var count = 0; function transform(payload){ count++; return count }`
+	reader := `function transform(payload){ return typeof count }`
+
+	transformer := NewTransformer()
+	_, _, err := transformer.Transform(counter, nil)
+	require.NoError(t, err)
+
+	// runtimes are only shared by the same function
+	value, _, err := transformer.Transform(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, "undefined", value)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, logs, err := NewTransformer().Transform(`function transform(payload){ console.log(payload.n); return payload.n * 2 }`, map[string]interface{}{"n": 2})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"2"}, logs)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTransformRequireFilesystem(t *testing.T) {
+	_, _, err := NewTransformer().Transform(`function transform(payload){ return require("/etc/hostname") }`, nil)
+	require.Error(t, err)
+}
+
+func TestTransformLibraries(t *testing.T) {
+	libraries := map[string]string{
+		"money":   `exports.cents = function (amount) { return Math.round(amount * 100) }`,
+		"invoice": `const money = require("money"); module.exports = function (inv) { return { total: money.cents(inv.total) } }`,
+	}
+
+	function := `function transform(payload) { return require("invoice")(payload) }`
+
+	value, _, err := NewTransformer().WithLibraries(libraries).Transform(function, map[string]interface{}{"total": 12.5})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"total": int64(1250)}, value)
+
+	// a changed library isn't served from a pooled runtime
+	libraries = map[string]string{
+		"money":   `exports.cents = function (amount) { return Math.round(amount * 1000) }`,
+		"invoice": `const money = require("money"); module.exports = function (inv) { return { total: money.cents(inv.total) } }`,
+	}
+
+	value, _, err = NewTransformer().WithLibraries(libraries).Transform(function, map[string]interface{}{"total": 12.5})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"total": int64(12500)}, value)
+
+	_, _, err = NewTransformer().Transform(function, map[string]interface{}{"total": 12.5})
+	require.Error(t, err)
+
+	_, _, err = NewTransformer().WithLibraries(map[string]string{"broken": `throw new Error("library failed")`}).
+		Transform(`function transform(payload) { return require("broken") }`, nil)
+	require.ErrorContains(t, err, "library failed")
+}
+
+func TestMemoryGuard_Check(t *testing.T) {
+	g := &memoryGuard{runs: map[*memoryRun]struct{}{}}
+
+	now := time.Now()
+	runaway := &memoryRun{rt: goja.New(), max: 100, heap: 1000, startedAt: now.Add(-time.Second)}
+	other := &memoryRun{rt: goja.New(), max: 100, heap: 1100, startedAt: now}
+	g.runs[runaway] = struct{}{}
+	g.runs[other] = struct{}{}
+
+	// each running transform adds its limit to the budget
+	g.check(1150)
+	require.False(t, runaway.interrupted)
+
+	// only the transform charged for most of the growth is interrupted
+	g.check(1250)
+	require.True(t, runaway.interrupted)
+	require.False(t, other.interrupted)
+}
+
+func TestTransform_PoolPerProject(t *testing.T) {
+	function := `
+var count = 0;
+function transform(payload) {
+	count++;
+	return count;
+}`
+
+	value, _, err := NewTransformer().WithProject("project-1").Transform(function, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, value)
+
+	// the runtime used for project-1 isn't handed to project-2
+	value, _, err = NewTransformer().WithProject("project-2").Transform(function, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, value)
+}
@@ -0,0 +1,263 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrKeyNotFound = errors.New("Signing key not found in key set")
+
+// DefaultJWKSCache is shared by all JWT verifiers so keys are fetched
+// once per key set url rather than once per request.
+var DefaultJWKSCache = NewJWKSCache(&http.Client{Timeout: 10 * time.Second}, time.Hour)
+
+// fetchTimeout bounds a key set fetch, it's shared by the callers waiting
+// on it so it isn't tied to any of their contexts.
+const fetchTimeout = 10 * time.Second
+
+// minRefreshInterval bounds how often a key set is re-fetched when a
+// token references a key id we don't know about.
+const minRefreshInterval = time.Minute
+
+type jwkSet struct {
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// JWKSCache fetches and caches JSON Web Key Sets. Concurrent fetches of
+// the same key set are collapsed into one, the lock only guards the map.
+type JWKSCache struct {
+	client *http.Client
+	ttl    time.Duration
+	group  singleflight.Group
+
+	mu   sync.RWMutex
+	sets map[string]*jwkSet
+}
+
+func NewJWKSCache(client *http.Client, ttl time.Duration) *JWKSCache {
+	return &JWKSCache{client: client, ttl: ttl, sets: map[string]*jwkSet{}}
+}
+
+// Key returns the key identified by kid from the key set at url. An empty
+// kid matches the set's only key.
+func (c *JWKSCache) Key(ctx context.Context, url, kid string) (interface{}, error) {
+	var err error
+
+	set := c.get(url)
+	if set == nil || time.Since(set.fetchedAt) > c.ttl {
+		set, err = c.refresh(ctx, url, set)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := set.lookup(kid)
+	if err == nil {
+		return key, nil
+	}
+
+	// the signer may have rotated its keys
+	if time.Since(set.fetchedAt) < minRefreshInterval {
+		return nil, err
+	}
+
+	set, err = c.refresh(ctx, url, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return set.lookup(kid)
+}
+
+func (c *JWKSCache) get(url string) *jwkSet {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.sets[url]
+}
+
+// refresh replaces stale, the set the caller has seen, with a newly
+// fetched one. When another caller has replaced it already, that set
+// is returned rather than fetching it again. The fetch is detached from
+// the caller that started it, so its cancellation doesn't fail the other
+// callers waiting on it, each caller only stops waiting when its own
+// context is done.
+func (c *JWKSCache) refresh(ctx context.Context, url string, stale *jwkSet) (*jwkSet, error) {
+	ch := c.group.DoChan(url, func() (interface{}, error) {
+		if set := c.get(url); set != nil && set != stale {
+			return set, nil
+		}
+
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fetchTimeout)
+		defer cancel()
+
+		set, err := c.fetch(fetchCtx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		c.sets[url] = set
+		c.mu.Unlock()
+
+		return set, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*jwkSet), nil
+	}
+}
+
+func (c *JWKSCache) fetch(ctx context.Context, url string) (*jwkSet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch key set: unexpected status %d", res.StatusCode)
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode key set: %v", err)
+	}
+
+	set := &jwkSet{keys: map[string]interface{}{}, fetchedAt: time.Now()}
+	for _, k := range body.Keys {
+		if len(k.Use) > 0 && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			// skip keys we can't use rather than failing the whole set
+			continue
+		}
+
+		set.keys[k.Kid] = key
+	}
+
+	return set, nil
+}
+
+func (s *jwkSet) lookup(kid string) (interface{}, error) {
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	if len(kid) == 0 && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func (k *jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
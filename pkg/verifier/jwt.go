@@ -0,0 +1,171 @@
+package verifier
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrInvalidToken         = errors.New("Invalid token")
+	ErrTokenExpired         = errors.New("Token is expired or missing an expiry")
+	ErrInvalidTokenIssuer   = errors.New("Invalid token issuer")
+	ErrInvalidTokenAudience = errors.New("Invalid token audience")
+	ErrInvalidTokenKey      = errors.New("Invalid token verification key")
+)
+
+// ClaimsVerifier is implemented by verifiers that can return the verified
+// claims about the sender of a request, the claims are attached to the
+// ingested event's metadata.
+type ClaimsVerifier interface {
+	Verifier
+	VerifyClaims(r *http.Request, payload []byte) (map[string]interface{}, error)
+}
+
+type JWTOptions struct {
+	// Algorithm is the expected signing algorithm e.g. RS256, tokens
+	// signed with any other algorithm are rejected.
+	Algorithm string
+
+	// Key is the HMAC secret or the PEM encoded public key used to verify
+	// the token, it is ignored when JwksURL is set.
+	Key string
+
+	// JwksURL is the url of a JSON Web Key Set, keys are selected using
+	// the token's kid header.
+	JwksURL string
+
+	Issuer   string
+	Audience string
+
+	// BodyHashClaim optionally names a claim containing the hex or base64
+	// encoded SHA256 hash of the request body.
+	BodyHashClaim string
+}
+
+type JWTVerifier struct {
+	opts *JWTOptions
+	jwks *JWKSCache
+	now  func() time.Time
+}
+
+func NewJWTVerifier(opts *JWTOptions) *JWTVerifier {
+	return &JWTVerifier{opts: opts, jwks: DefaultJWKSCache, now: time.Now}
+}
+
+func (jV *JWTVerifier) VerifyRequest(r *http.Request, payload []byte) error {
+	_, err := jV.VerifyClaims(r, payload)
+	return err
+}
+
+func (jV *JWTVerifier) VerifyClaims(r *http.Request, payload []byte) (map[string]interface{}, error) {
+	val := r.Header.Get("Authorization")
+	if len(strings.TrimSpace(val)) == 0 {
+		return nil, ErrAuthHeaderCannotBeEmpty
+	}
+
+	scheme, tokenString, found := strings.Cut(val, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrInvalidHeaderStructure
+	}
+
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	if len(jV.opts.Algorithm) > 0 {
+		parser.ValidMethods = []string{jV.opts.Algorithm}
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := parser.ParseWithClaims(strings.TrimSpace(tokenString), claims, func(t *jwt.Token) (interface{}, error) {
+		return jV.key(r, t)
+	})
+	if err != nil || !token.Valid {
+		var vErr *jwt.ValidationError
+		if errors.As(err, &vErr) && errors.Is(vErr.Inner, ErrInvalidTokenKey) {
+			return nil, ErrInvalidTokenKey
+		}
+		return nil, ErrInvalidToken
+	}
+
+	now := jV.now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, ErrTokenExpired
+	}
+
+	if !claims.VerifyNotBefore(now, false) {
+		return nil, ErrInvalidToken
+	}
+
+	if len(jV.opts.Issuer) > 0 && !claims.VerifyIssuer(jV.opts.Issuer, true) {
+		return nil, ErrInvalidTokenIssuer
+	}
+
+	if len(jV.opts.Audience) > 0 && !claims.VerifyAudience(jV.opts.Audience, true) {
+		return nil, ErrInvalidTokenAudience
+	}
+
+	if len(jV.opts.BodyHashClaim) > 0 {
+		sent, ok := claims[jV.opts.BodyHashClaim].(string)
+		if !ok || !matchesBodyHash(sent, payload) {
+			return nil, ErrBodyHashDoesNotMatch
+		}
+	}
+
+	return claims, nil
+}
+
+func (jV *JWTVerifier) key(r *http.Request, t *jwt.Token) (interface{}, error) {
+	if len(jV.opts.JwksURL) > 0 {
+		kid, _ := t.Header["kid"].(string)
+		return jV.jwks.Key(r.Context(), jV.opts.JwksURL, kid)
+	}
+
+	key := []byte(jV.opts.Key)
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return key, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		k, err := jwt.ParseRSAPublicKeyFromPEM(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTokenKey, err)
+		}
+		return k, nil
+	case *jwt.SigningMethodECDSA:
+		k, err := jwt.ParseECPublicKeyFromPEM(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTokenKey, err)
+		}
+		return k, nil
+	case *jwt.SigningMethodEd25519:
+		k, err := jwt.ParseEdPublicKeyFromPEM(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTokenKey, err)
+		}
+		return k, nil
+	default:
+		return nil, ErrAlgoNotFound
+	}
+}
+
+func matchesBodyHash(sent string, payload []byte) bool {
+	sum := sha256.Sum256(payload)
+
+	for _, computed := range []string{
+		hex.EncodeToString(sum[:]),
+		base64.StdEncoding.EncodeToString(sum[:]),
+		base64.RawURLEncoding.EncodeToString(sum[:]),
+	} {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimRight(sent, "=")), []byte(strings.TrimRight(computed, "="))) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
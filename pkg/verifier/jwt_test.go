@@ -0,0 +1,233 @@
+package verifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JWTVerifier_VerifyClaims(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := []byte(`{"order":{"id":"ord_1"}}`)
+	sum := sha256.Sum256(payload)
+
+	sign := func(t *testing.T, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("Convoy"))
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := map[string]struct {
+		opts          *JWTOptions
+		claims        jwt.MapClaims
+		header        func(token string) string
+		expectedError error
+	}{
+		"valid_token": {
+			opts: &JWTOptions{Algorithm: "HS256", Key: "Convoy", Issuer: "partner", Audience: "convoy", BodyHashClaim: "body_sha256"},
+			claims: jwt.MapClaims{
+				"iss":         "partner",
+				"aud":         []string{"other", "convoy"},
+				"exp":         now.Add(time.Minute).Unix(),
+				"body_sha256": hex.EncodeToString(sum[:]),
+				"tenant":      "acme",
+			},
+		},
+		"expired_token": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Convoy"},
+			claims:        jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()},
+			expectedError: ErrTokenExpired,
+		},
+		"missing_expiry": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Convoy"},
+			claims:        jwt.MapClaims{"iss": "partner"},
+			expectedError: ErrTokenExpired,
+		},
+		"wrong_issuer": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Convoy", Issuer: "partner"},
+			claims:        jwt.MapClaims{"iss": "someone-else", "exp": now.Add(time.Minute).Unix()},
+			expectedError: ErrInvalidTokenIssuer,
+		},
+		"wrong_audience": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Convoy", Audience: "convoy"},
+			claims:        jwt.MapClaims{"aud": "someone-else", "exp": now.Add(time.Minute).Unix()},
+			expectedError: ErrInvalidTokenAudience,
+		},
+		"tampered_body": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Convoy", BodyHashClaim: "body_sha256"},
+			claims:        jwt.MapClaims{"exp": now.Add(time.Minute).Unix(), "body_sha256": "abc"},
+			expectedError: ErrBodyHashDoesNotMatch,
+		},
+		"wrong_key": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Not-Convoy"},
+			claims:        jwt.MapClaims{"exp": now.Add(time.Minute).Unix()},
+			expectedError: ErrInvalidToken,
+		},
+		"unexpected_algorithm": {
+			opts:          &JWTOptions{Algorithm: "RS256", Key: "Convoy"},
+			claims:        jwt.MapClaims{"exp": now.Add(time.Minute).Unix()},
+			expectedError: ErrInvalidToken,
+		},
+		"missing_bearer_scheme": {
+			opts:          &JWTOptions{Algorithm: "HS256", Key: "Convoy"},
+			claims:        jwt.MapClaims{"exp": now.Add(time.Minute).Unix()},
+			header:        func(token string) string { return token },
+			expectedError: ErrInvalidHeaderStructure,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			v := NewJWTVerifier(tc.opts)
+			v.now = func() time.Time { return now }
+
+			req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+			require.NoError(t, err)
+
+			token := sign(t, tc.claims)
+			header := "Bearer " + token
+			if tc.header != nil {
+				header = tc.header(token)
+			}
+			req.Header.Add("Authorization", header)
+
+			// Act.
+			claims, err := v.VerifyClaims(req, payload)
+
+			// Assert.
+			require.ErrorIs(t, err, tc.expectedError)
+			if tc.expectedError == nil {
+				require.Equal(t, "acme", claims["tenant"])
+			}
+		})
+	}
+}
+
+func Test_JWTVerifier_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	v := NewJWTVerifier(&JWTOptions{Algorithm: "RS256", JwksURL: srv.URL})
+	v.jwks = NewJWKSCache(srv.Client(), time.Hour)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"exp": time.Now().Add(time.Minute).Unix()})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+		require.NoError(t, err)
+		req.Header.Add("Authorization", "Bearer "+sign("key-1"))
+
+		require.NoError(t, v.VerifyRequest(req, nil))
+	}
+
+	// keys are cached between requests
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+	require.NoError(t, err)
+	req.Header.Add("Authorization", "Bearer "+sign("key-2"))
+
+	require.ErrorIs(t, v.VerifyRequest(req, nil), ErrInvalidToken)
+	// unknown key ids don't trigger a refetch within the refresh interval
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func Test_JWKSCache_FirstCallerCancelled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`{"keys":[{"kty":"oct","kid":"key-1","k":"c2VjcmV0"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewJWKSCache(srv.Client(), time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.Key(ctx, srv.URL, "key-1")
+		first <- err
+	}()
+
+	// the second caller waits on the fetch the first one started
+	time.Sleep(50 * time.Millisecond)
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.Key(context.Background(), srv.URL, "key-1")
+		second <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	require.NoError(t, <-second)
+}
+
+func Test_JWKSCache_ConcurrentFetch(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		_, _ = w.Write([]byte(`{"keys":[{"kty":"oct","kid":"key-1","k":"c2VjcmV0"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewJWKSCache(srv.Client(), time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Key(context.Background(), srv.URL, "key-1")
+			require.NoError(t, err)
+		}()
+	}
+
+	// let the callers pile up behind the first fetch
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
@@ -2,14 +2,22 @@ package verifier
 
 import (
 	"crypto/hmac"
+	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"hash"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -25,12 +33,25 @@ var (
 	ErrInvalidHeaderStructure             = errors.New("Invalid header structure")
 	ErrInvalidAuthLength                  = errors.New("Invalid Basic Auth Length")
 	ErrInvalidEncoding                    = errors.New("Invalid header encoding")
+	ErrInvalidTimestamp                   = errors.New("Invalid signature timestamp")
+	ErrTimestampOutsideTolerance          = errors.New("Signature timestamp is outside the tolerance window")
+	ErrBodyHashDoesNotMatch               = errors.New("Invalid Signature - Body hash does not match")
 )
 
+// DefaultTolerance is the maximum age of a timestamped signature
+// accepted by the Stripe and Slack verifiers.
+const DefaultTolerance = 5 * time.Minute
+
 type Verifier interface {
 	VerifyRequest(r *http.Request, payload []byte) error
 }
 
+// ProxiedVerifier is implemented by verifiers that read headers set by
+// the proxies in front of convoy, they're only trusted from these proxies.
+type ProxiedVerifier interface {
+	SetTrustedProxies(trustedProxies []*net.IPNet)
+}
+
 type HmacOptions struct {
 	Header       string
 	GetSignature func(string) string
@@ -265,6 +286,280 @@ func (tV *TwitterVerifier) getSignature(sig string) string {
 	return strings.Split(sig, "sha256=")[1]
 }
 
+// StripeVerifier verifies the Stripe-Signature header, which carries a
+// timestamp and one or more v1 signatures of the form t=...,v1=...,v1=...
+// See https://stripe.com/docs/webhooks#verify-manually
+type StripeVerifier struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+func NewStripeVerifier(secret string, tolerance time.Duration) *StripeVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	return &StripeVerifier{secret: secret, tolerance: tolerance, now: time.Now}
+}
+
+func (sV *StripeVerifier) VerifyRequest(r *http.Request, payload []byte) error {
+	header := r.Header.Get("Stripe-Signature")
+	if len(strings.TrimSpace(header)) == 0 {
+		return ErrSignatureCannotBeEmpty
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return ErrInvalidHeaderStructure
+		}
+
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			sig, err := hex.DecodeString(kv[1])
+			if err != nil {
+				// stripe may send signatures we can't read alongside valid ones
+				continue
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+
+	if len(signatures) == 0 {
+		return ErrSignatureCannotBeEmpty
+	}
+
+	if err := checkTimestamp(timestamp, sV.tolerance, sV.now()); err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(sV.secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	computedMAC := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		if hmac.Equal(sig, computedMAC) {
+			return nil
+		}
+	}
+
+	return ErrHashDoesNotMatch
+}
+
+// SlackVerifier verifies requests signed with a Slack app's signing secret.
+// See https://api.slack.com/authentication/verifying-requests-from-slack
+type SlackVerifier struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+func NewSlackVerifier(secret string, tolerance time.Duration) *SlackVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	return &SlackVerifier{secret: secret, tolerance: tolerance, now: time.Now}
+}
+
+func (sV *SlackVerifier) VerifyRequest(r *http.Request, payload []byte) error {
+	signature := r.Header.Get("X-Slack-Signature")
+	if len(strings.TrimSpace(signature)) == 0 {
+		return ErrSignatureCannotBeEmpty
+	}
+
+	version, sig, found := strings.Cut(signature, "=")
+	if !found || version != "v0" {
+		return ErrInvalidHeaderStructure
+	}
+
+	sentMAC, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrCannotDecodeHexEncodedMACHeader
+	}
+
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	if err = checkTimestamp(timestamp, sV.tolerance, sV.now()); err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(sV.secret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:", timestamp)))
+	mac.Write(payload)
+
+	if !hmac.Equal(sentMAC, mac.Sum(nil)) {
+		return ErrHashDoesNotMatch
+	}
+
+	return nil
+}
+
+// GitlabVerifier verifies the secret token GitLab sends in the
+// X-Gitlab-Token header.
+type GitlabVerifier struct {
+	token string
+}
+
+func NewGitlabVerifier(token string) *GitlabVerifier {
+	return &GitlabVerifier{token: token}
+}
+
+func (gV *GitlabVerifier) VerifyRequest(r *http.Request, payload []byte) error {
+	val := r.Header.Get("X-Gitlab-Token")
+	if len(strings.TrimSpace(val)) == 0 {
+		return ErrAuthHeaderCannotBeEmpty
+	}
+
+	if subtle.ConstantTimeCompare([]byte(val), []byte(gV.token)) != 1 {
+		return ErrAuthHeader
+	}
+
+	return nil
+}
+
+// TwilioVerifier verifies the X-Twilio-Signature header using the account's
+// auth token. Form encoded requests are signed over the full URL followed by
+// the sorted POST parameters, JSON requests are signed over the URL alone and
+// carry the hex encoded SHA256 of the body in the bodySHA256 query parameter.
+// The URL is rebuilt from the request, X-Forwarded-Proto is only honoured
+// when the request came through one of the trusted proxies.
+// See https://www.twilio.com/docs/usage/webhooks/webhooks-security
+type TwilioVerifier struct {
+	authToken      string
+	trustedProxies []*net.IPNet
+}
+
+func NewTwilioVerifier(authToken string) *TwilioVerifier {
+	return &TwilioVerifier{authToken: authToken}
+}
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-Proto header is
+// trusted.
+func (tV *TwilioVerifier) SetTrustedProxies(trustedProxies []*net.IPNet) {
+	tV.trustedProxies = trustedProxies
+}
+
+func (tV *TwilioVerifier) VerifyRequest(r *http.Request, payload []byte) error {
+	signature := r.Header.Get("X-Twilio-Signature")
+	if len(strings.TrimSpace(signature)) == 0 {
+		return ErrSignatureCannotBeEmpty
+	}
+
+	sentMAC, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrCannotDecodeBase64EncodedMACHeader
+	}
+
+	signed := requestURL(r, tV.trustedProxies)
+
+	bodyHash := r.URL.Query().Get("bodySHA256")
+	if len(bodyHash) > 0 {
+		sum := sha256.Sum256(payload)
+		if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(strings.ToLower(bodyHash))) != 1 {
+			return ErrBodyHashDoesNotMatch
+		}
+	} else if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		params, err := url.ParseQuery(string(payload))
+		if err != nil {
+			return ErrCannotReadRequestBody
+		}
+
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var sb strings.Builder
+		sb.WriteString(signed)
+		for _, k := range keys {
+			values := params[k]
+			sort.Strings(values)
+			for _, v := range values {
+				sb.WriteString(k)
+				sb.WriteString(v)
+			}
+		}
+		signed = sb.String()
+	}
+
+	mac := hmac.New(sha1.New, []byte(tV.authToken))
+	mac.Write([]byte(signed))
+
+	if !hmac.Equal(sentMAC, mac.Sum(nil)) {
+		return ErrHashDoesNotMatch
+	}
+
+	return nil
+}
+
+// requestURL reconstructs the URL the sender used to reach us, honouring
+// the X-Forwarded-Proto header set by load balancers when the request
+// came from one of trustedProxies.
+func requestURL(r *http.Request, trustedProxies []*net.IPNet) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	proto := r.Header.Get("X-Forwarded-Proto")
+	if len(proto) > 0 && fromTrustedProxy(r, trustedProxies) {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+
+	host := r.Host
+	if len(host) == 0 {
+		host = r.URL.Host
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, host, r.URL.RequestURI())
+}
+
+func fromTrustedProxy(r *http.Request, trustedProxies []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkTimestamp(timestamp string, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	diff := now.Sub(time.Unix(ts, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+
+	if diff > tolerance {
+		return ErrTimestampOutsideTolerance
+	}
+
+	return nil
+}
+
 type NoopVerifier struct{}
 
 func (nV *NoopVerifier) VerifyRequest(r *http.Request, payload []byte) error {
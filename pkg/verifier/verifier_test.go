@@ -1,10 +1,13 @@
 package verifier
 
 import (
+	"crypto/tls"
 	"encoding/hex"
+	"net"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -454,3 +457,295 @@ func Test_TwitterVerifier_VerifyRequest(t *testing.T) {
 		})
 	}
 }
+
+func Test_StripeVerifier_VerifyRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := map[string]struct {
+		secret        string
+		payload       []byte
+		now           time.Time
+		requestFn     func(t *testing.T) *http.Request
+		expectedError error
+	}{
+		"valid_signature": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now.Add(time.Minute),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("Stripe-Signature", "t=1700000000,v1=deadbeef,v1=964f9dbb14bada990255f63a2cade40b71f49c2892011d187bd156fc6c519893,v0=abc")
+				return req
+			},
+			expectedError: nil,
+		},
+		"invalid_signature": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now,
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("Stripe-Signature", "t=1700000000,v1=deadbeef")
+				return req
+			},
+			expectedError: ErrHashDoesNotMatch,
+		},
+		"timestamp_outside_tolerance": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now.Add(time.Hour),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("Stripe-Signature", "t=1700000000,v1=964f9dbb14bada990255f63a2cade40b71f49c2892011d187bd156fc6c519893")
+				return req
+			},
+			expectedError: ErrTimestampOutsideTolerance,
+		},
+		"missing_signature": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now,
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("Stripe-Signature", "t=1700000000")
+				return req
+			},
+			expectedError: ErrSignatureCannotBeEmpty,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			v := NewStripeVerifier(tc.secret, 0)
+			v.now = func() time.Time { return tc.now }
+			req := tc.requestFn(t)
+
+			// Assert.
+			err := v.VerifyRequest(req, tc.payload)
+
+			// Act.
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func Test_SlackVerifier_VerifyRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := map[string]struct {
+		secret        string
+		payload       []byte
+		now           time.Time
+		requestFn     func(t *testing.T) *http.Request
+		expectedError error
+	}{
+		"valid_signature": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now,
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("X-Slack-Request-Timestamp", "1700000000")
+				req.Header.Add("X-Slack-Signature", "v0=53f0bf16a01cf44d10532fb04dc14277889fbbe05b649d7dbff754ffa6343344")
+				return req
+			},
+			expectedError: nil,
+		},
+		"invalid_version": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now,
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("X-Slack-Request-Timestamp", "1700000000")
+				req.Header.Add("X-Slack-Signature", "v1=53f0bf16a01cf44d10532fb04dc14277889fbbe05b649d7dbff754ffa6343344")
+				return req
+			},
+			expectedError: ErrInvalidHeaderStructure,
+		},
+		"replayed_request": {
+			secret:  "Convoy",
+			payload: []byte(`Test Payload Body`),
+			now:     now.Add(10 * time.Minute),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("X-Slack-Request-Timestamp", "1700000000")
+				req.Header.Add("X-Slack-Signature", "v0=53f0bf16a01cf44d10532fb04dc14277889fbbe05b649d7dbff754ffa6343344")
+				return req
+			},
+			expectedError: ErrTimestampOutsideTolerance,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			v := NewSlackVerifier(tc.secret, 0)
+			v.now = func() time.Time { return tc.now }
+			req := tc.requestFn(t)
+
+			// Assert.
+			err := v.VerifyRequest(req, tc.payload)
+
+			// Act.
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func Test_GitlabVerifier_VerifyRequest(t *testing.T) {
+	tests := map[string]struct {
+		token         string
+		header        string
+		expectedError error
+	}{
+		"valid_token": {
+			token:         "Convoy",
+			header:        "Convoy",
+			expectedError: nil,
+		},
+		"invalid_token": {
+			token:         "Convoy",
+			header:        "Not-Convoy",
+			expectedError: ErrAuthHeader,
+		},
+		"empty_token": {
+			token:         "Convoy",
+			header:        "",
+			expectedError: ErrAuthHeaderCannotBeEmpty,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			v := NewGitlabVerifier(tc.token)
+			req, err := http.NewRequest("POST", "URL", strings.NewReader(``))
+			require.NoError(t, err)
+			req.Header.Add("X-Gitlab-Token", tc.header)
+
+			// Assert.
+			err = v.VerifyRequest(req, []byte(`Test Payload Body`))
+
+			// Act.
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func Test_TwilioVerifier_VerifyRequest(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		authToken      string
+		trustedProxies []*net.IPNet
+		payload        []byte
+		requestFn      func(t *testing.T) *http.Request
+		expectedError  error
+	}{
+		"valid_form_signature": {
+			authToken: "Convoy",
+			payload:   []byte(`From=%2B1234&Body=Hello`),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "https://convoy.example.com/ingest/abc", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.TLS = &tls.ConnectionState{}
+				req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Add("X-Twilio-Signature", "1UXgQ1VTozKQYQmqlj6GA1FJwpY=")
+				return req
+			},
+			expectedError: nil,
+		},
+		"valid_json_signature_behind_proxy": {
+			authToken:      "Convoy",
+			trustedProxies: []*net.IPNet{proxies},
+			payload:        []byte(`{"a":1}`),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "http://convoy.example.com/ingest/abc?bodySHA256=015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.RemoteAddr = "10.0.0.1:4321"
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("X-Forwarded-Proto", "https")
+				req.Header.Add("X-Twilio-Signature", "cpf41/EAI1xKIPWVn3pjAvxWmI0=")
+				return req
+			},
+			expectedError: nil,
+		},
+		"forwarded_proto_from_untrusted_client": {
+			authToken:      "Convoy",
+			trustedProxies: []*net.IPNet{proxies},
+			payload:        []byte(`{"a":1}`),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "http://convoy.example.com/ingest/abc?bodySHA256=015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.RemoteAddr = "198.51.100.1:4321"
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("X-Forwarded-Proto", "https")
+				req.Header.Add("X-Twilio-Signature", "cpf41/EAI1xKIPWVn3pjAvxWmI0=")
+				return req
+			},
+			expectedError: ErrHashDoesNotMatch,
+		},
+		"tampered_json_body": {
+			authToken: "Convoy",
+			payload:   []byte(`{"a":2}`),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "https://convoy.example.com/ingest/abc?bodySHA256=015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("X-Twilio-Signature", "cpf41/EAI1xKIPWVn3pjAvxWmI0=")
+				return req
+			},
+			expectedError: ErrBodyHashDoesNotMatch,
+		},
+		"invalid_form_signature": {
+			authToken: "Convoy",
+			payload:   []byte(`From=%2B1234&Body=Goodbye`),
+			requestFn: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest("POST", "https://convoy.example.com/ingest/abc", strings.NewReader(``))
+				require.NoError(t, err)
+
+				req.TLS = &tls.ConnectionState{}
+				req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Add("X-Twilio-Signature", "1UXgQ1VTozKQYQmqlj6GA1FJwpY=")
+				return req
+			},
+			expectedError: ErrHashDoesNotMatch,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			v := NewTwilioVerifier(tc.authToken)
+			v.SetTrustedProxies(tc.trustedProxies)
+			req := tc.requestFn(t)
+
+			// Assert.
+			err := v.VerifyRequest(req, tc.payload)
+
+			// Act.
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
@@ -18,6 +18,7 @@ type CreateBroadcastEventService struct {
 	EndpointRepo   datastore.EndpointRepository
 	EventRepo      datastore.EventRepository
 	PortalLinkRepo datastore.PortalLinkRepository
+	EventTypeRepo  datastore.EventTypeRepository
 	Queue          queue.Queuer
 	JobID          string
 
@@ -30,6 +31,11 @@ func (e *CreateBroadcastEventService) Run(ctx context.Context) error {
 		return &ServiceError{ErrMsg: "an error occurred while creating broadcast event - invalid project"}
 	}
 
+	err := ValidateEventPayload(ctx, e.EventTypeRepo, e.Project, e.BroadcastEvent.EventType, e.BroadcastEvent.Data)
+	if err != nil {
+		return err
+	}
+
 	e.BroadcastEvent.ProjectID = e.Project.UID
 	e.BroadcastEvent.AcknowledgedAt = time.Now()
 
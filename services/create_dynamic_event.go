@@ -17,7 +17,8 @@ import (
 )
 
 type CreateDynamicEventService struct {
-	Queue queue.Queuer
+	Queue         queue.Queuer
+	EventTypeRepo datastore.EventTypeRepository
 
 	DynamicEvent *models.DynamicEvent
 	Project      *datastore.Project
@@ -28,6 +29,11 @@ func (e *CreateDynamicEventService) Run(ctx context.Context) error {
 		return &ServiceError{ErrMsg: "an error occurred while creating dynamic event - invalid project"}
 	}
 
+	err := ValidateEventPayload(ctx, e.EventTypeRepo, e.Project, e.DynamicEvent.EventType, e.DynamicEvent.Data)
+	if err != nil {
+		return err
+	}
+
 	e.DynamicEvent.ProjectID = e.Project.UID
 	e.DynamicEvent.AcknowledgedAt = time.Now()
 
@@ -17,9 +17,10 @@ import (
 
 func provideCreateDynamicEventService(ctrl *gomock.Controller, de *models.DynamicEvent, project *datastore.Project) *CreateDynamicEventService {
 	return &CreateDynamicEventService{
-		Queue:        mocks.NewMockQueuer(ctrl),
-		DynamicEvent: de,
-		Project:      project,
+		Queue:         mocks.NewMockQueuer(ctrl),
+		EventTypeRepo: mocks.NewMockEventTypeRepository(ctrl),
+		DynamicEvent:  de,
+		Project:       project,
 	}
 }
 
@@ -58,6 +59,28 @@ func TestCreateDynamicEventService_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should_reject_data_not_matching_event_type_schema",
+			dbFn: func(es *CreateDynamicEventService) {
+				et, _ := es.EventTypeRepo.(*mocks.MockEventTypeRepository)
+				et.EXPECT().FindEventTypeByName(gomock.Any(), "12345", "user.created").Times(1).Return(&datastore.ProjectEventType{
+					Name:       "user.created",
+					JSONSchema: []byte(`{"type":"object","required":["email"]}`),
+				}, nil)
+			},
+			args: args{
+				ctx: ctx,
+				dynamicEvent: &models.DynamicEvent{
+					URL:       "https://google.com",
+					Data:      []byte(`{"name":"daniel"}`),
+					EventType: "user.created",
+				},
+				g: &datastore.Project{UID: "12345", Config: &datastore.ProjectConfig{ValidateEventTypes: true}},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  `event data does not match the user.created schema: $: missing properties: 'email'`,
+		},
 		{
 			name: "should_error_for_nil_project",
 			dbFn: func(es *CreateDynamicEventService) {},
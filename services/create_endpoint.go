@@ -63,6 +63,8 @@ func (a *CreateEndpointService) Run(ctx context.Context) (*datastore.Endpoint, e
 		AdvancedSignatures: *a.E.AdvancedSignatures,
 		AppID:              a.E.AppID,
 		RateLimitDuration:  a.E.RateLimitDuration,
+		EventTypeVersions:  a.E.EventTypeVersions,
+		Labels:             a.E.Labels,
 		Status:             datastore.ActiveEndpointStatus,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
@@ -24,6 +24,7 @@ type CreateFanoutEventService struct {
 	EndpointRepo   datastore.EndpointRepository
 	EventRepo      datastore.EventRepository
 	PortalLinkRepo datastore.PortalLinkRepository
+	EventTypeRepo  datastore.EventTypeRepository
 	Queue          queue.Queuer
 
 	NewMessage *models.FanoutEvent
@@ -34,7 +35,10 @@ var (
 	ErrInvalidEventDeliveryStatus  = errors.New("only successful events can be force resent")
 	ErrNoValidEndpointFound        = errors.New("no valid endpoint found")
 	ErrNoValidOwnerIDEndpointFound = errors.New("owner ID has no configured endpoints")
+	ErrNoValidLabelsEndpointFound  = errors.New("no endpoint has the labels")
 	ErrInvalidEndpointID           = errors.New("please provide an endpoint ID")
+	ErrRedactedEvent               = errors.New("the event's payload was redacted, it can't be replayed")
+	ErrRedactedEventDelivery       = errors.New("the event delivery's payload was redacted, it can't be sent again")
 )
 
 type newEvent struct {
@@ -54,10 +58,14 @@ func (e *CreateFanoutEventService) Run(ctx context.Context) (*datastore.Event, e
 		return nil, &ServiceError{ErrMsg: "an error occurred while creating event - invalid project"}
 	}
 
-	if err := util.Validate(e.NewMessage); err != nil {
+	if err := e.NewMessage.Validate(); err != nil {
 		return nil, &ServiceError{ErrMsg: err.Error()}
 	}
 
+	if err := ValidateEventPayload(ctx, e.EventTypeRepo, e.Project, e.NewMessage.EventType, e.NewMessage.Data); err != nil {
+		return nil, err
+	}
+
 	var isDuplicate bool
 	if !util.IsStringEmpty(e.NewMessage.IdempotencyKey) {
 		events, err := e.EventRepo.FindEventsByIdempotencyKey(ctx, e.Project.UID, e.NewMessage.IdempotencyKey)
@@ -68,9 +76,23 @@ func (e *CreateFanoutEventService) Run(ctx context.Context) (*datastore.Event, e
 		isDuplicate = len(events) > 0
 	}
 
-	endpoints, err := e.EndpointRepo.FindEndpointsByOwnerID(ctx, e.Project.UID, e.NewMessage.OwnerID)
-	if err != nil {
-		return nil, &ServiceError{ErrMsg: err.Error()}
+	var endpoints []datastore.Endpoint
+	var err error
+
+	if len(e.NewMessage.Labels) > 0 {
+		endpoints, err = e.EndpointRepo.FindEndpointsByLabels(ctx, e.Project.UID, e.NewMessage.Labels)
+		if err != nil {
+			return nil, &ServiceError{ErrMsg: err.Error()}
+		}
+
+		if len(endpoints) == 0 {
+			return nil, &ServiceError{ErrMsg: ErrNoValidLabelsEndpointFound.Error()}
+		}
+	} else {
+		endpoints, err = e.EndpointRepo.FindEndpointsByOwnerID(ctx, e.Project.UID, e.NewMessage.OwnerID)
+		if err != nil {
+			return nil, &ServiceError{ErrMsg: err.Error()}
+		}
 	}
 
 	if len(endpoints) == 0 {
@@ -133,6 +155,11 @@ func createEvent(ctx context.Context, endpoints []datastore.Endpoint, newMessage
 		CreateSubscription: !util.IsStringEmpty(newMessage.EndpointID),
 	}
 
+	err := e.OffloadPayload(ctx)
+	if err != nil {
+		return nil, &ServiceError{ErrMsg: err.Error()}
+	}
+
 	eventByte, err := msgpack.EncodeMsgPack(e)
 	if err != nil {
 		return nil, &ServiceError{ErrMsg: err.Error()}
@@ -141,6 +141,85 @@ func TestCreateFanoutEventService_Run(t *testing.T) {
 			},
 		},
 
+		{
+			name: "should_create_fanout_event_for_labels",
+			dbFn: func(es *CreateFanoutEventService) {
+				a, _ := es.EndpointRepo.(*mocks.MockEndpointRepository)
+				a.EXPECT().FindEndpointsByLabels(gomock.Any(), "abc", datastore.Labels{"region": "eu"}).
+					Times(1).Return([]datastore.Endpoint{
+					{UID: "123", ProjectID: "abc", Labels: datastore.Labels{"region": "eu"}},
+				}, nil)
+
+				eq, _ := es.Queue.(*mocks.MockQueuer)
+				eq.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).
+					Times(1).Return(nil)
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.FanoutEvent{
+					Labels:    map[string]string{"region": "eu"},
+					EventType: "payment.created",
+					Data:      bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Project{
+					UID:  "abc",
+					Name: "test_project",
+					Config: &datastore.ProjectConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       "linear",
+							Duration:   1000,
+							RetryCount: 10,
+						},
+						Signature:     &datastore.SignatureConfiguration{},
+						ReplayAttacks: false,
+					},
+				},
+			},
+			wantEvent: &datastore.Event{
+				EventType: datastore.EventType("payment.created"),
+				Raw:       `{"name":"convoy"}`,
+				Data:      bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				Endpoints: []string{"123"},
+				ProjectID: "abc",
+			},
+		},
+
+		{
+			name: "should_error_when_no_endpoint_has_the_labels",
+			dbFn: func(es *CreateFanoutEventService) {
+				a, _ := es.EndpointRepo.(*mocks.MockEndpointRepository)
+				a.EXPECT().FindEndpointsByLabels(gomock.Any(), "abc", gomock.Any()).
+					Times(1).Return([]datastore.Endpoint{}, nil)
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.FanoutEvent{
+					Labels:    map[string]string{"region": "eu"},
+					EventType: "payment.created",
+					Data:      bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Project{UID: "abc"},
+			},
+			wantErr:    true,
+			wantErrMsg: ErrNoValidLabelsEndpointFound.Error(),
+		},
+
+		{
+			name: "should_error_for_owner_id_and_labels",
+			args: args{
+				ctx: ctx,
+				newMessage: &models.FanoutEvent{
+					OwnerID:   "12345",
+					Labels:    map[string]string{"region": "eu"},
+					EventType: "payment.created",
+					Data:      bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Project{UID: "abc"},
+			},
+			wantErr:    true,
+			wantErrMsg: "only one of owner id and labels can be provided",
+		},
+
 		{
 			name: "should_error_for_empty_endpoints",
 			dbFn: func(es *CreateFanoutEventService) {
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/frain-dev/convoy/pkg/log"
@@ -17,10 +18,12 @@ import (
 )
 
 type CreateSourceService struct {
-	SourceRepo datastore.SourceRepository
-	Cache      cache.Cache
-	NewSource  *models.CreateSource
-	Project    *datastore.Project
+	SourceRepo          datastore.SourceRepository
+	EndpointRepo        datastore.EndpointRepository
+	FunctionVersionRepo datastore.FunctionVersionRepository
+	Cache               cache.Cache
+	NewSource           *models.CreateSource
+	Project             *datastore.Project
 }
 
 func (s *CreateSourceService) Run(ctx context.Context) (*datastore.Source, error) {
@@ -45,14 +48,18 @@ func (s *CreateSourceService) Run(ctx context.Context) (*datastore.Source, error
 		Verifier:        s.NewSource.Verifier.Transform(),
 		PubSub:          s.NewSource.PubSub.Transform(),
 		IdempotencyKeys: s.NewSource.IdempotencyKeys,
+		DedupWindow:     s.NewSource.DedupWindow,
 		CustomResponse: datastore.CustomResponse{
 			Body:        s.NewSource.CustomResponse.Body,
 			ContentType: s.NewSource.CustomResponse.ContentType,
 		},
-		BodyFunction:   s.NewSource.BodyFunction,
-		HeaderFunction: s.NewSource.HeaderFunction,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		BodyFunction:    s.NewSource.BodyFunction,
+		HeaderFunction:  s.NewSource.HeaderFunction,
+		IPAllowlist:     s.NewSource.IPAllowlist,
+		ClientCACert:    s.NewSource.ClientCACert,
+		IngestRateLimit: s.NewSource.IngestRateLimit.Transform(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	buf := uint64(len([]byte(source.CustomResponse.Body)))
@@ -60,10 +67,24 @@ func (s *CreateSourceService) Run(ctx context.Context) (*datastore.Source, error
 		return nil, &ServiceError{ErrMsg: "source custom response too large"}
 	}
 
+	source.EventTypeConfig = s.NewSource.EventTypeConfig.Transform()
+	if source.EventTypeConfig == nil && source.Provider.IsValid() {
+		source.EventTypeConfig = &datastore.EventTypeConfig{Type: datastore.ProviderEventTypeSource}
+	}
+
+	source.SyncForwarding = s.NewSource.SyncForwarding.Transform()
+	if err = validateSyncForwarding(ctx, s.EndpointRepo, s.Project, source); err != nil {
+		return nil, err
+	}
+
 	if source.Provider == datastore.TwitterSourceProvider {
 		source.ProviderConfig = &datastore.ProviderConfig{Twitter: &datastore.TwitterProviderConfig{}}
 	}
 
+	if err = recordSourceFunctions(ctx, s.FunctionVersionRepo, source, nil, nil); err != nil {
+		return nil, err
+	}
+
 	err = s.SourceRepo.CreateSource(ctx, source)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Error("failed to create source")
@@ -72,3 +93,26 @@ func (s *CreateSourceService) Run(ctx context.Context) (*datastore.Source, error
 
 	return source, nil
 }
+
+// validateSyncForwarding checks that sync forwarding is only set on http
+// sources and that its endpoint belongs to the project.
+func validateSyncForwarding(ctx context.Context, endpointRepo datastore.EndpointRepository, project *datastore.Project, source *datastore.Source) error {
+	if source.SyncForwarding == nil {
+		return nil
+	}
+
+	if source.Type != datastore.HTTPSource {
+		return &ServiceError{ErrMsg: "sync forwarding is only supported for http sources"}
+	}
+
+	_, err := endpointRepo.FindEndpointByID(ctx, source.SyncForwarding.EndpointID, project.UID)
+	if err != nil {
+		if errors.Is(err, datastore.ErrEndpointNotFound) {
+			return &ServiceError{ErrMsg: "sync forwarding endpoint not found", Err: err}
+		}
+
+		return &ServiceError{ErrMsg: "failed to find sync forwarding endpoint", Err: err}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"gopkg.in/guregu/null.v4"
 	"net/http"
 	"time"
@@ -12,21 +13,25 @@ import (
 
 	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/celfilter"
+	"github.com/frain-dev/convoy/internal/pkg/pubsub"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/util"
 )
 
 var (
 	ErrInvalidSubscriptionFilterFormat = errors.New("invalid subscription filter format")
+	ErrInvalidSubscriptionFilterExpr   = errors.New("invalid subscription filter expression")
 	ErrCreateSubscriptionError         = errors.New("failed to create subscription")
 )
 
 type CreateSubscriptionService struct {
-	SubRepo         datastore.SubscriptionRepository
-	EndpointRepo    datastore.EndpointRepository
-	SourceRepo      datastore.SourceRepository
-	Project         *datastore.Project
-	NewSubscription *models.CreateSubscription
+	SubRepo             datastore.SubscriptionRepository
+	EndpointRepo        datastore.EndpointRepository
+	SourceRepo          datastore.SourceRepository
+	FunctionVersionRepo datastore.FunctionVersionRepository
+	Project             *datastore.Project
+	NewSubscription     *models.CreateSubscription
 }
 
 func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscription, error) {
@@ -65,6 +70,16 @@ func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
 	}
 
+	fallbackConfig, err := s.NewSubscription.FallbackConfig.Transform()
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	err = validateFallbackConfig(ctx, s.EndpointRepo, s.Project.UID, endpoint.UID, fallbackConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	subscription := &datastore.Subscription{
 		UID:        ulid.Make().String(),
 		ProjectID:  s.Project.UID,
@@ -78,6 +93,10 @@ func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		AlertConfig:     s.NewSubscription.AlertConfig.Transform(),
 		FilterConfig:    s.NewSubscription.FilterConfig.Transform(),
 		RateLimitConfig: s.NewSubscription.RateLimitConfig.Transform(),
+		DebounceConfig:  s.NewSubscription.DebounceConfig.Transform(),
+		FallbackConfig:  fallbackConfig,
+
+		EventTypeVersions: s.NewSubscription.EventTypeVersions,
 
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -91,10 +110,13 @@ func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		subscription.FilterConfig.EventTypes = []string{"*"}
 	}
 
-	if len(subscription.FilterConfig.Filter.Body) == 0 && len(subscription.FilterConfig.Filter.Headers) == 0 {
+	if len(subscription.FilterConfig.Filter.Body) == 0 && len(subscription.FilterConfig.Filter.Headers) == 0 &&
+		len(subscription.FilterConfig.Filter.Metadata) == 0 && util.IsStringEmpty(subscription.FilterConfig.Filter.Expression) &&
+		len(subscription.FilterConfig.Filter.Labels) == 0 {
 		subscription.FilterConfig.Filter = datastore.FilterSchema{
-			Headers: datastore.M{},
-			Body:    datastore.M{},
+			Headers:  datastore.M{},
+			Body:     datastore.M{},
+			Metadata: datastore.M{},
 		}
 	} else {
 		// validate that the filter is a json string
@@ -103,6 +125,18 @@ func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 			log.FromContext(ctx).WithError(err).Error(ErrInvalidSubscriptionFilterFormat.Error())
 			return nil, &ServiceError{ErrMsg: ErrInvalidSubscriptionFilterFormat.Error()}
 		}
+
+		if err = validateFilterExpression(subscription.FilterConfig.Filter.Expression); err != nil {
+			log.FromContext(ctx).WithError(err).Error(ErrInvalidSubscriptionFilterExpr.Error())
+			return nil, &ServiceError{ErrMsg: fmt.Sprintf("%s: %v", ErrInvalidSubscriptionFilterExpr, err), Err: err}
+		}
+	}
+
+	if !util.IsStringEmpty(subscription.Function.String) {
+		_, err = recordFunctionVersion(ctx, s.FunctionVersionRepo, s.Project.UID, datastore.SubscriptionFunctionOwner, subscription.UID, subscription.Function.String)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = s.SubRepo.CreateSubscription(ctx, s.Project.UID, subscription)
@@ -141,3 +175,39 @@ func (s *CreateSubscriptionService) findEndpoint(ctx context.Context, appID, end
 
 	return endpoint, nil
 }
+
+// validateFallbackConfig checks the fallback endpoint is another endpoint in
+// the project, and that the fallback pub sub destination can be reached.
+func validateFallbackConfig(ctx context.Context, endpointRepo datastore.EndpointRepository, projectID, endpointID string, fc *datastore.FallbackConfiguration) error {
+	if fc == nil {
+		return nil
+	}
+
+	if fc.PubSub != nil {
+		if err := pubsub.Validate(fc.PubSub); err != nil {
+			return &ServiceError{ErrMsg: fmt.Sprintf("invalid fallback pub sub config: %v", err), Err: err}
+		}
+
+		return nil
+	}
+
+	if fc.EndpointID == endpointID {
+		return &ServiceError{ErrMsg: "the fallback endpoint can't be the subscription's endpoint"}
+	}
+
+	_, err := endpointRepo.FindEndpointByID(ctx, fc.EndpointID, projectID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to find fallback endpoint by id")
+		return &ServiceError{ErrMsg: "failed to find fallback endpoint by id", Err: err}
+	}
+
+	return nil
+}
+
+func validateFilterExpression(expression string) error {
+	if util.IsStringEmpty(expression) {
+		return nil
+	}
+
+	return celfilter.Validate(expression)
+}
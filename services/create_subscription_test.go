@@ -78,6 +78,96 @@ func TestCreateSubscriptionService_Run(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "should create subscription with debounce config",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.CreateSubscription{
+					Name:           "sub 1",
+					EndpointID:     "endpoint-id-1",
+					DebounceConfig: &models.DebounceConfiguration{KeyPath: "data.id", Window: 30},
+				},
+				project: &datastore.Project{UID: "12345", Type: datastore.OutgoingProject, Config: &datastore.ProjectConfig{MultipleEndpointSubscriptions: true}},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name:           "sub 1",
+				Type:           datastore.SubscriptionTypeAPI,
+				EndpointID:     "endpoint-id-1",
+				DebounceConfig: &datastore.DebounceConfiguration{KeyPath: "data.id", Window: 30, Mode: datastore.LastDebounceMode},
+			},
+			dbFn: func(ss *CreateSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				a, _ := ss.EndpointRepo.(*mocks.MockEndpointRepository)
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).
+					Times(1).Return(
+					&datastore.Endpoint{
+						UID:       "endpoint-id-1",
+						ProjectID: "12345",
+					},
+					nil,
+				)
+			},
+		},
+		{
+			name: "should create subscription with fallback endpoint",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.CreateSubscription{
+					Name:       "sub 1",
+					EndpointID: "endpoint-id-1",
+					FallbackConfig: &models.FallbackConfiguration{
+						EndpointID:  "endpoint-id-2",
+						RetryConfig: &models.RetryConfiguration{Type: datastore.LinearStrategyProvider, Duration: "1m", RetryCount: 5},
+					},
+				},
+				project: &datastore.Project{UID: "12345", Type: datastore.OutgoingProject, Config: &datastore.ProjectConfig{MultipleEndpointSubscriptions: true}},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name:       "sub 1",
+				Type:       datastore.SubscriptionTypeAPI,
+				EndpointID: "endpoint-id-1",
+				FallbackConfig: &datastore.FallbackConfiguration{
+					EndpointID:  "endpoint-id-2",
+					RetryConfig: &datastore.RetryConfiguration{Type: datastore.LinearStrategyProvider, Duration: 60, RetryCount: 5},
+				},
+			},
+			dbFn: func(ss *CreateSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				a, _ := ss.EndpointRepo.(*mocks.MockEndpointRepository)
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).
+					Times(1).Return(&datastore.Endpoint{UID: "endpoint-id-1", ProjectID: "12345"}, nil)
+
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-2", "12345").
+					Times(1).Return(&datastore.Endpoint{UID: "endpoint-id-2", ProjectID: "12345"}, nil)
+			},
+		},
+		{
+			name: "should error for fallback to the subscription's endpoint",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.CreateSubscription{
+					Name:           "sub 1",
+					EndpointID:     "endpoint-id-1",
+					FallbackConfig: &models.FallbackConfiguration{EndpointID: "endpoint-id-1"},
+				},
+				project: &datastore.Project{UID: "12345", Type: datastore.OutgoingProject, Config: &datastore.ProjectConfig{MultipleEndpointSubscriptions: true}},
+			},
+			dbFn: func(ss *CreateSubscriptionService) {
+				a, _ := ss.EndpointRepo.(*mocks.MockEndpointRepository)
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).
+					Times(1).Return(&datastore.Endpoint{UID: "endpoint-id-1", ProjectID: "12345"}, nil)
+			},
+			wantErr:    true,
+			wantErrMsg: "the fallback endpoint can't be the subscription's endpoint",
+		},
 		{
 			name: "should fail to count endpoint subscriptions for outgoing project if multi endpoints for subscriptions is false",
 			args: args{
@@ -442,6 +532,9 @@ func TestCreateSubscriptionService_Run(t *testing.T) {
 				require.Equal(t, subscription.FilterConfig.EventTypes,
 					tc.wantSubscription.FilterConfig.EventTypes)
 			}
+
+			require.Equal(t, tc.wantSubscription.DebounceConfig, subscription.DebounceConfig)
+			require.Equal(t, tc.wantSubscription.FallbackConfig, subscription.FallbackConfig)
 		})
 	}
 }
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+	"github.com/frain-dev/convoy/internal/pkg/memorystore"
+	"github.com/frain-dev/convoy/pkg/jsonschema"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+var (
+	ErrCreateEventType = errors.New("failed to create event type")
+	ErrUpdateEventType = errors.New("failed to update event type")
+	ErrDeleteEventType = errors.New("failed to delete event type")
+
+	ErrCreateEventTypeVersion = errors.New("failed to create event type version")
+
+	ErrEventTypeSchemaChanged = errors.New("an event type's schema can't be changed in place, create a new version of it instead")
+)
+
+// eventTypeSchemas caches the compiled schema of each event type version,
+// a version's schema never changes.
+var eventTypeSchemas = memorystore.NewTable()
+
+type EventTypeService struct {
+	EventTypeRepo datastore.EventTypeRepository
+	Project       *datastore.Project
+}
+
+func (e *EventTypeService) CreateEventType(ctx context.Context, newEventType *models.CreateEventType) (*datastore.ProjectEventType, error) {
+	eventType := &datastore.ProjectEventType{
+		UID:         ulid.Make().String(),
+		ProjectID:   e.Project.UID,
+		Name:        newEventType.Name,
+		Description: newEventType.Description,
+		JSONSchema:  newEventType.JSONSchema,
+		Examples:    newEventType.Examples,
+		Deprecated:  newEventType.Deprecated,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := checkEventTypeSchema(eventType); err != nil {
+		return nil, err
+	}
+
+	err := e.EventTypeRepo.CreateEventType(ctx, eventType)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDuplicateEventTypeName) {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		log.FromContext(ctx).WithError(err).Error(ErrCreateEventType.Error())
+		return nil, &ServiceError{ErrMsg: ErrCreateEventType.Error(), Err: err}
+	}
+
+	return eventType, nil
+}
+
+func (e *EventTypeService) UpdateEventType(ctx context.Context, eventType *datastore.ProjectEventType, update *models.UpdateEventType) (*datastore.ProjectEventType, error) {
+	if update.Name != nil && len(*update.Name) > 0 {
+		eventType.Name = *update.Name
+	}
+
+	if update.Description != nil {
+		eventType.Description = *update.Description
+	}
+
+	if update.JSONSchema != nil {
+		equal, err := jsonEqual(eventType.JSONSchema, update.JSONSchema)
+		if err != nil {
+			return nil, &ServiceError{ErrMsg: fmt.Sprintf("invalid schema: %v", err), Err: err}
+		}
+
+		if !equal {
+			return nil, &ServiceError{ErrMsg: ErrEventTypeSchemaChanged.Error(), Err: ErrEventTypeSchemaChanged}
+		}
+	}
+
+	if update.Examples != nil {
+		eventType.Examples = update.Examples
+	}
+
+	if update.Deprecated != nil {
+		eventType.Deprecated = *update.Deprecated
+	}
+
+	if err := checkEventTypeSchema(eventType); err != nil {
+		return nil, err
+	}
+
+	err := e.EventTypeRepo.UpdateEventType(ctx, e.Project.UID, eventType)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDuplicateEventTypeName) {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		log.FromContext(ctx).WithError(err).Error(ErrUpdateEventType.Error())
+		return nil, &ServiceError{ErrMsg: ErrUpdateEventType.Error(), Err: err}
+	}
+
+	return eventType, nil
+}
+
+func (e *EventTypeService) DeleteEventType(ctx context.Context, eventType *datastore.ProjectEventType) error {
+	err := e.EventTypeRepo.DeleteEventType(ctx, e.Project.UID, eventType.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error(ErrDeleteEventType.Error())
+		return &ServiceError{ErrMsg: ErrDeleteEventType.Error(), Err: err}
+	}
+
+	eventTypeSchemas.Delete(eventTypeSchemaKey(eventType))
+	return nil
+}
+
+// CreateEventTypeVersion makes the new schema the event type's latest
+// version. The down converter must turn every new example into a payload the
+// current version's schema accepts, so endpoints pinned to older versions
+// keep receiving payloads they understand.
+func (e *EventTypeService) CreateEventTypeVersion(ctx context.Context, eventType *datastore.ProjectEventType, newVersion *models.CreateEventTypeVersion) (*datastore.EventTypeVersion, error) {
+	next := &datastore.ProjectEventType{
+		UID:        eventType.UID,
+		ProjectID:  eventType.ProjectID,
+		Name:       eventType.Name,
+		JSONSchema: newVersion.JSONSchema,
+		Examples:   newVersion.Examples,
+		Version:    eventType.Version + 1,
+	}
+
+	if err := checkEventTypeSchema(next); err != nil {
+		return nil, err
+	}
+
+	var current *jsonschema.Schema
+	if !isJSONNull(eventType.JSONSchema) {
+		schema, err := jsonschema.Compile(eventType.JSONSchema)
+		if err != nil {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+		current = schema
+	}
+
+	converter := []datastore.EventTypeVersion{{Version: next.Version, DownConverter: newVersion.DownConverter}}
+	for i, example := range next.Examples {
+		converted, err := functions.DownConvert(functions.NewTransformer(ctx, e.Project.UID), converter, next.Version, eventType.Version, example)
+		if err != nil {
+			return nil, &ServiceError{ErrMsg: fmt.Sprintf("example %d could not be down converted: %v", i, err), Err: err}
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if err = current.Validate(converted); err != nil {
+			return nil, &ServiceError{ErrMsg: fmt.Sprintf("example %d does not match version %d's schema once down converted: %v", i, eventType.Version, err), Err: err}
+		}
+	}
+
+	version := &datastore.EventTypeVersion{
+		ProjectID:     e.Project.UID,
+		EventTypeID:   eventType.UID,
+		Version:       next.Version,
+		JSONSchema:    next.JSONSchema,
+		DownConverter: newVersion.DownConverter,
+		CreatedAt:     time.Now(),
+	}
+
+	err := e.EventTypeRepo.CreateEventTypeVersion(ctx, next, version)
+	if err != nil {
+		if errors.Is(err, datastore.ErrEventTypeVersionConflict) {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		log.FromContext(ctx).WithError(err).Error(ErrCreateEventTypeVersion.Error())
+		return nil, &ServiceError{ErrMsg: ErrCreateEventTypeVersion.Error(), Err: err}
+	}
+
+	eventType.JSONSchema = next.JSONSchema
+	eventType.Examples = next.Examples
+	eventType.Version = next.Version
+
+	return version, nil
+}
+
+// checkEventTypeSchema makes sure the event type's schema is valid and its
+// examples match it.
+func checkEventTypeSchema(eventType *datastore.ProjectEventType) error {
+	if isJSONNull(eventType.JSONSchema) {
+		eventType.JSONSchema = nil
+		return nil
+	}
+
+	schema, err := jsonschema.Compile(eventType.JSONSchema)
+	if err != nil {
+		return &ServiceError{ErrMsg: err.Error(), Err: err}
+	}
+
+	for i, example := range eventType.Examples {
+		if err := schema.Validate(example); err != nil {
+			return &ServiceError{ErrMsg: fmt.Sprintf("example %d does not match the schema: %v", i, err), Err: err}
+		}
+	}
+
+	return nil
+}
+
+// ValidateEventPayload rejects data that doesn't match the schema of its
+// event type when the project validates event types. Event types that
+// aren't in the catalog, or don't have a schema, are accepted.
+func ValidateEventPayload(ctx context.Context, eventTypeRepo datastore.EventTypeRepository, project *datastore.Project, eventType string, data json.RawMessage) error {
+	if project.Config == nil || !project.Config.ValidateEventTypes {
+		return nil
+	}
+
+	et, err := eventTypeRepo.FindEventTypeByName(ctx, project.UID, eventType)
+	if err != nil {
+		if errors.Is(err, datastore.ErrEventTypeNotFound) {
+			return nil
+		}
+
+		log.FromContext(ctx).WithError(err).Error("failed to find event type")
+		return &ServiceError{ErrMsg: "failed to find event type", Err: err}
+	}
+
+	if isJSONNull(et.JSONSchema) {
+		return nil
+	}
+
+	schema, err := compileEventTypeSchema(et)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("event type %s has an invalid schema", et.Name)
+		return nil
+	}
+
+	if err = schema.Validate(data); err != nil {
+		return &ServiceError{ErrMsg: fmt.Sprintf("event data does not match the %s schema: %v", et.Name, err), Err: err}
+	}
+
+	return nil
+}
+
+// compileEventTypeSchema returns the compiled schema of the event type's
+// latest version.
+func compileEventTypeSchema(eventType *datastore.ProjectEventType) (*jsonschema.Schema, error) {
+	key := eventTypeSchemaKey(eventType)
+	if row := eventTypeSchemas.Get(key); row != nil {
+		if schema, ok := row.Value().(*jsonschema.Schema); ok {
+			return schema, nil
+		}
+	}
+
+	schema, err := jsonschema.Compile(eventType.JSONSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	eventTypeSchemas.Upsert(key, schema)
+	return schema, nil
+}
+
+func eventTypeSchemaKey(eventType *datastore.ProjectEventType) memorystore.Key {
+	return memorystore.NewKey(eventType.ProjectID, fmt.Sprintf("%s:%d", eventType.UID, eventType.Version))
+}
+
+func jsonEqual(a, b json.RawMessage) (bool, error) {
+	if isJSONNull(a) || isJSONNull(b) {
+		return isJSONNull(a) == isJSONNull(b), nil
+	}
+
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(x, y), nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+)
+
+func TestEventTypeService_CreateEventType(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventType  *models.CreateEventType
+		dbFn       func(repo *mocks.MockEventTypeRepository)
+		wantErrMsg string
+	}{
+		{
+			name: "should_create_event_type",
+			eventType: &models.CreateEventType{
+				Name:       "invoice.paid",
+				JSONSchema: json.RawMessage(`{"type":"object","required":["id"]}`),
+				Examples:   []json.RawMessage{json.RawMessage(`{"id":"inv_1"}`)},
+			},
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().CreateEventType(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name:      "should_create_event_type_without_schema",
+			eventType: &models.CreateEventType{Name: "invoice.paid"},
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().CreateEventType(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_error_for_invalid_schema",
+			eventType: &models.CreateEventType{
+				Name:       "invoice.paid",
+				JSONSchema: json.RawMessage(`{"type":"money"}`),
+			},
+			wantErrMsg: `invalid schema: $/type: value must be one of "array", "boolean", "integer", "null", "number", "object", "string"; $/type: expected array, but got string`,
+		},
+		{
+			name: "should_error_for_example_not_matching_schema",
+			eventType: &models.CreateEventType{
+				Name:       "invoice.paid",
+				JSONSchema: json.RawMessage(`{"type":"object","required":["id"]}`),
+				Examples:   []json.RawMessage{json.RawMessage(`{}`)},
+			},
+			wantErrMsg: `example 0 does not match the schema: $: missing properties: 'id'`,
+		},
+		{
+			name:      "should_error_for_duplicate_name",
+			eventType: &models.CreateEventType{Name: "invoice.paid"},
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().CreateEventType(gomock.Any(), gomock.Any()).Times(1).Return(datastore.ErrDuplicateEventTypeName)
+			},
+			wantErrMsg: datastore.ErrDuplicateEventTypeName.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockEventTypeRepository(ctrl)
+			if tt.dbFn != nil {
+				tt.dbFn(repo)
+			}
+
+			es := &EventTypeService{EventTypeRepo: repo, Project: &datastore.Project{UID: "project-1"}}
+			eventType, err := es.CreateEventType(context.Background(), tt.eventType)
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrMsg, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "project-1", eventType.ProjectID)
+			require.Equal(t, tt.eventType.Name, eventType.Name)
+		})
+	}
+}
+
+func TestEventTypeService_CreateEventTypeVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    *models.CreateEventTypeVersion
+		dbFn       func(repo *mocks.MockEventTypeRepository)
+		wantErrMsg string
+	}{
+		{
+			name: "should_create_event_type_version",
+			version: &models.CreateEventTypeVersion{
+				JSONSchema:    json.RawMessage(`{"type":"object","required":["amount"],"properties":{"amount":{"type":"object"}}}`),
+				DownConverter: `function transform(payload) { payload.amount = payload.amount.value; return payload }`,
+				Examples:      []json.RawMessage{json.RawMessage(`{"amount":{"value":100}}`)},
+			},
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().CreateEventTypeVersion(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_error_for_example_not_converting_to_current_schema",
+			version: &models.CreateEventTypeVersion{
+				JSONSchema:    json.RawMessage(`{"type":"object"}`),
+				DownConverter: `function transform(payload) { return payload }`,
+				Examples:      []json.RawMessage{json.RawMessage(`{"amount":{"value":100}}`)},
+			},
+			wantErrMsg: `example 0 does not match version 1's schema once down converted: $/amount: expected number, but got object`,
+		},
+		{
+			name: "should_error_for_failing_down_converter",
+			version: &models.CreateEventTypeVersion{
+				DownConverter: `function transform(payload) { throw new Error("boom") }`,
+				Examples:      []json.RawMessage{json.RawMessage(`{}`)},
+			},
+			wantErrMsg: `example 0 could not be down converted: converting version 2 to 1: Error: boom at transform (transform.js:1:37(3))`,
+		},
+		{
+			name: "should_error_for_version_conflict",
+			version: &models.CreateEventTypeVersion{
+				DownConverter: `function transform(payload) { return payload }`,
+			},
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().CreateEventTypeVersion(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(datastore.ErrEventTypeVersionConflict)
+			},
+			wantErrMsg: datastore.ErrEventTypeVersionConflict.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockEventTypeRepository(ctrl)
+			if tt.dbFn != nil {
+				tt.dbFn(repo)
+			}
+
+			eventType := &datastore.ProjectEventType{
+				UID:        "event-type-1",
+				ProjectID:  "project-1",
+				Name:       "invoice.paid",
+				JSONSchema: json.RawMessage(`{"type":"object","properties":{"amount":{"type":"number"}}}`),
+				Version:    1,
+			}
+
+			es := &EventTypeService{EventTypeRepo: repo, Project: &datastore.Project{UID: "project-1"}}
+			version, err := es.CreateEventTypeVersion(context.Background(), eventType, tt.version)
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrMsg, err.Error())
+				require.Equal(t, 1, eventType.Version)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, 2, version.Version)
+			require.Equal(t, 2, eventType.Version)
+			require.JSONEq(t, string(tt.version.JSONSchema), string(eventType.JSONSchema))
+		})
+	}
+}
+
+func TestEventTypeService_UpdateEventType(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","required":["id"]}`)
+
+	tests := []struct {
+		name       string
+		update     *models.UpdateEventType
+		dbFn       func(repo *mocks.MockEventTypeRepository)
+		wantErrMsg string
+	}{
+		{
+			name:   "should_update_event_type_with_the_same_schema",
+			update: &models.UpdateEventType{JSONSchema: json.RawMessage(`{"required": ["id"], "type": "object"}`)},
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().UpdateEventType(gomock.Any(), "project-1", gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name:       "should_error_for_changed_schema",
+			update:     &models.UpdateEventType{JSONSchema: json.RawMessage(`{"type":"object"}`)},
+			wantErrMsg: ErrEventTypeSchemaChanged.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockEventTypeRepository(ctrl)
+			if tt.dbFn != nil {
+				tt.dbFn(repo)
+			}
+
+			service := &EventTypeService{EventTypeRepo: repo, Project: &datastore.Project{UID: "project-1"}}
+			eventType := &datastore.ProjectEventType{UID: "event-type-1", ProjectID: "project-1", Name: "invoice.paid", JSONSchema: schema}
+
+			_, err := service.UpdateEventType(context.Background(), eventType, tt.update)
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrMsg, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateEventPayload(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"amount":{"type":"number"}}}`)
+
+	tests := []struct {
+		name       string
+		config     *datastore.ProjectConfig
+		data       string
+		dbFn       func(repo *mocks.MockEventTypeRepository)
+		wantErrMsg string
+	}{
+		{
+			name:   "should_skip_when_project_does_not_validate",
+			config: &datastore.ProjectConfig{},
+			data:   `{"amount":"ten"}`,
+		},
+		{
+			name:   "should_accept_event_type_not_in_catalog",
+			config: &datastore.ProjectConfig{ValidateEventTypes: true},
+			data:   `{"amount":"ten"}`,
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().FindEventTypeByName(gomock.Any(), "project-1", "invoice.paid").Times(1).Return(nil, datastore.ErrEventTypeNotFound)
+			},
+		},
+		{
+			name:   "should_accept_matching_data",
+			config: &datastore.ProjectConfig{ValidateEventTypes: true},
+			data:   `{"amount":10}`,
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().FindEventTypeByName(gomock.Any(), "project-1", "invoice.paid").Times(1).Return(&datastore.ProjectEventType{UID: "event-type-1", ProjectID: "project-1", Name: "invoice.paid", JSONSchema: schema}, nil)
+			},
+		},
+		{
+			name:   "should_reject_data_not_matching_schema",
+			config: &datastore.ProjectConfig{ValidateEventTypes: true},
+			data:   `{"amount":"ten"}`,
+			dbFn: func(repo *mocks.MockEventTypeRepository) {
+				repo.EXPECT().FindEventTypeByName(gomock.Any(), "project-1", "invoice.paid").Times(1).Return(&datastore.ProjectEventType{UID: "event-type-1", ProjectID: "project-1", Name: "invoice.paid", JSONSchema: schema}, nil)
+			},
+			wantErrMsg: "event data does not match the invoice.paid schema: $/amount: expected number, but got string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockEventTypeRepository(ctrl)
+			if tt.dbFn != nil {
+				tt.dbFn(repo)
+			}
+
+			project := &datastore.Project{UID: "project-1", Config: tt.config}
+			err := ValidateEventPayload(context.Background(), repo, project, "invoice.paid", json.RawMessage(tt.data))
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrMsg, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -45,6 +45,10 @@ func (e *ForceResendEventDeliveriesService) Run(ctx context.Context) (int, int,
 }
 
 func (e *ForceResendEventDeliveriesService) forceResendEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery, project *datastore.Project) error {
+	if eventDelivery.IsPubSubFallback() {
+		return requeueEventDelivery(ctx, eventDelivery, project, e.EventDeliveryRepo, e.Queue)
+	}
+
 	endpoint, err := e.EndpointRepo.FindEndpointByID(ctx, eventDelivery.EndpointID, project.UID)
 	if err != nil {
 		return datastore.ErrEndpointNotFound
@@ -62,6 +66,11 @@ func validateEventDeliveryStatus(deliveries []datastore.EventDelivery) error {
 		if delivery.Status != datastore.SuccessEventStatus {
 			return ErrInvalidEventDeliveryStatus
 		}
+
+		// finished deliveries are saved with their payload redacted
+		if delivery.Metadata != nil && delivery.Metadata.Redacted {
+			return ErrRedactedEventDelivery
+		}
 	}
 
 	return nil
@@ -115,6 +115,32 @@ func TestForceResendEventDeliveriesService_Run(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: ErrInvalidEventDeliveryStatus.Error(),
 		},
+		{
+			name: "should_fail_validation_for_redacted_event_delivery",
+			args: args{
+				ctx: ctx,
+				ids: []string{"ref"},
+				g:   &datastore.Project{UID: "123"},
+			},
+			dbFn: func(es *ForceResendEventDeliveriesService) {
+				ed, _ := es.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveriesByIDs(
+					gomock.Any(), gomock.Any(), []string{"ref"}).
+					Times(1).
+					Return(
+						[]datastore.EventDelivery{
+							{
+								UID:      "ref",
+								Status:   datastore.SuccessEventStatus,
+								Metadata: &datastore.Metadata{Redacted: true},
+							},
+						},
+						nil,
+					)
+			},
+			wantErr:    true,
+			wantErrMsg: ErrRedactedEventDelivery.Error(),
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/transform"
+)
+
+var (
+	ErrCreateFunctionLibrary = errors.New("failed to create function library")
+	ErrUpdateFunctionLibrary = errors.New("failed to update function library")
+	ErrDeleteFunctionLibrary = errors.New("failed to delete function library")
+	ErrRecordFunctionVersion = errors.New("failed to record function version")
+	ErrFindFunctionVersion   = errors.New("failed to find function version")
+)
+
+type FunctionLibraryService struct {
+	LibraryRepo datastore.FunctionLibraryRepository
+	VersionRepo datastore.FunctionVersionRepository
+	Project     *datastore.Project
+}
+
+func (f *FunctionLibraryService) CreateFunctionLibrary(ctx context.Context, newLibrary *models.CreateFunctionLibrary) (*datastore.FunctionLibrary, error) {
+	if err := transform.ValidateLibrary(newLibrary.Function); err != nil {
+		return nil, &ServiceError{ErrMsg: fmt.Sprintf("invalid function: %v", err), Err: err}
+	}
+
+	library := &datastore.FunctionLibrary{
+		UID:       ulid.Make().String(),
+		ProjectID: f.Project.UID,
+		Name:      newLibrary.Name,
+		Function:  newLibrary.Function,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	// the library's first version is recorded with it
+	err := f.LibraryRepo.CreateFunctionLibrary(ctx, library)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDuplicateFunctionLibraryName) {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		log.FromContext(ctx).WithError(err).Error(ErrCreateFunctionLibrary.Error())
+		return nil, &ServiceError{ErrMsg: ErrCreateFunctionLibrary.Error(), Err: err}
+	}
+
+	invalidateLibraries(f.Project.UID)
+	return library, nil
+}
+
+func (f *FunctionLibraryService) UpdateFunctionLibrary(ctx context.Context, library *datastore.FunctionLibrary, update *models.UpdateFunctionLibrary) (*datastore.FunctionLibrary, error) {
+	if update.Name != nil {
+		library.Name = *update.Name
+	}
+
+	if update.Function != nil && *update.Function != library.Function {
+		if err := transform.ValidateLibrary(*update.Function); err != nil {
+			return nil, &ServiceError{ErrMsg: fmt.Sprintf("invalid function: %v", err), Err: err}
+		}
+
+		library.Function = *update.Function
+	}
+
+	// a new version is recorded with the change to the function
+	err := f.LibraryRepo.UpdateFunctionLibrary(ctx, f.Project.UID, library)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDuplicateFunctionLibraryName) {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		log.FromContext(ctx).WithError(err).Error(ErrUpdateFunctionLibrary.Error())
+		return nil, &ServiceError{ErrMsg: ErrUpdateFunctionLibrary.Error(), Err: err}
+	}
+
+	invalidateLibraries(f.Project.UID)
+	return library, nil
+}
+
+// RollbackFunctionLibrary saves an earlier version of the library as its
+// latest version.
+func (f *FunctionLibraryService) RollbackFunctionLibrary(ctx context.Context, library *datastore.FunctionLibrary, version int) (*datastore.FunctionLibrary, error) {
+	v, err := findFunctionVersion(ctx, f.VersionRepo, f.Project.UID, datastore.FunctionLibraryOwner, library.UID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.UpdateFunctionLibrary(ctx, library, &models.UpdateFunctionLibrary{Function: &v.Function})
+}
+
+func (f *FunctionLibraryService) DeleteFunctionLibrary(ctx context.Context, library *datastore.FunctionLibrary) error {
+	err := f.LibraryRepo.DeleteFunctionLibrary(ctx, f.Project.UID, library.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error(ErrDeleteFunctionLibrary.Error())
+		return &ServiceError{ErrMsg: ErrDeleteFunctionLibrary.Error(), Err: err}
+	}
+
+	invalidateLibraries(f.Project.UID)
+	return nil
+}
+
+// invalidateLibraries drops the project's libraries cached by this
+// instance, other instances pick up the change when their cache expires.
+func invalidateLibraries(projectID string) {
+	if l := functions.Get(); l != nil {
+		l.Invalidate(projectID)
+	}
+}
+
+// recordFunctionVersion saves function as the next version of its owner's
+// function.
+func recordFunctionVersion(ctx context.Context, versionRepo datastore.FunctionVersionRepository, projectID string, ownerType datastore.FunctionOwnerType, ownerID string, function string) (*datastore.FunctionVersion, error) {
+	v := &datastore.FunctionVersion{
+		UID:       ulid.Make().String(),
+		ProjectID: projectID,
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		Function:  function,
+	}
+
+	err := versionRepo.CreateFunctionVersion(ctx, v)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error(ErrRecordFunctionVersion.Error())
+		return nil, &ServiceError{ErrMsg: ErrRecordFunctionVersion.Error(), Err: err}
+	}
+
+	return v, nil
+}
+
+func findFunctionVersion(ctx context.Context, versionRepo datastore.FunctionVersionRepository, projectID string, ownerType datastore.FunctionOwnerType, ownerID string, version int) (*datastore.FunctionVersion, error) {
+	v, err := versionRepo.FindFunctionVersion(ctx, projectID, ownerType, ownerID, version)
+	if err != nil {
+		if errors.Is(err, datastore.ErrFunctionVersionNotFound) {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		log.FromContext(ctx).WithError(err).Error(ErrFindFunctionVersion.Error())
+		return nil, &ServiceError{ErrMsg: ErrFindFunctionVersion.Error(), Err: err}
+	}
+
+	return v, nil
+}
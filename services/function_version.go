@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+var ErrFunctionPinned = errors.New("the subscription's function is pinned to a version, unpin it to change the function")
+
+// FunctionVersionService manages the version history of subscription and
+// source functions.
+type FunctionVersionService struct {
+	VersionRepo datastore.FunctionVersionRepository
+	LibraryRepo datastore.FunctionLibraryRepository
+	SubRepo     datastore.SubscriptionRepository
+	SourceRepo  datastore.SourceRepository
+	Project     *datastore.Project
+}
+
+func (f *FunctionVersionService) LoadFunctionVersions(ctx context.Context, ownerType datastore.FunctionOwnerType, ownerID string) ([]datastore.FunctionVersion, error) {
+	versions, err := f.VersionRepo.LoadFunctionVersions(ctx, f.Project.UID, ownerType, ownerID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to load function versions")
+		return nil, &ServiceError{ErrMsg: "failed to load function versions", Err: err}
+	}
+
+	return versions, nil
+}
+
+func (f *FunctionVersionService) FindFunctionVersion(ctx context.Context, ownerType datastore.FunctionOwnerType, ownerID string, version int) (*datastore.FunctionVersion, error) {
+	return findFunctionVersion(ctx, f.VersionRepo, f.Project.UID, ownerType, ownerID, version)
+}
+
+// RollbackSubscriptionFunction saves an earlier version of the
+// subscription's function as its latest version.
+func (f *FunctionVersionService) RollbackSubscriptionFunction(ctx context.Context, subscription *datastore.Subscription, version int) (*datastore.Subscription, error) {
+	if subscription.FunctionVersion.Valid {
+		return nil, &ServiceError{ErrMsg: ErrFunctionPinned.Error()}
+	}
+
+	v, err := f.FindFunctionVersion(ctx, datastore.SubscriptionFunctionOwner, subscription.UID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = recordFunctionVersion(ctx, f.VersionRepo, f.Project.UID, datastore.SubscriptionFunctionOwner, subscription.UID, v.Function)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Function = null.StringFrom(v.Function)
+	return f.updateSubscription(ctx, subscription)
+}
+
+// PinSubscriptionFunction runs a version of the subscription's function,
+// with the latest versions of the project's libraries, until it's unpinned.
+func (f *FunctionVersionService) PinSubscriptionFunction(ctx context.Context, subscription *datastore.Subscription, version int) (*datastore.Subscription, error) {
+	v, err := f.FindFunctionVersion(ctx, datastore.SubscriptionFunctionOwner, subscription.UID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	libraries, err := f.LibraryRepo.LoadFunctionLibraries(ctx, f.Project.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to load function libraries")
+		return nil, &ServiceError{ErrMsg: "failed to load function libraries", Err: err}
+	}
+
+	pins := make(datastore.FunctionLibraryPins, len(libraries))
+	for _, library := range libraries {
+		pins[library.Name] = datastore.FunctionLibraryPin{LibraryID: library.UID, Version: library.Version}
+	}
+
+	subscription.Function = null.StringFrom(v.Function)
+	subscription.FunctionVersion = null.IntFrom(int64(v.Version))
+	subscription.FunctionLibraries = pins
+	return f.updateSubscription(ctx, subscription)
+}
+
+// UnpinSubscriptionFunction lets the subscription's function be changed
+// again, the pinned version keeps running until it is.
+func (f *FunctionVersionService) UnpinSubscriptionFunction(ctx context.Context, subscription *datastore.Subscription) (*datastore.Subscription, error) {
+	subscription.FunctionVersion = null.Int{}
+	subscription.FunctionLibraries = nil
+	return f.updateSubscription(ctx, subscription)
+}
+
+func (f *FunctionVersionService) updateSubscription(ctx context.Context, subscription *datastore.Subscription) (*datastore.Subscription, error) {
+	err := f.SubRepo.UpdateSubscription(ctx, f.Project.UID, subscription)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error(ErrUpdateSubscriptionError.Error())
+		return nil, &ServiceError{ErrMsg: ErrUpdateSubscriptionError.Error(), Err: err}
+	}
+
+	return subscription, nil
+}
+
+// RollbackSourceFunction saves an earlier version of the source's body or
+// header function as its latest version.
+func (f *FunctionVersionService) RollbackSourceFunction(ctx context.Context, source *datastore.Source, ownerType datastore.FunctionOwnerType, version int) (*datastore.Source, error) {
+	if ownerType != datastore.SourceBodyFunctionOwner && ownerType != datastore.SourceHeaderFunctionOwner {
+		return nil, &ServiceError{ErrMsg: fmt.Sprintf("%s is not a source function", ownerType)}
+	}
+
+	v, err := f.FindFunctionVersion(ctx, ownerType, source.UID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = recordFunctionVersion(ctx, f.VersionRepo, f.Project.UID, ownerType, source.UID, v.Function)
+	if err != nil {
+		return nil, err
+	}
+
+	function := v.Function
+	if ownerType == datastore.SourceBodyFunctionOwner {
+		source.BodyFunction = &function
+	} else {
+		source.HeaderFunction = &function
+	}
+
+	err = f.SourceRepo.UpdateSource(ctx, f.Project.UID, source)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to update source")
+		return nil, &ServiceError{ErrMsg: "an error occurred while updating source", Err: err}
+	}
+
+	return source, nil
+}
+
+// recordSourceFunctions saves the source's functions that changed as new
+// versions.
+func recordSourceFunctions(ctx context.Context, versionRepo datastore.FunctionVersionRepository, source *datastore.Source, oldBody, oldHeader *string) error {
+	changed := func(newFn, oldFn *string) bool {
+		return newFn != nil && len(*newFn) > 0 && (oldFn == nil || *oldFn != *newFn)
+	}
+
+	if changed(source.BodyFunction, oldBody) {
+		_, err := recordFunctionVersion(ctx, versionRepo, source.ProjectID, datastore.SourceBodyFunctionOwner, source.UID, *source.BodyFunction)
+		if err != nil {
+			return err
+		}
+	}
+
+	if changed(source.HeaderFunction, oldHeader) {
+		_, err := recordFunctionVersion(ctx, versionRepo, source.ProjectID, datastore.SourceHeaderFunctionOwner, source.UID, *source.HeaderFunction)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
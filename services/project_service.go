@@ -13,6 +13,7 @@ import (
 	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/util"
 )
@@ -163,6 +164,8 @@ func (ps *ProjectService) UpdateProject(ctx context.Context, project *datastore.
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
 	}
 
+	redact.Invalidate(project.UID)
+
 	return project, nil
 }
 
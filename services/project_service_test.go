@@ -210,7 +210,7 @@ func TestProjectService_CreateProject(t *testing.T) {
 				OrganisationID: "1234",
 				Config: &datastore.ProjectConfig{
 					SearchPolicy:  "720h",
-					MaxIngestSize: 51200,
+					MaxIngestSize: config.MaxResponseSize,
 					Signature: &datastore.SignatureConfiguration{
 						Header: "X-Convoy-Signature",
 						Versions: []datastore.SignatureVersion{
@@ -263,7 +263,7 @@ func TestProjectService_CreateProject(t *testing.T) {
 				OrganisationID: "1234",
 				Config: &datastore.ProjectConfig{
 					SearchPolicy:  "720h",
-					MaxIngestSize: 51200,
+					MaxIngestSize: config.MaxResponseSize,
 					Signature: &datastore.SignatureConfiguration{
 						Header: "X-Convoy-Signature",
 						Versions: []datastore.SignatureVersion{
@@ -20,6 +20,12 @@ type ReplayEventService struct {
 }
 
 func (e *ReplayEventService) Run(ctx context.Context) error {
+	// the event was saved with parts of it masked, replaying it would
+	// send the masked payload
+	if e.Event.Redacted {
+		return &ServiceError{ErrMsg: ErrRedactedEvent.Error()}
+	}
+
 	createEvent := task.CreateEvent{
 		Event: e.Event,
 	}
@@ -65,6 +65,16 @@ func TestReplayEventService_Run(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "failed to write event to queue",
 		},
+		{
+			name: "should_not_replay_redacted_event",
+			args: args{
+				ctx:   ctx,
+				event: &datastore.Event{UID: "123", Redacted: true},
+				g:     &datastore.Project{UID: "123", Name: "test_project"},
+			},
+			wantErr:    true,
+			wantErrMsg: ErrRedactedEvent.Error(),
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -33,6 +33,15 @@ func (e *RetryEventDeliveryService) Run(ctx context.Context) error {
 		return &ServiceError{ErrMsg: "cannot resend event that did not fail previously"}
 	}
 
+	if e.EventDelivery.Metadata != nil && e.EventDelivery.Metadata.Redacted {
+		return &ServiceError{ErrMsg: ErrRedactedEventDelivery.Error()}
+	}
+
+	// pub sub fallbacks don't have an endpoint
+	if e.EventDelivery.IsPubSubFallback() {
+		return requeueEventDelivery(ctx, e.EventDelivery, e.Project, e.EventDeliveryRepo, e.Queue)
+	}
+
 	endpoint, err := e.EndpointRepo.FindEndpointByID(ctx, e.EventDelivery.EndpointID, e.Project.UID)
 	if err != nil {
 		return &ServiceError{ErrMsg: datastore.ErrEndpointNotFound.Error(), Err: err}
@@ -63,6 +72,10 @@ func requeueEventDelivery(ctx context.Context, eventDelivery *datastore.EventDel
 	}
 
 	taskName := convoy.EventProcessor
+	if eventDelivery.IsPubSubFallback() {
+		taskName = convoy.FallbackPublishProcessor
+	}
+
 	payload := task.EventDelivery{
 		EventDeliveryID: eventDelivery.UID,
 		ProjectID:       g.UID,
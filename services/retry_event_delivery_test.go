@@ -73,6 +73,20 @@ func TestRetryEventDeliveryService_Run(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "event already sent",
 		},
+		{
+			name: "should_error_for_redacted_event_delivery",
+			args: args{
+				ctx: ctx,
+				eventDelivery: &datastore.EventDelivery{
+					UID:      "123",
+					Status:   datastore.FailureEventStatus,
+					Metadata: &datastore.Metadata{Redacted: true},
+				},
+				g: &datastore.Project{UID: "abc"},
+			},
+			wantErr:    true,
+			wantErrMsg: ErrRedactedEventDelivery.Error(),
+		},
 		{
 			name: "should_retry_event_delivery",
 			dbFn: func(es *RetryEventDeliveryService) {
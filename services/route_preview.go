@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/celfilter"
+	"github.com/frain-dev/convoy/pkg/compare"
+	"github.com/frain-dev/convoy/pkg/flatten"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/util"
+)
+
+var ErrRoutePreview = errors.New("failed to preview event routes")
+
+const routePreviewPageSize = 1000
+
+// FilterInput is what a subscription filter is evaluated against.
+type FilterInput struct {
+	Body      interface{}
+	Headers   map[string]interface{}
+	Metadata  map[string]interface{}
+	EventType string
+	Source    string
+
+	// Labels are the labels of the subscription's endpoint
+	Labels datastore.Labels
+}
+
+// ExplainFilter evaluates filter against in like events are matched to
+// subscriptions, and explains each of its clauses.
+func ExplainFilter(filter datastore.FilterSchema, in FilterInput) (*models.FilterExplanation, error) {
+	var err error
+	e := &models.FilterExplanation{}
+
+	e.Body, err = explainFilterPart(in.Body, filter.Body, filter.IsFlattened)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Headers, err = explainFilterPart(in.Headers, filter.Headers, filter.IsFlattened)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Metadata, err = explainFilterPart(in.Metadata, filter.Metadata, filter.IsFlattened)
+	if err != nil {
+		return nil, err
+	}
+
+	if !util.IsStringEmpty(filter.Expression) {
+		e.Expression = &models.ExpressionExplanation{Expression: filter.Expression}
+
+		program, err := celfilter.Compile(filter.Expression)
+		if err != nil {
+			e.Expression.Error = err.Error()
+		} else {
+			e.Expression.Result, err = celfilter.Match(program, celfilter.Input{
+				Body:      in.Body,
+				Headers:   in.Headers,
+				EventType: in.EventType,
+				Source:    in.Source,
+			})
+			if err != nil {
+				e.Expression.Error = err.Error()
+			}
+		}
+	}
+
+	if len(filter.Labels) > 0 {
+		e.Labels = &models.LabelsExplanation{Selector: filter.Labels, Result: filter.Labels.Matches(in.Labels)}
+	}
+
+	e.Matched = (e.Body == nil || e.Body.Result) &&
+		(e.Headers == nil || e.Headers.Result) &&
+		(e.Metadata == nil || e.Metadata.Result) &&
+		(e.Expression == nil || e.Expression.Result) &&
+		(e.Labels == nil || e.Labels.Result)
+
+	return e, nil
+}
+
+// explainFilterPart returns nil when the filter is empty, which matches
+// every payload.
+func explainFilterPart(payload interface{}, filter datastore.M, isFlattened bool) (*compare.Clause, error) {
+	if len(filter) == 0 {
+		return nil, nil
+	}
+
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+
+	p, err := flatten.Flatten(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	f := flatten.M(filter)
+	if !isFlattened {
+		f, err = flatten.Flatten(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return compare.Explain(p, f)
+}
+
+type RoutePreviewService struct {
+	SubRepo      datastore.SubscriptionRepository
+	EndpointRepo datastore.EndpointRepository
+	Project      *datastore.Project
+	Event        *datastore.Event
+}
+
+// Run evaluates the event against each of the project's subscriptions,
+// without creating event deliveries.
+func (r *RoutePreviewService) Run(ctx context.Context) (*models.RoutePreview, error) {
+	subscriptions, err := r.SubRepo.LoadAllSubscriptionConfig(ctx, []string{r.Project.UID}, routePreviewPageSize)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error(ErrRoutePreview.Error())
+		return nil, &ServiceError{ErrMsg: ErrRoutePreview.Error(), Err: err}
+	}
+
+	var body interface{}
+	if len(r.Event.Data) > 0 {
+		if err = json.Unmarshal(r.Event.Data, &body); err != nil {
+			return nil, &ServiceError{ErrMsg: "event data is not valid json", Err: err}
+		}
+	}
+
+	in := FilterInput{
+		Body:      body,
+		Headers:   r.Event.GetRawHeaders(),
+		Metadata:  r.Event.Metadata,
+		EventType: string(r.Event.EventType),
+		Source:    r.Event.SourceID,
+	}
+
+	preview := &models.RoutePreview{
+		EventID:       r.Event.UID,
+		EventType:     string(r.Event.EventType),
+		Subscriptions: make([]models.SubscriptionRoutePreview, 0, len(subscriptions)),
+	}
+
+	for i := range subscriptions {
+		s := &subscriptions[i]
+		fc := s.GetFilterConfig()
+
+		in.Labels = nil
+		if len(fc.Filter.Labels) > 0 && !util.IsStringEmpty(s.EndpointID) {
+			endpoint, err := r.EndpointRepo.FindEndpointByID(ctx, s.EndpointID, r.Project.UID)
+			if err != nil && !errors.Is(err, datastore.ErrEndpointNotFound) {
+				return nil, &ServiceError{ErrMsg: ErrRoutePreview.Error(), Err: err}
+			}
+
+			if endpoint != nil {
+				in.Labels = endpoint.Labels
+			}
+		}
+
+		filter, err := ExplainFilter(fc.Filter, in)
+		if err != nil {
+			return nil, &ServiceError{ErrMsg: err.Error(), Err: err}
+		}
+
+		sp := models.SubscriptionRoutePreview{
+			SubscriptionID:   s.UID,
+			Name:             s.Name,
+			EndpointID:       s.EndpointID,
+			SourceID:         s.SourceID,
+			RouteMatched:     r.routeMatched(s),
+			EventTypeMatched: r.Project.Type == datastore.IncomingProject || eventTypeMatched(fc.EventTypes, string(r.Event.EventType)),
+			Filter:           filter,
+		}
+		sp.Matched = sp.RouteMatched && sp.EventTypeMatched && filter.Matched
+
+		preview.Subscriptions = append(preview.Subscriptions, sp)
+	}
+
+	return preview, nil
+}
+
+// routeMatched mirrors how subscriptions are found when an event is
+// created, by the event's endpoints or by its source.
+func (r *RoutePreviewService) routeMatched(s *datastore.Subscription) bool {
+	if r.Project.Type == datastore.IncomingProject {
+		return s.SourceID == r.Event.SourceID
+	}
+
+	for _, endpointID := range r.Event.Endpoints {
+		if endpointID == s.EndpointID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func eventTypeMatched(eventTypes []string, eventType string) bool {
+	for _, et := range eventTypes {
+		if et == eventType || et == "*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func TestRoutePreviewService_Run(t *testing.T) {
+	ctx := context.Background()
+
+	event := &datastore.Event{
+		UID:       "event-1",
+		EventType: "invoice.paid",
+		Endpoints: []string{"endpoint-1"},
+		Headers:   map[string][]string{"X-Region": {"eu"}},
+		Data:      []byte(`{"amount": 500, "currency": "EUR"}`),
+	}
+
+	subscriptions := []datastore.Subscription{
+		{
+			UID:        "sub-1",
+			EndpointID: "endpoint-1",
+			FilterConfig: &datastore.FilterConfiguration{
+				EventTypes: []string{"invoice.paid"},
+				Filter: datastore.FilterSchema{
+					Body:        datastore.M{"currency": "EUR"},
+					Expression:  `headers["X-Region"] == "eu"`,
+					IsFlattened: true,
+				},
+			},
+		},
+		{
+			UID:        "sub-2",
+			EndpointID: "endpoint-1",
+			FilterConfig: &datastore.FilterConfiguration{
+				EventTypes: []string{"*"},
+				Filter: datastore.FilterSchema{
+					Body:        datastore.M{"amount": map[string]interface{}{"$gte": 1000}},
+					IsFlattened: true,
+				},
+			},
+		},
+		{
+			UID:        "sub-3",
+			EndpointID: "endpoint-2",
+			FilterConfig: &datastore.FilterConfiguration{
+				EventTypes: []string{"*"},
+			},
+		},
+		{
+			UID:        "sub-4",
+			EndpointID: "endpoint-1",
+			FilterConfig: &datastore.FilterConfiguration{
+				EventTypes: []string{"invoice.created"},
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	subRepo.EXPECT().LoadAllSubscriptionConfig(gomock.Any(), []string{"project-1"}, gomock.Any()).Return(subscriptions, nil)
+
+	rs := &RoutePreviewService{
+		SubRepo: subRepo,
+		Project: &datastore.Project{UID: "project-1", Type: datastore.OutgoingProject},
+		Event:   event,
+	}
+
+	preview, err := rs.Run(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "event-1", preview.EventID)
+	require.Len(t, preview.Subscriptions, 4)
+
+	sub1 := preview.Subscriptions[0]
+	require.True(t, sub1.Matched)
+	require.True(t, sub1.Filter.Body.Result)
+	require.True(t, sub1.Filter.Expression.Result)
+
+	// the amount clause failed
+	sub2 := preview.Subscriptions[1]
+	require.False(t, sub2.Matched)
+	require.True(t, sub2.RouteMatched)
+	require.True(t, sub2.EventTypeMatched)
+	require.Equal(t, "amount", sub2.Filter.Body.Clauses[0].Path)
+	require.Equal(t, float64(500), sub2.Filter.Body.Clauses[0].Actual)
+	require.False(t, sub2.Filter.Body.Clauses[0].Result)
+
+	// the event wasn't sent to the subscription's endpoint
+	sub3 := preview.Subscriptions[2]
+	require.False(t, sub3.Matched)
+	require.False(t, sub3.RouteMatched)
+	require.True(t, sub3.Filter.Matched)
+
+	sub4 := preview.Subscriptions[3]
+	require.False(t, sub4.Matched)
+	require.False(t, sub4.EventTypeMatched)
+}
+
+func TestRoutePreviewService_Run_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	subRepo.EXPECT().LoadAllSubscriptionConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("failed"))
+
+	rs := &RoutePreviewService{
+		SubRepo: subRepo,
+		Project: &datastore.Project{UID: "project-1"},
+		Event:   &datastore.Event{UID: "event-1"},
+	}
+
+	_, err := rs.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, ErrRoutePreview.Error(), err.(*ServiceError).Error())
+}
+
+func TestExplainFilter(t *testing.T) {
+	filter := datastore.FilterSchema{
+		Headers:  datastore.M{"X-Region": "eu"},
+		Metadata: datastore.M{"jwt": map[string]interface{}{"sub": "user_1"}},
+	}
+
+	e, err := ExplainFilter(filter, FilterInput{
+		Body:     map[string]interface{}{"amount": 10},
+		Headers:  map[string]interface{}{"X-Region": "us"},
+		Metadata: map[string]interface{}{"jwt": map[string]interface{}{"sub": "user_1"}},
+	})
+	require.NoError(t, err)
+
+	require.False(t, e.Matched)
+	require.Nil(t, e.Body)
+	require.False(t, e.Headers.Result)
+	require.Equal(t, "us", e.Headers.Clauses[0].Actual)
+	require.True(t, e.Metadata.Result)
+	require.Nil(t, e.Expression)
+}
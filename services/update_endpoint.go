@@ -93,6 +93,14 @@ func updateEndpoint(endpoint *datastore.Endpoint, e models.UpdateEndpoint, proje
 
 	endpoint.Authentication = auth
 
+	if e.EventTypeVersions != nil {
+		endpoint.EventTypeVersions = e.EventTypeVersions
+	}
+
+	if e.Labels != nil {
+		endpoint.Labels = e.Labels
+	}
+
 	endpoint.UpdatedAt = time.Now()
 
 	return endpoint, nil
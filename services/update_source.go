@@ -10,14 +10,18 @@ import (
 )
 
 type UpdateSourceService struct {
-	SourceRepo   datastore.SourceRepository
-	Cache        cache.Cache
-	Project      *datastore.Project
-	SourceUpdate *models.UpdateSource
-	Source       *datastore.Source
+	SourceRepo          datastore.SourceRepository
+	EndpointRepo        datastore.EndpointRepository
+	FunctionVersionRepo datastore.FunctionVersionRepository
+	Cache               cache.Cache
+	Project             *datastore.Project
+	SourceUpdate        *models.UpdateSource
+	Source              *datastore.Source
 }
 
 func (s *UpdateSourceService) Run(ctx context.Context) (*datastore.Source, error) {
+	oldBodyFunction, oldHeaderFunction := s.Source.BodyFunction, s.Source.HeaderFunction
+
 	s.Source.Name = *s.SourceUpdate.Name
 	s.Source.Verifier = s.SourceUpdate.Verifier.Transform()
 	s.Source.Type = s.SourceUpdate.Type
@@ -26,16 +30,8 @@ func (s *UpdateSourceService) Run(ctx context.Context) (*datastore.Source, error
 		s.Source.IsDisabled = *s.SourceUpdate.IsDisabled
 	}
 
-	if s.SourceUpdate.Verifier.Type == datastore.HMacVerifier && s.SourceUpdate.Verifier.HMac == nil {
-		return nil, &ServiceError{ErrMsg: "Invalid verifier config for hmac"}
-	}
-
-	if s.SourceUpdate.Verifier.Type == datastore.APIKeyVerifier && s.SourceUpdate.Verifier.ApiKey == nil {
-		return nil, &ServiceError{ErrMsg: "Invalid verifier config for api key"}
-	}
-
-	if s.SourceUpdate.Verifier.Type == datastore.BasicAuthVerifier && s.SourceUpdate.Verifier.BasicAuth == nil {
-		return nil, &ServiceError{ErrMsg: "Invalid verifier config for basic auth"}
+	if err := s.SourceUpdate.Verifier.Validate(); err != nil {
+		return nil, &ServiceError{ErrMsg: err.Error()}
 	}
 
 	if s.SourceUpdate.Type == datastore.PubSubSource {
@@ -52,6 +48,10 @@ func (s *UpdateSourceService) Run(ctx context.Context) (*datastore.Source, error
 		s.Source.IdempotencyKeys = s.SourceUpdate.IdempotencyKeys
 	}
 
+	if s.SourceUpdate.DedupWindow != nil {
+		s.Source.DedupWindow = *s.SourceUpdate.DedupWindow
+	}
+
 	if s.SourceUpdate.PubSub != nil {
 		s.Source.PubSub = s.SourceUpdate.PubSub.Transform()
 	}
@@ -72,6 +72,38 @@ func (s *UpdateSourceService) Run(ctx context.Context) (*datastore.Source, error
 		s.Source.HeaderFunction = s.SourceUpdate.HeaderFunction
 	}
 
+	if s.SourceUpdate.IPAllowlist != nil {
+		s.Source.IPAllowlist = s.SourceUpdate.IPAllowlist
+	}
+
+	if s.SourceUpdate.ClientCACert != nil {
+		s.Source.ClientCACert = *s.SourceUpdate.ClientCACert
+	}
+
+	if s.SourceUpdate.EventTypeConfig != nil {
+		cfg := s.SourceUpdate.EventTypeConfig.Transform()
+		if cfg != nil && cfg.Type == datastore.ProviderEventTypeSource && !s.Source.Provider.IsValid() {
+			return nil, &ServiceError{ErrMsg: "the event type can only be read from a supported provider"}
+		}
+
+		s.Source.EventTypeConfig = cfg
+	}
+
+	if s.SourceUpdate.SyncForwarding != nil {
+		s.Source.SyncForwarding = s.SourceUpdate.SyncForwarding.Transform()
+		if err := validateSyncForwarding(ctx, s.EndpointRepo, s.Project, s.Source); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.SourceUpdate.IngestRateLimit != nil {
+		s.Source.IngestRateLimit = s.SourceUpdate.IngestRateLimit.Transform()
+	}
+
+	if err := recordSourceFunctions(ctx, s.FunctionVersionRepo, s.Source, oldBodyFunction, oldHeaderFunction); err != nil {
+		return nil, err
+	}
+
 	err := s.SourceRepo.UpdateSource(ctx, s.Project.UID, s.Source)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Error("failed to update source")
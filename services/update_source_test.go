@@ -126,6 +126,25 @@ func TestUpdateSourceService_Run(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "an error occurred while updating source",
 		},
+
+		{
+			name: "should_fail_to_update_source_with_jwt_verifier_without_key",
+			args: args{
+				ctx:    ctx,
+				source: &datastore.Source{UID: "12345"},
+				update: &models.UpdateSource{
+					Name: stringPtr("Convoy-Prod"),
+					Type: datastore.HTTPSource,
+					Verifier: models.VerifierConfig{
+						Type: datastore.JWTVerifier,
+						JWT:  &models.JWT{Algorithm: "RS256"},
+					},
+				},
+				project: &datastore.Project{UID: "12345"},
+			},
+			wantErr:    true,
+			wantErrMsg: "please provide either a key or a jwks url for the jwt verifier",
+		},
 	}
 
 	for _, tc := range tests {
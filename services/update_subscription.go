@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"gopkg.in/guregu/null.v4"
 
 	"github.com/frain-dev/convoy/api/models"
@@ -18,12 +19,13 @@ var (
 )
 
 type UpdateSubscriptionService struct {
-	SubRepo        datastore.SubscriptionRepository
-	EndpointRepo   datastore.EndpointRepository
-	SourceRepo     datastore.SourceRepository
-	ProjectId      string
-	SubscriptionId string
-	Update         *models.UpdateSubscription
+	SubRepo             datastore.SubscriptionRepository
+	EndpointRepo        datastore.EndpointRepository
+	SourceRepo          datastore.SourceRepository
+	FunctionVersionRepo datastore.FunctionVersionRepository
+	ProjectId           string
+	SubscriptionId      string
+	Update              *models.UpdateSubscription
 }
 
 func (s *UpdateSubscriptionService) Run(ctx context.Context) (*datastore.Subscription, error) {
@@ -38,6 +40,11 @@ func (s *UpdateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		return nil, &ServiceError{ErrMsg: err.Error()}
 	}
 
+	fallbackConfig, err := s.Update.FallbackConfig.Transform()
+	if err != nil {
+		return nil, &ServiceError{ErrMsg: err.Error()}
+	}
+
 	if !util.IsStringEmpty(s.Update.Name) {
 		subscription.Name = s.Update.Name
 	}
@@ -46,7 +53,12 @@ func (s *UpdateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		subscription.SourceID = s.Update.SourceID
 	}
 
-	if !util.IsStringEmpty(s.Update.Function) {
+	functionChanged := !util.IsStringEmpty(s.Update.Function) && s.Update.Function != subscription.Function.String
+	if functionChanged {
+		if subscription.FunctionVersion.Valid {
+			return nil, &ServiceError{ErrMsg: ErrFunctionPinned.Error()}
+		}
+
 		subscription.Function = null.StringFrom(s.Update.Function)
 	}
 
@@ -107,13 +119,20 @@ func (s *UpdateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 			subscription.FilterConfig.EventTypes = s.Update.FilterConfig.EventTypes
 		}
 
-		if len(s.Update.FilterConfig.Filter.Body) > 0 || len(s.Update.FilterConfig.Filter.Headers) > 0 {
+		if len(s.Update.FilterConfig.Filter.Body) > 0 || len(s.Update.FilterConfig.Filter.Headers) > 0 ||
+			len(s.Update.FilterConfig.Filter.Metadata) > 0 || !util.IsStringEmpty(s.Update.FilterConfig.Filter.Expression) ||
+			len(s.Update.FilterConfig.Filter.Labels) > 0 {
 			// validate that the filter is a json string
 			_, err := json.Marshal(s.Update.FilterConfig.Filter)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Error(ErrInvalidSubscriptionFilterFormat.Error())
 				return nil, &ServiceError{ErrMsg: ErrInvalidSubscriptionFilterFormat.Error(), Err: err}
 			}
+
+			if err = validateFilterExpression(s.Update.FilterConfig.Filter.Expression); err != nil {
+				log.FromContext(ctx).WithError(err).Error(ErrInvalidSubscriptionFilterExpr.Error())
+				return nil, &ServiceError{ErrMsg: fmt.Sprintf("%s: %v", ErrInvalidSubscriptionFilterExpr, err), Err: err}
+			}
 			subscription.FilterConfig.Filter = s.Update.FilterConfig.Filter.Transform()
 		}
 	}
@@ -132,6 +151,32 @@ func (s *UpdateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		subscription.RateLimitConfig.Duration = s.Update.RateLimitConfig.Duration
 	}
 
+	if s.Update.EventTypeVersions != nil {
+		subscription.EventTypeVersions = s.Update.EventTypeVersions
+	}
+
+	// a window of zero turns debouncing off
+	if s.Update.DebounceConfig != nil {
+		subscription.DebounceConfig = s.Update.DebounceConfig.Transform()
+	}
+
+	// an empty fallback turns it off
+	if s.Update.FallbackConfig != nil {
+		err = validateFallbackConfig(ctx, s.EndpointRepo, s.ProjectId, subscription.EndpointID, fallbackConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		subscription.FallbackConfig = fallbackConfig
+	}
+
+	if functionChanged {
+		_, err = recordFunctionVersion(ctx, s.FunctionVersionRepo, s.ProjectId, datastore.SubscriptionFunctionOwner, subscription.UID, subscription.Function.String)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = s.SubRepo.UpdateSubscription(ctx, s.ProjectId, subscription)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Error(ErrUpdateSubscriptionError.Error())
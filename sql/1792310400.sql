@@ -0,0 +1,19 @@
+-- +migrate Up
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS jwt_algorithm TEXT;
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS jwt_key TEXT;
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS jwt_jwks_url TEXT;
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS jwt_issuer TEXT;
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS jwt_audience TEXT;
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS jwt_body_hash_claim TEXT;
+ALTER TABLE convoy.events ADD COLUMN IF NOT EXISTS metadata JSONB DEFAULT NULL;
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS filter_config_filter_metadata JSONB DEFAULT NULL;
+
+-- +migrate Down
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS jwt_algorithm;
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS jwt_key;
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS jwt_jwks_url;
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS jwt_issuer;
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS jwt_audience;
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS jwt_body_hash_claim;
+ALTER TABLE convoy.events DROP COLUMN IF EXISTS metadata;
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS filter_config_filter_metadata;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS ip_allowlist TEXT[];
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS client_ca_cert TEXT;
+
+-- +migrate Down
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS ip_allowlist;
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS client_ca_cert;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.events ADD COLUMN IF NOT EXISTS original_body TEXT;
+
+-- +migrate Down
+ALTER TABLE convoy.events DROP COLUMN IF EXISTS original_body;
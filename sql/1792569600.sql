@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS event_type_config JSONB;
+
+-- +migrate Down
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS event_type_config;
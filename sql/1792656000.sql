@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS sync_forwarding JSONB;
+
+-- +migrate Down
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS sync_forwarding;
@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS ingest_rate_limit JSONB;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS ingest_ratelimit_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS ingest_ratelimit_burst INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS ingest_ratelimit_duration INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS ingest_quota_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS ingest_quota_period TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS ingest_rate_limit;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS ingest_ratelimit_count;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS ingest_ratelimit_burst;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS ingest_ratelimit_duration;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS ingest_quota_count;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS ingest_quota_period;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS dedup_window BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS dedup_window;
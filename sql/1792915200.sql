@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS filter_config_filter_expression TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS filter_config_filter_expression;
@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS convoy.function_libraries (
+    id CHAR(26) PRIMARY KEY,
+
+    project_id CHAR(26) NOT NULL REFERENCES convoy.projects (id),
+    name TEXT NOT NULL,
+    function TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_function_libraries_project_id_name ON convoy.function_libraries (project_id, name) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS convoy.function_versions (
+    id CHAR(26) PRIMARY KEY,
+
+    project_id CHAR(26) NOT NULL REFERENCES convoy.projects (id),
+    owner_type TEXT NOT NULL,
+    owner_id CHAR(26) NOT NULL,
+    version INTEGER NOT NULL,
+    function TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_function_versions_owner_version ON convoy.function_versions (owner_type, owner_id, version);
+
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS function_version INTEGER;
+
+-- +migrate Down
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS function_version;
+DROP TABLE IF EXISTS convoy.function_versions;
+DROP TABLE IF EXISTS convoy.function_libraries;
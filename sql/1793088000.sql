@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS convoy.event_types (
+    id CHAR(26) PRIMARY KEY,
+
+    project_id CHAR(26) NOT NULL REFERENCES convoy.projects (id),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    json_schema JSONB,
+    examples JSONB NOT NULL DEFAULT '[]',
+    deprecated BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_types_project_id_name ON convoy.event_types (project_id, name) WHERE deleted_at IS NULL;
+
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS validate_event_types BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS validate_event_types;
+DROP TABLE IF EXISTS convoy.event_types;
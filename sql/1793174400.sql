@@ -0,0 +1,29 @@
+-- +migrate Up
+ALTER TABLE convoy.event_types ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS convoy.event_type_versions (
+    project_id CHAR(26) NOT NULL REFERENCES convoy.projects (id),
+    event_type_id CHAR(26) NOT NULL REFERENCES convoy.event_types (id),
+    version INTEGER NOT NULL,
+    json_schema JSONB,
+    down_converter TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (event_type_id, version)
+);
+
+INSERT INTO convoy.event_type_versions (project_id, event_type_id, version, json_schema)
+SELECT project_id, id, version, json_schema FROM convoy.event_types
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS event_type_versions JSONB;
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS event_type_versions JSONB;
+ALTER TABLE convoy.event_deliveries ADD COLUMN IF NOT EXISTS event_type_version INTEGER;
+
+-- +migrate Down
+ALTER TABLE convoy.event_deliveries DROP COLUMN IF EXISTS event_type_version;
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS event_type_versions;
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS event_type_versions;
+DROP TABLE IF EXISTS convoy.event_type_versions;
+ALTER TABLE convoy.event_types DROP COLUMN IF EXISTS version;
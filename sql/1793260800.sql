@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS debounce_config_key_path TEXT NOT NULL DEFAULT '';
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS debounce_config_window INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS debounce_config_mode TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE convoy.event_deliveries ADD COLUMN IF NOT EXISTS debounce_key TEXT;
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_debounce_key ON convoy.event_deliveries (subscription_id, debounce_key) WHERE debounce_key IS NOT NULL AND status = 'Scheduled';
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_debounce_key;
+ALTER TABLE convoy.event_deliveries DROP COLUMN IF EXISTS debounce_key;
+
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS debounce_config_mode;
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS debounce_config_window;
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS debounce_config_key_path;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS fallback_config JSONB;
+
+ALTER TABLE convoy.event_deliveries ADD COLUMN IF NOT EXISTS fallback_for_id VARCHAR;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_fallback_for_id ON convoy.event_deliveries (fallback_for_id) WHERE fallback_for_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_fallback_for_id;
+ALTER TABLE convoy.event_deliveries DROP COLUMN IF EXISTS fallback_for_id;
+
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS fallback_config;
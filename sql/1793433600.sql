@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_endpoints_labels ON convoy.endpoints USING GIN (labels);
+
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS filter_config_filter_labels JSONB NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS filter_config_filter_labels;
+
+DROP INDEX IF EXISTS convoy.idx_endpoints_labels;
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS labels;
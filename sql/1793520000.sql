@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS convoy.project_data_keys (
+    id CHAR(26) PRIMARY KEY,
+
+    project_id CHAR(26) NOT NULL REFERENCES convoy.projects (id),
+    version INTEGER NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    kek_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_data_keys_project_id_version ON convoy.project_data_keys (project_id, version);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_data_keys_project_id_active ON convoy.project_data_keys (project_id) WHERE status = 'active';
+
+-- +migrate Down
+DROP TABLE IF EXISTS convoy.project_data_keys;
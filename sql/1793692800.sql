@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.events ADD COLUMN IF NOT EXISTS payload_reference TEXT;
+
+-- +migrate Down
+ALTER TABLE convoy.events DROP COLUMN IF EXISTS payload_reference;
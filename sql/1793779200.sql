@@ -0,0 +1,153 @@
+-- +migrate Up
+-- partitions are unique on (id, created_at) only, so the foreign keys to
+-- events and the unique fallback index can't be kept.
+ALTER TABLE convoy.events_endpoints DROP CONSTRAINT IF EXISTS events_endpoints_event_id_fkey;
+ALTER TABLE convoy.event_deliveries DROP CONSTRAINT IF EXISTS event_deliveries_event_id_fkey;
+
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_fallback_for_id;
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_fallback_for_id ON convoy.event_deliveries (fallback_for_id) WHERE fallback_for_id IS NOT NULL;
+
+-- +migrate Up
+-- +migrate StatementBegin
+-- partition_table turns _table into a table partitioned by day on
+-- created_at. The existing table is attached as the <table>_legacy
+-- partition for the rows created before tomorrow, so its rows aren't
+-- copied, and the partitions of the next days are created.
+--
+-- This isn't an online migration. The rename takes an ACCESS EXCLUSIVE
+-- lock on the table that's held until the migration commits, and while
+-- it's held the table is read in full: by the UPDATE of the rows without
+-- a created_at unless it uses the created_at index, and by the CHECK on
+-- created_at, which Postgres then reuses so SET NOT NULL and ATTACH
+-- PARTITION don't scan it again.
+-- Reads and writes of events and event deliveries block for as long as
+-- that takes, which grows with the size of the tables, so the migration
+-- should run in a maintenance window with the API and the workers
+-- stopped. Deleting the rows past the retention policy beforehand shortens
+-- it.
+CREATE OR REPLACE FUNCTION convoy.partition_table(_table TEXT, _days INTEGER) RETURNS VOID
+    LANGUAGE plpgsql
+AS
+$$
+DECLARE
+    legacy TEXT := _table || '_legacy';
+    cutoff TIMESTAMPTZ := date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + INTERVAL '1 day';
+    day TIMESTAMPTZ;
+    idx RECORD;
+    defs TEXT[] := '{}';
+    def TEXT;
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_partitioned_table pt JOIN pg_class c ON c.oid = pt.partrelid
+               JOIN pg_namespace n ON n.oid = c.relnamespace
+               WHERE n.nspname = 'convoy' AND c.relname = _table) THEN
+        RETURN;
+    END IF;
+
+    EXECUTE format('ALTER TABLE convoy.%I RENAME TO %I', _table, legacy);
+    EXECUTE format('ALTER TABLE convoy.%I RENAME CONSTRAINT %I TO %I', legacy, _table || '_pkey', legacy || '_pkey');
+
+    -- the indexes are recreated on the partitioned table with their names,
+    -- the legacy ones match their definition and are attached to them.
+    FOR idx IN SELECT i.relname AS name, pg_get_indexdef(i.oid) AS def
+               FROM pg_index x
+               JOIN pg_class i ON i.oid = x.indexrelid
+               JOIN pg_class t ON t.oid = x.indrelid
+               JOIN pg_namespace n ON n.oid = t.relnamespace
+               WHERE n.nspname = 'convoy' AND t.relname = legacy AND NOT x.indisprimary
+    LOOP
+        defs := defs || regexp_replace(idx.def, ' ON (ONLY )?(convoy\.)?' || legacy || ' ', ' ON convoy.' || _table || ' ');
+        EXECUTE format('ALTER INDEX convoy.%I RENAME TO %I', idx.name, left(idx.name, 56) || '_legacy');
+    END LOOP;
+
+    -- may scan the whole table under the lock
+    EXECUTE format('UPDATE convoy.%I SET created_at = COALESCE(updated_at, now()) WHERE created_at IS NULL', legacy);
+
+    -- another full scan under the lock, the check proves the column isn't
+    -- null and the partition bound, so the two statements after it don't
+    -- scan the table
+    EXECUTE format('ALTER TABLE convoy.%I ADD CONSTRAINT %I CHECK (created_at IS NOT NULL AND created_at < %L)', legacy, legacy || '_bound', cutoff);
+    EXECUTE format('ALTER TABLE convoy.%I ALTER COLUMN created_at SET NOT NULL', legacy);
+
+    EXECUTE format('CREATE TABLE convoy.%I (LIKE convoy.%I INCLUDING DEFAULTS) PARTITION BY RANGE (created_at)', _table, legacy);
+    EXECUTE format('ALTER TABLE convoy.%I ADD PRIMARY KEY (id, created_at)', _table);
+
+    EXECUTE format('ALTER TABLE convoy.%I ATTACH PARTITION convoy.%I FOR VALUES FROM (MINVALUE) TO (%L)', _table, legacy, cutoff);
+    EXECUTE format('ALTER TABLE convoy.%I DROP CONSTRAINT %I', legacy, legacy || '_bound');
+
+    EXECUTE format('CREATE TABLE convoy.%I PARTITION OF convoy.%I DEFAULT', _table || '_default', _table);
+
+    FOR i IN 0.._days - 1 LOOP
+        day := cutoff + make_interval(days := i);
+        EXECUTE format('CREATE TABLE convoy.%I PARTITION OF convoy.%I FOR VALUES FROM (%L) TO (%L)',
+                       _table || '_p' || to_char(day AT TIME ZONE 'UTC', 'YYYYMMDD'), _table, day, day + INTERVAL '1 day');
+    END LOOP;
+
+    FOREACH def IN ARRAY defs LOOP
+        EXECUTE def;
+    END LOOP;
+END;
+$$;
+-- +migrate StatementEnd
+
+-- +migrate Up
+SELECT convoy.partition_table('event_deliveries', 7);
+SELECT convoy.partition_table('events', 7);
+DROP FUNCTION IF EXISTS convoy.partition_table(TEXT, INTEGER);
+
+-- +migrate Down
+-- +migrate StatementBegin
+-- unpartition_table copies the rows of the partitioned _table back into a
+-- plain table, the indexes dropped with it are recreated below.
+CREATE OR REPLACE FUNCTION convoy.unpartition_table(_table TEXT) RETURNS VOID
+    LANGUAGE plpgsql
+AS
+$$
+DECLARE
+    plain TEXT := _table || '_unpartitioned';
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_partitioned_table pt JOIN pg_class c ON c.oid = pt.partrelid
+                   JOIN pg_namespace n ON n.oid = c.relnamespace
+                   WHERE n.nspname = 'convoy' AND c.relname = _table) THEN
+        RETURN;
+    END IF;
+
+    EXECUTE format('CREATE TABLE convoy.%I (LIKE convoy.%I INCLUDING DEFAULTS)', plain, _table);
+    EXECUTE format('INSERT INTO convoy.%I SELECT * FROM convoy.%I', plain, _table);
+    EXECUTE format('DROP TABLE convoy.%I CASCADE', _table);
+    EXECUTE format('ALTER TABLE convoy.%I RENAME TO %I', plain, _table);
+    EXECUTE format('ALTER TABLE convoy.%I ALTER COLUMN created_at DROP NOT NULL', _table);
+    EXECUTE format('ALTER TABLE convoy.%I ADD PRIMARY KEY (id)', _table);
+END;
+$$;
+-- +migrate StatementEnd
+
+-- +migrate Down
+SELECT convoy.unpartition_table('event_deliveries');
+SELECT convoy.unpartition_table('events');
+DROP FUNCTION IF EXISTS convoy.unpartition_table(TEXT);
+
+-- +migrate Down
+CREATE INDEX IF NOT EXISTS idx_events_project_id_key ON convoy.events (project_id);
+CREATE INDEX IF NOT EXISTS idx_events_source_id_key ON convoy.events (source_id);
+CREATE INDEX IF NOT EXISTS idx_events_created_at_key ON convoy.events (created_at);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at_key ON convoy.events (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_events_project_id_deleted_at_key ON convoy.events (project_id, deleted_at);
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_key ON convoy.events (idempotency_key);
+CREATE INDEX IF NOT EXISTS idx_project_id_on_not_deleted ON convoy.events(project_id) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_project_id_key ON convoy.event_deliveries (project_id);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_status_key ON convoy.event_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_event_id_key ON convoy.event_deliveries(event_id);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_created_at_key ON convoy.event_deliveries(created_at);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_deleted_at_key ON convoy.event_deliveries(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_endpoint_id_key ON convoy.event_deliveries(endpoint_id);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_device_id_key ON convoy.event_deliveries(device_id);
+CREATE INDEX IF NOT EXISTS event_deliveries_event_type_1 ON convoy.event_deliveries(event_type);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_debounce_key ON convoy.event_deliveries (subscription_id, debounce_key) WHERE debounce_key IS NOT NULL AND status = 'Scheduled';
+
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_fallback_for_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_fallback_for_id ON convoy.event_deliveries (fallback_for_id) WHERE fallback_for_id IS NOT NULL;
+
+DELETE FROM convoy.events_endpoints ee WHERE NOT EXISTS (SELECT 1 FROM convoy.events e WHERE e.id = ee.event_id);
+ALTER TABLE convoy.events_endpoints ADD CONSTRAINT events_endpoints_event_id_fkey FOREIGN KEY (event_id) REFERENCES convoy.events (id) ON DELETE CASCADE;
+ALTER TABLE convoy.event_deliveries ADD CONSTRAINT event_deliveries_event_id_fkey FOREIGN KEY (event_id) REFERENCES convoy.events (id) NOT VALID;
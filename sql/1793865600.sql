@@ -0,0 +1,81 @@
+-- +migrate Up
+-- payload holds an event's data as jsonb so payload filters can be indexed,
+-- it's written with the event and left null when the data isn't json e.g.
+-- when it's encrypted or offloaded. Adding the column doesn't rewrite the
+-- table, and events created before this migration aren't backfilled so
+-- payload filters don't match them.
+ALTER TABLE convoy.events ADD COLUMN IF NOT EXISTS payload JSONB;
+
+-- +migrate Up
+-- events_search is rebuilt from events by copy_rows, payload filters can be
+-- used with a search query.
+ALTER TABLE convoy.events_search ADD COLUMN IF NOT EXISTS payload JSONB;
+
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION convoy.copy_rows(pid VARCHAR, dur INTEGER) RETURNS VOID AS
+$$
+DECLARE
+    cs CURSOR FOR
+        SELECT * FROM convoy.events
+        WHERE project_id = pid
+        AND created_at >= NOW() - MAKE_INTERVAL(hours := dur);
+    row_data RECORD;
+BEGIN
+    OPEN cs;
+    LOOP
+        FETCH cs INTO row_data;
+        EXIT WHEN NOT FOUND;
+        INSERT INTO convoy.events_search (id, event_type, endpoints, project_id, source_id, headers, raw, data,
+                                          created_at, updated_at, deleted_at, url_query_params, idempotency_key,
+                                          is_duplicate_event, payload)
+        VALUES (row_data.id, row_data.event_type, row_data.endpoints, row_data.project_id, row_data.source_id,
+                row_data.headers, row_data.raw, row_data.data, row_data.created_at, row_data.updated_at,
+                row_data.deleted_at, row_data.url_query_params, row_data.idempotency_key, row_data.is_duplicate_event,
+                row_data.payload);
+    END LOOP;
+    CLOSE cs;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_events_payload_path_ops ON convoy.events USING GIN (payload jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_events_search_payload_path_ops ON convoy.events_search USING GIN (payload jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_payload_path_ops ON convoy.event_deliveries USING GIN ((metadata->'data') jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_payload_path_ops;
+DROP INDEX IF EXISTS convoy.idx_events_search_payload_path_ops;
+DROP INDEX IF EXISTS convoy.idx_events_payload_path_ops;
+
+-- +migrate Down
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION convoy.copy_rows(pid VARCHAR, dur INTEGER) RETURNS VOID AS
+$$
+DECLARE
+    cs CURSOR FOR
+        SELECT * FROM convoy.events
+        WHERE project_id = pid
+        AND created_at >= NOW() - MAKE_INTERVAL(hours := dur);
+    row_data RECORD;
+BEGIN
+    OPEN cs;
+    LOOP
+        FETCH cs INTO row_data;
+        EXIT WHEN NOT FOUND;
+        INSERT INTO convoy.events_search (id, event_type, endpoints, project_id, source_id, headers, raw, data,
+                                          created_at, updated_at, deleted_at, url_query_params, idempotency_key,
+                                          is_duplicate_event)
+        VALUES (row_data.id, row_data.event_type, row_data.endpoints, row_data.project_id, row_data.source_id,
+                row_data.headers, row_data.raw, row_data.data, row_data.created_at, row_data.updated_at,
+                row_data.deleted_at, row_data.url_query_params, row_data.idempotency_key, row_data.is_duplicate_event);
+    END LOOP;
+    CLOSE cs;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Down
+ALTER TABLE convoy.events_search DROP COLUMN IF EXISTS payload;
+ALTER TABLE convoy.events DROP COLUMN IF EXISTS payload;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.source_verifiers ADD COLUMN IF NOT EXISTS hmac_tolerance BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE convoy.source_verifiers DROP COLUMN IF EXISTS hmac_tolerance;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- the version of its event type an event was published under, it's 0 for
+-- the events published before it was recorded.
+ALTER TABLE convoy.events ADD COLUMN IF NOT EXISTS event_type_version INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE convoy.events DROP COLUMN IF EXISTS event_type_version;
@@ -0,0 +1,67 @@
+-- +migrate Up
+-- redacted is set on the events saved with parts of them masked by their
+-- project's redaction rules, they can't be replayed.
+ALTER TABLE convoy.events ADD COLUMN IF NOT EXISTS redacted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Up
+ALTER TABLE convoy.events_search ADD COLUMN IF NOT EXISTS redacted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION convoy.copy_rows(pid VARCHAR, dur INTEGER) RETURNS VOID AS
+$$
+DECLARE
+    cs CURSOR FOR
+        SELECT * FROM convoy.events
+        WHERE project_id = pid
+        AND created_at >= NOW() - MAKE_INTERVAL(hours := dur);
+    row_data RECORD;
+BEGIN
+    OPEN cs;
+    LOOP
+        FETCH cs INTO row_data;
+        EXIT WHEN NOT FOUND;
+        INSERT INTO convoy.events_search (id, event_type, endpoints, project_id, source_id, headers, raw, data,
+                                          created_at, updated_at, deleted_at, url_query_params, idempotency_key,
+                                          is_duplicate_event, payload, redacted)
+        VALUES (row_data.id, row_data.event_type, row_data.endpoints, row_data.project_id, row_data.source_id,
+                row_data.headers, row_data.raw, row_data.data, row_data.created_at, row_data.updated_at,
+                row_data.deleted_at, row_data.url_query_params, row_data.idempotency_key, row_data.is_duplicate_event,
+                row_data.payload, row_data.redacted);
+    END LOOP;
+    CLOSE cs;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION convoy.copy_rows(pid VARCHAR, dur INTEGER) RETURNS VOID AS
+$$
+DECLARE
+    cs CURSOR FOR
+        SELECT * FROM convoy.events
+        WHERE project_id = pid
+        AND created_at >= NOW() - MAKE_INTERVAL(hours := dur);
+    row_data RECORD;
+BEGIN
+    OPEN cs;
+    LOOP
+        FETCH cs INTO row_data;
+        EXIT WHEN NOT FOUND;
+        INSERT INTO convoy.events_search (id, event_type, endpoints, project_id, source_id, headers, raw, data,
+                                          created_at, updated_at, deleted_at, url_query_params, idempotency_key,
+                                          is_duplicate_event, payload)
+        VALUES (row_data.id, row_data.event_type, row_data.endpoints, row_data.project_id, row_data.source_id,
+                row_data.headers, row_data.raw, row_data.data, row_data.created_at, row_data.updated_at,
+                row_data.deleted_at, row_data.url_query_params, row_data.idempotency_key, row_data.is_duplicate_event,
+                row_data.payload);
+    END LOOP;
+    CLOSE cs;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Down
+ALTER TABLE convoy.events_search DROP COLUMN IF EXISTS redacted;
+ALTER TABLE convoy.events DROP COLUMN IF EXISTS redacted;
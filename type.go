@@ -87,6 +87,8 @@ const (
 	CreateDynamicEventProcessor   TaskName = "CreateDynamicEventProcessor"
 	CreateBroadcastEventProcessor TaskName = "CreateBroadcastEventProcessor"
 	MetaEventProcessor            TaskName = "MetaEventProcessor"
+	FallbackDeliveryProcessor     TaskName = "FallbackDeliveryProcessor"
+	FallbackPublishProcessor      TaskName = "FallbackPublishProcessor"
 	NotificationProcessor         TaskName = "NotificationProcessor"
 	TokenizeSearch                TaskName = "tokenize search"
 	TokenizeSearchForProject      TaskName = "tokenize search for project"
@@ -97,6 +99,8 @@ const (
 	EmailProcessor                TaskName = "EmailProcessor"
 	ExpireSecretsProcessor        TaskName = "ExpireSecretsProcessor"
 	DeleteArchivedTasksProcessor  TaskName = "DeleteArchivedTasksProcessor"
+	ReEncryptPayloads             TaskName = "reencrypt payloads"
+	ManagePartitions              TaskName = "manage partitions"
 
 	EndpointCacheKey     CacheKey = "endpoints"
 	ApiKeyCacheKey       CacheKey = "api_keys"
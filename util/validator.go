@@ -47,6 +47,7 @@ func init() {
 			string(datastore.HMacVerifier):      true,
 			string(datastore.BasicAuthVerifier): true,
 			string(datastore.APIKeyVerifier):    true,
+			string(datastore.JWTVerifier):       true,
 		}
 
 		if _, ok := verifiers[verifier]; !ok {
@@ -56,6 +57,37 @@ func init() {
 		return true
 	})
 
+	govalidator.TagMap["supported_event_type_source"] = govalidator.Validator(func(source string) bool {
+		sources := map[string]bool{
+			string(datastore.HeaderEventTypeSource):   true,
+			string(datastore.BodyEventTypeSource):     true,
+			string(datastore.FunctionEventTypeSource): true,
+			string(datastore.ProviderEventTypeSource): true,
+		}
+
+		if _, ok := sources[source]; !ok {
+			return false
+		}
+
+		return true
+	})
+
+	govalidator.TagMap["supported_jwt_algorithm"] = govalidator.Validator(func(alg string) bool {
+		algs := map[string]bool{
+			"HS256": true, "HS384": true, "HS512": true,
+			"RS256": true, "RS384": true, "RS512": true,
+			"PS256": true, "PS384": true, "PS512": true,
+			"ES256": true, "ES384": true, "ES512": true,
+			"EdDSA": true,
+		}
+
+		if _, ok := algs[alg]; !ok {
+			return false
+		}
+
+		return true
+	})
+
 	govalidator.TagMap["supported_encoding"] = govalidator.Validator(func(encoder string) bool {
 		encoders := map[string]bool{
 			string(datastore.Base64Encoding): true,
@@ -0,0 +1,121 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
+	"github.com/frain-dev/convoy/pkg/flatten"
+)
+
+// coalescedCountHeader tells the endpoint how many events a debounced
+// delivery stands for.
+const coalescedCountHeader = "X-Convoy-Coalesced-Count"
+
+// debounceKey reads the subscription's debounce key from the event's
+// payload, it's empty when the payload doesn't have the key. Keys are
+// scoped to the event type so different events for the same entity aren't
+// coalesced together.
+func debounceKey(dc *datastore.DebounceConfiguration, event *datastore.Event) string {
+	var payload interface{}
+	if err := json.Unmarshal(event.Data, &payload); err != nil {
+		return ""
+	}
+
+	flat, err := flatten.Flatten(payload)
+	if err != nil {
+		return ""
+	}
+
+	v, ok := flat[dc.KeyPath]
+	if !ok || v == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%v", event.EventType, v)
+}
+
+// debounce supersedes the scheduled deliveries with the same key as the
+// delivery and folds them into it. The delivery keeps the send time of the
+// earliest one, so the window is anchored to the first event and a steady
+// stream of events can't hold deliveries back forever.
+func debounce(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, s *datastore.Subscription, event *datastore.Event, eventDelivery *datastore.EventDelivery) error {
+	dc := s.DebounceConfig
+
+	key := debounceKey(dc, event)
+	if key == "" {
+		return nil
+	}
+
+	superseded, err := eventDeliveryRepo.SupersedeEventDeliveries(ctx, eventDelivery.ProjectID, s.UID, key, eventDelivery.UID)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(superseded, func(i, j int) bool {
+		return superseded[i].CreatedAt.Before(superseded[j].CreatedAt)
+	})
+
+	metadata := eventDelivery.Metadata
+	sendAt := time.Now().Add(time.Duration(dc.Window) * time.Second)
+	count := uint64(1)
+	payloads := make([]json.RawMessage, 0, len(superseded)+1)
+
+	for _, d := range superseded {
+		if d.Metadata == nil {
+			continue
+		}
+
+		if d.Metadata.NextSendTime.Before(sendAt) {
+			sendAt = d.Metadata.NextSendTime
+		}
+
+		count += max(d.Metadata.CoalescedCount, 1)
+
+		if dc.Mode == datastore.MergeDebounceMode {
+			data, err := claimcheck.Get().DeliveryData(ctx, d.ProjectID, d.Metadata)
+			if err != nil {
+				return err
+			}
+			payloads = append(payloads, data)
+		}
+	}
+
+	if dc.Mode == datastore.MergeDebounceMode && len(payloads) > 0 {
+		merged, err := mergePayloads(append(payloads, metadata.Data))
+		if err != nil {
+			return err
+		}
+
+		metadata.Data = merged
+		metadata.Raw = string(merged)
+	}
+
+	eventDelivery.DebounceKey = key
+	metadata.NextSendTime = sendAt
+	metadata.CoalescedCount = count
+
+	return nil
+}
+
+// mergePayloads merges json objects into one, fields of later payloads win.
+// The last payload is used as is when any of them isn't an object.
+func mergePayloads(payloads []json.RawMessage) (json.RawMessage, error) {
+	merged := map[string]json.RawMessage{}
+	for _, p := range payloads {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(p, &fields); err != nil || fields == nil {
+			return payloads[len(payloads)-1], nil
+		}
+
+		for k, v := range fields {
+			merged[k] = v
+		}
+	}
+
+	return json.Marshal(merged)
+}
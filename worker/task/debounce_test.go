@@ -0,0 +1,100 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+)
+
+func TestDebounceKey(t *testing.T) {
+	dc := &datastore.DebounceConfiguration{KeyPath: "profile.id", Window: 10}
+
+	event := &datastore.Event{EventType: "profile.updated", Data: json.RawMessage(`{"profile":{"id":"p_1"}}`)}
+	require.Equal(t, "profile.updated:p_1", debounceKey(dc, event))
+
+	event = &datastore.Event{EventType: "profile.updated", Data: json.RawMessage(`{"name":"ada"}`)}
+	require.Empty(t, debounceKey(dc, event))
+}
+
+func TestDebounce(t *testing.T) {
+	firstSendTime := time.Now().Add(4 * time.Second)
+
+	tests := []struct {
+		name         string
+		mode         datastore.DebounceMode
+		superseded   []datastore.EventDelivery
+		wantData     string
+		wantCount    uint64
+		wantSendTime func(t *testing.T, sendTime time.Time)
+	}{
+		{
+			name:      "should_start_window_for_first_event",
+			mode:      datastore.LastDebounceMode,
+			wantData:  `{"id":"p_1","email":"ada@example.com"}`,
+			wantCount: 1,
+			wantSendTime: func(t *testing.T, sendTime time.Time) {
+				require.WithinDuration(t, time.Now().Add(10*time.Second), sendTime, time.Second)
+			},
+		},
+		{
+			name: "should_send_last_event_at_end_of_first_window",
+			mode: datastore.LastDebounceMode,
+			superseded: []datastore.EventDelivery{
+				{Metadata: &datastore.Metadata{Data: json.RawMessage(`{"id":"p_1","name":"ada"}`), NextSendTime: firstSendTime, CoalescedCount: 2}},
+			},
+			wantData:  `{"id":"p_1","email":"ada@example.com"}`,
+			wantCount: 3,
+			wantSendTime: func(t *testing.T, sendTime time.Time) {
+				require.Equal(t, firstSendTime, sendTime)
+			},
+		},
+		{
+			name: "should_merge_events",
+			mode: datastore.MergeDebounceMode,
+			superseded: []datastore.EventDelivery{
+				{Metadata: &datastore.Metadata{Data: json.RawMessage(`{"id":"p_1","name":"ada","email":"old@example.com"}`), NextSendTime: firstSendTime}},
+			},
+			wantData:  `{"id":"p_1","name":"ada","email":"ada@example.com"}`,
+			wantCount: 2,
+			wantSendTime: func(t *testing.T, sendTime time.Time) {
+				require.Equal(t, firstSendTime, sendTime)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockEventDeliveryRepository(ctrl)
+			repo.EXPECT().SupersedeEventDeliveries(gomock.Any(), "project-1", "sub-1", "profile.updated:p_1", "delivery-1").
+				Times(1).Return(tt.superseded, nil)
+
+			data := json.RawMessage(`{"id":"p_1","email":"ada@example.com"}`)
+			s := &datastore.Subscription{UID: "sub-1", DebounceConfig: &datastore.DebounceConfiguration{KeyPath: "id", Window: 10, Mode: tt.mode}}
+			event := &datastore.Event{EventType: "profile.updated", Data: data}
+			eventDelivery := &datastore.EventDelivery{
+				UID:       "delivery-1",
+				ProjectID: "project-1",
+				Metadata:  &datastore.Metadata{Data: data, Raw: string(data), NextSendTime: time.Now()},
+			}
+
+			err := debounce(context.Background(), repo, s, event, eventDelivery)
+			require.NoError(t, err)
+
+			require.Equal(t, "profile.updated:p_1", eventDelivery.DebounceKey)
+			require.JSONEq(t, tt.wantData, string(eventDelivery.Metadata.Data))
+			require.JSONEq(t, tt.wantData, eventDelivery.Metadata.Raw)
+			require.Equal(t, tt.wantCount, eventDelivery.Metadata.CoalescedCount)
+			tt.wantSendTime(t, eventDelivery.Metadata.NextSendTime)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+)
+
+// eventVersioner down converts an event's payload to the version of its
+// event type each subscription is pinned to. The event type and its
+// versions are loaded the first time a subscription needs them.
+type eventVersioner struct {
+	repo    datastore.EventTypeRepository
+	project *datastore.Project
+	event   *datastore.Event
+
+	loaded    bool
+	eventType *datastore.ProjectEventType
+	versions  []datastore.EventTypeVersion
+}
+
+func newEventVersioner(repo datastore.EventTypeRepository, project *datastore.Project, event *datastore.Event) *eventVersioner {
+	return &eventVersioner{repo: repo, project: project, event: event}
+}
+
+// pinnedVersion returns the version the subscription is pinned to, the
+// subscription's pin wins over its endpoint's.
+func pinnedVersion(s *datastore.Subscription, eventType string) int {
+	if v, ok := s.EventTypeVersions[eventType]; ok {
+		return v
+	}
+
+	if s.Endpoint != nil {
+		if v, ok := s.Endpoint.EventTypeVersions[eventType]; ok {
+			return v
+		}
+	}
+
+	return 0
+}
+
+// loadEventType fetches the event type the first time it is called.
+func (ev *eventVersioner) loadEventType(ctx context.Context) error {
+	if ev.loaded {
+		return nil
+	}
+
+	eventType, err := ev.repo.FindEventTypeByName(ctx, ev.project.UID, string(ev.event.EventType))
+	if err != nil && !errors.Is(err, datastore.ErrEventTypeNotFound) {
+		return err
+	}
+
+	ev.eventType = eventType
+	ev.loaded = true
+	return nil
+}
+
+// publish sets the version the event is published under to its type's
+// latest, it's called before the event is saved. An event that has one
+// e.g. a replayed event keeps it.
+func (ev *eventVersioner) publish(ctx context.Context) error {
+	if ev.event.EventTypeVersion > 0 {
+		return nil
+	}
+
+	err := ev.loadEventType(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ev.eventType != nil {
+		ev.event.EventTypeVersion = ev.eventType.Version
+	}
+
+	return nil
+}
+
+// published returns the version the event's payload is in, events saved
+// before it was recorded are in the latest.
+func (ev *eventVersioner) published() int {
+	if ev.event.EventTypeVersion > 0 {
+		return ev.event.EventTypeVersion
+	}
+
+	return ev.eventType.Version
+}
+
+// load fetches the event type the first time it is called, and its
+// versions the first time a subscription is pinned to an older one.
+func (ev *eventVersioner) load(ctx context.Context, s *datastore.Subscription) error {
+	err := ev.loadEventType(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ev.eventType == nil || ev.versions != nil {
+		return nil
+	}
+
+	pinned := pinnedVersion(s, ev.eventType.Name)
+	if pinned < 1 || pinned >= ev.published() {
+		return nil
+	}
+
+	versions, err := ev.repo.LoadEventTypeVersions(ctx, ev.project.UID, ev.eventType.UID)
+	if err != nil {
+		return err
+	}
+
+	ev.versions = versions
+	return nil
+}
+
+// convert returns the payload the subscription should receive and the
+// version it is in. The payload is converted from the version the event
+// was published under, so a version added since doesn't change it. The
+// version is zero when the event type isn't in the catalog. load must be
+// called for the subscription first.
+func (ev *eventVersioner) convert(ctx context.Context, s *datastore.Subscription, data json.RawMessage) (json.RawMessage, int, error) {
+	if ev.eventType == nil {
+		return data, 0, nil
+	}
+
+	published := ev.published()
+	pinned := pinnedVersion(s, ev.eventType.Name)
+	if pinned < 1 || pinned >= published {
+		return data, published, nil
+	}
+
+	// the event's creation time keeps conversions stable across retries
+	transformer := functions.NewTransformer(ctx, ev.project.UID).WithTime(ev.event.CreatedAt)
+	converted, err := functions.DownConvert(transformer, ev.versions, published, pinned, data)
+	if err != nil {
+		return nil, pinned, err
+	}
+
+	return converted, pinned, nil
+}
@@ -0,0 +1,82 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/url"
+	"github.com/frain-dev/convoy/util"
+)
+
+// SyncDelivery records an event that was forwarded to an endpoint while
+// the ingest request was held open. The event's delivery to that endpoint
+// is created with the attempt, it is only queued again when it failed.
+type SyncDelivery struct {
+	EventDeliveryID string
+	EndpointID      string
+	Status          datastore.EventDeliveryStatus
+	Attempt         datastore.DeliveryAttempt
+}
+
+// ForwardEvent sends event to endpoint and waits for its response. An error
+// is returned when no response was received, e.g. the request timed out,
+// the caller should then fall back to queueing the event.
+func ForwardEvent(ctx context.Context, dispatch *net.Dispatcher, project *datastore.Project, endpoint *datastore.Endpoint,
+	event *datastore.Event, maxResponseSize int64, timeout time.Duration,
+) (*net.Response, *SyncDelivery, error) {
+	sig := newSignature(endpoint, project, event.Data)
+	header, err := sig.ComputeHeaderValue()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetURL := endpoint.Url
+	if !util.IsStringEmpty(event.URLQueryParams) {
+		targetURL, err = url.ConcatQueryParams(endpoint.Url, event.URLQueryParams)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	headers := event.Headers
+	if endpoint.Authentication != nil && endpoint.Authentication.Type == datastore.APIKeyAuthentication {
+		headers = make(httpheader.HTTPHeader)
+		headers[endpoint.Authentication.ApiKey.HeaderName] = []string{endpoint.Authentication.ApiKey.HeaderValue}
+		headers.MergeHeaders(event.Headers)
+	}
+
+	delivery := &datastore.EventDelivery{UID: ulid.Make().String()}
+	if project.Config.AddEventIDTraceHeaders {
+		traced := httpheader.HTTPHeader{
+			"X-Convoy-EventDelivery-ID": []string{delivery.UID},
+			"X-Convoy-Event-ID":         []string{event.UID},
+		}
+		traced.MergeHeaders(headers)
+		headers = traced
+	}
+
+	resp, err := dispatch.SendRequest(ctx, targetURL, string(convoy.HttpPost), sig.Payload, project.Config.Signature.Header.String(),
+		header, maxResponseSize, headers, event.IdempotencyKey, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	succeeded := resp.StatusCode >= 200 && resp.StatusCode <= 299
+	status := datastore.FailureEventStatus
+	if succeeded {
+		status = datastore.SuccessEventStatus
+	}
+
+	return resp, &SyncDelivery{
+		EventDeliveryID: delivery.UID,
+		EndpointID:      endpoint.UID,
+		Status:          status,
+		Attempt:         parseAttemptFromResponse(delivery, endpoint, resp, succeeded),
+	}, nil
+}
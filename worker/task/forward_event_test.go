@@ -0,0 +1,52 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "true" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		require.NotEmpty(t, r.Header.Get("X-Convoy-Signature"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"text":"pong"}`))
+	}))
+	defer srv.Close()
+
+	dispatch, err := net.NewDispatcher("", false)
+	require.NoError(t, err)
+
+	project := &datastore.Project{UID: "project-id-1", Config: &datastore.DefaultProjectConfig}
+	endpoint := &datastore.Endpoint{
+		UID:     "endpoint-id-1",
+		Url:     srv.URL,
+		Secrets: []datastore.Secret{{Value: "secret"}},
+	}
+
+	event := &datastore.Event{UID: "event-id-1", Data: []byte(`{"command":"/ping"}`)}
+
+	resp, syncDelivery, err := ForwardEvent(context.Background(), dispatch, project, endpoint, event, 1024, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Equal(t, `{"text":"pong"}`, string(resp.Body))
+	require.Equal(t, "endpoint-id-1", syncDelivery.EndpointID)
+	require.Equal(t, datastore.SuccessEventStatus, syncDelivery.Status)
+	require.True(t, syncDelivery.Attempt.Status)
+	require.Equal(t, syncDelivery.EventDeliveryID, syncDelivery.Attempt.MsgID)
+
+	event.URLQueryParams = "slow=true"
+	_, _, err = ForwardEvent(context.Background(), dispatch, project, endpoint, event, 1024, 50*time.Millisecond)
+	require.Error(t, err)
+}
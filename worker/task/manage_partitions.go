@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/rdb"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/go-redsync/redsync/v4"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/hibiken/asynq"
+)
+
+// ManagePartitions creates the daily partitions of the events and event
+// deliveries tables ahead of time, so new rows don't end up in the default
+// partition. Expired partitions are removed by the retention policy job.
+func ManagePartitions(partitionRepo datastore.PartitionRepository, rd *rdb.Redis) func(context.Context, *asynq.Task) error {
+	pool := goredis.NewPool(rd.Client())
+	rs := redsync.New(pool)
+
+	return func(ctx context.Context, t *asynq.Task) error {
+		const mutexName = "convoy:partitions:mutex"
+		mutex := rs.NewMutex(mutexName, redsync.WithExpiry(time.Minute*5), redsync.WithTries(1))
+
+		tctx, cancel := context.WithTimeout(ctx, time.Second*2)
+		defer cancel()
+
+		err := mutex.LockContext(tctx)
+		if err != nil {
+			return fmt.Errorf("failed to obtain lock: %v", err)
+		}
+
+		defer func() {
+			tctx, cancel := context.WithTimeout(ctx, time.Second*2)
+			defer cancel()
+
+			ok, err := mutex.UnlockContext(tctx)
+			if !ok || err != nil {
+				log.WithError(err).Error("failed to release lock")
+			}
+		}()
+
+		cfg, err := config.Get()
+		if err != nil {
+			return err
+		}
+
+		days := cfg.Partition.PremakeDays
+		if days <= 0 {
+			days = config.DefaultPartitionPremakeDays
+		}
+
+		until := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, days+1)
+		created, err := partitionRepo.CreatePartitions(ctx, until)
+		if len(created) > 0 {
+			log.Infof("created partitions %v", created)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		partitions, err := partitionRepo.LoadPartitions(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range partitions {
+			if p.IsDefault && p.Rows > 0 {
+				log.Warnf("default partition %s has about %d rows, partitions for them may be missing", p.Name, p.Rows)
+			}
+		}
+
+		return nil
+	}
+}
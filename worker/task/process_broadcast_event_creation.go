@@ -28,7 +28,7 @@ var (
 	defaultBroadcastDelay   = 30 * time.Second
 )
 
-func ProcessBroadcastEventCreation(endpointRepo datastore.EndpointRepository, eventRepo datastore.EventRepository, projectRepo datastore.ProjectRepository, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, subRepo datastore.SubscriptionRepository, deviceRepo datastore.DeviceRepository, subscriptionsTable memorystore.ITable) func(context.Context, *asynq.Task) error {
+func ProcessBroadcastEventCreation(endpointRepo datastore.EndpointRepository, eventRepo datastore.EventRepository, projectRepo datastore.ProjectRepository, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, subRepo datastore.SubscriptionRepository, deviceRepo datastore.DeviceRepository, eventTypeRepo datastore.EventTypeRepository, subscriptionsTable memorystore.ITable) func(context.Context, *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) (err error) {
 		var broadcastEvent models.BroadcastEvent
 
@@ -82,10 +82,21 @@ func ProcessBroadcastEventCreation(endpointRepo datastore.EndpointRepository, ev
 			return &EndpointError{Err: fmt.Errorf("failed to match subscriptions using filter, err: %s", err.Error()), delay: defaultBroadcastDelay}
 		}
 
+		subscriptions, err = matchSubscriptionsUsingEndpointLabels(ctx, endpointRepo, project.UID, subscriptions)
+		if err != nil {
+			return &EndpointError{Err: fmt.Errorf("failed to match subscriptions using labels, err: %s", err.Error()), delay: defaultBroadcastDelay}
+		}
+
 		es, ss := getEndpointIDs(subscriptions)
 		event.Endpoints = es
 
-		err = eventRepo.CreateEvent(ctx, event)
+		ev := newEventVersioner(eventTypeRepo, project, event)
+		err = ev.publish(ctx)
+		if err != nil {
+			return &EndpointError{Err: fmt.Errorf("CODE: 1005, err: %s", err.Error()), delay: defaultBroadcastDelay}
+		}
+
+		err = saveEvent(ctx, eventRepo, project, event)
 		if err != nil {
 			return &EndpointError{Err: fmt.Errorf("CODE: 1005, err: %s", err.Error()), delay: defaultBroadcastDelay}
 		}
@@ -103,7 +114,7 @@ func ProcessBroadcastEventCreation(endpointRepo datastore.EndpointRepository, ev
 			return nil
 		}
 
-		err = writeEventDeliveriesToQueue(ctx, ss, event, project, eventDeliveryRepo, eventQueue, deviceRepo, endpointRepo)
+		err = writeEventDeliveriesToQueue(ctx, ss, event, project, eventDeliveryRepo, eventQueue, deviceRepo, endpointRepo, ev, nil)
 		if err != nil {
 			log.WithError(err).Error(ErrFailedToWriteToQueue)
 			return &EndpointError{Err: fmt.Errorf("%s, err: %s", ErrFailedToWriteToQueue.Error(), err.Error()), delay: defaultBroadcastDelay}
@@ -135,6 +135,9 @@ func TestProcessBroadcastEventCreation(t *testing.T) {
 				tt.dbFn(args)
 			}
 
+			et, _ := args.eventTypeRepo.(*mocks.MockEventTypeRepository)
+			et.EXPECT().FindEventTypeByName(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil, datastore.ErrEventTypeNotFound)
+
 			payload, err := msgpack.EncodeMsgPack(tt.dynamicEvent)
 			require.NoError(t, err)
 
@@ -146,7 +149,7 @@ func TestProcessBroadcastEventCreation(t *testing.T) {
 
 			fn := ProcessBroadcastEventCreation(args.endpointRepo,
 				args.eventRepo, args.projectRepo, args.eventDeliveryRepo, args.eventQueue, args.subRepo,
-				args.deviceRepo, args.subTable)
+				args.deviceRepo, args.eventTypeRepo, args.subTable)
 			err = fn(context.Background(), task)
 			if tt.wantErr {
 				require.NotNil(t, err)
@@ -23,7 +23,7 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
-func ProcessDynamicEventCreation(endpointRepo datastore.EndpointRepository, eventRepo datastore.EventRepository, projectRepo datastore.ProjectRepository, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, subRepo datastore.SubscriptionRepository, deviceRepo datastore.DeviceRepository) func(context.Context, *asynq.Task) error {
+func ProcessDynamicEventCreation(endpointRepo datastore.EndpointRepository, eventRepo datastore.EventRepository, projectRepo datastore.ProjectRepository, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, subRepo datastore.SubscriptionRepository, deviceRepo datastore.DeviceRepository, eventTypeRepo datastore.EventTypeRepository) func(context.Context, *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
 		var dynamicEvent models.DynamicEvent
 
@@ -73,7 +73,13 @@ func ProcessDynamicEventCreation(endpointRepo datastore.EndpointRepository, even
 			AcknowledgedAt:   null.TimeFrom(time.Now()),
 		}
 
-		err = eventRepo.CreateEvent(ctx, event)
+		ev := newEventVersioner(eventTypeRepo, project, event)
+		err = ev.publish(ctx)
+		if err != nil {
+			return &EndpointError{Err: err, delay: 10 * time.Second}
+		}
+
+		err = saveEvent(ctx, eventRepo, project, event)
 		if err != nil {
 			return &EndpointError{Err: err, delay: 10 * time.Second}
 		}
@@ -85,7 +91,7 @@ func ProcessDynamicEventCreation(endpointRepo datastore.EndpointRepository, even
 
 		return writeEventDeliveriesToQueue(
 			ctx, []datastore.Subscription{*s}, event, project, eventDeliveryRepo,
-			eventQueue, deviceRepo, endpointRepo,
+			eventQueue, deviceRepo, endpointRepo, ev, nil,
 		)
 	}
 }
@@ -181,6 +181,9 @@ func TestProcessDynamicEventCreation(t *testing.T) {
 				tt.dbFn(args)
 			}
 
+			et, _ := args.eventTypeRepo.(*mocks.MockEventTypeRepository)
+			et.EXPECT().FindEventTypeByName(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil, datastore.ErrEventTypeNotFound)
+
 			payload, err := json.Marshal(tt.dynamicEvent)
 			require.NoError(t, err)
 
@@ -190,7 +193,7 @@ func TestProcessDynamicEventCreation(t *testing.T) {
 
 			task := asynq.NewTask(string(convoy.EventProcessor), job.Payload, asynq.Queue(string(convoy.EventQueue)), asynq.ProcessIn(job.Delay))
 
-			fn := ProcessDynamicEventCreation(args.endpointRepo, args.eventRepo, args.projectRepo, args.eventDeliveryRepo, args.eventQueue, args.subRepo, args.deviceRepo)
+			fn := ProcessDynamicEventCreation(args.endpointRepo, args.eventRepo, args.projectRepo, args.eventDeliveryRepo, args.eventQueue, args.subRepo, args.deviceRepo, args.eventTypeRepo)
 			err = fn(context.Background(), task)
 			if tt.wantErr {
 				require.NotNil(t, err)
@@ -11,15 +11,19 @@ import (
 	"github.com/frain-dev/convoy/pkg/flatten"
 
 	"github.com/frain-dev/convoy"
-	"github.com/frain-dev/convoy/pkg/transform"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
+	"github.com/frain-dev/convoy/internal/pkg/functions"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 
 	"github.com/frain-dev/convoy/pkg/msgpack"
 	"github.com/frain-dev/convoy/util"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/celfilter"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/retrystrategies"
 	"github.com/hibiken/asynq"
 	"github.com/oklog/ulid/v2"
 )
@@ -42,12 +46,91 @@ type CreateEvent struct {
 	Params             CreateEventTaskParams
 	Event              *datastore.Event
 	CreateSubscription bool
+
+	// SyncDelivery is set when the event was already forwarded to an
+	// endpoint during ingest.
+	SyncDelivery *SyncDelivery
+
+	// PayloadReference is set when the event's payload was offloaded
+	// during ingest, the job doesn't carry the payload then.
+	PayloadReference string
+}
+
+// OffloadPayload offloads the payload of the event the job creates when
+// it's too large to be carried in the queue, the job is changed to carry
+// its reference instead. The event it was given isn't changed.
+func (c *CreateEvent) OffloadPayload(ctx context.Context) error {
+	var projectID, eventID string
+	var payload []byte
+
+	switch {
+	case c.Event != nil:
+		// the payload is loaded back as both the data and the raw body
+		if len(c.Event.PayloadReference) > 0 || c.Event.Raw != string(c.Event.Data) {
+			return nil
+		}
+		projectID, eventID, payload = c.Event.ProjectID, c.Event.UID, c.Event.Data
+	default:
+		projectID, eventID, payload = c.Params.ProjectID, c.Params.UID, c.Params.Data
+	}
+
+	reference, err := claimcheck.Get().OffloadIngest(ctx, projectID, eventID, payload)
+	if err != nil || len(reference) == 0 {
+		return err
+	}
+
+	if c.Event != nil {
+		e := *c.Event
+		e.Data, e.Raw = nil, ""
+		c.Event = &e
+	} else {
+		c.Params.Data = nil
+	}
+
+	c.PayloadReference = reference
+	return nil
+}
+
+// loadPayload loads the payload the job's event was offloaded with during
+// ingest.
+func (c *CreateEvent) loadPayload(ctx context.Context, projectID string) error {
+	if len(c.PayloadReference) == 0 {
+		return nil
+	}
+
+	payload, err := claimcheck.Get().Load(ctx, projectID, c.PayloadReference)
+	if err != nil {
+		return err
+	}
+
+	if c.Event != nil {
+		c.Event.Data, c.Event.Raw = payload, string(payload)
+	} else {
+		c.Params.Data = payload
+	}
+
+	return nil
+}
+
+// removePayload removes the object the job's event was offloaded to during
+// ingest once the event is created, the retention policy removes the ones
+// left behind.
+func (c *CreateEvent) removePayload(ctx context.Context) {
+	if len(c.PayloadReference) == 0 {
+		return
+	}
+
+	err := claimcheck.Get().Delete(ctx, c.PayloadReference)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("failed to remove the ingested payload %s", c.PayloadReference)
+	}
 }
 
 func ProcessEventCreation(
 	endpointRepo datastore.EndpointRepository, eventRepo datastore.EventRepository, projectRepo datastore.ProjectRepository,
 	eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer,
 	subRepo datastore.SubscriptionRepository, deviceRepo datastore.DeviceRepository,
+	eventTypeRepo datastore.EventTypeRepository,
 ) func(context.Context, *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
 		var createEvent CreateEvent
@@ -73,6 +156,11 @@ func ProcessEventCreation(
 			return &EndpointError{Err: err, delay: defaultDelay}
 		}
 
+		err = createEvent.loadPayload(ctx, project.UID)
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
 		if createEvent.Event == nil {
 			event, err = buildEvent(ctx, eventRepo, endpointRepo, &createEvent.Params, project)
 			if err != nil {
@@ -80,6 +168,12 @@ func ProcessEventCreation(
 			}
 		} else {
 			event = createEvent.Event
+
+			// replayed events don't carry their offloaded payload
+			err = claimcheck.Get().LoadEvent(ctx, event)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
 		}
 
 		subscriptions, err := findSubscriptions(ctx, endpointRepo, subRepo, project, event, createEvent.CreateSubscription)
@@ -87,7 +181,13 @@ func ProcessEventCreation(
 			return &EndpointError{Err: err, delay: defaultDelay}
 		}
 
-		_, err = eventRepo.FindEventByID(ctx, project.UID, event.UID)
+		subscriptions, err = withSyncSubscription(ctx, endpointRepo, subRepo, project, event, subscriptions, createEvent.SyncDelivery)
+		if err != nil {
+			return err
+		}
+
+		ev := newEventVersioner(eventTypeRepo, project, event)
+		stored, err := eventRepo.FindEventByID(ctx, project.UID, event.UID)
 		if err != nil {
 			if len(event.Endpoints) < 1 {
 				var endpointIDs []string
@@ -99,28 +199,49 @@ func ProcessEventCreation(
 				event.Endpoints = endpointIDs
 			}
 
-			err = eventRepo.CreateEvent(ctx, event)
+			err = ev.publish(ctx)
 			if err != nil {
 				return &EndpointError{Err: err, delay: defaultDelay}
 			}
+
+			err = saveEvent(ctx, eventRepo, project, event)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+		} else if stored != nil {
+			// a replayed event is converted from the version it was
+			// published under
+			event.EventTypeVersion = stored.EventTypeVersion
 		}
 
 		if event.IsDuplicateEvent {
 			log.FromContext(ctx).Infof("[asynq]: duplicate event with idempotency key %v will not be sent", event.IdempotencyKey)
+			createEvent.removePayload(ctx)
 			return nil
 		}
 
-		return writeEventDeliveriesToQueue(
+		err = writeEventDeliveriesToQueue(
 			ctx, subscriptions, event, project, eventDeliveryRepo,
-			eventQueue, deviceRepo, endpointRepo,
+			eventQueue, deviceRepo, endpointRepo, ev, createEvent.SyncDelivery,
 		)
+		if err != nil {
+			return err
+		}
+
+		createEvent.removePayload(ctx)
+		return nil
 	}
 }
 
-func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.Subscription, event *datastore.Event, project *datastore.Project, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, deviceRepo datastore.DeviceRepository, endpointRepo datastore.EndpointRepository) error {
+func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.Subscription, event *datastore.Event, project *datastore.Project, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, deviceRepo datastore.DeviceRepository, endpointRepo datastore.EndpointRepository, ev *eventVersioner, syncDelivery *SyncDelivery) error {
 	ec := &EventDeliveryConfig{project: project}
 
+	var synced bool
 	eventDeliveries := make([]*datastore.EventDelivery, 0)
+
+	// debounced holds the subscription of each delivery to debounce by
+	// the delivery's index
+	debounced := map[int]*datastore.Subscription{}
 	for _, s := range subscriptions {
 		ec.subscription = &s
 		headers := event.Headers
@@ -135,12 +256,7 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 				return &EndpointError{Err: fmt.Errorf("CODE: 1006, err: %s", err.Error()), delay: defaultDelay}
 			}
 
-			if endpoint.Authentication != nil && endpoint.Authentication.Type == datastore.APIKeyAuthentication {
-				headers = make(httpheader.HTTPHeader)
-				headers[endpoint.Authentication.ApiKey.HeaderName] = []string{endpoint.Authentication.ApiKey.HeaderValue}
-				headers.MergeHeaders(event.Headers)
-			}
-
+			headers = endpointHeaders(endpoint, event.Headers)
 			s.Endpoint = endpoint
 		}
 
@@ -149,29 +265,52 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 			return &EndpointError{Err: err, delay: defaultDelay}
 		}
 
+		err = ev.load(ctx, &s)
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
 		raw := event.Raw
 		data := event.Data
+		var transformErr error
+
+		// endpoints pinned to an older version of the event type get the
+		// payload down converted to it
+		converted, version, convertErr := ev.convert(ctx, &s, event.Data)
+		if convertErr != nil {
+			log.FromContext(ctx).WithError(convertErr).Errorf("failed to down convert event %s for subscription %s", event.UID, s.UID)
+		} else if version > 0 && version < ev.eventType.Version {
+			raw = string(converted)
+			data = converted
+		}
 
-		if s.Function.Ptr() != nil && !util.IsStringEmpty(s.Function.String) {
+		if convertErr == nil && s.Function.Ptr() != nil && !util.IsStringEmpty(s.Function.String) {
 			var payload map[string]interface{}
-			err = json.Unmarshal(event.Data, &payload)
+			err = json.Unmarshal(data, &payload)
 			if err != nil {
 				return &EndpointError{Err: err, delay: 10 * time.Second}
 			}
 
-			transformer := transform.NewTransformer()
+			// the event's creation time keeps deterministic transforms
+			// stable across retries and replays, a pinned function runs
+			// the libraries it was pinned with
+			transformer := functions.NewPinnedTransformer(ctx, project.UID, s.FunctionLibraries).WithTime(event.CreatedAt)
 			mutated, _, err := transformer.Transform(s.Function.String, payload)
-			if err != nil {
-				return &EndpointError{Err: err, delay: 10 * time.Second}
+			if err == nil {
+				var bytes []byte
+				bytes, err = json.Marshal(mutated)
+				if err == nil {
+					raw = string(bytes)
+					data = bytes
+				}
 			}
 
-			bytes, err := json.Marshal(mutated)
+			// retrying won't fix a failing function, so the failure is
+			// recorded on the delivery instead
 			if err != nil {
-				return &EndpointError{Err: err, delay: 10 * time.Second}
+				log.FromContext(ctx).WithError(err).Errorf("failed to transform event %s for subscription %s", event.UID, s.UID)
+				transformErr = err
 			}
-
-			raw = string(bytes)
-			data = bytes
 		}
 
 		metadata := &datastore.Metadata{
@@ -200,6 +339,57 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 			AcknowledgedAt:   null.TimeFrom(time.Now()),
 		}
 
+		if version > 0 {
+			eventDelivery.EventTypeVersion = null.IntFrom(int64(version))
+		}
+
+		if convertErr != nil {
+			eventDelivery.Status = datastore.FailureEventStatus
+			eventDelivery.Description = fmt.Sprintf("down conversion to version %d failed: %v", version, convertErr)
+		}
+
+		if transformErr != nil {
+			eventDelivery.Status = datastore.FailureEventStatus
+			eventDelivery.Description = fmt.Sprintf("transform failed: %v", transformErr)
+		}
+
+		// only scheduled deliveries are debounced, the one sent during
+		// ingest below has already gone out. The attempt is recorded on
+		// the endpoint's first delivery only.
+		isSynced := syncDelivery != nil && s.EndpointID == syncDelivery.EndpointID && !synced
+		if s.Type == datastore.SubscriptionTypeAPI && s.DebounceConfig != nil && s.DebounceConfig.Window > 0 &&
+			eventDelivery.Status == datastore.ScheduledEventStatus && !isSynced {
+			sub := s
+			debounced[len(eventDeliveries)] = &sub
+		}
+
+		// the event was forwarded to this endpoint during ingest, record
+		// the attempt rather than sending it again
+		if isSynced {
+			synced = true
+			eventDelivery.UID = syncDelivery.EventDeliveryID
+			eventDelivery.Status = syncDelivery.Status
+			eventDelivery.DeliveryAttempts = []datastore.DeliveryAttempt{redact.ForProject(project).Attempt(syncDelivery.Attempt)}
+			eventDelivery.Metadata.NumTrials = 1
+
+			// a failed forward is retried like any other delivery
+			if syncDelivery.Status != datastore.SuccessEventStatus {
+				if metadata.NumTrials < metadata.RetryLimit {
+					delay := retrystrategies.NewRetryStrategyFromMetadata(*metadata).NextDuration(0)
+					eventDelivery.Status = datastore.RetryEventStatus
+					metadata.NextSendTime = time.Now().Add(delay)
+				} else {
+					eventDelivery.Status = datastore.FailureEventStatus
+					eventDelivery.Description = "Retry limit exceeded"
+				}
+			}
+
+			err = redactFinishedDelivery(ctx, project, eventDelivery)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+		}
+
 		if s.Type == datastore.SubscriptionTypeCLI {
 			event.Endpoints = []string{}
 			eventDelivery.CLIMetadata = &datastore.CLIMetadata{
@@ -211,14 +401,57 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 		eventDeliveries = append(eventDeliveries, eventDelivery)
 	}
 
-	err := eventDeliveryRepo.CreateEventDeliveries(ctx, eventDeliveries)
+	createEventDeliveries := func(ctx context.Context) error {
+		for i, s := range debounced {
+			err := debounce(ctx, eventDeliveryRepo, s, event, eventDeliveries[i])
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+		}
+
+		for _, eventDelivery := range eventDeliveries {
+			err := claimcheck.Get().OffloadDelivery(ctx, event, eventDelivery)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+		}
+
+		err := eventDeliveryRepo.CreateEventDeliveries(ctx, eventDeliveries)
+		if err != nil {
+			return &EndpointError{Err: fmt.Errorf("CODE: 1008, err: %s", err.Error()), delay: defaultDelay}
+		}
+
+		return nil
+	}
+
+	// the deliveries a debounced delivery supersedes are only superseded
+	// if it's created, otherwise their events would be lost
+	var err error
+	if len(debounced) == 0 {
+		err = createEventDeliveries(ctx)
+	} else {
+		err = eventDeliveryRepo.RunInTransaction(ctx, createEventDeliveries)
+	}
+
 	if err != nil {
-		return &EndpointError{Err: fmt.Errorf("CODE: 1008, err: %s", err.Error()), delay: defaultDelay}
+		var endpointErr *EndpointError
+		if errors.As(err, &endpointErr) {
+			return endpointErr
+		}
+
+		return &EndpointError{Err: err, delay: defaultDelay}
 	}
 
 	for i, eventDelivery := range eventDeliveries {
 		s := subscriptions[i]
-		if eventDelivery.Status != datastore.DiscardedEventStatus {
+
+		// deliveries that failed while being created, like failed
+		// transforms, are routed to their fallback too
+		queueFallbackDelivery(ctx, eventQueue, eventDelivery)
+
+		// only the delivery forwarded during ingest can have succeeded
+		if eventDelivery.Status != datastore.DiscardedEventStatus && eventDelivery.Status != datastore.FailureEventStatus &&
+			eventDelivery.Status != datastore.SuccessEventStatus {
 			payload := EventDelivery{
 				EventDeliveryID: eventDelivery.UID,
 				ProjectID:       eventDelivery.ProjectID,
@@ -229,10 +462,16 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 				return &EndpointError{Err: err, delay: defaultDelay}
 			}
 
+			// debounced deliveries wait for their window to close
+			delay := 1 * time.Second
+			if d := time.Until(eventDelivery.Metadata.NextSendTime); d > delay {
+				delay = d
+			}
+
 			job := &queue.Job{
 				ID:      eventDelivery.UID,
 				Payload: data,
-				Delay:   1 * time.Second,
+				Delay:   delay,
 			}
 
 			if s.Type == datastore.SubscriptionTypeAPI {
@@ -252,6 +491,32 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 	return nil
 }
 
+// endpointHeaders returns the headers deliveries to the endpoint are sent
+// with, the event's headers and the endpoint's api key.
+func endpointHeaders(endpoint *datastore.Endpoint, headers httpheader.HTTPHeader) httpheader.HTTPHeader {
+	if endpoint.Authentication == nil || endpoint.Authentication.Type != datastore.APIKeyAuthentication {
+		return headers
+	}
+
+	h := make(httpheader.HTTPHeader)
+	h[endpoint.Authentication.ApiKey.HeaderName] = []string{endpoint.Authentication.ApiKey.HeaderValue}
+	h.MergeHeaders(headers)
+	return h
+}
+
+// saveEvent saves the event redacted with the project's rules, its payload
+// is offloaded when it's too large to keep in the database.
+func saveEvent(ctx context.Context, eventRepo datastore.EventRepository, project *datastore.Project, event *datastore.Event) error {
+	stored := redact.ForProject(project).Event(event)
+
+	err := claimcheck.Get().OffloadEvent(ctx, stored)
+	if err != nil {
+		return err
+	}
+
+	return eventRepo.CreateEvent(ctx, stored)
+}
+
 func findSubscriptions(ctx context.Context, endpointRepo datastore.EndpointRepository,
 	subRepo datastore.SubscriptionRepository, project *datastore.Project, event *datastore.Event, shouldCreateSubscription bool,
 ) ([]datastore.Subscription, error) {
@@ -290,6 +555,8 @@ func findSubscriptions(ctx context.Context, endpointRepo datastore.EndpointRepos
 				return subscriptions, &EndpointError{Err: errors.New("error fetching subscriptions for event type"), delay: defaultDelay}
 			}
 
+			subs = matchSubscriptionsUsingLabels(endpoint, subs)
+
 			subscriptions = append(subscriptions, subs...)
 		}
 	} else if project.Type == datastore.IncomingProject {
@@ -303,6 +570,11 @@ func findSubscriptions(ctx context.Context, endpointRepo datastore.EndpointRepos
 			log.WithError(err).Error("error find a matching subscription for this source")
 			return subscriptions, &EndpointError{Err: errors.New("error find a matching subscription for this source"), delay: defaultDelay}
 		}
+
+		subscriptions, err = matchSubscriptionsUsingEndpointLabels(ctx, endpointRepo, project.UID, subscriptions)
+		if err != nil {
+			return nil, &EndpointError{Err: err, delay: defaultDelay}
+		}
 	}
 
 	return subscriptions, nil
@@ -333,11 +605,18 @@ func matchSubscriptionsUsingFilter(ctx context.Context, e *datastore.Event, subR
 	}
 
 	headers := e.GetRawHeaders()
+
+	flatMetadata, err := flatten.Flatten(map[string]interface{}(e.Metadata))
+	if err != nil {
+		return nil, err
+	}
+
 	var s *datastore.Subscription
 
 	for i := range subscriptions {
 		s = &subscriptions[i]
-		if len(s.FilterConfig.Filter.Body) == 0 && len(s.FilterConfig.Filter.Headers) == 0 {
+		if len(s.FilterConfig.Filter.Body) == 0 && len(s.FilterConfig.Filter.Headers) == 0 &&
+			len(s.FilterConfig.Filter.Metadata) == 0 && util.IsStringEmpty(s.FilterConfig.Filter.Expression) {
 			matched = append(matched, *s)
 			continue
 		}
@@ -358,7 +637,38 @@ func matchSubscriptionsUsingFilter(ctx context.Context, e *datastore.Event, subR
 			return nil, err
 		}
 
-		isMatched := isHeaderMatched && isBodyMatched
+		isMetadataMatched := true
+		if len(s.FilterConfig.Filter.Metadata) > 0 {
+			isMetadataMatched, err = subRepo.CompareFlattenedPayload(ctx, flatMetadata, s.FilterConfig.Filter.Metadata, s.FilterConfig.Filter.IsFlattened)
+			if err != nil && soft {
+				log.WithError(err).Errorf("subscription (%s) failed to match metadata", s.UID)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		isExpressionMatched := true
+		if !util.IsStringEmpty(s.FilterConfig.Filter.Expression) {
+			program, err := celfilter.DefaultCache.Program(s.FilterConfig.Filter.Expression)
+			if err != nil && soft {
+				log.WithError(err).Errorf("subscription (%s) failed to match expression", s.UID)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			in := celfilter.Input{Body: payload, Headers: headers, EventType: string(e.EventType), Source: e.SourceID}
+
+			// evaluation errors depend on the payload, e.g. a missing
+			// field, so they're a non-match rather than a failure.
+			isExpressionMatched, err = celfilter.Match(program, in)
+			if err != nil {
+				log.WithError(err).Debugf("subscription (%s) expression did not evaluate", s.UID)
+			}
+		}
+
+		isMatched := isHeaderMatched && isBodyMatched && isMetadataMatched && isExpressionMatched
 
 		if isMatched {
 			matched = append(matched, *s)
@@ -368,6 +678,51 @@ func matchSubscriptionsUsingFilter(ctx context.Context, e *datastore.Event, subR
 	return matched, nil
 }
 
+// matchSubscriptionsUsingLabels returns the subscriptions whose filter's
+// label selector matches the labels of their endpoint.
+func matchSubscriptionsUsingLabels(endpoint *datastore.Endpoint, subscriptions []datastore.Subscription) []datastore.Subscription {
+	var matched []datastore.Subscription
+	for _, s := range subscriptions {
+		if s.FilterConfig == nil || s.FilterConfig.Filter.Labels.Matches(endpoint.Labels) {
+			matched = append(matched, s)
+		}
+	}
+
+	return matched
+}
+
+// matchSubscriptionsUsingEndpointLabels is matchSubscriptionsUsingLabels for
+// subscriptions of different endpoints, which are only fetched when the
+// subscription has a label selector.
+func matchSubscriptionsUsingEndpointLabels(ctx context.Context, endpointRepo datastore.EndpointRepository, projectID string, subscriptions []datastore.Subscription) ([]datastore.Subscription, error) {
+	var matched []datastore.Subscription
+	for _, s := range subscriptions {
+		if s.FilterConfig == nil || len(s.FilterConfig.Filter.Labels) == 0 {
+			matched = append(matched, s)
+			continue
+		}
+
+		// only endpoints have labels
+		if util.IsStringEmpty(s.EndpointID) {
+			continue
+		}
+
+		endpoint, err := endpointRepo.FindEndpointByID(ctx, s.EndpointID, projectID)
+		if err != nil {
+			if errors.Is(err, datastore.ErrEndpointNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		if s.FilterConfig.Filter.Labels.Matches(endpoint.Labels) {
+			matched = append(matched, s)
+		}
+	}
+
+	return matched, nil
+}
+
 func matchSubscriptions(eventType string, subscriptions []datastore.Subscription) []datastore.Subscription {
 	var matched []datastore.Subscription
 	for _, sub := range subscriptions {
@@ -405,6 +760,61 @@ func getEventDeliveryStatus(ctx context.Context, subscription *datastore.Subscri
 	return datastore.ScheduledEventStatus
 }
 
+// withSyncSubscription makes sure the attempt of an event forwarded during
+// ingest is recorded, it is stored on a delivery and deliveries need a
+// subscription. When the event didn't match one of the endpoint's
+// subscriptions another of them is used, or one is created for it.
+func withSyncSubscription(ctx context.Context, endpointRepo datastore.EndpointRepository, subRepo datastore.SubscriptionRepository,
+	project *datastore.Project, event *datastore.Event, subscriptions []datastore.Subscription, syncDelivery *SyncDelivery,
+) ([]datastore.Subscription, error) {
+	if syncDelivery == nil {
+		return subscriptions, nil
+	}
+
+	for _, s := range subscriptions {
+		if s.EndpointID == syncDelivery.EndpointID {
+			return subscriptions, nil
+		}
+	}
+
+	subs, err := subRepo.FindSubscriptionsByEndpointID(ctx, project.UID, syncDelivery.EndpointID)
+	if err != nil {
+		return nil, &EndpointError{Err: err, delay: defaultDelay}
+	}
+
+	var sub *datastore.Subscription
+	for i := range subs {
+		if subs[i].Type != datastore.SubscriptionTypeAPI {
+			continue
+		}
+
+		if sub == nil || subs[i].SourceID == event.SourceID {
+			sub = &subs[i]
+		}
+	}
+
+	if sub == nil {
+		endpoint, err := endpointRepo.FindEndpointByID(ctx, syncDelivery.EndpointID, project.UID)
+		if err != nil {
+			if errors.Is(err, datastore.ErrEndpointNotFound) {
+				log.FromContext(ctx).Warnf("sync forwarding endpoint %s of event %s no longer exists, its attempt is dropped", syncDelivery.EndpointID, event.UID)
+				return subscriptions, nil
+			}
+
+			return nil, &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		sub = generateSubscription(project, endpoint)
+		sub.SourceID = event.SourceID
+		err = subRepo.CreateSubscription(ctx, project.UID, sub)
+		if err != nil {
+			return nil, &EndpointError{Err: errors.New("error creating subscription for endpoint"), delay: defaultDelay}
+		}
+	}
+
+	return append(subscriptions, *sub), nil
+}
+
 func generateSubscription(project *datastore.Project, endpoint *datastore.Endpoint) *datastore.Subscription {
 	return &datastore.Subscription{
 		ProjectID:  project.UID,
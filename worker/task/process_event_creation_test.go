@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/internal/pkg/memorystore"
 
 	"github.com/frain-dev/convoy"
@@ -18,6 +20,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"gopkg.in/guregu/null.v4"
 )
 
 type args struct {
@@ -30,6 +33,7 @@ type args struct {
 	eventQueue        queue.Queuer
 	subRepo           datastore.SubscriptionRepository
 	deviceRepo        datastore.DeviceRepository
+	eventTypeRepo     datastore.EventTypeRepository
 	subTable          memorystore.ITable
 }
 
@@ -44,6 +48,7 @@ func provideArgs(ctrl *gomock.Controller) *args {
 	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
 	db := mocks.NewMockDatabase(ctrl)
 	subTable := mocks.NewMockITable(ctrl)
+	eventTypeRepo := mocks.NewMockEventTypeRepository(ctrl)
 
 	return &args{
 		endpointRepo:      endpointRepo,
@@ -55,6 +60,7 @@ func provideArgs(ctrl *gomock.Controller) *args {
 		cache:             mockCache,
 		eventQueue:        mockQueuer,
 		subRepo:           subRepo,
+		eventTypeRepo:     eventTypeRepo,
 		subTable:          subTable,
 	}
 }
@@ -143,6 +149,239 @@ func TestProcessEventCreated(t *testing.T) {
 			wantErr: false,
 		},
 
+		{
+			name: "should_record_failed_transform_on_event_delivery",
+			event: &CreateEvent{
+				Params: CreateEventTaskParams{
+					UID:        ulid.Make().String(),
+					EventType:  "*",
+					SourceID:   "source-id-1",
+					ProjectID:  "project-id-1",
+					EndpointID: "endpoint-id-1",
+					Data:       []byte(`{"key": "value"}`),
+				},
+			},
+			dbFn: func(args *args) {
+				project := &datastore.Project{
+					UID:  "project-id-1",
+					Type: datastore.OutgoingProject,
+					Config: &datastore.ProjectConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   10,
+							RetryCount: 3,
+						},
+					},
+				}
+
+				g, _ := args.projectRepo.(*mocks.MockProjectRepository)
+				g.EXPECT().FetchProjectByID(gomock.Any(), "project-id-1").Times(1).Return(project, nil)
+
+				a, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+				endpoint := &datastore.Endpoint{UID: "endpoint-id-1", Url: "https://google.com", Status: datastore.ActiveEndpointStatus}
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).Times(3).Return(endpoint, nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				subscriptions := []datastore.Subscription{
+					{
+						UID:        "456",
+						EndpointID: "endpoint-id-1",
+						Type:       datastore.SubscriptionTypeAPI,
+						Function:   null.StringFrom(`function transform(payload) { throw new Error("boom") }`),
+						FilterConfig: &datastore.FilterConfiguration{
+							EventTypes: []string{"*"},
+						},
+					},
+				}
+				s.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-id-1", "endpoint-id-1").Times(1).Return(subscriptions, nil)
+				s.EXPECT().CompareFlattenedPayload(gomock.Any(), gomock.Any(), gomock.Any(), false).AnyTimes().Return(true, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, datastore.ErrEventNotFound)
+				e.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) error {
+						require.Len(t, deliveries, 1)
+						require.Equal(t, datastore.FailureEventStatus, deliveries[0].Status)
+						require.Contains(t, deliveries[0].Description, "transform failed")
+						require.JSONEq(t, `{"key": "value"}`, string(deliveries[0].Metadata.Data))
+						return nil
+					})
+
+				// failed deliveries aren't sent, they're routed to their
+				// fallback
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.FallbackDeliveryProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+				q.EXPECT().Write(convoy.EventProcessor, gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: false,
+		},
+
+		{
+			name: "should_down_convert_event_for_pinned_endpoint",
+			event: &CreateEvent{
+				Params: CreateEventTaskParams{
+					UID:        ulid.Make().String(),
+					EventType:  "invoice.paid",
+					SourceID:   "source-id-1",
+					ProjectID:  "project-id-1",
+					EndpointID: "endpoint-id-1",
+					Data:       []byte(`{"amount": {"value": 100, "currency": "NGN"}}`),
+				},
+			},
+			dbFn: func(args *args) {
+				project := &datastore.Project{
+					UID:  "project-id-1",
+					Type: datastore.OutgoingProject,
+					Config: &datastore.ProjectConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   10,
+							RetryCount: 3,
+						},
+					},
+				}
+
+				g, _ := args.projectRepo.(*mocks.MockProjectRepository)
+				g.EXPECT().FetchProjectByID(gomock.Any(), "project-id-1").Times(1).Return(project, nil)
+
+				a, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+				endpoint := &datastore.Endpoint{
+					UID:               "endpoint-id-1",
+					Url:               "https://google.com",
+					Status:            datastore.ActiveEndpointStatus,
+					EventTypeVersions: datastore.EventTypeVersions{"invoice.paid": 1},
+				}
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).Times(3).Return(endpoint, nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				subscriptions := []datastore.Subscription{
+					{
+						UID:        "456",
+						EndpointID: "endpoint-id-1",
+						Type:       datastore.SubscriptionTypeAPI,
+						FilterConfig: &datastore.FilterConfiguration{
+							EventTypes: []string{"*"},
+						},
+					},
+				}
+				s.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-id-1", "endpoint-id-1").Times(1).Return(subscriptions, nil)
+				s.EXPECT().CompareFlattenedPayload(gomock.Any(), gomock.Any(), gomock.Any(), false).AnyTimes().Return(true, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, datastore.ErrEventNotFound)
+				e.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, event *datastore.Event) error {
+						require.Equal(t, 2, event.EventTypeVersion)
+						return nil
+					})
+
+				et, _ := args.eventTypeRepo.(*mocks.MockEventTypeRepository)
+				et.EXPECT().FindEventTypeByName(gomock.Any(), "project-id-1", "invoice.paid").Times(1).
+					Return(&datastore.ProjectEventType{UID: "event-type-1", Name: "invoice.paid", Version: 2}, nil)
+				et.EXPECT().LoadEventTypeVersions(gomock.Any(), "project-id-1", "event-type-1").Times(1).
+					Return([]datastore.EventTypeVersion{
+						{Version: 2, DownConverter: `function transform(payload) { payload.amount = payload.amount.value; return payload }`},
+						{Version: 1},
+					}, nil)
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) error {
+						require.Len(t, deliveries, 1)
+						require.Equal(t, int64(1), deliveries[0].EventTypeVersion.Int64)
+						require.JSONEq(t, `{"amount": 100}`, string(deliveries[0].Metadata.Data))
+						return nil
+					})
+
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+			},
+			wantErr: false,
+		},
+
+		{
+			name: "should_down_convert_replayed_event_from_its_published_version",
+			event: &CreateEvent{
+				Params: CreateEventTaskParams{
+					UID:        ulid.Make().String(),
+					EventType:  "invoice.paid",
+					SourceID:   "source-id-1",
+					ProjectID:  "project-id-1",
+					EndpointID: "endpoint-id-1",
+					Data:       []byte(`{"amount": {"value": 100, "currency": "NGN"}}`),
+				},
+			},
+			dbFn: func(args *args) {
+				project := &datastore.Project{
+					UID:  "project-id-1",
+					Type: datastore.OutgoingProject,
+					Config: &datastore.ProjectConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   10,
+							RetryCount: 3,
+						},
+					},
+				}
+
+				g, _ := args.projectRepo.(*mocks.MockProjectRepository)
+				g.EXPECT().FetchProjectByID(gomock.Any(), "project-id-1").Times(1).Return(project, nil)
+
+				a, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+				endpoint := &datastore.Endpoint{
+					UID:               "endpoint-id-1",
+					Url:               "https://google.com",
+					Status:            datastore.ActiveEndpointStatus,
+					EventTypeVersions: datastore.EventTypeVersions{"invoice.paid": 1},
+				}
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).Times(3).Return(endpoint, nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				subscriptions := []datastore.Subscription{
+					{
+						UID:        "456",
+						EndpointID: "endpoint-id-1",
+						Type:       datastore.SubscriptionTypeAPI,
+						FilterConfig: &datastore.FilterConfiguration{
+							EventTypes: []string{"*"},
+						},
+					},
+				}
+				s.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-id-1", "endpoint-id-1").Times(1).Return(subscriptions, nil)
+				s.EXPECT().CompareFlattenedPayload(gomock.Any(), gomock.Any(), gomock.Any(), false).AnyTimes().Return(true, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				// the event was published before version 3 was added
+				e.EXPECT().FindEventByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&datastore.Event{EventTypeVersion: 2}, nil)
+
+				et, _ := args.eventTypeRepo.(*mocks.MockEventTypeRepository)
+				et.EXPECT().FindEventTypeByName(gomock.Any(), "project-id-1", "invoice.paid").Times(1).
+					Return(&datastore.ProjectEventType{UID: "event-type-1", Name: "invoice.paid", Version: 3}, nil)
+				et.EXPECT().LoadEventTypeVersions(gomock.Any(), "project-id-1", "event-type-1").Times(1).
+					Return([]datastore.EventTypeVersion{
+						{Version: 3, DownConverter: `function transform(payload) { payload.amount = payload.amount.total; return payload }`},
+						{Version: 2, DownConverter: `function transform(payload) { payload.amount = payload.amount.value; return payload }`},
+						{Version: 1},
+					}, nil)
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) error {
+						require.Len(t, deliveries, 1)
+						require.Equal(t, int64(1), deliveries[0].EventTypeVersion.Int64)
+						require.JSONEq(t, `{"amount": 100}`, string(deliveries[0].Metadata.Data))
+						return nil
+					})
+
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+			},
+			wantErr: false,
+		},
+
 		{
 			name: "should_process_event_for_outgoing_project_without_subscription",
 			event: &CreateEvent{
@@ -447,6 +686,143 @@ func TestProcessEventCreated(t *testing.T) {
 			},
 			wantErr: false,
 		},
+
+		{
+			name: "should_record_and_retry_failed_sync_delivery",
+			event: &CreateEvent{
+				Event: &datastore.Event{
+					UID:       ulid.Make().String(),
+					EventType: "*",
+					SourceID:  "source-id-1",
+					ProjectID: "project-id-1",
+					Data:      []byte(`{}`),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				SyncDelivery: &SyncDelivery{
+					EventDeliveryID: "sync-delivery-id",
+					EndpointID:      "endpoint-id-2",
+					Status:          datastore.FailureEventStatus,
+					Attempt:         datastore.DeliveryAttempt{UID: "attempt-id", HttpResponseCode: "500"},
+				},
+			},
+			dbFn: func(args *args) {
+				project := &datastore.Project{
+					UID:  "project-id-1",
+					Type: datastore.IncomingProject,
+					Config: &datastore.ProjectConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   10,
+							RetryCount: 3,
+						},
+					},
+				}
+
+				g, _ := args.projectRepo.(*mocks.MockProjectRepository)
+				g.EXPECT().FetchProjectByID(gomock.Any(), "project-id-1").Times(1).Return(project, nil)
+
+				// the event matched none of the sync endpoint's subscriptions
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionsBySourceID(gomock.Any(), "project-id-1", "source-id-1").Times(1).Return(nil, nil)
+				s.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-id-1", "endpoint-id-2").Times(1).Return([]datastore.Subscription{
+					{UID: "789", EndpointID: "endpoint-id-2", SourceID: "source-id-1", Type: datastore.SubscriptionTypeAPI},
+				}, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+
+				a, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+				endpoint := &datastore.Endpoint{UID: "endpoint-id-2", Url: "https://google.com", Status: datastore.ActiveEndpointStatus}
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-2", gomock.Any()).Times(1).Return(endpoint, nil)
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, deliveries []*datastore.EventDelivery) error {
+						require.Len(t, deliveries, 1)
+						require.Equal(t, "sync-delivery-id", deliveries[0].UID)
+						require.Equal(t, "789", deliveries[0].SubscriptionID)
+						require.Equal(t, datastore.RetryEventStatus, deliveries[0].Status)
+						require.Len(t, deliveries[0].DeliveryAttempts, 1)
+						require.Equal(t, uint64(1), deliveries[0].Metadata.NumTrials)
+						return nil
+					})
+
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "should_supersede_and_create_debounced_delivery_in_one_transaction",
+			event: &CreateEvent{
+				Event: &datastore.Event{
+					UID:       ulid.Make().String(),
+					EventType: "profile.updated",
+					SourceID:  "source-id-1",
+					ProjectID: "project-id-1",
+					Data:      []byte(`{"id":"p_1"}`),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+			},
+			dbFn: func(args *args) {
+				project := &datastore.Project{
+					UID:  "project-id-1",
+					Type: datastore.IncomingProject,
+					Config: &datastore.ProjectConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   10,
+							RetryCount: 3,
+						},
+					},
+				}
+
+				g, _ := args.projectRepo.(*mocks.MockProjectRepository)
+				g.EXPECT().FetchProjectByID(gomock.Any(), "project-id-1").Times(1).Return(project, nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionsBySourceID(gomock.Any(), "project-id-1", "source-id-1").Times(1).Return([]datastore.Subscription{
+					{
+						UID:            "456",
+						EndpointID:     "endpoint-id-1",
+						ProjectID:      "project-id-1",
+						Type:           datastore.SubscriptionTypeAPI,
+						FilterConfig:   &datastore.FilterConfiguration{EventTypes: []string{"*"}},
+						DebounceConfig: &datastore.DebounceConfiguration{KeyPath: "id", Window: 10},
+					},
+				}, nil)
+				s.EXPECT().CompareFlattenedPayload(gomock.Any(), gomock.Any(), gomock.Any(), false).AnyTimes().Return(true, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, datastore.ErrEventNotFound)
+				e.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+				a, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+				endpoint := &datastore.Endpoint{UID: "endpoint-id-1", Url: "https://google.com", Status: datastore.ActiveEndpointStatus}
+				a.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", gomock.Any()).AnyTimes().Return(endpoint, nil)
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().RunInTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+				superseded := ed.EXPECT().SupersedeEventDeliveries(gomock.Any(), "project-id-1", "456", "profile.updated:p_1", gomock.Any()).Times(1).
+					Return([]datastore.EventDelivery{{Metadata: &datastore.Metadata{NextSendTime: time.Now(), CoalescedCount: 2}}}, nil)
+				ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).Times(1).After(superseded).DoAndReturn(
+					func(_ context.Context, deliveries []*datastore.EventDelivery) error {
+						require.Len(t, deliveries, 1)
+						require.Equal(t, "profile.updated:p_1", deliveries[0].DebounceKey)
+						require.Equal(t, uint64(3), deliveries[0].Metadata.CoalescedCount)
+						return nil
+					})
+
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -459,6 +835,10 @@ func TestProcessEventCreated(t *testing.T) {
 				tt.dbFn(args)
 			}
 
+			// events aren't in the catalog unless the case says otherwise
+			et, _ := args.eventTypeRepo.(*mocks.MockEventTypeRepository)
+			et.EXPECT().FindEventTypeByName(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil, datastore.ErrEventTypeNotFound)
+
 			payload, err := json.Marshal(tt.event)
 			require.NoError(t, err)
 
@@ -468,7 +848,7 @@ func TestProcessEventCreated(t *testing.T) {
 
 			task := asynq.NewTask(string(convoy.EventProcessor), job.Payload, asynq.Queue(string(convoy.EventQueue)), asynq.ProcessIn(job.Delay))
 
-			fn := ProcessEventCreation(args.endpointRepo, args.eventRepo, args.projectRepo, args.eventDeliveryRepo, args.eventQueue, args.subRepo, args.deviceRepo)
+			fn := ProcessEventCreation(args.endpointRepo, args.eventRepo, args.projectRepo, args.eventDeliveryRepo, args.eventQueue, args.subRepo, args.deviceRepo, args.eventTypeRepo)
 			err = fn(context.Background(), task)
 			if tt.wantErr {
 				require.NotNil(t, err)
@@ -826,6 +1206,65 @@ func TestMatchSubscriptionsUsingFilter(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Expression Filter",
+			payload: map[string]interface{}{
+				"person": map[string]interface{}{
+					"age":  10,
+					"tags": []string{"admin"},
+				},
+			},
+			dbFn: func(args *args) {
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CompareFlattenedPayload(gomock.Any(), gomock.Any(), gomock.Any(), false).Times(6).Return(true, nil)
+			},
+			inputSubs: []datastore.Subscription{
+				{
+					UID: "123",
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{Expression: `body.person.age >= 10 && "admin" in body.person.tags`},
+					},
+				},
+				{
+					UID: "1234",
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{Expression: `body.person.age < 10`},
+					},
+				},
+				{
+					UID: "12345",
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{Expression: `body.person.name == "raymond"`},
+					},
+				},
+			},
+			wantSubs: []datastore.Subscription{
+				{
+					UID: "123",
+				},
+			},
+		},
+		{
+			name: "Invalid Expression Filter",
+			payload: map[string]interface{}{
+				"person": map[string]interface{}{
+					"age": 10,
+				},
+			},
+			dbFn: func(args *args) {
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CompareFlattenedPayload(gomock.Any(), gomock.Any(), gomock.Any(), false).Times(2).Return(true, nil)
+			},
+			inputSubs: []datastore.Subscription{
+				{
+					UID: "123",
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{Expression: `body.person.age >`},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -856,3 +1295,85 @@ func TestMatchSubscriptionsUsingFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchSubscriptionsUsingEndpointLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	args := provideArgs(ctrl)
+
+	e, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+	e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-eu", "project-1").
+		Times(1).Return(&datastore.Endpoint{UID: "endpoint-eu", Labels: datastore.Labels{"region": "eu", "tier": "enterprise"}}, nil)
+	e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-us", "project-1").
+		Times(1).Return(&datastore.Endpoint{UID: "endpoint-us", Labels: datastore.Labels{"region": "us"}}, nil)
+	e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-deleted", "project-1").
+		Times(1).Return(nil, datastore.ErrEndpointNotFound)
+
+	selector := func(labels datastore.Labels) *datastore.FilterConfiguration {
+		return &datastore.FilterConfiguration{Filter: datastore.FilterSchema{Labels: labels}}
+	}
+
+	subs, err := matchSubscriptionsUsingEndpointLabels(context.Background(), args.endpointRepo, "project-1", []datastore.Subscription{
+		{UID: "no-selector", EndpointID: "endpoint-us", FilterConfig: selector(nil)},
+		{UID: "eu", EndpointID: "endpoint-eu", FilterConfig: selector(datastore.Labels{"region": "eu"})},
+		{UID: "us-enterprise", EndpointID: "endpoint-us", FilterConfig: selector(datastore.Labels{"tier": "enterprise"})},
+		{UID: "deleted", EndpointID: "endpoint-deleted", FilterConfig: selector(datastore.Labels{"region": "eu"})},
+		{UID: "cli", FilterConfig: selector(datastore.Labels{"region": "eu"})},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(subs))
+	require.Equal(t, "no-selector", subs[0].UID)
+	require.Equal(t, "eu", subs[1].UID)
+
+	subs = matchSubscriptionsUsingLabels(&datastore.Endpoint{Labels: datastore.Labels{"region": "eu"}}, []datastore.Subscription{
+		{UID: "eu", FilterConfig: selector(datastore.Labels{"region": "eu"})},
+		{UID: "us", FilterConfig: selector(datastore.Labels{"region": "us"})},
+	})
+	require.Equal(t, 1, len(subs))
+	require.Equal(t, "eu", subs[0].UID)
+}
+
+func TestCreateEvent_OffloadPayload(t *testing.T) {
+	_, err := claimcheck.Init(config.PayloadOffloadConfiguration{Enabled: true, Threshold: 16}, &datastore.StoragePolicyConfiguration{
+		Type:   datastore.OnPrem,
+		OnPrem: &datastore.OnPremStorage{Path: null.StringFrom(t.TempDir())},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = claimcheck.Init(config.PayloadOffloadConfiguration{}, nil)
+	})
+
+	ctx := context.Background()
+	payload := json.RawMessage(`{"name":"a large enough payload"}`)
+
+	event := &datastore.Event{UID: "event-1", ProjectID: "project-1", Data: payload, Raw: string(payload)}
+	ce := CreateEvent{Event: event}
+	require.NoError(t, ce.OffloadPayload(ctx))
+	require.NotEmpty(t, ce.PayloadReference)
+	require.Empty(t, ce.Event.Data)
+	require.Empty(t, ce.Event.Raw)
+
+	// the event it was given keeps its payload
+	require.Equal(t, string(payload), event.Raw)
+
+	require.NoError(t, ce.loadPayload(ctx, "project-1"))
+	require.Equal(t, string(payload), string(ce.Event.Data))
+	require.Equal(t, string(payload), ce.Event.Raw)
+
+	ce.removePayload(ctx)
+	require.NoFileExists(t, ce.PayloadReference)
+
+	params := CreateEvent{Params: CreateEventTaskParams{UID: "event-2", ProjectID: "project-1", Data: payload}}
+	require.NoError(t, params.OffloadPayload(ctx))
+	require.NotEmpty(t, params.PayloadReference)
+	require.Empty(t, params.Params.Data)
+
+	require.NoError(t, params.loadPayload(ctx, "project-1"))
+	require.Equal(t, string(payload), string(params.Params.Data))
+
+	// small payloads are carried in the job
+	small := CreateEvent{Params: CreateEventTaskParams{UID: "event-3", ProjectID: "project-1", Data: json.RawMessage(`{"a":1}`)}}
+	require.NoError(t, small.OffloadPayload(ctx))
+	require.Empty(t, small.PayloadReference)
+}
@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"strconv"
 	"time"
 
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 
 	"github.com/frain-dev/convoy/pkg/msgpack"
 
@@ -97,7 +100,8 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 
 		switch eventDelivery.Status {
 		case datastore.ProcessingEventStatus,
-			datastore.SuccessEventStatus:
+			datastore.SuccessEventStatus,
+			datastore.SupersededEventStatus:
 			return nil
 		}
 
@@ -132,7 +136,12 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			return nil
 		}
 
-		sig := newSignature(endpoint, project, json.RawMessage(eventDelivery.Metadata.Raw))
+		raw, err := claimcheck.Get().DeliveryRaw(ctx, eventDelivery.ProjectID, eventDelivery.Metadata)
+		if err != nil {
+			return &DeliveryError{Err: err}
+		}
+
+		sig := newSignature(endpoint, project, json.RawMessage(raw))
 		header, err := sig.ComputeHeaderValue()
 		if err != nil {
 			return &DeliveryError{Err: err}
@@ -158,6 +167,13 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			eventDelivery.Headers["X-Convoy-Event-ID"] = []string{eventDelivery.EventID}
 		}
 
+		if eventDelivery.Metadata.CoalescedCount > 0 {
+			if eventDelivery.Headers == nil {
+				eventDelivery.Headers = httpheader.HTTPHeader{}
+			}
+			eventDelivery.Headers[coalescedCountHeader] = []string{strconv.FormatUint(eventDelivery.Metadata.CoalescedCount, 10)}
+		}
+
 		var httpDuration time.Duration
 		if endpoint.HttpTimeout == 0 {
 			httpDuration = convoy.HTTP_TIMEOUT_IN_DURATION
@@ -268,12 +284,20 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			}
 		}
 
-		err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(ctx, project.UID, *eventDelivery, attempt)
+		err = redactFinishedDelivery(ctx, project, eventDelivery)
+		if err != nil {
+			log.WithError(err).Error("failed to redact message ", eventDelivery.UID)
+			return &DeliveryError{Err: fmt.Errorf("%s, err: %s", ErrDeliveryAttemptFailed, err.Error())}
+		}
+
+		err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(ctx, project.UID, *eventDelivery, redact.ForProject(project).Attempt(attempt))
 		if err != nil {
 			log.WithError(err).Error("failed to update message ", eventDelivery.UID)
 			return &DeliveryError{Err: fmt.Errorf("%s, err: %s", ErrDeliveryAttemptFailed, err.Error())}
 		}
 
+		queueFallbackDelivery(ctx, q, eventDelivery)
+
 		if !done && eventDelivery.Metadata.NumTrials < eventDelivery.Metadata.RetryLimit {
 			errS := "nil"
 			if err != nil {
@@ -285,3 +309,33 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 		return nil
 	}
 }
+
+// redactFinishedDelivery redacts the payload of a delivery that won't be
+// sent again. A failed delivery keeps it until its fallback is created
+// from it, fallbacks don't have fallbacks of their own.
+func redactFinishedDelivery(ctx context.Context, project *datastore.Project, eventDelivery *datastore.EventDelivery) error {
+	switch {
+	case eventDelivery.Status == datastore.SuccessEventStatus,
+		eventDelivery.Status == datastore.FailureEventStatus && eventDelivery.FallbackForID != "":
+		return redactDelivery(ctx, project, eventDelivery)
+	}
+
+	return nil
+}
+
+// redactDelivery redacts the delivery's payload with the project's rules,
+// an offloaded payload is read from the object store and saved redacted.
+func redactDelivery(ctx context.Context, project *datastore.Project, eventDelivery *datastore.EventDelivery) error {
+	r := redact.ForProject(project)
+	if r == nil {
+		return nil
+	}
+
+	err := claimcheck.Get().LoadDelivery(ctx, eventDelivery)
+	if err != nil {
+		return err
+	}
+
+	eventDelivery.Metadata = r.Metadata(eventDelivery.Metadata)
+	return claimcheck.Get().ReplaceDelivery(ctx, eventDelivery)
+}
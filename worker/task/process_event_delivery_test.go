@@ -15,9 +15,11 @@ import (
 	"github.com/jarcoal/httpmock"
 
 	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"gopkg.in/guregu/null.v4"
 )
 
 func TestProcessEventDelivery(t *testing.T) {
@@ -807,6 +809,9 @@ func TestProcessEventDelivery(t *testing.T) {
 				tc.dbFn(endpointRepo, projectRepo, msgRepo, q, rateLimiter)
 			}
 
+			// failed deliveries are routed to their subscription's fallback
+			q.EXPECT().Write(convoy.FallbackDeliveryProcessor, convoy.EventQueue, gomock.Any()).AnyTimes()
+
 			dispatcher, err := net.NewDispatcher("", false)
 			require.NoError(t, err)
 
@@ -937,3 +942,43 @@ func TestProcessEventDeliveryConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRedactFinishedDelivery_OffloadedPayload(t *testing.T) {
+	_, err := claimcheck.Init(config.PayloadOffloadConfiguration{Enabled: true, Threshold: 16}, &datastore.StoragePolicyConfiguration{
+		Type:   datastore.OnPrem,
+		OnPrem: &datastore.OnPremStorage{Path: null.StringFrom(t.TempDir())},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = claimcheck.Init(config.PayloadOffloadConfiguration{}, nil)
+	})
+
+	ctx := context.Background()
+	project := &datastore.Project{
+		UID: "project-1",
+		Config: &datastore.ProjectConfig{
+			Redaction: datastore.RedactionConfiguration{Paths: []string{"$.email"}},
+		},
+	}
+
+	payload := `{"email":"jane@example.com","name":"a large enough payload"}`
+	ed := &datastore.EventDelivery{
+		UID:       "delivery-1",
+		ProjectID: project.UID,
+		Status:    datastore.SuccessEventStatus,
+		Metadata:  &datastore.Metadata{Raw: payload, Data: []byte(payload)},
+	}
+	require.NoError(t, claimcheck.Get().OffloadDelivery(ctx, nil, ed))
+	require.NotEmpty(t, ed.Metadata.PayloadReference)
+
+	// the delivery is read from the database without its payload
+	ed.Metadata.Raw, ed.Metadata.Data = "", nil
+
+	require.NoError(t, redactFinishedDelivery(ctx, project, ed))
+	require.True(t, ed.Metadata.Redacted)
+
+	saved, err := claimcheck.Get().DeliveryRaw(ctx, project.UID, ed.Metadata)
+	require.NoError(t, err)
+	require.NotContains(t, saved, "jane@example.com")
+	require.Contains(t, saved, "a large enough payload")
+}
@@ -0,0 +1,351 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
+	rqm "github.com/frain-dev/convoy/internal/pkg/pubsub/amqp"
+	"github.com/frain-dev/convoy/internal/pkg/pubsub/kafka"
+	"github.com/frain-dev/convoy/internal/pkg/pubsub/sqs"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/retrystrategies"
+)
+
+// fallbackForHeader tells the fallback destination which delivery failed.
+const fallbackForHeader = "X-Convoy-Fallback-For"
+
+var ErrFallbackPublishFailed = errors.New("fallback delivery publish failed")
+
+// publisher publishes fallback deliveries to a pub sub destination.
+type publisher interface {
+	Publish(ctx context.Context, payload []byte, headers map[string]string) error
+}
+
+// newPublisher returns the client for the destination, google pub sub isn't
+// supported because its config doesn't name a topic.
+var newPublisher = func(cfg *datastore.PubSubConfig) (publisher, error) {
+	switch {
+	case cfg.Type == datastore.SqsPubSub && cfg.Sqs != nil:
+		return &sqs.Sqs{Cfg: cfg.Sqs}, nil
+	case cfg.Type == datastore.KafkaPubSub && cfg.Kafka != nil:
+		return &kafka.Kafka{Cfg: cfg.Kafka}, nil
+	case cfg.Type == datastore.AmqpPubSub && cfg.Amqp != nil:
+		return &rqm.Amqp{Cfg: cfg.Amqp}, nil
+	default:
+		return nil, fmt.Errorf("pub sub type %s is not supported as a fallback destination", cfg.Type)
+	}
+}
+
+// queueFallbackDelivery queues the delivery to be routed to its
+// subscription's fallback, the route job is a no-op when the subscription
+// doesn't have one.
+func queueFallbackDelivery(ctx context.Context, q queue.Queuer, eventDelivery *datastore.EventDelivery) {
+	if eventDelivery.Status != datastore.FailureEventStatus || eventDelivery.FallbackForID != "" {
+		return
+	}
+
+	payload, err := msgpack.EncodeMsgPack(EventDelivery{
+		EventDeliveryID: eventDelivery.UID,
+		ProjectID:       eventDelivery.ProjectID,
+	})
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to encode fallback delivery payload")
+		return
+	}
+
+	job := &queue.Job{
+		ID:      fmt.Sprintf("fallback:%s", eventDelivery.UID),
+		Payload: payload,
+		Delay:   1 * time.Second,
+	}
+
+	err = q.Write(convoy.FallbackDeliveryProcessor, convoy.EventQueue, job)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("[asynq]: an error occurred queueing the fallback for event delivery %s", eventDelivery.UID)
+	}
+}
+
+// ProcessFallbackDelivery creates the fallback delivery of a failed
+// delivery and queues it to be sent to the fallback endpoint, or published
+// to the fallback pub sub destination.
+func ProcessFallbackDelivery(eventDeliveryRepo datastore.EventDeliveryRepository, eventRepo datastore.EventRepository,
+	subRepo datastore.SubscriptionRepository, endpointRepo datastore.EndpointRepository, projectRepo datastore.ProjectRepository, q queue.Queuer,
+) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var data EventDelivery
+
+		err := msgpack.DecodeMsgPack(t.Payload(), &data)
+		if err != nil {
+			err = json.Unmarshal(t.Payload(), &data)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+		}
+
+		eventDelivery, err := eventDeliveryRepo.FindEventDeliveryByIDSlim(ctx, data.ProjectID, data.EventDeliveryID)
+		if err != nil {
+			if errors.Is(err, datastore.ErrEventDeliveryNotFound) {
+				return nil
+			}
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		// fallbacks don't have fallbacks of their own
+		if eventDelivery.Status != datastore.FailureEventStatus || eventDelivery.FallbackForID != "" {
+			return nil
+		}
+
+		project, err := projectRepo.FetchProjectByID(ctx, data.ProjectID)
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		// the failed delivery's payload is redacted once it has no
+		// fallback, or its fallback has been created from it
+		redactPayload := func() error {
+			if redact.ForProject(project) == nil {
+				return nil
+			}
+
+			err := redactDelivery(ctx, project, eventDelivery)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+
+			err = eventDeliveryRepo.UpdateEventDeliveryMetadata(ctx, project.UID, *eventDelivery)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+			return nil
+		}
+
+		subscription, err := subRepo.FindSubscriptionByID(ctx, data.ProjectID, eventDelivery.SubscriptionID)
+		if err != nil {
+			if errors.Is(err, datastore.ErrSubscriptionNotFound) {
+				return redactPayload()
+			}
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		fc := subscription.FallbackConfig
+		if fc == nil || (fc.EndpointID == "" && fc.PubSub == nil) {
+			return redactPayload()
+		}
+
+		ec := &EventDeliveryConfig{project: project, subscription: &datastore.Subscription{RetryConfig: fc.RetryConfig}}
+		rc, err := ec.RetryConfig()
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		// the failed delivery's headers carry its endpoint's credentials,
+		// the fallback's are built from the event's
+		var headers httpheader.HTTPHeader
+		event, err := eventRepo.FindEventByID(ctx, project.UID, eventDelivery.EventID)
+		if err != nil && !errors.Is(err, datastore.ErrEventNotFound) {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		if event != nil {
+			headers = event.Headers
+		}
+
+		fallback := &datastore.EventDelivery{
+			UID:            ulid.Make().String(),
+			ProjectID:      eventDelivery.ProjectID,
+			EventID:        eventDelivery.EventID,
+			SubscriptionID: eventDelivery.SubscriptionID,
+			EventType:      eventDelivery.EventType,
+			Headers:        headers,
+			IdempotencyKey: eventDelivery.IdempotencyKey,
+			URLQueryParams: eventDelivery.URLQueryParams,
+			FallbackForID:  eventDelivery.UID,
+			Metadata: &datastore.Metadata{
+				Raw:              eventDelivery.Metadata.Raw,
+				Data:             eventDelivery.Metadata.Data,
+				PayloadReference: eventDelivery.Metadata.PayloadReference,
+				Strategy:         rc.Type,
+				NextSendTime:     time.Now(),
+				IntervalSeconds:  rc.Duration,
+				RetryLimit:       rc.RetryCount,
+			},
+			Status:           datastore.ScheduledEventStatus,
+			DeliveryAttempts: []datastore.DeliveryAttempt{},
+			AcknowledgedAt:   eventDelivery.AcknowledgedAt,
+		}
+
+		taskName := convoy.FallbackPublishProcessor
+		if fc.PubSub == nil {
+			endpoint, err := endpointRepo.FindEndpointByID(ctx, fc.EndpointID, project.UID)
+			if err != nil {
+				if errors.Is(err, datastore.ErrEndpointNotFound) {
+					log.FromContext(ctx).Errorf("fallback endpoint %s of subscription %s no longer exists", fc.EndpointID, subscription.UID)
+					return redactPayload()
+				}
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+
+			taskName = convoy.EventProcessor
+			fallback.EndpointID = endpoint.UID
+			fallback.Headers = endpointHeaders(endpoint, headers)
+			if endpoint.Status != datastore.ActiveEndpointStatus {
+				fallback.Status = datastore.DiscardedEventStatus
+			}
+		}
+
+		err = eventDeliveryRepo.CreateEventDelivery(ctx, fallback)
+		if err != nil {
+			// the delivery failed again after a manual retry
+			if errors.Is(err, datastore.ErrDuplicateFallbackDelivery) {
+				return redactPayload()
+			}
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		if fallback.Status == datastore.DiscardedEventStatus {
+			return redactPayload()
+		}
+
+		payload, err := msgpack.EncodeMsgPack(EventDelivery{
+			EventDeliveryID: fallback.UID,
+			ProjectID:       fallback.ProjectID,
+		})
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		job := &queue.Job{
+			ID:      fallback.UID,
+			Payload: payload,
+			Delay:   1 * time.Second,
+		}
+
+		// the delivery is scheduled now, it's requeued if this fails
+		err = q.Write(taskName, convoy.EventQueue, job)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("[asynq]: an error occurred queueing fallback delivery %s", fallback.UID)
+		}
+
+		return redactPayload()
+	}
+}
+
+// ProcessFallbackPublish publishes a fallback delivery to its
+// subscription's fallback pub sub destination, it's retried with the
+// delivery's retry config.
+func ProcessFallbackPublish(eventDeliveryRepo datastore.EventDeliveryRepository, subRepo datastore.SubscriptionRepository) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var data EventDelivery
+
+		err := msgpack.DecodeMsgPack(t.Payload(), &data)
+		if err != nil {
+			err = json.Unmarshal(t.Payload(), &data)
+			if err != nil {
+				return &EndpointError{Err: err, delay: defaultDelay}
+			}
+		}
+
+		eventDelivery, err := eventDeliveryRepo.FindEventDeliveryByIDSlim(ctx, data.ProjectID, data.EventDeliveryID)
+		if err != nil {
+			if errors.Is(err, datastore.ErrEventDeliveryNotFound) {
+				return nil
+			}
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		switch eventDelivery.Status {
+		case datastore.ProcessingEventStatus, datastore.SuccessEventStatus:
+			return nil
+		}
+
+		err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, eventDelivery.ProjectID, *eventDelivery, datastore.ProcessingEventStatus)
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+
+		delayDuration := retrystrategies.NewRetryStrategyFromMetadata(*eventDelivery.Metadata).NextDuration(eventDelivery.Metadata.NumTrials)
+
+		err = publishFallback(ctx, subRepo, eventDelivery)
+		eventDelivery.Metadata.NumTrials++
+
+		attempt := datastore.DeliveryAttempt{
+			UID:        ulid.Make().String(),
+			MsgID:      eventDelivery.UID,
+			APIVersion: convoy.GetVersion(),
+			Status:     err == nil,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+
+		if err == nil {
+			eventDelivery.Status = datastore.SuccessEventStatus
+			eventDelivery.Description = ""
+		} else {
+			log.FromContext(ctx).WithError(err).Errorf("failed to publish fallback delivery %s", eventDelivery.UID)
+			attempt.Error = err.Error()
+
+			eventDelivery.Status = datastore.RetryEventStatus
+			eventDelivery.Metadata.NextSendTime = time.Now().Add(delayDuration)
+
+			if eventDelivery.Metadata.NumTrials >= eventDelivery.Metadata.RetryLimit {
+				eventDelivery.Status = datastore.FailureEventStatus
+				eventDelivery.Description = "Retry limit exceeded"
+			}
+		}
+
+		err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(ctx, eventDelivery.ProjectID, *eventDelivery, attempt)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to update fallback delivery ", eventDelivery.UID)
+		}
+
+		if eventDelivery.Status == datastore.RetryEventStatus {
+			return &EndpointError{Err: ErrFallbackPublishFailed, delay: delayDuration}
+		}
+
+		return nil
+	}
+}
+
+func publishFallback(ctx context.Context, subRepo datastore.SubscriptionRepository, eventDelivery *datastore.EventDelivery) error {
+	subscription, err := subRepo.FindSubscriptionByID(ctx, eventDelivery.ProjectID, eventDelivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if subscription.FallbackConfig == nil || subscription.FallbackConfig.PubSub == nil {
+		return errors.New("subscription no longer has a fallback pub sub destination")
+	}
+
+	p, err := newPublisher(subscription.FallbackConfig.PubSub)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string, len(eventDelivery.Headers)+1)
+	for k, v := range eventDelivery.Headers {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+	headers[fallbackForHeader] = eventDelivery.FallbackForID
+
+	data, err := claimcheck.Get().DeliveryData(ctx, eventDelivery.ProjectID, eventDelivery.Metadata)
+	if err != nil {
+		return err
+	}
+
+	return p.Publish(ctx, data, headers)
+}
@@ -0,0 +1,310 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+)
+
+type fakePublisher struct {
+	err     error
+	payload []byte
+	headers map[string]string
+}
+
+func (f *fakePublisher) Publish(_ context.Context, payload []byte, headers map[string]string) error {
+	f.payload = payload
+	f.headers = headers
+	return f.err
+}
+
+func fallbackTask(t *testing.T, taskName convoy.TaskName, eventDeliveryID string) *asynq.Task {
+	payload, err := msgpack.EncodeMsgPack(EventDelivery{EventDeliveryID: eventDeliveryID, ProjectID: "project-1"})
+	require.NoError(t, err)
+
+	return asynq.NewTask(string(taskName), payload)
+}
+
+func failedDelivery() *datastore.EventDelivery {
+	return &datastore.EventDelivery{
+		UID:            "delivery-1",
+		ProjectID:      "project-1",
+		EventID:        "event-1",
+		EndpointID:     "endpoint-1",
+		SubscriptionID: "sub-1",
+		Headers:        httpheader.HTTPHeader{"X-Api-Key": {"endpoint-1-key"}, "X-Trace": {"t_1"}},
+		Status:         datastore.FailureEventStatus,
+		Metadata: &datastore.Metadata{
+			Data:       json.RawMessage(`{"id":"p_1"}`),
+			Raw:        `{"id":"p_1"}`,
+			NumTrials:  3,
+			RetryLimit: 3,
+		},
+	}
+}
+
+func TestProcessFallbackDelivery(t *testing.T) {
+	project := &datastore.Project{
+		UID: "project-1",
+		Config: &datastore.ProjectConfig{
+			Strategy: &datastore.StrategyConfiguration{Type: datastore.LinearStrategyProvider, Duration: 10, RetryCount: 3},
+		},
+	}
+
+	redacted := &datastore.Project{
+		UID: "project-1",
+		Config: &datastore.ProjectConfig{
+			Strategy:  project.Config.Strategy,
+			Redaction: datastore.RedactionConfiguration{Paths: []string{"$.id"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		project  *datastore.Project
+		delivery *datastore.EventDelivery
+		fallback *datastore.FallbackConfiguration
+		dbFn     func(ed *mocks.MockEventDeliveryRepository, e *mocks.MockEndpointRepository, q *mocks.MockQueuer)
+	}{
+		{
+			name:     "should_send_to_fallback_endpoint",
+			delivery: failedDelivery(),
+			fallback: &datastore.FallbackConfiguration{
+				EndpointID:  "endpoint-2",
+				RetryConfig: &datastore.RetryConfiguration{Type: datastore.ExponentialStrategyProvider, Duration: 5, RetryCount: 7},
+			},
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, e *mocks.MockEndpointRepository, q *mocks.MockQueuer) {
+				e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-2", "project-1").
+					Times(1).Return(&datastore.Endpoint{
+					UID:    "endpoint-2",
+					Status: datastore.ActiveEndpointStatus,
+					Authentication: &datastore.EndpointAuthentication{
+						Type:   datastore.APIKeyAuthentication,
+						ApiKey: &datastore.ApiKey{HeaderName: "Authorization", HeaderValue: "endpoint-2-key"},
+					},
+				}, nil)
+
+				ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, d *datastore.EventDelivery) error {
+						require.Equal(t, "delivery-1", d.FallbackForID)
+						require.Equal(t, "endpoint-2", d.EndpointID)
+						require.Equal(t, httpheader.HTTPHeader{"Authorization": {"endpoint-2-key"}, "X-Trace": {"t_1"}}, d.Headers)
+						require.Equal(t, datastore.ScheduledEventStatus, d.Status)
+						require.Equal(t, datastore.ExponentialStrategyProvider, d.Metadata.Strategy)
+						require.Equal(t, uint64(7), d.Metadata.RetryLimit)
+						require.Zero(t, d.Metadata.NumTrials)
+						require.JSONEq(t, `{"id":"p_1"}`, string(d.Metadata.Data))
+						return nil
+					})
+
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name:     "should_publish_to_fallback_pub_sub_with_project_retry_config",
+			delivery: failedDelivery(),
+			fallback: &datastore.FallbackConfiguration{
+				PubSub: &datastore.PubSubConfig{Type: datastore.SqsPubSub, Sqs: &datastore.SQSPubSubConfig{QueueName: "failed"}},
+			},
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, e *mocks.MockEndpointRepository, q *mocks.MockQueuer) {
+				ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, d *datastore.EventDelivery) error {
+						require.True(t, d.IsPubSubFallback())
+						require.Equal(t, httpheader.HTTPHeader{"X-Trace": {"t_1"}}, d.Headers)
+						require.Equal(t, datastore.LinearStrategyProvider, d.Metadata.Strategy)
+						require.Equal(t, uint64(3), d.Metadata.RetryLimit)
+						return nil
+					})
+
+				q.EXPECT().Write(convoy.FallbackPublishProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name:     "should_skip_subscription_without_fallback",
+			delivery: failedDelivery(),
+		},
+		{
+			name:     "should_redact_failed_delivery_without_fallback",
+			project:  redacted,
+			delivery: failedDelivery(),
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, e *mocks.MockEndpointRepository, q *mocks.MockQueuer) {
+				ed.EXPECT().UpdateEventDeliveryMetadata(gomock.Any(), "project-1", gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _ string, d datastore.EventDelivery) error {
+						require.JSONEq(t, `{"id":"[REDACTED]"}`, string(d.Metadata.Data))
+						require.JSONEq(t, `{"id":"[REDACTED]"}`, d.Metadata.Raw)
+						return nil
+					})
+			},
+		},
+		{
+			name:     "should_redact_failed_delivery_after_creating_its_fallback",
+			project:  redacted,
+			delivery: failedDelivery(),
+			fallback: &datastore.FallbackConfiguration{
+				PubSub: &datastore.PubSubConfig{Type: datastore.SqsPubSub, Sqs: &datastore.SQSPubSubConfig{QueueName: "failed"}},
+			},
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, e *mocks.MockEndpointRepository, q *mocks.MockQueuer) {
+				create := ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, d *datastore.EventDelivery) error {
+						// the fallback is sent the payload the delivery failed with
+						require.JSONEq(t, `{"id":"p_1"}`, string(d.Metadata.Data))
+						return nil
+					})
+
+				q.EXPECT().Write(convoy.FallbackPublishProcessor, convoy.EventQueue, gomock.Any()).Times(1).Return(nil)
+
+				ed.EXPECT().UpdateEventDeliveryMetadata(gomock.Any(), "project-1", gomock.Any()).Times(1).After(create).
+					DoAndReturn(func(_ context.Context, _ string, d datastore.EventDelivery) error {
+						require.JSONEq(t, `{"id":"[REDACTED]"}`, string(d.Metadata.Data))
+						return nil
+					})
+			},
+		},
+		{
+			name: "should_not_route_fallback_deliveries",
+			delivery: func() *datastore.EventDelivery {
+				d := failedDelivery()
+				d.FallbackForID = "delivery-0"
+				return d
+			}(),
+			fallback: &datastore.FallbackConfiguration{EndpointID: "endpoint-2"},
+		},
+		{
+			name:     "should_ignore_already_routed_delivery",
+			delivery: failedDelivery(),
+			fallback: &datastore.FallbackConfiguration{EndpointID: "endpoint-2"},
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, e *mocks.MockEndpointRepository, q *mocks.MockQueuer) {
+				e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-2", "project-1").
+					Times(1).Return(&datastore.Endpoint{UID: "endpoint-2", Status: datastore.ActiveEndpointStatus}, nil)
+
+				ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(1).Return(datastore.ErrDuplicateFallbackDelivery)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			eventRepo := mocks.NewMockEventRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+
+			eventDeliveryRepo.EXPECT().FindEventDeliveryByIDSlim(gomock.Any(), "project-1", "delivery-1").Times(1).Return(tt.delivery, nil)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), "project-1", "sub-1").
+				AnyTimes().Return(&datastore.Subscription{UID: "sub-1", FallbackConfig: tt.fallback}, nil)
+			p := project
+			if tt.project != nil {
+				p = tt.project
+			}
+
+			projectRepo.EXPECT().FetchProjectByID(gomock.Any(), "project-1").AnyTimes().Return(p, nil)
+			eventRepo.EXPECT().FindEventByID(gomock.Any(), "project-1", "event-1").
+				AnyTimes().Return(&datastore.Event{UID: "event-1", Headers: httpheader.HTTPHeader{"X-Trace": {"t_1"}}}, nil)
+
+			if tt.dbFn != nil {
+				tt.dbFn(eventDeliveryRepo, endpointRepo, q)
+			}
+
+			processFn := ProcessFallbackDelivery(eventDeliveryRepo, eventRepo, subRepo, endpointRepo, projectRepo, q)
+			err := processFn(context.Background(), fallbackTask(t, convoy.FallbackDeliveryProcessor, "delivery-1"))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProcessFallbackPublish(t *testing.T) {
+	tests := []struct {
+		name       string
+		numTrials  uint64
+		publishErr error
+		wantStatus datastore.EventDeliveryStatus
+		wantErr    bool
+	}{
+		{
+			name:       "should_publish_fallback_delivery",
+			wantStatus: datastore.SuccessEventStatus,
+		},
+		{
+			name:       "should_retry_failed_publish",
+			publishErr: errors.New("queue not found"),
+			wantStatus: datastore.RetryEventStatus,
+			wantErr:    true,
+		},
+		{
+			name:       "should_fail_at_retry_limit",
+			numTrials:  2,
+			publishErr: errors.New("queue not found"),
+			wantStatus: datastore.FailureEventStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			p := &fakePublisher{err: tt.publishErr}
+			defer func(fn func(*datastore.PubSubConfig) (publisher, error)) { newPublisher = fn }(newPublisher)
+			newPublisher = func(*datastore.PubSubConfig) (publisher, error) { return p, nil }
+
+			eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+
+			delivery := &datastore.EventDelivery{
+				UID:            "fallback-1",
+				ProjectID:      "project-1",
+				SubscriptionID: "sub-1",
+				FallbackForID:  "delivery-1",
+				Status:         datastore.ScheduledEventStatus,
+				Metadata: &datastore.Metadata{
+					Data:            json.RawMessage(`{"id":"p_1"}`),
+					Strategy:        datastore.LinearStrategyProvider,
+					IntervalSeconds: 10,
+					NumTrials:       tt.numTrials,
+					RetryLimit:      3,
+				},
+			}
+
+			eventDeliveryRepo.EXPECT().FindEventDeliveryByIDSlim(gomock.Any(), "project-1", "fallback-1").Times(1).Return(delivery, nil)
+			eventDeliveryRepo.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), "project-1", gomock.Any(), datastore.ProcessingEventStatus).Times(1).Return(nil)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), "project-1", "sub-1").Times(1).Return(&datastore.Subscription{
+				UID:            "sub-1",
+				FallbackConfig: &datastore.FallbackConfiguration{PubSub: &datastore.PubSubConfig{Type: datastore.KafkaPubSub}},
+			}, nil)
+			eventDeliveryRepo.EXPECT().UpdateEventDeliveryWithAttempt(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, _ string, d datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+					require.Equal(t, tt.wantStatus, d.Status)
+					require.Equal(t, tt.numTrials+1, d.Metadata.NumTrials)
+					require.Equal(t, tt.publishErr == nil, attempt.Status)
+					return nil
+				})
+
+			processFn := ProcessFallbackPublish(eventDeliveryRepo, subRepo)
+			err := processFn(context.Background(), fallbackTask(t, convoy.FallbackPublishProcessor, "fallback-1"))
+			if tt.wantErr {
+				require.ErrorIs(t, err.(*EndpointError).Err, ErrFallbackPublishFailed)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.JSONEq(t, `{"id":"p_1"}`, string(p.payload))
+			require.Equal(t, "delivery-1", p.headers[fallbackForHeader])
+		})
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	"github.com/frain-dev/convoy/internal/pkg/redact"
 
 	"github.com/frain-dev/convoy/pkg/msgpack"
 
@@ -85,7 +88,8 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 
 		switch eventDelivery.Status {
 		case datastore.ProcessingEventStatus,
-			datastore.SuccessEventStatus:
+			datastore.SuccessEventStatus,
+			datastore.SupersededEventStatus:
 			return nil
 		}
 
@@ -121,7 +125,12 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			return nil
 		}
 
-		sig := newSignature(endpoint, project, json.RawMessage(eventDelivery.Metadata.Raw))
+		raw, err := claimcheck.Get().DeliveryRaw(ctx, eventDelivery.ProjectID, eventDelivery.Metadata)
+		if err != nil {
+			return &DeliveryError{Err: err}
+		}
+
+		sig := newSignature(endpoint, project, json.RawMessage(raw))
 		header, err := sig.ComputeHeaderValue()
 		if err != nil {
 			return &EndpointError{Err: err, delay: defaultEventDelay}
@@ -147,6 +156,13 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			eventDelivery.Headers["X-Convoy-Event-ID"] = []string{eventDelivery.EventID}
 		}
 
+		if eventDelivery.Metadata.CoalescedCount > 0 {
+			if eventDelivery.Headers == nil {
+				eventDelivery.Headers = httpheader.HTTPHeader{}
+			}
+			eventDelivery.Headers[coalescedCountHeader] = []string{strconv.FormatUint(eventDelivery.Metadata.CoalescedCount, 10)}
+		}
+
 		var httpDuration time.Duration
 		if endpoint.HttpTimeout == 0 {
 			httpDuration = convoy.HTTP_TIMEOUT_IN_DURATION
@@ -252,12 +268,20 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			}
 		}
 
-		err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(ctx, project.UID, *eventDelivery, attempt)
+		err = redactFinishedDelivery(ctx, project, eventDelivery)
+		if err != nil {
+			log.WithError(err).Error("failed to redact message ", eventDelivery.UID)
+			return &EndpointError{Err: fmt.Errorf("%s, err: %s", ErrDeliveryAttemptFailed, err.Error()), delay: defaultEventDelay}
+		}
+
+		err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(ctx, project.UID, *eventDelivery, redact.ForProject(project).Attempt(attempt))
 		if err != nil {
 			log.WithError(err).Error("failed to update message ", eventDelivery.UID)
 			return &EndpointError{Err: fmt.Errorf("%s, err: %s", ErrDeliveryAttemptFailed, err.Error()), delay: defaultEventDelay}
 		}
 
+		queueFallbackDelivery(ctx, q, eventDelivery)
+
 		if !done && eventDelivery.Metadata.NumTrials < eventDelivery.Metadata.RetryLimit {
 			errS := "nil"
 			if err != nil {
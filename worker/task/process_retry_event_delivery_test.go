@@ -806,6 +806,9 @@ func TestProcessRetryEventDelivery(t *testing.T) {
 				tc.dbFn(endpointRepo, projectRepo, msgRepo, q, rateLimiter)
 			}
 
+			// failed deliveries are routed to their subscription's fallback
+			q.EXPECT().Write(convoy.FallbackDeliveryProcessor, convoy.EventQueue, gomock.Any()).AnyTimes()
+
 			dispatcher, err := net.NewDispatcher("", false)
 			require.NoError(t, err)
 
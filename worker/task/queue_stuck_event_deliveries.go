@@ -52,7 +52,12 @@ func QueueStuckEventDeliveries(ctx context.Context, edRepo datastore.EventDelive
 				Delay:   1 * time.Second,
 			}
 
-			err = q.Write(convoy.EventProcessor, convoy.EventQueue, job)
+			taskName := convoy.EventProcessor
+			if eventDelivery.IsPubSubFallback() {
+				taskName = convoy.FallbackPublishProcessor
+			}
+
+			err = q.Write(taskName, convoy.EventQueue, job)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Errorf("an error occurred queueing stuck event delivery with id %s", eventDelivery.UID)
 				continue
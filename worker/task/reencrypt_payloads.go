@@ -0,0 +1,110 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/encryption"
+	"github.com/frain-dev/convoy/internal/pkg/rdb"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/go-redsync/redsync/v4"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	reEncryptBatchSize = 500
+
+	// reEncryptMaxBatches caps the batches done for a project in one
+	// run, so a large backlog doesn't hold the lock for too long.
+	reEncryptMaxBatches = 100
+)
+
+// ReEncryptPayloads moves the payloads of events and deliveries to their
+// project's active data key, after a rotation or when encryption is turned
+// on. When encryption is turned off while the master key is still set the
+// payloads are decrypted instead.
+func ReEncryptPayloads(projectRepo datastore.ProjectRepository, eventRepo datastore.EventRepository, eventDeliveryRepo datastore.EventDeliveryRepository, rd *rdb.Redis) func(context.Context, *asynq.Task) error {
+	pool := goredis.NewPool(rd.Client())
+	rs := redsync.New(pool)
+
+	return func(ctx context.Context, t *asynq.Task) error {
+		if encryption.Get() == nil {
+			return nil
+		}
+
+		const mutexName = "convoy:reencrypt:mutex"
+		mutex := rs.NewMutex(mutexName, redsync.WithExpiry(time.Hour), redsync.WithTries(1))
+
+		tctx, cancel := context.WithTimeout(ctx, time.Second*2)
+		defer cancel()
+
+		err := mutex.LockContext(tctx)
+		if err != nil {
+			return fmt.Errorf("failed to obtain lock: %v", err)
+		}
+
+		defer func() {
+			tctx, cancel := context.WithTimeout(ctx, time.Second*2)
+			defer cancel()
+
+			ok, err := mutex.UnlockContext(tctx)
+			if !ok || err != nil {
+				log.WithError(err).Error("failed to release lock")
+			}
+		}()
+
+		projects, err := projectRepo.LoadProjects(ctx, &datastore.ProjectFilter{})
+		if err != nil {
+			return err
+		}
+
+		for _, p := range projects {
+			events, err := reEncrypt(ctx, p.UID, "events", eventRepo.ReEncryptEvents)
+			if err != nil {
+				log.WithError(err).Errorf("failed to re-encrypt events of project %s", p.UID)
+			}
+
+			deliveries, err := reEncrypt(ctx, p.UID, "event deliveries", eventDeliveryRepo.ReEncryptEventDeliveries)
+			if err != nil {
+				log.WithError(err).Errorf("failed to re-encrypt event deliveries of project %s", p.UID)
+			}
+
+			if events > 0 || deliveries > 0 {
+				log.Infof("re-encrypted %d events and %d event deliveries of project %s", events, deliveries, p.UID)
+			}
+		}
+
+		return nil
+	}
+}
+
+// reEncrypt pages through the project's rows by id, so each row is read
+// once a run. Rows that can't be decrypted are reported and skipped.
+func reEncrypt(ctx context.Context, projectID, rows string, fn func(context.Context, string, string, int) (*datastore.ReEncryptBatch, error)) (int64, error) {
+	var total int64
+	var cursor string
+
+	for i := 0; i < reEncryptMaxBatches; i++ {
+		batch, err := fn(ctx, projectID, cursor, reEncryptBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		if len(batch.Failed) > 0 {
+			log.Errorf("failed to decrypt %d %s of project %s, they were skipped: %s", len(batch.Failed), rows, projectID, strings.Join(batch.Failed, ", "))
+		}
+
+		total += batch.ReEncrypted
+		cursor = batch.Cursor
+
+		if batch.Fetched < reEncryptBatchSize {
+			break
+		}
+	}
+
+	return total, nil
+}
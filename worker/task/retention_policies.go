@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/internal/pkg/rdb"
 	"github.com/go-redsync/redsync/v4"
 	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
@@ -13,12 +14,13 @@ import (
 	"github.com/frain-dev/convoy/internal/pkg/exporter"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/claimcheck"
 	objectstore "github.com/frain-dev/convoy/internal/pkg/object-store"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/hibiken/asynq"
 )
 
-func RetentionPolicies(configRepo datastore.ConfigurationRepository, projectRepo datastore.ProjectRepository, eventRepo datastore.EventRepository, eventDeliveryRepo datastore.EventDeliveryRepository, rd *rdb.Redis) func(context.Context, *asynq.Task) error {
+func RetentionPolicies(configRepo datastore.ConfigurationRepository, projectRepo datastore.ProjectRepository, eventRepo datastore.EventRepository, eventDeliveryRepo datastore.EventDeliveryRepository, partitionRepo datastore.PartitionRepository, rd *rdb.Redis) func(context.Context, *asynq.Task) error {
 	pool := goredis.NewPool(rd.Client())
 	rs := redsync.New(pool)
 
@@ -64,6 +66,12 @@ func RetentionPolicies(configRepo datastore.ConfigurationRepository, projectRepo
 			return nil
 		}
 
+		// the partitions past the retention policy are removed once every
+		// project's events were exported, the rows left in the partition
+		// that's partly past it are deleted by the exporters.
+		exported := true
+		exporters := make([]*exporter.Exporter, 0, len(projects))
+
 		for _, p := range projects {
 			exporter, err := exporter.NewExporter(projectRepo, eventRepo, eventDeliveryRepo, p, config)
 			if err != nil {
@@ -72,6 +80,7 @@ func RetentionPolicies(configRepo datastore.ConfigurationRepository, projectRepo
 
 			result, err := exporter.Export(ctx)
 			if err != nil {
+				exported = false
 				log.WithError(err).Errorf("Failed to archive project id's (%s) events ", p.UID)
 			}
 
@@ -90,7 +99,23 @@ func RetentionPolicies(configRepo datastore.ConfigurationRepository, projectRepo
 				}
 			}
 
-			// prune tables and files.
+			exporters = append(exporters, exporter)
+		}
+
+		if exported && config.RetentionPolicy.IsRetentionPolicyEnabled {
+			err = removeExpiredPartitions(ctx, partitionRepo, config.RetentionPolicy.Policy)
+			if err != nil {
+				log.WithError(err).Error("failed to remove expired partitions")
+			}
+
+			err = removeExpiredPayloads(ctx, projects, config.RetentionPolicy.Policy)
+			if err != nil {
+				log.WithError(err).Error("failed to remove expired offloaded payloads")
+			}
+		}
+
+		// prune tables and files.
+		for _, exporter := range exporters {
 			err = exporter.Cleanup(ctx)
 			if err != nil {
 				return err
@@ -101,3 +126,45 @@ func RetentionPolicies(configRepo datastore.ConfigurationRepository, projectRepo
 		return nil
 	}
 }
+
+func removeExpiredPartitions(ctx context.Context, partitionRepo datastore.PartitionRepository, policy string) error {
+	retention, err := time.ParseDuration(policy)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	removed, err := partitionRepo.RemovePartitions(ctx, time.Now().UTC().Add(-retention), cfg.Partition.DetachExpired)
+	if len(removed) > 0 {
+		log.Infof("removed expired partitions %v, detached: %v", removed, cfg.Partition.DetachExpired)
+	}
+
+	return err
+}
+
+// removeExpiredPayloads removes the offloaded payloads of the events and
+// deliveries past the retention policy.
+func removeExpiredPayloads(ctx context.Context, projects []*datastore.Project, policy string) error {
+	retention, err := time.ParseDuration(policy)
+	if err != nil {
+		return err
+	}
+
+	expiry := time.Now().UTC().Add(-retention)
+	for _, p := range projects {
+		removed, err := claimcheck.Get().Prune(ctx, p.UID, expiry)
+		if err != nil {
+			return err
+		}
+
+		if removed > 0 {
+			log.Infof("removed %d expired offloaded payloads of project %s", removed, p.UID)
+		}
+	}
+
+	return nil
+}